@@ -0,0 +1,270 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: api/predictor/v1/predictor.proto
+
+// Package startupcpuboost.predictor.v1 defines the version 1 of the predictor
+// protocol used by the auto resource and duration policies of the
+// kube-startup-cpu-boost. The incompatible changes are introduced in a new
+// package version.
+
+package predictorv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Predictor_Predict_FullMethodName          = "/startupcpuboost.predictor.v1.Predictor/Predict"
+	Predictor_PredictDuration_FullMethodName  = "/startupcpuboost.predictor.v1.Predictor/PredictDuration"
+	Predictor_NotifyReversion_FullMethodName  = "/startupcpuboost.predictor.v1.Predictor/NotifyReversion"
+	Predictor_NotifyReversions_FullMethodName = "/startupcpuboost.predictor.v1.Predictor/NotifyReversions"
+)
+
+// PredictorClient is the client API for Predictor service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Predictor predicts the resource boost of the PODs and learns from the boost
+// outcome.
+type PredictorClient interface {
+	// Predict returns the CPU targets of the POD containers and, optionally, the
+	// boost duration. It is called once per POD, when the POD is admitted.
+	Predict(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (*PredictResponse, error)
+	// PredictDuration returns the boost duration of the POD. It is meant for the
+	// predictors that predict the duration only.
+	PredictDuration(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (*PredictDurationResponse, error)
+	// NotifyReversion notifies the predictor that the resources of the POD were
+	// reverted, so the predictor can learn the actual boost duration.
+	NotifyReversion(ctx context.Context, in *NotifyReversionRequest, opts ...grpc.CallOption) (*NotifyReversionResponse, error)
+	// NotifyReversions notifies the predictor about a batch of POD reversions
+	// sent over the client stream.
+	NotifyReversions(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[NotifyReversionRequest, NotifyReversionsResponse], error)
+}
+
+type predictorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPredictorClient(cc grpc.ClientConnInterface) PredictorClient {
+	return &predictorClient{cc}
+}
+
+func (c *predictorClient) Predict(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (*PredictResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PredictResponse)
+	err := c.cc.Invoke(ctx, Predictor_Predict_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *predictorClient) PredictDuration(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (*PredictDurationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PredictDurationResponse)
+	err := c.cc.Invoke(ctx, Predictor_PredictDuration_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *predictorClient) NotifyReversion(ctx context.Context, in *NotifyReversionRequest, opts ...grpc.CallOption) (*NotifyReversionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NotifyReversionResponse)
+	err := c.cc.Invoke(ctx, Predictor_NotifyReversion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *predictorClient) NotifyReversions(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[NotifyReversionRequest, NotifyReversionsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Predictor_ServiceDesc.Streams[0], Predictor_NotifyReversions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[NotifyReversionRequest, NotifyReversionsResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Predictor_NotifyReversionsClient = grpc.ClientStreamingClient[NotifyReversionRequest, NotifyReversionsResponse]
+
+// PredictorServer is the server API for Predictor service.
+// All implementations must embed UnimplementedPredictorServer
+// for forward compatibility.
+//
+// Predictor predicts the resource boost of the PODs and learns from the boost
+// outcome.
+type PredictorServer interface {
+	// Predict returns the CPU targets of the POD containers and, optionally, the
+	// boost duration. It is called once per POD, when the POD is admitted.
+	Predict(context.Context, *PredictRequest) (*PredictResponse, error)
+	// PredictDuration returns the boost duration of the POD. It is meant for the
+	// predictors that predict the duration only.
+	PredictDuration(context.Context, *PredictRequest) (*PredictDurationResponse, error)
+	// NotifyReversion notifies the predictor that the resources of the POD were
+	// reverted, so the predictor can learn the actual boost duration.
+	NotifyReversion(context.Context, *NotifyReversionRequest) (*NotifyReversionResponse, error)
+	// NotifyReversions notifies the predictor about a batch of POD reversions
+	// sent over the client stream.
+	NotifyReversions(grpc.ClientStreamingServer[NotifyReversionRequest, NotifyReversionsResponse]) error
+	mustEmbedUnimplementedPredictorServer()
+}
+
+// UnimplementedPredictorServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPredictorServer struct{}
+
+func (UnimplementedPredictorServer) Predict(context.Context, *PredictRequest) (*PredictResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Predict not implemented")
+}
+func (UnimplementedPredictorServer) PredictDuration(context.Context, *PredictRequest) (*PredictDurationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PredictDuration not implemented")
+}
+func (UnimplementedPredictorServer) NotifyReversion(context.Context, *NotifyReversionRequest) (*NotifyReversionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NotifyReversion not implemented")
+}
+func (UnimplementedPredictorServer) NotifyReversions(grpc.ClientStreamingServer[NotifyReversionRequest, NotifyReversionsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method NotifyReversions not implemented")
+}
+func (UnimplementedPredictorServer) mustEmbedUnimplementedPredictorServer() {}
+func (UnimplementedPredictorServer) testEmbeddedByValue()                   {}
+
+// UnsafePredictorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PredictorServer will
+// result in compilation errors.
+type UnsafePredictorServer interface {
+	mustEmbedUnimplementedPredictorServer()
+}
+
+func RegisterPredictorServer(s grpc.ServiceRegistrar, srv PredictorServer) {
+	// If the following call pancis, it indicates UnimplementedPredictorServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Predictor_ServiceDesc, srv)
+}
+
+func _Predictor_Predict_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PredictRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PredictorServer).Predict(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Predictor_Predict_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PredictorServer).Predict(ctx, req.(*PredictRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Predictor_PredictDuration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PredictRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PredictorServer).PredictDuration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Predictor_PredictDuration_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PredictorServer).PredictDuration(ctx, req.(*PredictRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Predictor_NotifyReversion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NotifyReversionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PredictorServer).NotifyReversion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Predictor_NotifyReversion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PredictorServer).NotifyReversion(ctx, req.(*NotifyReversionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Predictor_NotifyReversions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PredictorServer).NotifyReversions(&grpc.GenericServerStream[NotifyReversionRequest, NotifyReversionsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Predictor_NotifyReversionsServer = grpc.ClientStreamingServer[NotifyReversionRequest, NotifyReversionsResponse]
+
+// Predictor_ServiceDesc is the grpc.ServiceDesc for Predictor service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Predictor_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "startupcpuboost.predictor.v1.Predictor",
+	HandlerType: (*PredictorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Predict",
+			Handler:    _Predictor_Predict_Handler,
+		},
+		{
+			MethodName: "PredictDuration",
+			Handler:    _Predictor_PredictDuration_Handler,
+		},
+		{
+			MethodName: "NotifyReversion",
+			Handler:    _Predictor_NotifyReversion_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "NotifyReversions",
+			Handler:       _Predictor_NotifyReversions_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "api/predictor/v1/predictor.proto",
+}
@@ -50,12 +50,95 @@ type PodConditionDurationPolicy struct {
 	Status corev1.ConditionStatus `json:"status,omitempty"`
 }
 
+// ContainerStatusCondition defines the container status condition checked
+// in a container status duration policy
+// +kubebuilder:validation:Enum=Started;Ready
+type ContainerStatusCondition string
+
+const (
+	ContainerStatusConditionStarted ContainerStatusCondition = "Started"
+	ContainerStatusConditionReady   ContainerStatusCondition = "Ready"
+)
+
+// ContainerStatusDurationPolicy defines the container status based
+// duration policy
+type ContainerStatusDurationPolicy struct {
+	// condition of a container status to check in a policy, i.e. Started
+	// once the container startup probe succeeds
+	// +kubebuilder:validation:Required
+	Condition ContainerStatusCondition `json:"condition,omitempty"`
+}
+
 // AutoDurationPolicy defines the autoPolicy based duration policy
 type AutoDurationPolicy struct {
 	// Metric specifies the metric to be used for automatic adjustment
 	ApiEndpoint string `json:"apiEndpoint,omitempty"`
+	// Timeout specifies the time limit of a predictor call, including the
+	// retries. Defaults to 1s
+	// +kubebuilder:validation:Optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Fallback specifies the boost duration applied when the prediction
+	// fails, i.e. the predictor errors, times out or returns invalid value.
+	// The duration is predicted once, when the POD is admitted. Defaults
+	// to 30s
+	// +kubebuilder:validation:Optional
+	Fallback *FixedDurationPolicy `json:"fallback,omitempty"`
+	// Service references the predictor Service, as an alternative to the
+	// ApiEndpoint. The predictor is called over HTTPS
+	// +kubebuilder:validation:Optional
+	Service *PredictorServiceReference `json:"service,omitempty"`
+	// CABundle is a PEM encoded CA bundle used to verify the predictor
+	// serving certificate. Defaults to the system trust roots
+	// +kubebuilder:validation:Optional
+	CABundle []byte `json:"caBundle,omitempty"`
+	// SecretRef references the Secret with the predictor credentials
+	// +kubebuilder:validation:Optional
+	SecretRef *PredictorSecretReference `json:"secretRef,omitempty"`
+}
+
+// LearnedDurationPolicy defines the duration policy learned by the controller
+// from the time the previous PODs of the same workload took to meet the
+// POD condition
+type LearnedDurationPolicy struct {
+	// condition is the type of a POD condition which time since the POD
+	// creation is learned. Defaults to Ready
+	// +kubebuilder:validation:Optional
+	Condition corev1.PodConditionType `json:"condition,omitempty"`
+	// percentile of the learned durations used as the boost duration.
+	// Defaults to 90
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=100
+	Percentile int32 `json:"percentile,omitempty"`
+	// margin is added to the percentile of the learned durations
+	// +kubebuilder:validation:Optional
+	Margin *metav1.Duration `json:"margin,omitempty"`
+	// minSamples is the number of the learned durations needed to apply
+	// the percentile. Defaults to 5
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum:=1
+	MinSamples int32 `json:"minSamples,omitempty"`
+	// maxSamples is the number of the latest durations kept per workload.
+	// Defaults to 50
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=1000
+	MaxSamples int32 `json:"maxSamples,omitempty"`
+	// fallback specifies the boost duration applied until there are enough
+	// learned durations of the workload
+	// +kubebuilder:validation:Required
+	Fallback FixedDurationPolicy `json:"fallback"`
 }
 
+// DurationPolicyOperator defines how the duration policies are combined
+// +kubebuilder:validation:Enum=Any;All
+type DurationPolicyOperator string
+
+const (
+	DurationPolicyOperatorAny DurationPolicyOperator = "Any"
+	DurationPolicyOperatorAll DurationPolicyOperator = "All"
+)
+
 // DurationPolicy defines the policy used to determine the duration
 // time of a resource boost
 type DurationPolicy struct {
@@ -68,21 +151,35 @@ type DurationPolicy struct {
 	// autoPolicy based duration policy
 	// +kubebuilder:validation:Optional
 	AutoPolicy *AutoDurationPolicy `json:"autoPolicy,omitempty"`
+	// containerStatus based duration policy. The policy is evaluated for each
+	// container separately, and the resources of a container are reverted as
+	// soon as its status matches
+	// +kubebuilder:validation:Optional
+	ContainerStatus *ContainerStatusDurationPolicy `json:"containerStatus,omitempty"`
+	// learned duration policy. The duration is learned from the previous PODs
+	// of the workload and stored in the ConfigMap of the StartupCPUBoost
+	// +kubebuilder:validation:Optional
+	Learned *LearnedDurationPolicy `json:"learned,omitempty"`
+	// operator defines how multiple duration policies are combined. With Any,
+	// the boost ends when any of the policies is met. With All, the boost ends
+	// when all of the policies are met. Defaults to Any
+	// +kubebuilder:validation:Optional
+	Operator DurationPolicyOperator `json:"operator,omitempty"`
 }
 
-// FixedResources defines the CPU resource policy that sets CPU resources
-// to the given values
+// FixedResources defines the resource policy that sets CPU or memory
+// resources to the given values
 type FixedResources struct {
-	// Requests specifies the CPU requests
+	// Requests specifies the resource requests
 	// +kubebuilder:validation:Required
 	Requests resource.Quantity `json:"requests,omitempty"`
-	// Limits specifies the CPU requests
+	// Limits specifies the resource limits
 	// +kubebuilder:validation:Optional
 	Limits resource.Quantity `json:"limits,omitempty"`
 }
 
-// PercentageIncrease defines the CPU resource policy that increases
-// CPU resources by the given percentage value
+// PercentageIncrease defines the resource policy that increases CPU or
+// memory resources by the given percentage value
 type PercentageIncrease struct {
 	// Value specifies the percentage value
 	// +kubebuilder:validation:Required
@@ -95,14 +192,136 @@ type PercentageIncrease struct {
 type AutoResourcePolicy struct {
 	// Metric specifies the metric to be used for automatic adjustment
 	ApiEndpoint string `json:"apiEndpoint,omitempty"`
+	// Timeout specifies the time limit of a predictor call, including the
+	// retries. The call is made during the POD admission, so the timeout
+	// should fit in the admission webhook time limit. Defaults to 1s
+	// +kubebuilder:validation:Optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Fallback specifies the CPU resource policy applied when the prediction
+	// fails, i.e. the predictor errors, times out or returns invalid values.
+	// The container is not boosted when the prediction fails and the fallback
+	// policy is not defined
+	// +kubebuilder:validation:Optional
+	Fallback *AutoResourceFallbackPolicy `json:"fallback,omitempty"`
+	// Service references the predictor Service, as an alternative to the
+	// ApiEndpoint. The predictor is called over HTTPS
+	// +kubebuilder:validation:Optional
+	Service *PredictorServiceReference `json:"service,omitempty"`
+	// CABundle is a PEM encoded CA bundle used to verify the predictor
+	// serving certificate. Defaults to the system trust roots
+	// +kubebuilder:validation:Optional
+	CABundle []byte `json:"caBundle,omitempty"`
+	// SecretRef references the Secret with the predictor credentials
+	// +kubebuilder:validation:Optional
+	SecretRef *PredictorSecretReference `json:"secretRef,omitempty"`
+}
+
+// PredictorServiceReference references the Service of the predictor, as in
+// the client configuration of the admission webhooks
+type PredictorServiceReference struct {
+	// Namespace is the namespace of the Service. Defaults to the namespace of
+	// the StartupCPUBoost
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the name of the Service
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// Path is the URL path prefix of the predictor API
+	// +kubebuilder:validation:Optional
+	Path *string `json:"path,omitempty"`
+	// Port is the port of the Service. Defaults to 443
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=65535
+	Port *int32 `json:"port,omitempty"`
+}
+
+// PredictorSecretReference references the Secret with the credentials of the
+// predictor calls. The Secret holds a bearer token under the "token" key, or
+// a client certificate and key under the "tls.crt" and "tls.key" keys, and
+// optionally a CA bundle under the "ca.crt" key. The credentials are reloaded
+// when the Secret changes
+type PredictorSecretReference struct {
+	// Namespace is the namespace of the Secret. Defaults to the namespace of
+	// the StartupCPUBoost, which is the only one allowed
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the name of the Secret
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
+// AutoResourceFallbackPolicy defines the CPU resource policy applied when
+// the auto resource policy fails to get the prediction
+type AutoResourceFallbackPolicy struct {
+	// PercentageIncrease specifies the CPU resource policy that increases
+	// CPU resources by the given percentage value
+	// +kubebuilder:validation:Optional
+	PercentageIncrease *PercentageIncrease `json:"percentageIncrease,omitempty"`
+	// FixedResources specifies the CPU resource policy that sets the CPU
+	// resources to the given values
+	// +kubebuilder:validation:Optional
+	FixedResources *FixedResources `json:"fixedResources,omitempty"`
+}
+
+// LearnedResourcePolicy defines the CPU resource policy which CPU target is
+// learned from the previous startups of the same workload, i.e. the PODs with
+// the same top-level owner and container images. The time the PODs took to
+// become ready is observed against the boosted CPU, so the lowest CPU target
+// that does not slow down the startup is chosen. The target starts at the
+// minimum and is raised while the startups get faster
+type LearnedResourcePolicy struct {
+	// Min specifies the lowest CPU target, which is also the initial one
+	// +kubebuilder:validation:Required
+	Min resource.Quantity `json:"min"`
+	// Max specifies the highest CPU target
+	// +kubebuilder:validation:Required
+	Max resource.Quantity `json:"max"`
+	// MinSamples specifies the number of startups observed with a CPU target
+	// before it is compared with the other targets. Defaults to 3
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum:=1
+	MinSamples int32 `json:"minSamples,omitempty"`
+	// MaxSamples specifies the number of the latest startups kept per workload
+	// container. Defaults to 50
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=1000
+	MaxSamples int32 `json:"maxSamples,omitempty"`
+	// UseCPUUsage specifies if the CPU usage of the containers, read from
+	// the metrics.k8s.io API when the POD becomes ready, lowers the CPU target
+	// that exceeds the usage
+	// +kubebuilder:validation:Optional
+	UseCPUUsage bool `json:"useCPUUsage,omitempty"`
+}
+
+// StartupProfilePolicy defines the recording of the POD startups in the
+// StartupProfiles of their workloads, i.e. the PODs with the same top-level
+// owner and container images. The profile holds the boost applied to the POD
+// containers, the time the POD took to get scheduled, started and ready, and
+// the time its resources were reverted
+type StartupProfilePolicy struct {
+	// MaxSamples specifies the number of the latest startups kept per workload.
+	// Defaults to 20
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=100
+	MaxSamples int32 `json:"maxSamples,omitempty"`
 }
 
 // ContainerPolicy defines the policy used to determine the target
 // resources for a container
 type ContainerPolicy struct {
-	// ContainerName specifies the name of container for a given policy
-	// +kubebuilder:validation:Required
+	// ContainerName specifies the name of container for a given policy.
+	// The init containers and sidecar containers can be targeted as well.
+	// The name can be a glob pattern, i.e. "app-*", and the "*" name defines
+	// the default policy for all containers
+	// +kubebuilder:validation:Optional
 	ContainerName string `json:"containerName,omitempty"`
+	// ContainerNameRegex specifies the regular expression matching the whole
+	// names of containers for a given policy
+	// +kubebuilder:validation:Optional
+	ContainerNameRegex string `json:"containerNameRegex,omitempty"`
 	// PercentageIncrease specifies the CPU resource policy that increases
 	// CPU resources by the given percentage value
 	// +kubebuilder:validation:Optional
@@ -115,6 +334,19 @@ type ContainerPolicy struct {
 	// CPU resources based on certain metrics or conditions
 	// +kubebuilder:validation:Optional
 	AutoPolicy *AutoResourcePolicy `json:"autoPolicy,omitempty"`
+	// LearnedResources specifies the CPU resource policy that sets the CPU
+	// resources to the target learned from the previous startups of the
+	// same workload
+	// +kubebuilder:validation:Optional
+	LearnedResources *LearnedResourcePolicy `json:"learnedResources,omitempty"`
+	// MemoryPercentageIncrease specifies the memory resource policy that
+	// increases memory resources by the given percentage value
+	// +kubebuilder:validation:Optional
+	MemoryPercentageIncrease *PercentageIncrease `json:"memoryPercentageIncrease,omitempty"`
+	// MemoryFixedResources specifies the memory resource policy that sets
+	// the memory resources to the given values
+	// +kubebuilder:validation:Optional
+	MemoryFixedResources *FixedResources `json:"memoryFixedResources,omitempty"`
 }
 
 // ResourcePolicy defines the policy used to determine the target
@@ -124,6 +356,23 @@ type ResourcePolicy struct {
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MinItems:=1
 	ContainerPolicies []ContainerPolicy `json:"containerPolicies,omitempty"`
+	// ExcludedContainers specifies the names or glob patterns of containers
+	// that are not boosted regardless of the container policies, i.e. the
+	// injected sidecar containers
+	// +kubebuilder:validation:Optional
+	ExcludedContainers []string `json:"excludedContainers,omitempty"`
+}
+
+// BoostPhase defines a step of the CPU resource boost ramp-down
+type BoostPhase struct {
+	// PercentageIncrease specifies the increase of the original CPU resources
+	// during the phase
+	// +kubebuilder:validation:Required
+	PercentageIncrease PercentageIncrease `json:"percentageIncrease,omitempty"`
+	// DurationPolicy specifies the duration of the phase. The fixed duration
+	// is measured since the phase start
+	// +kubebuilder:validation:Required
+	DurationPolicy DurationPolicy `json:"durationPolicy,omitempty"`
 }
 
 // StartupCPUBoostSpec defines the desired state of StartupCPUBoost
@@ -133,6 +382,21 @@ type StartupCPUBoostSpec struct {
 	// DurationPolicy specifies policies for resource boost duration
 	// +kubebuilder:validation:Required
 	DurationPolicy DurationPolicy `json:"durationPolicy,omitempty"`
+	// Priority specifies the precedence of the StartupCPUBoost when several
+	// of them match a POD. The StartupCPUBoost with the highest priority
+	// is used and the ties are resolved by the name order. Defaults to 0
+	// +kubebuilder:validation:Optional
+	Priority int32 `json:"priority,omitempty"`
+	// Phases specifies the ordered steps of the CPU resource ramp-down that
+	// follow the end of the duration policy. The CPU resources are reverted
+	// to the original values once the last phase ends
+	// +kubebuilder:validation:Optional
+	Phases []BoostPhase `json:"phases,omitempty"`
+	// StartupProfile specifies the recording of the POD startups in the
+	// StartupProfiles of their workloads. The startups are not recorded
+	// when not set
+	// +kubebuilder:validation:Optional
+	StartupProfile *StartupProfilePolicy `json:"startupProfile,omitempty"`
 }
 
 // StartupCPUBoostStatus defines the observed state of StartupCPUBoost
@@ -146,6 +410,14 @@ type StartupCPUBoostStatus struct {
 	// resources were increased by the StartupCPUBoost
 	// +kubebuilder:validation:Optional
 	TotalContainerBoosts int32 `json:"totalContainerBoosts,omitempty"`
+	// LearnedResources holds the latest CPU targets chosen by the learned
+	// resource policies, per workload and container, with the reason of
+	// the choice. At most 32 latest targets are kept
+	// +optional
+	// +listType=map
+	// +listMapKey=workload
+	// +listMapKey=container
+	LearnedResources []LearnedResourceStatus `json:"learnedResources,omitempty"`
 	// Conditions hold the latest available observations of the StartupCPUBoost
 	// current state.
 	// +optional
@@ -156,6 +428,29 @@ type StartupCPUBoostStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
+// LearnedResourceStatus describes the CPU target chosen by the learned resource
+// policy for a container of a workload
+type LearnedResourceStatus struct {
+	// Workload is the key of the workload, i.e. the kind and name of the POD
+	// top-level owner followed by the hash of the container images
+	Workload string `json:"workload"`
+	// Container is the name of the container
+	Container string `json:"container"`
+	// CPU is the chosen CPU target
+	CPU resource.Quantity `json:"cpu"`
+	// Samples is the number of the startups the choice is based on
+	// +optional
+	Samples int32 `json:"samples,omitempty"`
+	// Reason is a brief CamelCase reason of the choice, one of Initial,
+	// Sampling, Exploring, StartupDuration or CPUUsage
+	Reason string `json:"reason"`
+	// Message is a human readable explanation of the choice
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastUpdateTime is the time the target was chosen
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+}
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 
@@ -0,0 +1,183 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StartupProfileOwner identifies the top-level owner of the workload PODs,
+// i.e. Deployment or StatefulSet
+type StartupProfileOwner struct {
+	// APIVersion is the API version of the owner
+	APIVersion string `json:"apiVersion"`
+	// Kind is the kind of the owner
+	Kind string `json:"kind"`
+	// Name is the name of the owner
+	Name string `json:"name"`
+}
+
+// StartupProfileSpec identifies the workload which startups are recorded
+// in the StartupProfile
+type StartupProfileSpec struct {
+	// BoostName is the name of the StartupCPUBoost that boosts the workload
+	// PODs and records their startups
+	BoostName string `json:"boostName"`
+	// Workload is the key of the workload, i.e. the kind and name of the POD
+	// top-level owner, or the hash of the container images of the PODs without
+	// the owner
+	Workload string `json:"workload"`
+	// Owner is the top-level owner of the workload PODs, if any
+	// +optional
+	Owner *StartupProfileOwner `json:"owner,omitempty"`
+}
+
+// ContainerBoostSample describes the CPU resource boost applied to a container
+// of the POD
+type ContainerBoostSample struct {
+	// Name is the name of the container
+	Name string `json:"name"`
+	// Policy is the name of the resource policy that boosted the container
+	// +optional
+	Policy string `json:"policy,omitempty"`
+	// CPURequests are the boosted CPU requests of the container
+	// +optional
+	CPURequests *resource.Quantity `json:"cpuRequests,omitempty"`
+	// CPULimits are the boosted CPU limits of the container
+	// +optional
+	CPULimits *resource.Quantity `json:"cpuLimits,omitempty"`
+	// OriginalCPURequests are the CPU requests of the container before the boost
+	// +optional
+	OriginalCPURequests *resource.Quantity `json:"originalCPURequests,omitempty"`
+	// OriginalCPULimits are the CPU limits of the container before the boost
+	// +optional
+	OriginalCPULimits *resource.Quantity `json:"originalCPULimits,omitempty"`
+}
+
+// StartupSample describes the startup of a POD boosted by the StartupCPUBoost.
+// The times are measured since the POD creation and are not set when the POD
+// was deleted before reaching them
+type StartupSample struct {
+	// PodName is the name of the POD
+	PodName string `json:"podName"`
+	// NodeName is the name of the node the POD was scheduled to
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+	// ImagesHash is the hash of the container images of the POD, which
+	// distinguishes the startups of the workload versions
+	// +optional
+	ImagesHash string `json:"imagesHash,omitempty"`
+	// CreationTime is the creation time of the POD
+	CreationTime metav1.Time `json:"creationTime"`
+	// Containers describe the boost applied to the POD containers
+	// +optional
+	Containers []ContainerBoostSample `json:"containers,omitempty"`
+	// TimeToScheduled is the time the POD took to get scheduled
+	// +optional
+	TimeToScheduled *metav1.Duration `json:"timeToScheduled,omitempty"`
+	// TimeToStarted is the time the POD took to get all of its containers
+	// started
+	// +optional
+	TimeToStarted *metav1.Duration `json:"timeToStarted,omitempty"`
+	// TimeToReady is the time the POD took to become ready
+	// +optional
+	TimeToReady *metav1.Duration `json:"timeToReady,omitempty"`
+	// RevertTime is the time the POD resources were reverted to the original
+	// values
+	// +optional
+	RevertTime *metav1.Time `json:"revertTime,omitempty"`
+}
+
+// DurationSummary holds the statistics of the durations of the startup samples
+type DurationSummary struct {
+	// Samples is the number of the samples with the duration
+	Samples int32 `json:"samples"`
+	// Min is the shortest duration
+	Min metav1.Duration `json:"min"`
+	// Median is the median of the durations
+	Median metav1.Duration `json:"median"`
+	// P90 is the 90th percentile of the durations
+	P90 metav1.Duration `json:"p90"`
+	// Max is the longest duration
+	Max metav1.Duration `json:"max"`
+}
+
+// StartupProfileSummary holds the statistics of the startup samples of the
+// StartupProfile
+type StartupProfileSummary struct {
+	// TimeToScheduled summarizes the time the PODs took to get scheduled
+	// +optional
+	TimeToScheduled *DurationSummary `json:"timeToScheduled,omitempty"`
+	// TimeToStarted summarizes the time the PODs took to get their containers
+	// started
+	// +optional
+	TimeToStarted *DurationSummary `json:"timeToStarted,omitempty"`
+	// TimeToReady summarizes the time the PODs took to become ready
+	// +optional
+	TimeToReady *DurationSummary `json:"timeToReady,omitempty"`
+	// BoostDuration summarizes the time from the POD creation to the revert
+	// of its resources
+	// +optional
+	BoostDuration *DurationSummary `json:"boostDuration,omitempty"`
+}
+
+// StartupProfileStatus defines the recorded startups of the StartupProfile
+type StartupProfileStatus struct {
+	// Samples holds the latest startups of the workload PODs, from the oldest
+	// to the latest. The oldest startups are dropped when there are more than
+	// the maximum number of samples of the StartupCPUBoost startup profile
+	// policy
+	// +optional
+	// +kubebuilder:validation:MaxItems:=100
+	Samples []StartupSample `json:"samples,omitempty"`
+	// TotalSamples is the number of all startups recorded in the profile,
+	// including the dropped ones
+	// +optional
+	TotalSamples int32 `json:"totalSamples,omitempty"`
+	// Summary holds the statistics of the startups kept in the profile
+	// +optional
+	Summary StartupProfileSummary `json:"summary,omitempty"`
+	// LastUpdateTime is the time the latest startup was recorded
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// StartupProfile is the Schema for the startupprofiles API. The profile is
+// maintained by the controller for every workload boosted by a StartupCPUBoost
+// with the startup profile policy, and is owned by the StartupCPUBoost
+type StartupProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   StartupProfileSpec   `json:"spec,omitempty"`
+	Status StartupProfileStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// StartupProfileList contains a list of StartupProfile
+type StartupProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []StartupProfile `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&StartupProfile{}, &StartupProfileList{})
+}
@@ -26,6 +26,31 @@ import (
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AutoDurationPolicy) DeepCopyInto(out *AutoDurationPolicy) {
 	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Fallback != nil {
+		in, out := &in.Fallback, &out.Fallback
+		*out = new(FixedDurationPolicy)
+		**out = **in
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(PredictorServiceReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(PredictorSecretReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoDurationPolicy.
@@ -38,9 +63,59 @@ func (in *AutoDurationPolicy) DeepCopy() *AutoDurationPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoResourceFallbackPolicy) DeepCopyInto(out *AutoResourceFallbackPolicy) {
+	*out = *in
+	if in.PercentageIncrease != nil {
+		in, out := &in.PercentageIncrease, &out.PercentageIncrease
+		*out = new(PercentageIncrease)
+		**out = **in
+	}
+	if in.FixedResources != nil {
+		in, out := &in.FixedResources, &out.FixedResources
+		*out = new(FixedResources)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoResourceFallbackPolicy.
+func (in *AutoResourceFallbackPolicy) DeepCopy() *AutoResourceFallbackPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoResourceFallbackPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AutoResourcePolicy) DeepCopyInto(out *AutoResourcePolicy) {
 	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Fallback != nil {
+		in, out := &in.Fallback, &out.Fallback
+		*out = new(AutoResourceFallbackPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(PredictorServiceReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(PredictorSecretReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoResourcePolicy.
@@ -53,6 +128,58 @@ func (in *AutoResourcePolicy) DeepCopy() *AutoResourcePolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BoostPhase) DeepCopyInto(out *BoostPhase) {
+	*out = *in
+	out.PercentageIncrease = in.PercentageIncrease
+	in.DurationPolicy.DeepCopyInto(&out.DurationPolicy)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BoostPhase.
+func (in *BoostPhase) DeepCopy() *BoostPhase {
+	if in == nil {
+		return nil
+	}
+	out := new(BoostPhase)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerBoostSample) DeepCopyInto(out *ContainerBoostSample) {
+	*out = *in
+	if in.CPURequests != nil {
+		in, out := &in.CPURequests, &out.CPURequests
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.CPULimits != nil {
+		in, out := &in.CPULimits, &out.CPULimits
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.OriginalCPURequests != nil {
+		in, out := &in.OriginalCPURequests, &out.OriginalCPURequests
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.OriginalCPULimits != nil {
+		in, out := &in.OriginalCPULimits, &out.OriginalCPULimits
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerBoostSample.
+func (in *ContainerBoostSample) DeepCopy() *ContainerBoostSample {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerBoostSample)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContainerPolicy) DeepCopyInto(out *ContainerPolicy) {
 	*out = *in
@@ -69,8 +196,23 @@ func (in *ContainerPolicy) DeepCopyInto(out *ContainerPolicy) {
 	if in.AutoPolicy != nil {
 		in, out := &in.AutoPolicy, &out.AutoPolicy
 		*out = new(AutoResourcePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LearnedResources != nil {
+		in, out := &in.LearnedResources, &out.LearnedResources
+		*out = new(LearnedResourcePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MemoryPercentageIncrease != nil {
+		in, out := &in.MemoryPercentageIncrease, &out.MemoryPercentageIncrease
+		*out = new(PercentageIncrease)
 		**out = **in
 	}
+	if in.MemoryFixedResources != nil {
+		in, out := &in.MemoryFixedResources, &out.MemoryFixedResources
+		*out = new(FixedResources)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerPolicy.
@@ -83,6 +225,21 @@ func (in *ContainerPolicy) DeepCopy() *ContainerPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerStatusDurationPolicy) DeepCopyInto(out *ContainerStatusDurationPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerStatusDurationPolicy.
+func (in *ContainerStatusDurationPolicy) DeepCopy() *ContainerStatusDurationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerStatusDurationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DurationPolicy) DeepCopyInto(out *DurationPolicy) {
 	*out = *in
@@ -99,8 +256,18 @@ func (in *DurationPolicy) DeepCopyInto(out *DurationPolicy) {
 	if in.AutoPolicy != nil {
 		in, out := &in.AutoPolicy, &out.AutoPolicy
 		*out = new(AutoDurationPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ContainerStatus != nil {
+		in, out := &in.ContainerStatus, &out.ContainerStatus
+		*out = new(ContainerStatusDurationPolicy)
 		**out = **in
 	}
+	if in.Learned != nil {
+		in, out := &in.Learned, &out.Learned
+		*out = new(LearnedDurationPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DurationPolicy.
@@ -113,6 +280,25 @@ func (in *DurationPolicy) DeepCopy() *DurationPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DurationSummary) DeepCopyInto(out *DurationSummary) {
+	*out = *in
+	out.Min = in.Min
+	out.Median = in.Median
+	out.P90 = in.P90
+	out.Max = in.Max
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DurationSummary.
+func (in *DurationSummary) DeepCopy() *DurationSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(DurationSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FixedDurationPolicy) DeepCopyInto(out *FixedDurationPolicy) {
 	*out = *in
@@ -145,6 +331,61 @@ func (in *FixedResources) DeepCopy() *FixedResources {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LearnedDurationPolicy) DeepCopyInto(out *LearnedDurationPolicy) {
+	*out = *in
+	if in.Margin != nil {
+		in, out := &in.Margin, &out.Margin
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	out.Fallback = in.Fallback
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LearnedDurationPolicy.
+func (in *LearnedDurationPolicy) DeepCopy() *LearnedDurationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(LearnedDurationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LearnedResourcePolicy) DeepCopyInto(out *LearnedResourcePolicy) {
+	*out = *in
+	out.Min = in.Min.DeepCopy()
+	out.Max = in.Max.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LearnedResourcePolicy.
+func (in *LearnedResourcePolicy) DeepCopy() *LearnedResourcePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(LearnedResourcePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LearnedResourceStatus) DeepCopyInto(out *LearnedResourceStatus) {
+	*out = *in
+	out.CPU = in.CPU.DeepCopy()
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LearnedResourceStatus.
+func (in *LearnedResourceStatus) DeepCopy() *LearnedResourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LearnedResourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PercentageIncrease) DeepCopyInto(out *PercentageIncrease) {
 	*out = *in
@@ -175,6 +416,46 @@ func (in *PodConditionDurationPolicy) DeepCopy() *PodConditionDurationPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PredictorSecretReference) DeepCopyInto(out *PredictorSecretReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PredictorSecretReference.
+func (in *PredictorSecretReference) DeepCopy() *PredictorSecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(PredictorSecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PredictorServiceReference) DeepCopyInto(out *PredictorServiceReference) {
+	*out = *in
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = new(string)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PredictorServiceReference.
+func (in *PredictorServiceReference) DeepCopy() *PredictorServiceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(PredictorServiceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourcePolicy) DeepCopyInto(out *ResourcePolicy) {
 	*out = *in
@@ -185,6 +466,11 @@ func (in *ResourcePolicy) DeepCopyInto(out *ResourcePolicy) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ExcludedContainers != nil {
+		in, out := &in.ExcludedContainers, &out.ExcludedContainers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourcePolicy.
@@ -262,6 +548,18 @@ func (in *StartupCPUBoostSpec) DeepCopyInto(out *StartupCPUBoostSpec) {
 	*out = *in
 	in.ResourcePolicy.DeepCopyInto(&out.ResourcePolicy)
 	in.DurationPolicy.DeepCopyInto(&out.DurationPolicy)
+	if in.Phases != nil {
+		in, out := &in.Phases, &out.Phases
+		*out = make([]BoostPhase, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartupProfile != nil {
+		in, out := &in.StartupProfile, &out.StartupProfile
+		*out = new(StartupProfilePolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartupCPUBoostSpec.
@@ -277,6 +575,13 @@ func (in *StartupCPUBoostSpec) DeepCopy() *StartupCPUBoostSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StartupCPUBoostStatus) DeepCopyInto(out *StartupCPUBoostStatus) {
 	*out = *in
+	if in.LearnedResources != nil {
+		in, out := &in.LearnedResources, &out.LearnedResources
+		*out = make([]LearnedResourceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -295,3 +600,216 @@ func (in *StartupCPUBoostStatus) DeepCopy() *StartupCPUBoostStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StartupProfile) DeepCopyInto(out *StartupProfile) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartupProfile.
+func (in *StartupProfile) DeepCopy() *StartupProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(StartupProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StartupProfile) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StartupProfileList) DeepCopyInto(out *StartupProfileList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]StartupProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartupProfileList.
+func (in *StartupProfileList) DeepCopy() *StartupProfileList {
+	if in == nil {
+		return nil
+	}
+	out := new(StartupProfileList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StartupProfileList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StartupProfileOwner) DeepCopyInto(out *StartupProfileOwner) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartupProfileOwner.
+func (in *StartupProfileOwner) DeepCopy() *StartupProfileOwner {
+	if in == nil {
+		return nil
+	}
+	out := new(StartupProfileOwner)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StartupProfilePolicy) DeepCopyInto(out *StartupProfilePolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartupProfilePolicy.
+func (in *StartupProfilePolicy) DeepCopy() *StartupProfilePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(StartupProfilePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StartupProfileSpec) DeepCopyInto(out *StartupProfileSpec) {
+	*out = *in
+	if in.Owner != nil {
+		in, out := &in.Owner, &out.Owner
+		*out = new(StartupProfileOwner)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartupProfileSpec.
+func (in *StartupProfileSpec) DeepCopy() *StartupProfileSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StartupProfileSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StartupProfileStatus) DeepCopyInto(out *StartupProfileStatus) {
+	*out = *in
+	if in.Samples != nil {
+		in, out := &in.Samples, &out.Samples
+		*out = make([]StartupSample, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Summary.DeepCopyInto(&out.Summary)
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartupProfileStatus.
+func (in *StartupProfileStatus) DeepCopy() *StartupProfileStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StartupProfileStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StartupProfileSummary) DeepCopyInto(out *StartupProfileSummary) {
+	*out = *in
+	if in.TimeToScheduled != nil {
+		in, out := &in.TimeToScheduled, &out.TimeToScheduled
+		*out = new(DurationSummary)
+		**out = **in
+	}
+	if in.TimeToStarted != nil {
+		in, out := &in.TimeToStarted, &out.TimeToStarted
+		*out = new(DurationSummary)
+		**out = **in
+	}
+	if in.TimeToReady != nil {
+		in, out := &in.TimeToReady, &out.TimeToReady
+		*out = new(DurationSummary)
+		**out = **in
+	}
+	if in.BoostDuration != nil {
+		in, out := &in.BoostDuration, &out.BoostDuration
+		*out = new(DurationSummary)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartupProfileSummary.
+func (in *StartupProfileSummary) DeepCopy() *StartupProfileSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(StartupProfileSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StartupSample) DeepCopyInto(out *StartupSample) {
+	*out = *in
+	in.CreationTime.DeepCopyInto(&out.CreationTime)
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]ContainerBoostSample, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TimeToScheduled != nil {
+		in, out := &in.TimeToScheduled, &out.TimeToScheduled
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.TimeToStarted != nil {
+		in, out := &in.TimeToStarted, &out.TimeToStarted
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.TimeToReady != nil {
+		in, out := &in.TimeToReady, &out.TimeToReady
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RevertTime != nil {
+		in, out := &in.RevertTime, &out.RevertTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartupSample.
+func (in *StartupSample) DeepCopy() *StartupSample {
+	if in == nil {
+		return nil
+	}
+	out := new(StartupSample)
+	in.DeepCopyInto(out)
+	return out
+}
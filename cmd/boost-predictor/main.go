@@ -0,0 +1,176 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// boost-predictor is the reference predictor server of the auto resource and
+// duration policies. It predicts the boost duration from the startup durations
+// of the workload PODs reported with the reversion notifications.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	predictorv1 "github.com/google/kube-startup-cpu-boost/api/predictor/v1"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+const (
+	movingAverageModel = "moving-average"
+	percentileModel    = "percentile"
+)
+
+var setupLog = ctrl.Log.WithName("setup")
+
+func main() {
+	var (
+		bindAddr        string
+		grpcBindAddr    string
+		certFile        string
+		keyFile         string
+		stateFile       string
+		modelName       string
+		window          int
+		percentile      float64
+		minSamples      int
+		maxSamples      int
+		margin          time.Duration
+		defaultDuration time.Duration
+		cpuIncrease     int64
+	)
+	flag.StringVar(&bindAddr, "bind-address", ":8080", "The address the HTTP predictor endpoint binds to.")
+	flag.StringVar(&grpcBindAddr, "grpc-bind-address", "",
+		"The address the gRPC predictor endpoint binds to. The gRPC endpoint is disabled when empty.")
+	flag.StringVar(&certFile, "tls-cert-file", "",
+		"The server certificate file. The endpoints are served over TLS when set.")
+	flag.StringVar(&keyFile, "tls-key-file", "", "The server certificate key file.")
+	flag.StringVar(&stateFile, "state-file", "",
+		"The file the samples are persisted to. The samples are kept in memory only when empty.")
+	flag.StringVar(&modelName, "model", movingAverageModel,
+		fmt.Sprintf("The model estimating the startup duration: %s or %s.", movingAverageModel, percentileModel))
+	flag.IntVar(&window, "window", server.DefaultWindow, "The number of the latest samples averaged by the moving-average model.")
+	flag.Float64Var(&percentile, "percentile", server.DefaultPercentile, "The startup duration percentile of the percentile model.")
+	flag.IntVar(&minSamples, "min-samples", server.DefaultMinSamples, "The number of the samples needed to predict the duration.")
+	flag.IntVar(&maxSamples, "max-samples", server.DefaultMaxSamples, "The number of the latest samples kept per workload.")
+	flag.DurationVar(&margin, "margin", 0, "The margin added to the estimated startup duration.")
+	flag.DurationVar(&defaultDuration, "default-duration", 0,
+		"The duration predicted until there are enough samples. The duration is not predicted when zero.")
+	flag.Int64Var(&cpuIncrease, "cpu-increase", 0,
+		"The percentage increase of the container CPU requests and limits. The CPU is not predicted when zero.")
+	opts := zap.Options{}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+	estimator, err := newEstimator(modelName, window, percentile)
+	if err != nil {
+		setupLog.Error(err, "invalid model")
+		os.Exit(1)
+	}
+	history, err := server.NewHistory(stateFile, maxSamples)
+	if err != nil {
+		setupLog.Error(err, "unable to load history")
+		os.Exit(1)
+	}
+	srv := server.New(server.NewEstimatorModel(estimator, server.ModelOptions{
+		MinSamples:      minSamples,
+		Margin:          margin,
+		DefaultDuration: defaultDuration,
+		CPUIncrease:     cpuIncrease,
+	}), history)
+
+	ctx := ctrl.SetupSignalHandler()
+	errs := make(chan error, 2)
+	go func() {
+		errs <- serveHTTP(ctx, srv, bindAddr, certFile, keyFile)
+	}()
+	if grpcBindAddr != "" {
+		go func() {
+			errs <- serveGRPC(ctx, srv, grpcBindAddr, certFile, keyFile)
+		}()
+	}
+	setupLog.Info("starting predictor", "model", modelName, "address", bindAddr, "grpcAddress", grpcBindAddr)
+	if err := <-errs; err != nil {
+		setupLog.Error(err, "problem running predictor")
+		os.Exit(1)
+	}
+}
+
+// newEstimator returns the startup duration estimator of a model with a given name
+func newEstimator(name string, window int, percentile float64) (server.Estimator, error) {
+	switch name {
+	case movingAverageModel:
+		return server.MovingAverage(window), nil
+	case percentileModel:
+		return server.Percentile(percentile), nil
+	}
+	return nil, fmt.Errorf("unknown model %q", name)
+}
+
+// serveHTTP serves the HTTP predictor endpoint until a given context is done
+func serveHTTP(ctx context.Context, srv *server.Server, addr, certFile, keyFile string) error {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           srv,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+	var err error
+	if certFile != "" {
+		err = httpServer.ListenAndServeTLS(certFile, keyFile)
+	} else {
+		err = httpServer.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// serveGRPC serves the gRPC predictor endpoint until a given context is done
+func serveGRPC(ctx context.Context, srv *server.Server, addr, certFile, keyFile string) error {
+	var opts []grpc.ServerOption
+	if certFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load server certificate: %w", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	grpcServer := grpc.NewServer(opts...)
+	predictorv1.RegisterPredictorServer(grpcServer, srv)
+	go func() {
+		<-ctx.Done()
+		grpcServer.GracefulStop()
+	}()
+	return grpcServer.Serve(listener)
+}
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"os"
 
@@ -23,15 +24,20 @@ import (
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	autoscalingv1alpha1 "github.com/google/kube-startup-cpu-boost/api/v1alpha1"
 	"github.com/google/kube-startup-cpu-boost/internal/boost"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resize"
 	"github.com/google/kube-startup-cpu-boost/internal/config"
 	"github.com/google/kube-startup-cpu-boost/internal/controller"
 	"github.com/google/kube-startup-cpu-boost/internal/metrics"
@@ -87,20 +93,41 @@ func main() {
 		HealthProbeBindAddress: cfg.HealthProbeBindAddr,
 		LeaderElection:         cfg.LeaderElection,
 		LeaderElectionID:       leaderElectionID,
+		Client: client.Options{
+			Cache: &client.CacheOptions{
+				// the learned histories and the predictor credentials are read from the
+				// API server, so the ConfigMaps and Secrets of the whole cluster are not
+				// cached and the reads of the ConfigMaps follow the writes
+				DisableFor: []client.Object{&corev1.ConfigMap{}, &corev1.Secret{}},
+			},
+		},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	// the webhook resolves the POD owners from the cached ReplicaSets, so their
+	// informer is started with the manager rather than on the first admission
+	if _, err := mgr.GetCache().GetInformer(context.Background(), &appsv1.ReplicaSet{}); err != nil {
+		setupLog.Error(err, "unable to set up replicaset informer")
+		os.Exit(1)
+	}
+
 	certsReady := make(chan struct{})
 	if err = util.ManageCerts(mgr, cfg.Namespace, certsReady); err != nil {
 		setupLog.Error(err, "Unable to set up certificates")
 		os.Exit(1)
 	}
 
-	boostMgr := boost.NewManager(mgr.GetClient())
-	go setupControllers(mgr, boostMgr, cfg, certsReady)
+	resizer, err := newResizeStrategy(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to detect pod resize support")
+		os.Exit(1)
+	}
+	boostMgr := boost.NewManager(mgr.GetClient(), resizer)
+	boostMgr.SetEventRecorder(mgr.GetEventRecorderFor("startup-cpu-boost"))
+	go setupControllers(mgr, boostMgr, resizer, cfg, certsReady)
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
@@ -120,7 +147,26 @@ func main() {
 	}
 }
 
-func setupControllers(mgr ctrl.Manager, boostMgr boost.Manager, cfg *config.Config, certsReady chan struct{}) {
+// newResizeStrategy returns the POD resize strategy supported by the API server
+func newResizeStrategy(mgr ctrl.Manager) (resize.Strategy, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	supported, err := resize.SubResourceSupported(discoveryClient)
+	if err != nil {
+		return nil, err
+	}
+	if !supported {
+		setupLog.Info("Pod resize subresource not supported, using pod patch")
+		return resize.NewPatchStrategy(mgr.GetClient()), nil
+	}
+	setupLog.Info("Using pod resize subresource")
+	return resize.NewSubResourceStrategy(mgr.GetClient()), nil
+}
+
+func setupControllers(mgr ctrl.Manager, boostMgr boost.Manager, resizer resize.Strategy, cfg *config.Config,
+	certsReady chan struct{}) {
 	setupLog.Info("Waiting for certificate generation to complete")
 	<-certsReady
 	setupLog.Info("Certificate generation has completed")
@@ -129,13 +175,15 @@ func setupControllers(mgr ctrl.Manager, boostMgr boost.Manager, cfg *config.Conf
 		setupLog.Error(err, "Unable to create webhook", "webhook", failedWebhook)
 		os.Exit(1)
 	}
-	cpuBoostWebHook := boostWebhook.NewPodCPUBoostWebHook(boostMgr, scheme, cfg.RemoveLimits)
+	cpuBoostWebHook := boostWebhook.NewPodCPUBoostWebHook(boostMgr, scheme, mgr.GetClient(), cfg.RemoveLimits)
 	mgr.GetWebhookServer().Register("/mutate-v1-pod", cpuBoostWebHook)
 	boostCtrl := &controller.StartupCPUBoostReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		Log:     ctrl.Log.WithName("boost-reconciler"),
-		Manager: boostMgr,
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Log:      ctrl.Log.WithName("boost-reconciler"),
+		Manager:  boostMgr,
+		Resizer:  resizer,
+		Recorder: mgr.GetEventRecorderFor("startup-cpu-boost"),
 	}
 	boostMgr.SetStartupCPUBoostReconciler(boostCtrl)
 	if err := boostCtrl.SetupWithManager(mgr); err != nil {
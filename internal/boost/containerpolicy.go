@@ -0,0 +1,156 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boost
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resource"
+)
+
+const (
+	// DefaultContainerName is the container name of the policy that
+	// applies to all containers
+	DefaultContainerName = "*"
+	// globSpecialChars are the characters that make the container name
+	// a glob pattern
+	globSpecialChars = "*?["
+)
+
+// IsContainerNameGlob returns true if a given container name is a glob pattern
+func IsContainerNameGlob(name string) bool {
+	return strings.ContainsAny(name, globSpecialChars)
+}
+
+// ValidateContainerNameGlob verifies if a given container name glob pattern
+// is valid
+func ValidateContainerNameGlob(pattern string) error {
+	_, err := path.Match(pattern, "")
+	return err
+}
+
+// globContainerPolicy is a resource policy for containers which names
+// match the glob pattern
+type globContainerPolicy struct {
+	pattern string
+	policy  resource.ContainerPolicy
+}
+
+// regexpContainerPolicy is a resource policy for containers which names
+// match the regular expression
+type regexpContainerPolicy struct {
+	regexp *regexp.Regexp
+	policy resource.ContainerPolicy
+}
+
+// containerPolicies resolves the resource policy for a container name.
+// The most specific match wins, in the following order: exact name, glob pattern
+// (the one with more literal characters first), regular expression and the
+// default policy. The patterns with the same specificity are matched in the API
+// spec order. The excluded containers never get the policy.
+type containerPolicies struct {
+	exact         map[string]resource.ContainerPolicy
+	globs         []globContainerPolicy
+	regexps       []regexpContainerPolicy
+	defaultPolicy resource.ContainerPolicy
+	excluded      []string
+}
+
+func newContainerPolicies() *containerPolicies {
+	return &containerPolicies{
+		exact: make(map[string]resource.ContainerPolicy),
+	}
+}
+
+// addName adds the policy for a given container name or glob pattern
+func (c *containerPolicies) addName(name string, policy resource.ContainerPolicy) error {
+	switch {
+	case name == DefaultContainerName:
+		c.defaultPolicy = policy
+	case IsContainerNameGlob(name):
+		if err := ValidateContainerNameGlob(name); err != nil {
+			return err
+		}
+		c.globs = append(c.globs, globContainerPolicy{pattern: name, policy: policy})
+		sort.SliceStable(c.globs, func(i, j int) bool {
+			return globLiteralLen(c.globs[i].pattern) > globLiteralLen(c.globs[j].pattern)
+		})
+	default:
+		c.exact[name] = policy
+	}
+	return nil
+}
+
+// addRegexp adds the policy for a given container name regular expression.
+// The expression has to match the whole container name.
+func (c *containerPolicies) addRegexp(expr string, policy resource.ContainerPolicy) error {
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return err
+	}
+	c.regexps = append(c.regexps, regexpContainerPolicy{regexp: re, policy: policy})
+	return nil
+}
+
+// exclude adds the container names or glob patterns to the exclusion list
+func (c *containerPolicies) exclude(patterns ...string) error {
+	for _, pattern := range patterns {
+		if err := ValidateContainerNameGlob(pattern); err != nil {
+			return err
+		}
+	}
+	c.excluded = append(c.excluded, patterns...)
+	return nil
+}
+
+// policy returns the resource policy for a given container name
+func (c *containerPolicies) policy(name string) (resource.ContainerPolicy, bool) {
+	for _, pattern := range c.excluded {
+		if matched, _ := path.Match(pattern, name); matched {
+			return nil, false
+		}
+	}
+	if policy, ok := c.exact[name]; ok {
+		return policy, true
+	}
+	for _, glob := range c.globs {
+		if matched, _ := path.Match(glob.pattern, name); matched {
+			return glob.policy, true
+		}
+	}
+	for _, re := range c.regexps {
+		if re.regexp.MatchString(name) {
+			return re.policy, true
+		}
+	}
+	if c.defaultPolicy != nil {
+		return c.defaultPolicy, true
+	}
+	return nil, false
+}
+
+// globLiteralLen returns the number of literal characters in a glob pattern
+func globLiteralLen(pattern string) int {
+	var cnt int
+	for _, r := range pattern {
+		if !strings.ContainsRune(globSpecialChars+"]", r) {
+			cnt++
+		}
+	}
+	return cnt
+}
@@ -15,107 +15,102 @@
 package duration
 
 import (
-	"bytes"
-	"encoding/json"
-	"log"
-	"net/http"
+	"context"
 	"time"
 
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
 	v1 "k8s.io/api/core/v1"
 )
 
 const (
 	AutoDurationPolicyName = "AutoDuration"
+	// DefaultAutoFallback is the default duration applied when the prediction fails
+	DefaultAutoFallback = 30 * time.Second
 )
 
+// AutoDurationPolicy is the duration policy which duration is predicted by the
+// external predictor. The prediction is made once, when the POD is boosted, and
+// recorded in the boost annotation as an absolute deadline. When the prediction
+// fails, the deadline is set using the fallback duration, which is DefaultAutoFallback
+// unless defined, so the POD resources are not reverted on the first policy check.
 type AutoDurationPolicy struct {
-	apiEndpoint string
+	client   predictor.Predictor
+	timeFunc TimeFunc
+	fallback time.Duration
 }
 
 func (p *AutoDurationPolicy) Name() string {
 	return AutoDurationPolicyName
 }
 
-// Valid returns true if the pod is still within the duration
+// Valid returns true if the pod is still before the predicted deadline
 func (p *AutoDurationPolicy) Valid(pod *v1.Pod) bool {
-	now := time.Now()
-	duration, err := p.GetDuration(pod)
-
-	if err != nil {
-		return false
-	}
-
-	return pod.CreationTimestamp.Add(duration).After(now)
+	return p.Deadline(pod).After(p.timeFunc())
 }
 
-type DurationPrediction struct {
-	Duration string `json:"duration"`
+// TimeDependent returns true, as the policy validity depends on time
+func (*AutoDurationPolicy) TimeDependent() bool {
+	return true
 }
 
-func NewAutoDurationPolicy(apiEndpoint string) *AutoDurationPolicy {
-	return &AutoDurationPolicy{
-		apiEndpoint: apiEndpoint,
-	}
+// Deadline returns the time when the policy stops being valid for a given POD, i.e.
+// the deadline recorded in the boost annotation when the POD was boosted. When the
+// deadline is not recorded, the POD creation time increased by the fallback duration
+// is returned.
+func (p *AutoDurationPolicy) Deadline(pod *v1.Pod) time.Time {
+	return annotatedDeadline(pod, p.fallback)
 }
 
-func (p *AutoDurationPolicy) GetDuration(pod *v1.Pod) (time.Duration, error) {
-	prediction, err := p.getPrediction(pod)
-	if err != nil {
-		return 0, err
-	}
-	return time.ParseDuration(prediction.Duration)
+// Fallback returns the duration applied when the prediction fails
+func (p *AutoDurationPolicy) Fallback() time.Duration {
+	return p.fallback
 }
 
-func (p *AutoDurationPolicy) getPrediction(pod *v1.Pod) (*DurationPrediction, error) {
-	podName := pod.Name
-	podNamespace := pod.Namespace
-
-	podData, err := json.Marshal(map[string]string{
-		"podName":      podName,
-		"podNamespace": podNamespace,
-	})
+// Endpoint returns the endpoint of the predictor
+func (p *AutoDurationPolicy) Endpoint() string {
+	return p.client.Endpoint()
+}
 
+// PredictDeadline returns the deadline of a POD boost, i.e. the current time increased
+// by the duration from given POD predictions. When the prediction fails or has no
+// duration, it returns the current time increased by the fallback duration and
+// the prediction error.
+func (p *AutoDurationPolicy) PredictDeadline(ctx context.Context, predictions *predictor.PodPredictions) (time.Time, error) {
+	now := p.timeFunc()
+	prediction, err := predictions.Predict(ctx, p.client)
 	if err != nil {
-		return nil, err
+		return now.Add(p.fallback), err
 	}
-
-	resp, err := http.Post(p.apiEndpoint+"/duration", "application/json", bytes.NewBuffer(podData))
+	duration, err := prediction.GetDuration()
 	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	var prediction DurationPrediction
-	if err := json.NewDecoder(resp.Body).Decode(&prediction); err != nil {
-		return nil, err
+		return now.Add(p.fallback), err
 	}
-	return &prediction, nil
+	return now.Add(duration), nil
 }
 
-func (p *AutoDurationPolicy) NotifyReversion(pod *v1.Pod) error {
-
-	podName := pod.Name
-	podNamespace := pod.Namespace
-
-	podData, err := json.Marshal(map[string]string{
-		"podName":      podName,
-		"podNamespace": podNamespace,
-	})
-
-	if err != nil {
-		return err
-	}
-
-	resp, err := http.Post(p.apiEndpoint+"/notify", "application/json", bytes.NewBuffer(podData))
-	if err != nil {
-		return err
-	}
+func NewAutoDurationPolicy(apiEndpoint string) *AutoDurationPolicy {
+	return NewAutoDurationPolicyWithClient(predictor.New(apiEndpoint, predictor.DefaultOptions(), nil))
+}
 
-	defer resp.Body.Close()
+// NewAutoDurationPolicyWithClient returns the auto duration policy that gets the
+// predictions with a given predictor client
+func NewAutoDurationPolicyWithClient(client predictor.Predictor) *AutoDurationPolicy {
+	return NewAutoDurationPolicyWithFallback(client, time.Now, DefaultAutoFallback)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("unexpected status code: %d", resp.StatusCode)
+// NewAutoDurationPolicyWithFallback returns the auto duration policy that gets the
+// predictions with a given predictor client and applies a given fallback duration
+// when the prediction fails
+func NewAutoDurationPolicyWithFallback(client predictor.Predictor, timeFunc TimeFunc, fallback time.Duration) *AutoDurationPolicy {
+	return &AutoDurationPolicy{
+		client:   client,
+		timeFunc: timeFunc,
+		fallback: fallback,
 	}
+}
 
-	return nil
+// NotifyReversion notifies the predictor with a given notification about the
+// reverted resources of a POD, so the predictor can learn the actual boost duration
+func (p *AutoDurationPolicy) NotifyReversion(ctx context.Context, request *predictor.NotifyRequest) error {
+	return p.client.NotifyReversion(ctx, request)
 }
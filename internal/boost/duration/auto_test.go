@@ -1,27 +1,34 @@
 package duration
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func TestAutoDurationPolicy_GetDuration(t *testing.T) {
+func TestAutoDurationPolicy_PredictDeadline_Request(t *testing.T) {
 	// Mock API server
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "/duration", r.URL.Path)
+		assert.Equal(t, predictor.PredictPath, r.URL.Path)
 		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
 
-		var pod corev1.Pod
-		err := json.NewDecoder(r.Body).Decode(&pod)
+		var request predictor.PodRequest
+		err := json.NewDecoder(r.Body).Decode(&request)
 		assert.NoError(t, err)
+		assert.Equal(t, "test-pod", request.PodName)
+		assert.Equal(t, "test-namespace", request.PodNamespace)
+		assert.Len(t, request.Containers, 1)
 
-		prediction := DurationPrediction{
+		prediction := predictor.PodPrediction{
 			Duration: "5m",
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -30,10 +37,16 @@ func TestAutoDurationPolicy_GetDuration(t *testing.T) {
 	defer mockServer.Close()
 
 	// Create an instance of AutoDurationPolicy with the mock server URL
-	policy := NewAutoDurationPolicy(mockServer.URL)
+	now := time.Now()
+	policy := NewAutoDurationPolicyWithFallback(predictor.NewClient(mockServer.URL, predictor.DefaultOptions()),
+		func() time.Time { return now }, 0)
 
 	// Create a sample pod
 	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "test-namespace",
+		},
 		Spec: corev1.PodSpec{
 			Containers: []corev1.Container{
 				{
@@ -44,47 +57,113 @@ func TestAutoDurationPolicy_GetDuration(t *testing.T) {
 		},
 	}
 
-	// Call the GetDuration method
-	duration, err := policy.GetDuration(pod)
+	// Call the PredictDeadline method
+	deadline, err := policy.PredictDeadline(context.TODO(), predictor.NewPodPredictions(pod, "", nil))
 	assert.NoError(t, err)
-	assert.Equal(t, 5*time.Minute, duration)
+	assert.Equal(t, now.Add(5*time.Minute), deadline)
 }
 
-func TestAutoDurationPolicy_getPrediction(t *testing.T) {
-	// Mock API server
+func TestAutoDurationPolicy_PredictDeadline_NoDuration(t *testing.T) {
+	// Mock API server that predicts the resources only
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "/duration", r.URL.Path)
-		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(predictor.PodPrediction{})
+	}))
+	defer mockServer.Close()
 
-		var pod corev1.Pod
-		err := json.NewDecoder(r.Body).Decode(&pod)
-		assert.NoError(t, err)
+	now := time.Now()
+	policy := NewAutoDurationPolicyWithFallback(predictor.NewClient(mockServer.URL, predictor.DefaultOptions()),
+		func() time.Time { return now }, time.Minute)
 
-		prediction := DurationPrediction{
-			Duration: "5m",
-		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(prediction)
+	// The fallback duration is applied when the prediction has no duration
+	deadline, err := policy.PredictDeadline(context.TODO(), predictor.NewPodPredictions(&corev1.Pod{}, "", nil))
+	assert.ErrorIs(t, err, predictor.ErrNoDuration)
+	assert.Equal(t, now.Add(time.Minute), deadline)
+}
+
+func TestAutoDurationPolicy_PredictDeadline_DefaultFallback(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
 	}))
 	defer mockServer.Close()
 
-	// Create an instance of AutoDurationPolicy with the mock server URL
-	policy := NewAutoDurationPolicy(mockServer.URL)
+	options := predictor.DefaultOptions()
+	options.MaxRetries = 0
+	policy := NewAutoDurationPolicyWithClient(predictor.NewClient(mockServer.URL, options))
+	assert.Equal(t, DefaultAutoFallback, policy.Fallback())
 
-	// Create a sample pod
+	// The POD resources are not reverted right away when the prediction fails
+	// and the fallback duration is not configured
+	deadline, err := policy.PredictDeadline(context.TODO(), predictor.NewPodPredictions(&corev1.Pod{}, "", nil))
+	assert.Error(t, err)
+	annotation := bpod.NewBoostAnnotation()
+	annotation.DurationDeadline = &deadline
 	pod := &corev1.Pod{
-		Spec: corev1.PodSpec{
-			Containers: []corev1.Container{
-				{
-					Name:  "test-container",
-					Image: "test-image",
-				},
-			},
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{bpod.BoostAnnotationKey: annotation.ToJSON()},
+		},
+	}
+	assert.True(t, policy.Valid(pod))
+}
+
+func TestAutoDurationPolicy_Deadline(t *testing.T) {
+	policy := NewAutoDurationPolicyWithFallback(predictor.NewClient("http://localhost", predictor.DefaultOptions()),
+		time.Now, 30*time.Second)
+	creationTimestamp := time.Now().Add(-1 * time.Minute)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			CreationTimestamp: metav1.NewTime(creationTimestamp),
 		},
 	}
 
-	// Call the getPrediction method
-	prediction, err := policy.getPrediction(pod)
+	// The deadline is the POD creation time increased by the fallback duration
+	// when the deadline is not recorded in the boost annotation
+	assert.Equal(t, pod.CreationTimestamp.Add(30*time.Second), policy.Deadline(pod))
+	assert.False(t, policy.Valid(pod))
+
+	// The deadline is the one recorded in the boost annotation
+	deadline := time.Now().Add(5 * time.Minute).Round(time.Second)
+	annotation := bpod.NewBoostAnnotation()
+	annotation.DurationDeadline = &deadline
+	pod.Annotations = map[string]string{bpod.BoostAnnotationKey: annotation.ToJSON()}
+	assert.True(t, policy.Deadline(pod).Equal(deadline))
+	assert.True(t, policy.Valid(pod))
+}
+
+func TestAutoDurationPolicy_PredictDeadline(t *testing.T) {
+	var calls int
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		prediction := predictor.PodPrediction{
+			Duration: "5m",
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(prediction)
+	}))
+	now := time.Now()
+	options := predictor.DefaultOptions()
+	options.MaxRetries = 0
+	policy := NewAutoDurationPolicyWithFallback(predictor.NewClient(mockServer.URL, options),
+		func() time.Time { return now }, time.Minute)
+	pod := &corev1.Pod{}
+
+	// The deadline is the current time increased by the predicted duration
+	deadline, err := policy.PredictDeadline(context.TODO(), predictor.NewPodPredictions(pod, "", nil))
 	assert.NoError(t, err)
-	assert.Equal(t, "5m", prediction.Duration)
+	assert.Equal(t, now.Add(5*time.Minute), deadline)
+	assert.Equal(t, 1, calls)
+
+	// The policy validity is evaluated without calling the predictor
+	pod.Annotations = map[string]string{bpod.BoostAnnotationKey: (&bpod.BoostPodAnnotation{
+		DurationDeadline: &deadline,
+	}).ToJSON()}
+	policy.Valid(pod)
+	assert.Equal(t, 1, calls)
+
+	// The deadline is the current time increased by the fallback duration when
+	// the prediction fails
+	mockServer.Close()
+	deadline, err = policy.PredictDeadline(context.TODO(), predictor.NewPodPredictions(pod, "", nil))
+	assert.Error(t, err)
+	assert.Equal(t, now.Add(time.Minute), deadline)
 }
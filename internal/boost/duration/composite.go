@@ -0,0 +1,150 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package duration
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	CompositePolicyName = "Composite"
+)
+
+// Operator defines how the policies of a composite policy are combined
+type Operator string
+
+const (
+	// OperatorAny ends the boost when any of the policies is met
+	OperatorAny Operator = "Any"
+	// OperatorAll ends the boost when all of the policies are met
+	OperatorAll Operator = "All"
+)
+
+// CompositePolicy combines several duration policies with a given operator
+type CompositePolicy struct {
+	operator Operator
+	policies []Policy
+}
+
+func NewCompositePolicy(operator Operator, policies ...Policy) Policy {
+	return &CompositePolicy{
+		operator: operator,
+		policies: policies,
+	}
+}
+
+func (*CompositePolicy) Name() string {
+	return CompositePolicyName
+}
+
+func (p *CompositePolicy) Operator() Operator {
+	return p.operator
+}
+
+func (p *CompositePolicy) Policies() []Policy {
+	return p.policies
+}
+
+// Valid returns true if the boost of a given POD should last according to the
+// combined policies. With OperatorAll, the boost lasts until all of the policies
+// are met, otherwise it lasts until any of the policies is met.
+func (p *CompositePolicy) Valid(pod *corev1.Pod) bool {
+	if p.operator == OperatorAll {
+		for _, policy := range p.policies {
+			if policy.Valid(pod) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, policy := range p.policies {
+		if !policy.Valid(pod) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidContainer returns true if the boost of a given POD container should last
+// according to the combined policies. The policies are combined as in Valid, but
+// the container policies are evaluated for a given container only.
+func (p *CompositePolicy) ValidContainer(pod *corev1.Pod, containerName string) bool {
+	if p.operator == OperatorAll {
+		for _, policy := range p.policies {
+			if ValidContainer(policy, pod, containerName) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, policy := range p.policies {
+		if !ValidContainer(policy, pod, containerName) {
+			return false
+		}
+	}
+	return true
+}
+
+// TimeDependent returns true if any of the combined policies depends on time
+func (p *CompositePolicy) TimeDependent() bool {
+	for _, policy := range p.policies {
+		if TimeDependent(policy) {
+			return true
+		}
+	}
+	return false
+}
+
+// Deadline returns the time when the combined policies stop being valid for a given
+// POD. With OperatorAll, it is the latest deadline of the time dependent policies,
+// otherwise it is the earliest one. The policies that do not depend on time are
+// validated on the POD updates instead.
+func (p *CompositePolicy) Deadline(pod *corev1.Pod) time.Time {
+	var result time.Time
+	var found bool
+	for _, policy := range p.policies {
+		deadline, ok := Deadline(policy, pod)
+		if !ok {
+			continue
+		}
+		later := deadline.After(result)
+		if !found || (p.operator == OperatorAll && later) || (p.operator != OperatorAll && !later) {
+			result = deadline
+		}
+		found = true
+	}
+	return result
+}
+
+// FindPolicy returns the policy with a given name. The policy is looked up
+// in the policies combined by the composite policy as well.
+func FindPolicy(policy Policy, name string) (Policy, bool) {
+	if policy == nil {
+		return nil, false
+	}
+	if policy.Name() == name {
+		return policy, true
+	}
+	if composite, ok := policy.(*CompositePolicy); ok {
+		for _, p := range composite.policies {
+			if found, ok := FindPolicy(p, name); ok {
+				return found, true
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,156 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package duration_test
+
+import (
+	"time"
+
+	"github.com/google/kube-startup-cpu-boost/internal/boost/duration"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type staticPolicy struct {
+	name  string
+	valid bool
+}
+
+func (p *staticPolicy) Name() string {
+	return p.name
+}
+
+func (p *staticPolicy) Valid(*corev1.Pod) bool {
+	return p.valid
+}
+
+var _ = Describe("CompositePolicy", func() {
+	var policy duration.Policy
+	var operator duration.Operator
+	var first, second *staticPolicy
+
+	BeforeEach(func() {
+		first = &staticPolicy{name: "first", valid: true}
+		second = &staticPolicy{name: "second", valid: true}
+	})
+	JustBeforeEach(func() {
+		policy = duration.NewCompositePolicy(operator, first, second)
+	})
+
+	Describe("Validates POD", func() {
+		When("the operator is any", func() {
+			BeforeEach(func() {
+				operator = duration.OperatorAny
+			})
+			It("returns policy is valid when all policies are valid", func() {
+				Expect(policy.Valid(pod)).To(BeTrue())
+			})
+			It("returns policy is invalid when any policy is invalid", func() {
+				second.valid = false
+				Expect(policy.Valid(pod)).To(BeFalse())
+			})
+		})
+		When("the operator is all", func() {
+			BeforeEach(func() {
+				operator = duration.OperatorAll
+			})
+			It("returns policy is valid when any policy is valid", func() {
+				first.valid = false
+				Expect(policy.Valid(pod)).To(BeTrue())
+			})
+			It("returns policy is invalid when all policies are invalid", func() {
+				first.valid = false
+				second.valid = false
+				Expect(policy.Valid(pod)).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("Finds policy", func() {
+		BeforeEach(func() {
+			operator = duration.OperatorAny
+		})
+		It("returns the combined policy with a given name", func() {
+			found, ok := duration.FindPolicy(policy, "second")
+			Expect(ok).To(BeTrue())
+			Expect(found).To(Equal(second))
+		})
+		It("returns the nested combined policy with a given name", func() {
+			nested := duration.NewCompositePolicy(duration.OperatorAll, policy)
+			found, ok := duration.FindPolicy(nested, "first")
+			Expect(ok).To(BeTrue())
+			Expect(found).To(Equal(first))
+		})
+		It("returns false when there is no policy with a given name", func() {
+			_, ok := duration.FindPolicy(policy, "third")
+			Expect(ok).To(BeFalse())
+		})
+		It("returns false for nil policy", func() {
+			_, ok := duration.FindPolicy(nil, "first")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("Determines time dependency", func() {
+		BeforeEach(func() {
+			operator = duration.OperatorAny
+		})
+		It("returns false when none of the policies depends on time", func() {
+			Expect(duration.TimeDependent(policy)).To(BeFalse())
+		})
+		It("returns true when any of the policies depends on time", func() {
+			policy := duration.NewCompositePolicy(operator, first, duration.NewFixedDurationPolicy(time.Minute))
+			Expect(duration.TimeDependent(policy)).To(BeTrue())
+		})
+		It("returns true when any of the nested policies depends on time", func() {
+			nested := duration.NewCompositePolicy(duration.OperatorAll, second, duration.NewAutoDurationPolicy(""))
+			Expect(duration.TimeDependent(duration.NewCompositePolicy(operator, first, nested))).To(BeTrue())
+		})
+	})
+
+	Describe("Returns deadline", func() {
+		var now time.Time
+		var shorter, longer duration.Policy
+		BeforeEach(func() {
+			now = time.Now()
+			pod.CreationTimestamp = metav1.NewTime(now)
+			shorter = duration.NewFixedDurationPolicy(10 * time.Second)
+			longer = duration.NewFixedDurationPolicy(time.Minute)
+		})
+		When("the operator is any", func() {
+			It("returns the earliest deadline", func() {
+				policy := duration.NewCompositePolicy(duration.OperatorAny, longer, first, shorter)
+				deadline, ok := duration.Deadline(policy, pod)
+				Expect(ok).To(BeTrue())
+				Expect(deadline).To(BeTemporally("==", now.Add(10*time.Second)))
+			})
+		})
+		When("the operator is all", func() {
+			It("returns the latest deadline", func() {
+				policy := duration.NewCompositePolicy(duration.OperatorAll, shorter, first, longer)
+				deadline, ok := duration.Deadline(policy, pod)
+				Expect(ok).To(BeTrue())
+				Expect(deadline).To(BeTemporally("==", now.Add(time.Minute)))
+			})
+		})
+		When("none of the policies depends on time", func() {
+			It("returns false", func() {
+				_, ok := duration.Deadline(policy, pod)
+				Expect(ok).To(BeFalse())
+			})
+		})
+	})
+})
@@ -0,0 +1,121 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package duration
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	ContainerStatusPolicyName = "ContainerStatus"
+)
+
+// ContainerCondition defines the condition of a container status
+type ContainerCondition string
+
+const (
+	// ContainerConditionStarted is met when the container startup probe succeeds
+	ContainerConditionStarted ContainerCondition = "Started"
+	// ContainerConditionReady is met when the container readiness probe succeeds
+	ContainerConditionReady ContainerCondition = "Ready"
+)
+
+// ContainerPolicy is a duration policy that is evaluated for each POD container
+// separately
+type ContainerPolicy interface {
+	Policy
+	// ValidContainer returns true if the boost of a given POD container should last
+	ValidContainer(pod *corev1.Pod, containerName string) bool
+}
+
+// ContainerStatusPolicy is a duration policy that lasts until the status of
+// a container meets the given condition
+type ContainerStatusPolicy struct {
+	condition ContainerCondition
+}
+
+func NewContainerStatusPolicy(condition ContainerCondition) Policy {
+	return &ContainerStatusPolicy{
+		condition: condition,
+	}
+}
+
+func (*ContainerStatusPolicy) Name() string {
+	return ContainerStatusPolicyName
+}
+
+func (p *ContainerStatusPolicy) Condition() ContainerCondition {
+	return p.condition
+}
+
+// Valid returns true until the statuses of all POD containers and sidecar
+// containers meet the policy condition
+func (p *ContainerStatusPolicy) Valid(pod *corev1.Pod) bool {
+	for i := range pod.Spec.InitContainers {
+		container := &pod.Spec.InitContainers[i]
+		if container.RestartPolicy == nil || *container.RestartPolicy != corev1.ContainerRestartPolicyAlways {
+			continue
+		}
+		if p.ValidContainer(pod, container.Name) {
+			return true
+		}
+	}
+	for i := range pod.Spec.Containers {
+		if p.ValidContainer(pod, pod.Spec.Containers[i].Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidContainer returns true until the status of a given container meets
+// the policy condition. The policy is valid for containers without status.
+func (p *ContainerStatusPolicy) ValidContainer(pod *corev1.Pod, containerName string) bool {
+	status, ok := containerStatus(pod, containerName)
+	if !ok {
+		return true
+	}
+	switch p.condition {
+	case ContainerConditionStarted:
+		return status.Started == nil || !*status.Started
+	case ContainerConditionReady:
+		return !status.Ready
+	}
+	return true
+}
+
+// ValidContainer returns true if the boost of a given POD container should last
+// according to a given policy. The container policies are evaluated for a given
+// container, while the remaining ones for the whole POD.
+func ValidContainer(policy Policy, pod *corev1.Pod, containerName string) bool {
+	if containerPolicy, ok := policy.(ContainerPolicy); ok {
+		return containerPolicy.ValidContainer(pod, containerName)
+	}
+	return policy.Valid(pod)
+}
+
+// containerStatus returns the status of a container or sidecar container
+// with a given name
+func containerStatus(pod *corev1.Pod, containerName string) (*corev1.ContainerStatus, bool) {
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.ContainerStatuses,
+		pod.Status.InitContainerStatuses} {
+		for i := range statuses {
+			if statuses[i].Name == containerName {
+				return &statuses[i], true
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,114 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package duration_test
+
+import (
+	"github.com/google/kube-startup-cpu-boost/internal/boost/duration"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("ContainerStatusPolicy", func() {
+	var policy duration.ContainerPolicy
+	var condition duration.ContainerCondition
+	var statusPod *corev1.Pod
+
+	BeforeEach(func() {
+		restartAlways := corev1.ContainerRestartPolicyAlways
+		started, notStarted := true, false
+		condition = duration.ContainerConditionStarted
+		statusPod = &corev1.Pod{
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					{Name: "sidecar", RestartPolicy: &restartAlways},
+				},
+				Containers: []corev1.Container{
+					{Name: "container-one"},
+					{Name: "container-two"},
+				},
+			},
+			Status: corev1.PodStatus{
+				InitContainerStatuses: []corev1.ContainerStatus{
+					{Name: "sidecar", Started: &started, Ready: true},
+				},
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "container-one", Started: &started, Ready: false},
+					{Name: "container-two", Started: &notStarted, Ready: false},
+				},
+			},
+		}
+	})
+	JustBeforeEach(func() {
+		policy = duration.NewContainerStatusPolicy(condition).(duration.ContainerPolicy)
+	})
+
+	Describe("Validates POD container", func() {
+		When("the condition is started", func() {
+			It("returns policy is not valid for started container", func() {
+				Expect(policy.ValidContainer(statusPod, "container-one")).To(BeFalse())
+			})
+			It("returns policy is valid for not started container", func() {
+				Expect(policy.ValidContainer(statusPod, "container-two")).To(BeTrue())
+			})
+			It("returns policy is not valid for started sidecar container", func() {
+				Expect(policy.ValidContainer(statusPod, "sidecar")).To(BeFalse())
+			})
+			It("returns policy is valid for container without status", func() {
+				Expect(policy.ValidContainer(statusPod, "container-three")).To(BeTrue())
+			})
+		})
+		When("the condition is ready", func() {
+			BeforeEach(func() {
+				condition = duration.ContainerConditionReady
+			})
+			It("returns policy is valid for not ready container", func() {
+				Expect(policy.ValidContainer(statusPod, "container-one")).To(BeTrue())
+			})
+			It("returns policy is not valid for ready container", func() {
+				Expect(policy.ValidContainer(statusPod, "sidecar")).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("Validates POD", func() {
+		It("returns policy is valid when any container does not meet the condition", func() {
+			Expect(policy.Valid(statusPod)).To(BeTrue())
+		})
+		It("returns policy is not valid when all containers meet the condition", func() {
+			started := true
+			statusPod.Status.ContainerStatuses[1].Started = &started
+			Expect(policy.Valid(statusPod)).To(BeFalse())
+		})
+	})
+
+	Describe("Validates POD container with combined policies", func() {
+		var podPolicy *staticPolicy
+		BeforeEach(func() {
+			podPolicy = &staticPolicy{name: "pod", valid: true}
+		})
+		It("returns policy is not valid when any of container policies ended", func() {
+			composite := duration.NewCompositePolicy(duration.OperatorAny, podPolicy, policy)
+			Expect(duration.ValidContainer(composite, statusPod, "container-one")).To(BeFalse())
+			Expect(duration.ValidContainer(composite, statusPod, "container-two")).To(BeTrue())
+		})
+		It("returns policy is valid until all of container policies ended", func() {
+			composite := duration.NewCompositePolicy(duration.OperatorAll, podPolicy, policy)
+			Expect(duration.ValidContainer(composite, statusPod, "container-one")).To(BeTrue())
+			podPolicy.valid = false
+			Expect(duration.ValidContainer(composite, statusPod, "container-one")).To(BeFalse())
+		})
+	})
+})
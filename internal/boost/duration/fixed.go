@@ -26,9 +26,14 @@ const (
 
 type TimeFunc func() time.Time
 
+// StartTimeFunc returns the time since which the duration is measured for
+// a given POD
+type StartTimeFunc func(pod *v1.Pod) time.Time
+
 type FixedDurationPolicy struct {
-	timeFunc TimeFunc
-	duration time.Duration
+	timeFunc      TimeFunc
+	startTimeFunc StartTimeFunc
+	duration      time.Duration
 }
 
 func NewFixedDurationPolicy(duration time.Duration) Policy {
@@ -36,9 +41,17 @@ func NewFixedDurationPolicy(duration time.Duration) Policy {
 }
 
 func NewFixedDurationPolicyWithTimeFunc(timeFunc TimeFunc, duration time.Duration) Policy {
+	return NewFixedDurationPolicyWithStartTimeFunc(timeFunc, podCreationTime, duration)
+}
+
+// NewFixedDurationPolicyWithStartTimeFunc returns the fixed duration policy which
+// duration is measured since the time returned by a given start time function
+// instead of the POD creation time
+func NewFixedDurationPolicyWithStartTimeFunc(timeFunc TimeFunc, startTimeFunc StartTimeFunc, duration time.Duration) Policy {
 	return &FixedDurationPolicy{
-		timeFunc: timeFunc,
-		duration: duration,
+		timeFunc:      timeFunc,
+		startTimeFunc: startTimeFunc,
+		duration:      duration,
 	}
 }
 
@@ -52,5 +65,19 @@ func (p *FixedDurationPolicy) Duration() time.Duration {
 
 func (p *FixedDurationPolicy) Valid(pod *v1.Pod) bool {
 	now := p.timeFunc()
-	return pod.CreationTimestamp.Add(p.duration).After(now)
+	return p.startTimeFunc(pod).Add(p.duration).After(now)
+}
+
+// TimeDependent returns true, as the policy validity depends on time
+func (*FixedDurationPolicy) TimeDependent() bool {
+	return true
+}
+
+// Deadline returns the time when the policy stops being valid for a given POD
+func (p *FixedDurationPolicy) Deadline(pod *v1.Pod) time.Time {
+	return p.startTimeFunc(pod).Add(p.duration)
+}
+
+func podCreationTime(pod *v1.Pod) time.Time {
+	return pod.CreationTimestamp.Time
 }
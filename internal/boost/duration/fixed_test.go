@@ -20,6 +20,7 @@ import (
 	"github.com/google/kube-startup-cpu-boost/internal/boost/duration"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -53,5 +54,30 @@ var _ = Describe("FixedDurationPolicy", func() {
 				Expect(policy.Valid(pod)).To(BeTrue())
 			})
 		})
+		When("the duration is measured since the given start time", func() {
+			var start time.Time
+			BeforeEach(func() {
+				pod.CreationTimestamp = metav1.NewTime(now.Add(-1 * time.Hour))
+				policy = duration.NewFixedDurationPolicyWithStartTimeFunc(timeFunc,
+					func(*corev1.Pod) time.Time { return start }, timeDuration)
+			})
+			It("returns policy is valid when the start time is within policy duration", func() {
+				start = now.Add(-1 * time.Second)
+				Expect(policy.Valid(pod)).To(BeTrue())
+			})
+			It("returns policy is not valid when the start time exceeds policy duration", func() {
+				start = now.Add(-1 * timeDuration).Add(-1 * time.Second)
+				Expect(policy.Valid(pod)).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("Returns deadline", func() {
+		It("returns the start time increased by the policy duration", func() {
+			pod.CreationTimestamp = metav1.NewTime(now.Add(-1 * time.Second))
+			deadline, ok := duration.Deadline(policy, pod)
+			Expect(ok).To(BeTrue())
+			Expect(deadline).To(BeTemporally("==", now.Add(-1*time.Second).Add(timeDuration)))
+		})
 	})
 })
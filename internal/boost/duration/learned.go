@@ -0,0 +1,191 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package duration
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	LearnedDurationPolicyName = "LearnedDuration"
+	// DefaultLearnedPercentile is the default percentile of the learned durations
+	DefaultLearnedPercentile = 90
+	// DefaultLearnedMinSamples is the default number of the learned durations
+	// needed to apply the percentile
+	DefaultLearnedMinSamples = 5
+	// DefaultLearnedMaxSamples is the default number of the latest durations
+	// kept per workload
+	DefaultLearnedMaxSamples = 50
+)
+
+// DurationHistory stores the durations learned per workload key
+type DurationHistory interface {
+	// Durations returns the durations learned for a given workload key, from
+	// the oldest to the latest
+	Durations(ctx context.Context, key string) ([]time.Duration, error)
+	// Add records a given duration learned for a given workload key
+	Add(ctx context.Context, key string, d time.Duration) error
+}
+
+// LearnedDurationPolicy is the duration policy which duration is learned from the
+// time the previous PODs of the same workload took to meet the POD condition. The
+// deadline is set once, when the POD is boosted, to a percentile of the learned
+// durations increased by the margin. Until there are enough learned durations,
+// the deadline is set using the fallback duration.
+type LearnedDurationPolicy struct {
+	history    DurationHistory
+	condition  corev1.PodConditionType
+	percentile int
+	margin     time.Duration
+	minSamples int
+	fallback   time.Duration
+	timeFunc   TimeFunc
+}
+
+// NewLearnedDurationPolicy returns the learned duration policy that learns the time
+// to a given POD condition and stores it in a given history. The deadline is set to
+// a given percentile of the learned durations increased by a given margin, or to
+// a given fallback duration until a given number of durations is learned.
+func NewLearnedDurationPolicy(history DurationHistory, condition corev1.PodConditionType, percentile int,
+	margin time.Duration, minSamples int, fallback time.Duration) *LearnedDurationPolicy {
+	return NewLearnedDurationPolicyWithTimeFunc(history, time.Now, condition, percentile, margin, minSamples, fallback)
+}
+
+// NewLearnedDurationPolicyWithTimeFunc returns the learned duration policy with
+// a given time function
+func NewLearnedDurationPolicyWithTimeFunc(history DurationHistory, timeFunc TimeFunc, condition corev1.PodConditionType,
+	percentile int, margin time.Duration, minSamples int, fallback time.Duration) *LearnedDurationPolicy {
+	if condition == "" {
+		condition = corev1.PodReady
+	}
+	if percentile <= 0 || percentile > 100 {
+		percentile = DefaultLearnedPercentile
+	}
+	if minSamples <= 0 {
+		minSamples = DefaultLearnedMinSamples
+	}
+	return &LearnedDurationPolicy{
+		history:    history,
+		condition:  condition,
+		percentile: percentile,
+		margin:     margin,
+		minSamples: minSamples,
+		fallback:   fallback,
+		timeFunc:   timeFunc,
+	}
+}
+
+func (p *LearnedDurationPolicy) Name() string {
+	return LearnedDurationPolicyName
+}
+
+// Valid returns true if the pod is still before the learned deadline
+func (p *LearnedDurationPolicy) Valid(pod *corev1.Pod) bool {
+	return p.Deadline(pod).After(p.timeFunc())
+}
+
+// TimeDependent returns true, as the policy validity depends on time
+func (*LearnedDurationPolicy) TimeDependent() bool {
+	return true
+}
+
+// Deadline returns the time when the policy stops being valid for a given POD, i.e.
+// the deadline recorded in the boost annotation when the POD was boosted. When the
+// deadline is not recorded, the POD creation time increased by the fallback duration
+// is returned.
+func (p *LearnedDurationPolicy) Deadline(pod *corev1.Pod) time.Time {
+	return annotatedDeadline(pod, p.fallback)
+}
+
+// Condition returns the type of the POD condition which time is learned
+func (p *LearnedDurationPolicy) Condition() corev1.PodConditionType {
+	return p.condition
+}
+
+// Fallback returns the duration applied until there are enough learned durations
+func (p *LearnedDurationPolicy) Fallback() time.Duration {
+	return p.fallback
+}
+
+// PredictDeadline returns the deadline of a POD boost, i.e. the current time increased
+// by the percentile of the durations learned for a given workload key and by the
+// margin. When the durations cannot be read or there are not enough of them, it
+// returns the current time increased by the fallback duration and false.
+func (p *LearnedDurationPolicy) PredictDeadline(ctx context.Context, key string) (time.Time, bool) {
+	now := p.timeFunc()
+	if p.history == nil {
+		return now.Add(p.fallback), false
+	}
+	durations, err := p.history.Durations(ctx, key)
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to read learned durations", "key", key)
+		return now.Add(p.fallback), false
+	}
+	if len(durations) < p.minSamples {
+		return now.Add(p.fallback), false
+	}
+	return now.Add(percentileDuration(durations, p.percentile) + p.margin), true
+}
+
+// Learn records the time a given POD took to meet the policy condition, measured since
+// the POD creation, for a given workload key. It returns false if the POD does not meet
+// the condition yet.
+func (p *LearnedDurationPolicy) Learn(ctx context.Context, key string, pod *corev1.Pod) (bool, error) {
+	d, ok := p.ConditionDuration(pod)
+	if !ok {
+		return false, nil
+	}
+	if p.history == nil {
+		return true, nil
+	}
+	return true, p.history.Add(ctx, key, d)
+}
+
+// ConditionDuration returns the time a given POD took to meet the policy condition,
+// measured since the POD creation, and true if the POD meets the condition
+func (p *LearnedDurationPolicy) ConditionDuration(pod *corev1.Pod) (time.Duration, bool) {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == p.condition && condition.Status == corev1.ConditionTrue {
+			return condition.LastTransitionTime.Sub(pod.CreationTimestamp.Time), true
+		}
+	}
+	return 0, false
+}
+
+// percentileDuration returns a given percentile of given durations using the
+// nearest-rank method
+func percentileDuration(durations []time.Duration, percentile int) time.Duration {
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	rank := int(math.Ceil(float64(percentile) / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// annotatedDeadline returns the boost deadline recorded in the boost annotation of
+// a given POD or, when not recorded, the POD creation time increased by a given
+// fallback duration
+func annotatedDeadline(pod *corev1.Pod, fallback time.Duration) time.Time {
+	if annotation, err := bpod.BoostAnnotationFromPod(pod); err == nil && annotation.DurationDeadline != nil {
+		return *annotation.DurationDeadline
+	}
+	return pod.CreationTimestamp.Add(fallback)
+}
@@ -0,0 +1,169 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package duration_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/kube-startup-cpu-boost/internal/boost/duration"
+	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeHistory is the in-memory history of the learned durations
+type fakeHistory struct {
+	durations map[string][]time.Duration
+	err       error
+}
+
+func (h *fakeHistory) Durations(ctx context.Context, key string) ([]time.Duration, error) {
+	return h.durations[key], h.err
+}
+
+func (h *fakeHistory) Add(ctx context.Context, key string, d time.Duration) error {
+	if h.err != nil {
+		return h.err
+	}
+	h.durations[key] = append(h.durations[key], d)
+	return nil
+}
+
+var _ = Describe("LearnedDurationPolicy", func() {
+	var (
+		history     *fakeHistory
+		now         time.Time
+		minSamples  int
+		policy      *duration.LearnedDurationPolicy
+		learnedPod  *corev1.Pod
+		creationAgo time.Duration
+	)
+	BeforeEach(func() {
+		history = &fakeHistory{durations: make(map[string][]time.Duration)}
+		now = time.Now()
+		minSamples = 3
+		creationAgo = time.Minute
+	})
+	JustBeforeEach(func() {
+		policy = duration.NewLearnedDurationPolicyWithTimeFunc(history, func() time.Time { return now },
+			"", 90, 5*time.Second, minSamples, 30*time.Second)
+		learnedPod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "demo-7f6d",
+				CreationTimestamp: metav1.NewTime(now.Add(-creationAgo)),
+			},
+		}
+	})
+	It("has a valid name", func() {
+		Expect(policy.Name()).To(Equal(duration.LearnedDurationPolicyName))
+	})
+	It("defaults the condition to Ready", func() {
+		Expect(policy.Condition()).To(Equal(corev1.PodReady))
+	})
+	It("is time dependent", func() {
+		Expect(duration.TimeDependent(policy)).To(BeTrue())
+	})
+	Describe("Predicts the deadline", func() {
+		var (
+			deadline time.Time
+			learned  bool
+		)
+		JustBeforeEach(func() {
+			deadline, learned = policy.PredictDeadline(context.TODO(), "deployment.demo.0123")
+		})
+		When("there are not enough learned durations", func() {
+			BeforeEach(func() {
+				history.durations["deployment.demo.0123"] = []time.Duration{10 * time.Second, 20 * time.Second}
+			})
+			It("returns the fallback deadline", func() {
+				Expect(learned).To(BeFalse())
+				Expect(deadline).To(Equal(now.Add(30 * time.Second)))
+			})
+		})
+		When("there are enough learned durations", func() {
+			BeforeEach(func() {
+				for i := 10; i >= 1; i-- {
+					history.durations["deployment.demo.0123"] = append(history.durations["deployment.demo.0123"],
+						time.Duration(i)*10*time.Second)
+				}
+			})
+			It("returns the percentile of the durations increased by the margin", func() {
+				Expect(learned).To(BeTrue())
+				Expect(deadline).To(Equal(now.Add(95 * time.Second)))
+			})
+		})
+		When("the history fails", func() {
+			BeforeEach(func() {
+				history.err = errors.New("history failure")
+			})
+			It("returns the fallback deadline", func() {
+				Expect(learned).To(BeFalse())
+				Expect(deadline).To(Equal(now.Add(30 * time.Second)))
+			})
+		})
+	})
+	Describe("Validates POD", func() {
+		It("returns policy is invalid after the fallback duration since the POD creation", func() {
+			Expect(policy.Valid(learnedPod)).To(BeFalse())
+		})
+		When("the POD has the deadline in the boost annotation", func() {
+			JustBeforeEach(func() {
+				annotation := bpod.NewBoostAnnotation()
+				deadline := now.Add(time.Second)
+				annotation.DurationDeadline = &deadline
+				learnedPod.Annotations = map[string]string{bpod.BoostAnnotationKey: annotation.ToJSON()}
+			})
+			It("returns policy is valid before the deadline", func() {
+				Expect(policy.Valid(learnedPod)).To(BeTrue())
+			})
+		})
+	})
+	Describe("Learns the POD duration", func() {
+		var (
+			learned bool
+			err     error
+		)
+		JustBeforeEach(func() {
+			learned, err = policy.Learn(context.TODO(), "deployment.demo.0123", learnedPod)
+		})
+		It("does not learn the POD that does not meet the condition", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(learned).To(BeFalse())
+			Expect(history.durations).To(BeEmpty())
+		})
+		When("the POD meets the condition", func() {
+			BeforeEach(func() {
+				creationAgo = 2 * time.Minute
+			})
+			JustBeforeEach(func() {
+				learnedPod.Status.Conditions = []corev1.PodCondition{{
+					Type:               corev1.PodReady,
+					Status:             corev1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(learnedPod.CreationTimestamp.Add(42 * time.Second)),
+				}}
+				learned, err = policy.Learn(context.TODO(), "deployment.demo.0123", learnedPod)
+			})
+			It("records the time since the POD creation", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(learned).To(BeTrue())
+				Expect(history.durations["deployment.demo.0123"]).To(Equal([]time.Duration{42 * time.Second}))
+			})
+		})
+	})
+})
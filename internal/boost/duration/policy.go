@@ -15,7 +15,11 @@
 // Package duration contains implementation of resource boost duration policies
 package duration
 
-import corev1 "k8s.io/api/core/v1"
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
 
 const (
 	PolicyTypeFixed        = "Fixed"
@@ -26,3 +30,29 @@ type Policy interface {
 	Valid(pod *corev1.Pod) bool
 	Name() string
 }
+
+// TimePolicy is implemented by the policies which validity may depend on time rather
+// than on the POD state. Such policies require periodic evaluation, as the POD updates
+// do not indicate when they stop being valid.
+type TimePolicy interface {
+	// TimeDependent returns true if the policy validity depends on time
+	TimeDependent() bool
+	// Deadline returns the time when the policy stops being valid for a given POD
+	Deadline(pod *corev1.Pod) time.Time
+}
+
+// TimeDependent returns true if the validity of a given policy depends on time,
+// thus the policy requires periodic evaluation
+func TimeDependent(policy Policy) bool {
+	timePolicy, ok := policy.(TimePolicy)
+	return ok && timePolicy.TimeDependent()
+}
+
+// Deadline returns the time when a given policy stops being valid for a given POD
+// and true if the policy depends on time. Otherwise, it returns false.
+func Deadline(policy Policy, pod *corev1.Pod) (time.Time, bool) {
+	if !TimeDependent(policy) {
+		return time.Time{}, false
+	}
+	return policy.(TimePolicy).Deadline(pod), true
+}
@@ -0,0 +1,282 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package history contains implementation of the stores of the boost durations
+// and resources learned by the learned duration and resource policies, and of the
+// POD startups recorded in the startup profiles
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	autoscaling "github.com/google/kube-startup-cpu-boost/api/v1alpha1"
+	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConfigMapNameSuffix is appended to the startup-cpu-boost name to get the name
+	// of the ConfigMap with its learned durations
+	ConfigMapNameSuffix = "-learned-durations"
+	// LastUpdatesAnnotationKey is the key of the ConfigMap annotation with the last
+	// update times of the ConfigMap keys
+	LastUpdatesAnnotationKey = "autoscaling.x-k8s.io/last-updates"
+	// DefaultMaxWorkloadKeys is the maximum number of the workload keys kept in the
+	// ConfigMap with the learned durations
+	DefaultMaxWorkloadKeys = 100
+	// MaxConfigMapDataSize is the maximum size of the ConfigMap data, in bytes, kept
+	// well below the ConfigMap size limit of 1 MiB
+	MaxConfigMapDataSize = 512 * 1024
+)
+
+// ConfigMapHistory stores the durations learned for the PODs of a startup-cpu-boost
+// in a ConfigMap in the startup-cpu-boost namespace. The ConfigMap is owned by the
+// startup-cpu-boost, so it is garbage collected with it, and it holds the latest
+// durations of every workload key as a JSON list of durations. The workload keys
+// change with the POD images, so the least recently updated keys are evicted when
+// there are more than the DefaultMaxWorkloadKeys or the data exceeds the
+// MaxConfigMapDataSize.
+type ConfigMapHistory struct {
+	store      configMapStore
+	maxSamples int
+}
+
+// ConfigMapName returns the name of the ConfigMap with the learned durations of
+// a startup-cpu-boost with a given name
+func ConfigMapName(boostName string) string {
+	return boostName + ConfigMapNameSuffix
+}
+
+// NewConfigMapHistory returns the history of a given startup-cpu-boost that keeps
+// a given number of the latest durations per workload key. The ConfigMap is read
+// and written with a given client, which is expected to read the ConfigMaps from
+// the API server, as the update reads the ConfigMap right after its creation.
+func NewConfigMapHistory(c client.Client, boost *autoscaling.StartupCPUBoost, maxSamples int) *ConfigMapHistory {
+	return &ConfigMapHistory{
+		store:      newConfigMapStore(c, boost, ConfigMapName(boost.Name), DefaultMaxWorkloadKeys),
+		maxSamples: maxSamples,
+	}
+}
+
+// Durations returns the durations learned for a given workload key, from the oldest
+// to the latest. It returns no durations when the ConfigMap does not exist.
+func (h *ConfigMapHistory) Durations(ctx context.Context, key string) ([]time.Duration, error) {
+	value, err := h.store.get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get learned durations: %w", err)
+	}
+	return decodeDurations(value)
+}
+
+// Add records a given duration learned for a given workload key. The oldest
+// durations of the key are dropped when there are more than the maximum. The
+// ConfigMap is created when it does not exist. The least recently updated keys are
+// evicted when the ConfigMap holds too many keys or too much data.
+func (h *ConfigMapHistory) Add(ctx context.Context, key string, d time.Duration) error {
+	return h.store.update(ctx, key, func(value string) string {
+		durations, err := decodeDurations(value)
+		if err != nil {
+			// the corrupted durations are replaced rather than blocking the learning
+			durations = nil
+		}
+		durations = append(durations, d)
+		if len(durations) > h.maxSamples && h.maxSamples > 0 {
+			durations = durations[len(durations)-h.maxSamples:]
+		}
+		return encodeDurations(durations)
+	})
+}
+
+// configMapStore reads and writes the values of a ConfigMap owned by a startup-cpu-boost
+type configMapStore struct {
+	client    client.Client
+	name      string
+	namespace string
+	owner     metav1.OwnerReference
+	maxKeys   int
+	timeFunc  func() time.Time
+}
+
+// newConfigMapStore returns the store of the ConfigMap with a given name owned by
+// a given startup-cpu-boost that keeps a given number of the most recently updated keys
+func newConfigMapStore(c client.Client, boost *autoscaling.StartupCPUBoost, name string, maxKeys int) configMapStore {
+	return configMapStore{
+		client:    c,
+		name:      name,
+		namespace: boost.Namespace,
+		maxKeys:   maxKeys,
+		timeFunc:  time.Now,
+		owner: metav1.OwnerReference{
+			APIVersion: autoscaling.GroupVersion.String(),
+			Kind:       "StartupCPUBoost",
+			Name:       boost.Name,
+			UID:        boost.UID,
+		},
+	}
+}
+
+// get returns the value of a given key. It returns empty value when the ConfigMap
+// does not exist.
+func (s configMapStore) get(ctx context.Context, key string) (string, error) {
+	configMap := &corev1.ConfigMap{}
+	err := s.client.Get(ctx, client.ObjectKey{Namespace: s.namespace, Name: s.name}, configMap)
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return configMap.Data[key], nil
+}
+
+// update sets the value of a given key to the one returned by a given function for
+// the current value and records the update time of the key. The least recently updated
+// keys are evicted when there are more than the maximum or the data exceeds the
+// MaxConfigMapDataSize. The ConfigMap is created when it does not exist, and the update
+// is retried on conflicts.
+func (s configMapStore) update(ctx context.Context, key string, updateFunc func(value string) string) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		configMap := &corev1.ConfigMap{}
+		err := s.client.Get(ctx, client.ObjectKey{Namespace: s.namespace, Name: s.name}, configMap)
+		if apierrors.IsNotFound(err) {
+			configMap = s.newConfigMap()
+		} else if err != nil {
+			return fmt.Errorf("failed to get ConfigMap %s: %w", s.name, err)
+		}
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		configMap.Data[key] = updateFunc(configMap.Data[key])
+		s.evictKeys(configMap, key)
+		if configMap.ResourceVersion == "" {
+			err = s.client.Create(ctx, configMap)
+			if apierrors.IsAlreadyExists(err) {
+				// retried as a conflict, so the existing ConfigMap is updated
+				return apierrors.NewConflict(corev1.Resource("configmaps"), s.name, err)
+			}
+			return err
+		}
+		return s.client.Update(ctx, configMap)
+	})
+}
+
+// evictKeys records the update time of a given key in a given ConfigMap and removes
+// the least recently updated keys, other than the given one, while there are more
+// keys than the maximum or the data exceeds the MaxConfigMapDataSize. The keys without
+// the recorded update time are considered the least recently updated.
+func (s configMapStore) evictKeys(configMap *corev1.ConfigMap, key string) {
+	updates := decodeLastUpdates(configMap.Annotations[LastUpdatesAnnotationKey])
+	updates[key] = s.timeFunc()
+	keys := make([]string, 0, len(configMap.Data))
+	size := 0
+	for k, v := range configMap.Data {
+		size += len(k) + len(v)
+		if k != key {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if ti, tj := updates[keys[i]], updates[keys[j]]; !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return keys[i] < keys[j]
+	})
+	for _, k := range keys {
+		if (s.maxKeys <= 0 || len(configMap.Data) <= s.maxKeys) && size <= MaxConfigMapDataSize {
+			break
+		}
+		size -= len(k) + len(configMap.Data[k])
+		delete(configMap.Data, k)
+	}
+	for k := range updates {
+		if _, ok := configMap.Data[k]; !ok {
+			delete(updates, k)
+		}
+	}
+	if configMap.Annotations == nil {
+		configMap.Annotations = make(map[string]string)
+	}
+	configMap.Annotations[LastUpdatesAnnotationKey] = encodeLastUpdates(updates)
+}
+
+// newConfigMap returns the empty ConfigMap of the store
+func (s configMapStore) newConfigMap() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            s.name,
+			Namespace:       s.namespace,
+			Labels:          map[string]string{bpod.BoostLabelKey: s.owner.Name},
+			OwnerReferences: []metav1.OwnerReference{s.owner},
+		},
+		Data: make(map[string]string),
+	}
+}
+
+// decodeLastUpdates decodes the update times of the keys from a given JSON object.
+// The corrupted update times are ignored, so the keys are considered the least
+// recently updated.
+func decodeLastUpdates(value string) map[string]time.Time {
+	updates := make(map[string]time.Time)
+	if value == "" {
+		return updates
+	}
+	if err := json.Unmarshal([]byte(value), &updates); err != nil {
+		return make(map[string]time.Time)
+	}
+	return updates
+}
+
+// encodeLastUpdates encodes given update times of the keys as a JSON object
+func encodeLastUpdates(updates map[string]time.Time) string {
+	encoded, _ := json.Marshal(updates)
+	return string(encoded)
+}
+
+// decodeDurations decodes the durations from a given JSON list of durations
+func decodeDurations(value string) ([]time.Duration, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var values []string
+	if err := json.Unmarshal([]byte(value), &values); err != nil {
+		return nil, fmt.Errorf("failed to decode learned durations: %w", err)
+	}
+	durations := make([]time.Duration, 0, len(values))
+	for _, v := range values {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode learned durations: %w", err)
+		}
+		durations = append(durations, d)
+	}
+	return durations, nil
+}
+
+// encodeDurations encodes given durations as a JSON list of durations
+func encodeDurations(durations []time.Duration) string {
+	values := make([]string, 0, len(durations))
+	for _, d := range durations {
+		values = append(values, d.String())
+	}
+	encoded, _ := json.Marshal(values)
+	return string(encoded)
+}
@@ -0,0 +1,193 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	autoscaling "github.com/google/kube-startup-cpu-boost/api/v1alpha1"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/history"
+	"github.com/google/kube-startup-cpu-boost/internal/mock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("ConfigMapHistory", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *mock.MockClient
+		stored     *corev1.ConfigMap
+		conflicts  int
+		boostObj   *autoscaling.StartupCPUBoost
+		h          *history.ConfigMapHistory
+	)
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock.NewMockClient(mockCtrl)
+		stored = nil
+		conflicts = 0
+		boostObj = &autoscaling.StartupCPUBoost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "boost-001",
+				Namespace: "demo",
+				UID:       "b0057-001",
+			},
+		}
+		name := types.NamespacedName{Namespace: "demo", Name: history.ConfigMapName("boost-001")}
+		mockClient.EXPECT().Get(gomock.Any(), gomock.Eq(name), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
+			DoAndReturn(func(ctx context.Context, key types.NamespacedName, obj client.Object, opts ...client.GetOption) error {
+				if stored == nil {
+					return apierrors.NewNotFound(corev1.Resource("configmaps"), key.Name)
+				}
+				stored.DeepCopyInto(obj.(*corev1.ConfigMap))
+				return nil
+			}).AnyTimes()
+		mockClient.EXPECT().Create(gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
+			DoAndReturn(func(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+				stored = obj.(*corev1.ConfigMap).DeepCopy()
+				stored.ResourceVersion = "1"
+				return nil
+			}).AnyTimes()
+		mockClient.EXPECT().Update(gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
+			DoAndReturn(func(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+				if conflicts > 0 {
+					conflicts--
+					return apierrors.NewConflict(corev1.Resource("configmaps"), obj.GetName(), nil)
+				}
+				stored = obj.(*corev1.ConfigMap).DeepCopy()
+				version, _ := strconv.Atoi(stored.ResourceVersion)
+				stored.ResourceVersion = strconv.Itoa(version + 1)
+				return nil
+			}).AnyTimes()
+	})
+	JustBeforeEach(func() {
+		h = history.NewConfigMapHistory(mockClient, boostObj, 3)
+	})
+	When("the ConfigMap does not exist", func() {
+		It("returns no durations", func() {
+			durations, err := h.Durations(context.TODO(), "deployment.demo.0123")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(durations).To(BeEmpty())
+		})
+		It("creates the ConfigMap owned by the boost", func() {
+			Expect(h.Add(context.TODO(), "deployment.demo.0123", 42*time.Second)).To(Succeed())
+			Expect(stored).NotTo(BeNil())
+			Expect(stored.Namespace).To(Equal("demo"))
+			Expect(stored.OwnerReferences).To(HaveLen(1))
+			Expect(stored.OwnerReferences[0].Kind).To(Equal("StartupCPUBoost"))
+			Expect(stored.OwnerReferences[0].UID).To(Equal(types.UID("b0057-001")))
+			Expect(stored.Data).To(HaveKeyWithValue("deployment.demo.0123", `["42s"]`))
+		})
+	})
+	When("the ConfigMap exists", func() {
+		BeforeEach(func() {
+			stored = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            history.ConfigMapName("boost-001"),
+					Namespace:       "demo",
+					ResourceVersion: "5",
+				},
+				Data: map[string]string{
+					"deployment.demo.0123":  `["10s","20s","30s"]`,
+					"deployment.other.4567": `["1m0s"]`,
+				},
+			}
+		})
+		It("returns the durations of the key", func() {
+			durations, err := h.Durations(context.TODO(), "deployment.demo.0123")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(durations).To(Equal([]time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second}))
+		})
+		It("keeps the latest durations of the key", func() {
+			Expect(h.Add(context.TODO(), "deployment.demo.0123", 40*time.Second)).To(Succeed())
+			Expect(stored.Data).To(HaveKeyWithValue("deployment.demo.0123", `["20s","30s","40s"]`))
+			Expect(stored.Data).To(HaveKeyWithValue("deployment.other.4567", `["1m0s"]`))
+		})
+		When("the update conflicts", func() {
+			BeforeEach(func() {
+				conflicts = 1
+			})
+			It("retries the update", func() {
+				Expect(h.Add(context.TODO(), "deployment.demo.0123", 40*time.Second)).To(Succeed())
+				Expect(stored.Data).To(HaveKeyWithValue("deployment.demo.0123", `["20s","30s","40s"]`))
+			})
+		})
+		It("records the update time of the key", func() {
+			Expect(h.Add(context.TODO(), "deployment.demo.0123", 40*time.Second)).To(Succeed())
+			Expect(stored.Annotations).To(HaveKeyWithValue(history.LastUpdatesAnnotationKey,
+				ContainSubstring(`"deployment.demo.0123"`)))
+		})
+		When("the ConfigMap has the maximum number of keys", func() {
+			BeforeEach(func() {
+				updates := make(map[string]time.Time)
+				for i := len(stored.Data); i < history.DefaultMaxWorkloadKeys; i++ {
+					key := fmt.Sprintf("deployment.demo.%04d", i)
+					stored.Data[key] = `["10s"]`
+					updates[key] = time.Now().Add(-time.Hour)
+				}
+				updates["deployment.demo.0123"] = time.Now().Add(-time.Hour)
+				encoded, err := json.Marshal(updates)
+				Expect(err).NotTo(HaveOccurred())
+				stored.Annotations = map[string]string{history.LastUpdatesAnnotationKey: string(encoded)}
+			})
+			It("evicts the least recently updated key", func() {
+				Expect(h.Add(context.TODO(), "deployment.new.8901", 40*time.Second)).To(Succeed())
+				Expect(stored.Data).To(HaveLen(history.DefaultMaxWorkloadKeys))
+				Expect(stored.Data).To(HaveKeyWithValue("deployment.new.8901", `["40s"]`))
+				Expect(stored.Data).NotTo(HaveKey("deployment.other.4567"))
+				Expect(stored.Annotations[history.LastUpdatesAnnotationKey]).NotTo(ContainSubstring("deployment.other.4567"))
+			})
+			It("does not evict keys when the existing key is updated", func() {
+				Expect(h.Add(context.TODO(), "deployment.demo.0123", 40*time.Second)).To(Succeed())
+				Expect(stored.Data).To(HaveLen(history.DefaultMaxWorkloadKeys))
+				Expect(stored.Data).To(HaveKey("deployment.other.4567"))
+			})
+		})
+		When("the ConfigMap data exceeds the maximum size", func() {
+			BeforeEach(func() {
+				stored.Data["deployment.other.4567"] = strings.Repeat("x", history.MaxConfigMapDataSize)
+			})
+			It("evicts the least recently updated key", func() {
+				Expect(h.Add(context.TODO(), "deployment.demo.0123", 40*time.Second)).To(Succeed())
+				Expect(stored.Data).NotTo(HaveKey("deployment.other.4567"))
+				Expect(stored.Data).To(HaveKeyWithValue("deployment.demo.0123", `["20s","30s","40s"]`))
+			})
+		})
+		When("the durations are corrupted", func() {
+			BeforeEach(func() {
+				stored.Data["deployment.demo.0123"] = "corrupted"
+			})
+			It("returns error", func() {
+				_, err := h.Durations(context.TODO(), "deployment.demo.0123")
+				Expect(err).To(HaveOccurred())
+			})
+			It("replaces them with the new duration", func() {
+				Expect(h.Add(context.TODO(), "deployment.demo.0123", 40*time.Second)).To(Succeed())
+				Expect(stored.Data).To(HaveKeyWithValue("deployment.demo.0123", `["40s"]`))
+			})
+		})
+	})
+})
@@ -0,0 +1,225 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	autoscaling "github.com/google/kube-startup-cpu-boost/api/v1alpha1"
+	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultStartupProfileMaxSamples is the default number of the latest startups kept
+// in the StartupProfile
+const DefaultStartupProfileMaxSamples = 20
+
+// StartupProfileStore records the startups of the PODs boosted by a startup-cpu-boost
+// in the StartupProfiles of their workloads in the startup-cpu-boost namespace. The
+// profiles are owned by the startup-cpu-boost, so they are garbage collected with it,
+// and they keep the latest startups with their summary statistics in the status. The
+// workload is identified by the POD top-level owner only, so the new workload version
+// updates the same profile, and the startups record the hash of the container images.
+type StartupProfileStore struct {
+	client     client.Client
+	owners     *predictor.OwnerResolver
+	boostName  string
+	namespace  string
+	owner      metav1.OwnerReference
+	maxSamples int
+	timeFunc   func() time.Time
+}
+
+// StartupProfileName returns the name of the StartupProfile of a workload with a given
+// key boosted by a startup-cpu-boost with a given name. The workload key is replaced
+// by its hash when the name is not a valid object name.
+func StartupProfileName(boostName, workloadKey string) string {
+	name := boostName + "." + workloadKey
+	if len(validation.IsDNS1123Subdomain(name)) == 0 {
+		return name
+	}
+	hash := sha256.Sum256([]byte(workloadKey))
+	return boostName + "." + hex.EncodeToString(hash[:])[:16]
+}
+
+// NewStartupProfileStore returns the store of a given startup-cpu-boost that keeps
+// a given number of the latest startups per workload. The profiles are read and
+// written, and the POD owners are resolved with a given client.
+func NewStartupProfileStore(c client.Client, boost *autoscaling.StartupCPUBoost, maxSamples int) *StartupProfileStore {
+	return NewStartupProfileStoreWithTimeFunc(c, time.Now, boost, maxSamples)
+}
+
+// NewStartupProfileStoreWithTimeFunc returns the store of a given startup-cpu-boost that
+// keeps a given number of the latest startups per workload and gets the update time with
+// a given time function
+func NewStartupProfileStoreWithTimeFunc(c client.Client, timeFunc func() time.Time,
+	boost *autoscaling.StartupCPUBoost, maxSamples int) *StartupProfileStore {
+	return &StartupProfileStore{
+		client:    c,
+		owners:    predictor.NewOwnerResolver(c),
+		boostName: boost.Name,
+		namespace: boost.Namespace,
+		owner: metav1.OwnerReference{
+			APIVersion: autoscaling.GroupVersion.String(),
+			Kind:       "StartupCPUBoost",
+			Name:       boost.Name,
+			UID:        boost.UID,
+		},
+		maxSamples: maxSamples,
+		timeFunc:   timeFunc,
+	}
+}
+
+// Record adds a given startup sample of a given POD to the StartupProfile of the POD
+// workload. The oldest samples are dropped when there are more than the maximum, and
+// the summary is computed again from the kept samples. The profile is created when
+// it does not exist, and the update is retried on conflicts.
+func (s *StartupProfileStore) Record(ctx context.Context, pod *corev1.Pod, sample autoscaling.StartupSample) error {
+	owner, err := s.owners.Resolve(ctx, pod)
+	if err != nil {
+		return fmt.Errorf("failed to resolve pod owner: %w", err)
+	}
+	workloadKey := ProfileWorkloadKey(owner, pod)
+	name := StartupProfileName(s.boostName, workloadKey)
+	sample.ImagesHash = predictor.ImagesHash(pod)
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		profile := &autoscaling.StartupProfile{}
+		err := s.client.Get(ctx, client.ObjectKey{Namespace: s.namespace, Name: name}, profile)
+		if apierrors.IsNotFound(err) {
+			profile = s.newStartupProfile(name, workloadKey, owner)
+			err = s.client.Create(ctx, profile)
+			if apierrors.IsAlreadyExists(err) {
+				// retried as a conflict, so the existing profile is updated
+				return apierrors.NewConflict(autoscaling.GroupVersion.WithResource("startupprofiles").GroupResource(),
+					name, err)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to create StartupProfile %s: %w", name, err)
+			}
+		} else if err != nil {
+			return fmt.Errorf("failed to get StartupProfile %s: %w", name, err)
+		}
+		s.addSample(&profile.Status, sample)
+		return s.client.Status().Update(ctx, profile)
+	})
+}
+
+// ProfileWorkloadKey returns the key of the StartupProfile workload of a given POD,
+// i.e. the kind and name of the POD top-level owner. The PODs without the owner are
+// keyed by the hash of their container images.
+func ProfileWorkloadKey(owner *predictor.Owner, pod *corev1.Pod) string {
+	if owner == nil {
+		return predictor.ImagesHash(pod)
+	}
+	return strings.ToLower(owner.Kind) + "." + owner.Name
+}
+
+// addSample adds a given sample to a given profile status
+func (s *StartupProfileStore) addSample(status *autoscaling.StartupProfileStatus, sample autoscaling.StartupSample) {
+	status.Samples = append(status.Samples, sample)
+	if len(status.Samples) > s.maxSamples && s.maxSamples > 0 {
+		status.Samples = status.Samples[len(status.Samples)-s.maxSamples:]
+	}
+	status.TotalSamples++
+	status.Summary = SummarizeStartups(status.Samples)
+	now := metav1.NewTime(s.timeFunc())
+	status.LastUpdateTime = &now
+}
+
+// newStartupProfile returns the empty StartupProfile of the store with a given name
+// for a workload with a given key and owner
+func (s *StartupProfileStore) newStartupProfile(name, workloadKey string, owner *predictor.Owner) *autoscaling.StartupProfile {
+	profile := &autoscaling.StartupProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       s.namespace,
+			Labels:          map[string]string{bpod.BoostLabelKey: s.boostName},
+			OwnerReferences: []metav1.OwnerReference{s.owner},
+		},
+		Spec: autoscaling.StartupProfileSpec{
+			BoostName: s.boostName,
+			Workload:  workloadKey,
+		},
+	}
+	if owner != nil {
+		profile.Spec.Owner = &autoscaling.StartupProfileOwner{
+			APIVersion: owner.APIVersion,
+			Kind:       owner.Kind,
+			Name:       owner.Name,
+		}
+	}
+	return profile
+}
+
+// SummarizeStartups returns the statistics of given startup samples. The durations
+// that are not set in some samples are summarized from the remaining ones, and are
+// not summarized when not set in any sample.
+func SummarizeStartups(samples []autoscaling.StartupSample) autoscaling.StartupProfileSummary {
+	var scheduled, started, ready, boosted []time.Duration
+	for _, sample := range samples {
+		if sample.TimeToScheduled != nil {
+			scheduled = append(scheduled, sample.TimeToScheduled.Duration)
+		}
+		if sample.TimeToStarted != nil {
+			started = append(started, sample.TimeToStarted.Duration)
+		}
+		if sample.TimeToReady != nil {
+			ready = append(ready, sample.TimeToReady.Duration)
+		}
+		if sample.RevertTime != nil {
+			boosted = append(boosted, sample.RevertTime.Sub(sample.CreationTime.Time))
+		}
+	}
+	return autoscaling.StartupProfileSummary{
+		TimeToScheduled: summarizeDurations(scheduled),
+		TimeToStarted:   summarizeDurations(started),
+		TimeToReady:     summarizeDurations(ready),
+		BoostDuration:   summarizeDurations(boosted),
+	}
+}
+
+// summarizeDurations returns the statistics of given durations or nil if there are
+// no durations. The percentiles use the nearest-rank method.
+func summarizeDurations(durations []time.Duration) *autoscaling.DurationSummary {
+	if len(durations) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	percentile := func(p int) metav1.Duration {
+		rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+		return metav1.Duration{Duration: sorted[max(rank, 1)-1]}
+	}
+	return &autoscaling.DurationSummary{
+		Samples: int32(len(sorted)),
+		Min:     metav1.Duration{Duration: sorted[0]},
+		Median:  percentile(50),
+		P90:     percentile(90),
+		Max:     metav1.Duration{Duration: sorted[len(sorted)-1]},
+	}
+}
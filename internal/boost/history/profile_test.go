@@ -0,0 +1,196 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	autoscaling "github.com/google/kube-startup-cpu-boost/api/v1alpha1"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/history"
+	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
+	"github.com/google/kube-startup-cpu-boost/internal/mock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("StartupProfileStore", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *mock.MockClient
+		stored     *autoscaling.StartupProfile
+		now        time.Time
+		pod        *corev1.Pod
+		store      *history.StartupProfileStore
+		profileKey types.NamespacedName
+	)
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock.NewMockClient(mockCtrl)
+		stored = nil
+		now = time.Now()
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "demo-0",
+				Namespace: "demo",
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "apps/v1",
+					Kind:       "StatefulSet",
+					Name:       "demo",
+					Controller: func() *bool { c := true; return &c }(),
+				}},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "main", Image: "demo:1.0"}},
+			},
+		}
+		profileKey = types.NamespacedName{Namespace: "demo", Name: "boost-001.statefulset.demo"}
+		mockClient.EXPECT().Get(gomock.Any(), gomock.Eq(profileKey), gomock.AssignableToTypeOf(&autoscaling.StartupProfile{})).
+			DoAndReturn(func(ctx context.Context, key types.NamespacedName, obj client.Object, opts ...client.GetOption) error {
+				if stored == nil {
+					return apierrors.NewNotFound(autoscaling.GroupVersion.WithResource("startupprofiles").GroupResource(), key.Name)
+				}
+				stored.DeepCopyInto(obj.(*autoscaling.StartupProfile))
+				return nil
+			}).AnyTimes()
+		mockClient.EXPECT().Create(gomock.Any(), gomock.AssignableToTypeOf(&autoscaling.StartupProfile{})).
+			DoAndReturn(func(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+				obj.SetResourceVersion("1")
+				stored = obj.(*autoscaling.StartupProfile).DeepCopy()
+				return nil
+			}).AnyTimes()
+		mockSubResWriter := mock.NewMockSubResourceWriter(mockCtrl)
+		mockSubResWriter.EXPECT().Update(gomock.Any(), gomock.AssignableToTypeOf(&autoscaling.StartupProfile{})).
+			DoAndReturn(func(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				stored = obj.(*autoscaling.StartupProfile).DeepCopy()
+				return nil
+			}).AnyTimes()
+		mockClient.EXPECT().Status().Return(mockSubResWriter).AnyTimes()
+		store = history.NewStartupProfileStoreWithTimeFunc(mockClient, func() time.Time { return now },
+			&autoscaling.StartupCPUBoost{
+				ObjectMeta: metav1.ObjectMeta{Name: "boost-001", Namespace: "demo", UID: "b0057-001"},
+			}, 2)
+	})
+	sample := func(podName string, timeToReady time.Duration) autoscaling.StartupSample {
+		return autoscaling.StartupSample{
+			PodName:      podName,
+			CreationTime: metav1.NewTime(now.Add(-time.Minute)),
+			TimeToReady:  &metav1.Duration{Duration: timeToReady},
+		}
+	}
+	It("creates the profile of the POD workload", func() {
+		Expect(store.Record(context.TODO(), pod, sample("demo-0", 42*time.Second))).To(Succeed())
+		Expect(stored).NotTo(BeNil())
+		Expect(stored.Name).To(Equal("boost-001.statefulset.demo"))
+		Expect(stored.Labels).To(HaveKeyWithValue(bpod.BoostLabelKey, "boost-001"))
+		Expect(stored.OwnerReferences).To(HaveLen(1))
+		Expect(stored.OwnerReferences[0].Name).To(Equal("boost-001"))
+		Expect(stored.Spec.BoostName).To(Equal("boost-001"))
+		Expect(stored.Spec.Workload).To(Equal("statefulset.demo"))
+		Expect(stored.Spec.Owner).To(Equal(&autoscaling.StartupProfileOwner{
+			APIVersion: "apps/v1",
+			Kind:       "StatefulSet",
+			Name:       "demo",
+		}))
+		Expect(stored.Status.Samples).To(HaveLen(1))
+		Expect(stored.Status.Samples[0].ImagesHash).To(Equal(predictor.ImagesHash(pod)))
+		Expect(stored.Status.TotalSamples).To(Equal(int32(1)))
+		Expect(stored.Status.LastUpdateTime.Time).To(BeTemporally("==", now))
+	})
+	It("records the startups of the new workload version in the same profile", func() {
+		Expect(store.Record(context.TODO(), pod, sample("demo-0", 42*time.Second))).To(Succeed())
+		updated := pod.DeepCopy()
+		updated.Name = "demo-1"
+		updated.Spec.Containers[0].Image = "demo:2.0"
+		Expect(store.Record(context.TODO(), updated, sample("demo-1", 21*time.Second))).To(Succeed())
+		Expect(stored.Status.Samples).To(HaveLen(2))
+		Expect(stored.Status.Samples[0].ImagesHash).To(Equal(predictor.ImagesHash(pod)))
+		Expect(stored.Status.Samples[1].ImagesHash).To(Equal(predictor.ImagesHash(updated)))
+		Expect(stored.Status.Samples[0].ImagesHash).NotTo(Equal(stored.Status.Samples[1].ImagesHash))
+	})
+	It("keeps the latest samples with their summary", func() {
+		for i, timeToReady := range []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second} {
+			Expect(store.Record(context.TODO(), pod, sample(fmt.Sprintf("demo-%d", i), timeToReady))).To(Succeed())
+		}
+		Expect(stored.Status.Samples).To(HaveLen(2))
+		Expect(stored.Status.Samples[0].PodName).To(Equal("demo-1"))
+		Expect(stored.Status.Samples[1].PodName).To(Equal("demo-2"))
+		Expect(stored.Status.TotalSamples).To(Equal(int32(3)))
+		Expect(stored.Status.Summary.TimeToReady).NotTo(BeNil())
+		Expect(stored.Status.Summary.TimeToReady.Samples).To(Equal(int32(2)))
+		Expect(stored.Status.Summary.TimeToReady.Min.Duration).To(Equal(20 * time.Second))
+		Expect(stored.Status.Summary.TimeToReady.Max.Duration).To(Equal(30 * time.Second))
+		Expect(stored.Status.Summary.TimeToScheduled).To(BeNil())
+	})
+})
+
+var _ = Describe("StartupProfileName", func() {
+	It("joins the boost name and the workload key", func() {
+		Expect(history.StartupProfileName("boost-001", "deployment.demo")).
+			To(Equal("boost-001.deployment.demo"))
+	})
+	It("hashes the workload key that is not a valid name", func() {
+		name := history.StartupProfileName("boost-001", "deployment.Demo_App")
+		Expect(name).To(HavePrefix("boost-001."))
+		Expect(name).To(HaveLen(len("boost-001.") + 16))
+	})
+})
+
+var _ = Describe("SummarizeStartups", func() {
+	var (
+		created time.Time
+		samples []autoscaling.StartupSample
+	)
+	BeforeEach(func() {
+		created = time.Now()
+		samples = nil
+		for i := 1; i <= 10; i++ {
+			revertTime := metav1.NewTime(created.Add(time.Duration(i) * time.Minute))
+			samples = append(samples, autoscaling.StartupSample{
+				PodName:         fmt.Sprintf("demo-%d", i),
+				CreationTime:    metav1.NewTime(created),
+				TimeToScheduled: &metav1.Duration{Duration: time.Duration(i) * time.Second},
+				TimeToReady:     &metav1.Duration{Duration: time.Duration(11-i) * 10 * time.Second},
+				RevertTime:      &revertTime,
+			})
+		}
+		samples = append(samples, autoscaling.StartupSample{PodName: "demo-deleted", CreationTime: metav1.NewTime(created)})
+	})
+	It("summarizes the durations set in the samples", func() {
+		summary := history.SummarizeStartups(samples)
+		Expect(summary.TimeToScheduled).To(Equal(&autoscaling.DurationSummary{
+			Samples: 10,
+			Min:     metav1.Duration{Duration: time.Second},
+			Median:  metav1.Duration{Duration: 5 * time.Second},
+			P90:     metav1.Duration{Duration: 9 * time.Second},
+			Max:     metav1.Duration{Duration: 10 * time.Second},
+		}))
+		Expect(summary.TimeToReady.Median.Duration).To(Equal(50 * time.Second))
+		Expect(summary.TimeToReady.P90.Duration).To(Equal(90 * time.Second))
+		Expect(summary.BoostDuration.Max.Duration).To(Equal(10 * time.Minute))
+		Expect(summary.TimeToStarted).To(BeNil())
+	})
+	It("returns the empty summary when there are no samples", func() {
+		Expect(history.SummarizeStartups(nil)).To(Equal(autoscaling.StartupProfileSummary{}))
+	})
+})
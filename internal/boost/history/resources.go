@@ -0,0 +1,106 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	autoscaling "github.com/google/kube-startup-cpu-boost/api/v1alpha1"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ResourceConfigMapNameSuffix is appended to the startup-cpu-boost name to get the
+	// name of the ConfigMap with its learned resources
+	ResourceConfigMapNameSuffix = "-learned-resources"
+	// DefaultMaxWorkloadContainerKeys is the maximum number of the workload container
+	// keys kept in the ConfigMap with the learned resources
+	DefaultMaxWorkloadContainerKeys = 200
+)
+
+// ConfigMapResourceHistory stores the startups observed by the learned resource
+// policies of a startup-cpu-boost in a ConfigMap in the startup-cpu-boost namespace.
+// The ConfigMap is owned by the startup-cpu-boost, so it is garbage collected with
+// it, and it holds the latest startups of every workload container as a JSON list.
+// The workload keys change with the POD images, so the least recently updated keys are
+// evicted when there are more than the DefaultMaxWorkloadContainerKeys or the data
+// exceeds the MaxConfigMapDataSize.
+type ConfigMapResourceHistory struct {
+	store      configMapStore
+	maxSamples int
+}
+
+// ResourceConfigMapName returns the name of the ConfigMap with the learned resources
+// of a startup-cpu-boost with a given name
+func ResourceConfigMapName(boostName string) string {
+	return boostName + ResourceConfigMapNameSuffix
+}
+
+// NewConfigMapResourceHistory returns the history of a given startup-cpu-boost that
+// keeps a given number of the latest startups per workload container. The ConfigMap
+// is read and written with a given client, which is expected to read the ConfigMaps
+// from the API server, as the update reads the ConfigMap right after its creation.
+func NewConfigMapResourceHistory(c client.Client, boost *autoscaling.StartupCPUBoost,
+	maxSamples int) *ConfigMapResourceHistory {
+	return &ConfigMapResourceHistory{
+		store:      newConfigMapStore(c, boost, ResourceConfigMapName(boost.Name), DefaultMaxWorkloadContainerKeys),
+		maxSamples: maxSamples,
+	}
+}
+
+// Samples returns the startups observed for a given workload container key, from
+// the oldest to the latest. It returns no startups when the ConfigMap does not exist.
+func (h *ConfigMapResourceHistory) Samples(ctx context.Context, key string) ([]resource.ResourceSample, error) {
+	value, err := h.store.get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get learned resources: %w", err)
+	}
+	return decodeSamples(value)
+}
+
+// Add records a given startup observed for a given workload container key. The oldest
+// startups of the key are dropped when there are more than the maximum. The ConfigMap
+// is created when it does not exist. The least recently updated keys are evicted when
+// the ConfigMap holds too many keys or too much data.
+func (h *ConfigMapResourceHistory) Add(ctx context.Context, key string, sample resource.ResourceSample) error {
+	return h.store.update(ctx, key, func(value string) string {
+		samples, err := decodeSamples(value)
+		if err != nil {
+			// the corrupted startups are replaced rather than blocking the learning
+			samples = nil
+		}
+		samples = append(samples, sample)
+		if len(samples) > h.maxSamples && h.maxSamples > 0 {
+			samples = samples[len(samples)-h.maxSamples:]
+		}
+		encoded, _ := json.Marshal(samples)
+		return string(encoded)
+	})
+}
+
+// decodeSamples decodes the startups from a given JSON list of startups
+func decodeSamples(value string) ([]resource.ResourceSample, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var samples []resource.ResourceSample
+	if err := json.Unmarshal([]byte(value), &samples); err != nil {
+		return nil, fmt.Errorf("failed to decode learned resources: %w", err)
+	}
+	return samples, nil
+}
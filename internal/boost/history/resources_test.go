@@ -0,0 +1,132 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	autoscaling "github.com/google/kube-startup-cpu-boost/api/v1alpha1"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/history"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resource"
+	"github.com/google/kube-startup-cpu-boost/internal/mock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apiResource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("ConfigMapResourceHistory", func() {
+	var (
+		mockClient *mock.MockClient
+		stored     *corev1.ConfigMap
+		h          *history.ConfigMapResourceHistory
+	)
+	BeforeEach(func() {
+		mockClient = mock.NewMockClient(gomock.NewController(GinkgoT()))
+		stored = nil
+		name := types.NamespacedName{Namespace: "demo", Name: history.ResourceConfigMapName("boost-001")}
+		mockClient.EXPECT().Get(gomock.Any(), gomock.Eq(name), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
+			DoAndReturn(func(ctx context.Context, key types.NamespacedName, obj client.Object, opts ...client.GetOption) error {
+				if stored == nil {
+					return apierrors.NewNotFound(corev1.Resource("configmaps"), key.Name)
+				}
+				stored.DeepCopyInto(obj.(*corev1.ConfigMap))
+				return nil
+			}).AnyTimes()
+		mockClient.EXPECT().Create(gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
+			DoAndReturn(func(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+				stored = obj.(*corev1.ConfigMap).DeepCopy()
+				stored.ResourceVersion = "1"
+				return nil
+			}).AnyTimes()
+		mockClient.EXPECT().Update(gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
+			DoAndReturn(func(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+				stored = obj.(*corev1.ConfigMap).DeepCopy()
+				return nil
+			}).AnyTimes()
+		h = history.NewConfigMapResourceHistory(mockClient, &autoscaling.StartupCPUBoost{
+			ObjectMeta: metav1.ObjectMeta{Name: "boost-001", Namespace: "demo", UID: "b0057-001"},
+		}, 2)
+	})
+	It("returns no startups when the ConfigMap does not exist", func() {
+		samples, err := h.Samples(context.TODO(), "deployment.demo.0123.main")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(samples).To(BeEmpty())
+	})
+	It("stores the latest startups of the key", func() {
+		usage := apiResource.MustParse("750m")
+		for _, cpu := range []string{"1", "1250m", "1570m"} {
+			Expect(h.Add(context.TODO(), "deployment.demo.0123.main", resource.ResourceSample{
+				CPU:             apiResource.MustParse(cpu),
+				StartupDuration: metav1.Duration{Duration: 42 * time.Second},
+				CPUUsage:        &usage,
+			})).To(Succeed())
+		}
+		Expect(stored.Name).To(Equal("boost-001-learned-resources"))
+		Expect(stored.OwnerReferences).To(HaveLen(1))
+		Expect(stored.Data).To(HaveKeyWithValue("deployment.demo.0123.main",
+			`[{"cpu":"1250m","startupDuration":"42s","cpuUsage":"750m"},{"cpu":"1570m","startupDuration":"42s","cpuUsage":"750m"}]`))
+		samples, err := h.Samples(context.TODO(), "deployment.demo.0123.main")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(samples).To(HaveLen(2))
+		Expect(samples[0].CPU.String()).To(Equal("1250m"))
+		Expect(samples[1].StartupDuration.Duration).To(Equal(42 * time.Second))
+		Expect(samples[1].CPUUsage.String()).To(Equal("750m"))
+	})
+	It("evicts the least recently updated keys", func() {
+		stored = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{ResourceVersion: "5"},
+			Data:       make(map[string]string),
+		}
+		for i := 0; i < history.DefaultMaxWorkloadContainerKeys; i++ {
+			stored.Data[fmt.Sprintf("deployment.demo.%04d.main", i)] = `[{"cpu":"1","startupDuration":"1m0s"}]`
+		}
+		stored.Annotations = map[string]string{history.LastUpdatesAnnotationKey: fmt.Sprintf(`{"deployment.demo.0000.main":%q}`,
+			time.Now().Add(-time.Hour).Format(time.RFC3339))}
+		for i := 1; i < history.DefaultMaxWorkloadContainerKeys; i++ {
+			Expect(h.Add(context.TODO(), fmt.Sprintf("deployment.demo.%04d.main", i), resource.ResourceSample{
+				CPU:             apiResource.MustParse("1"),
+				StartupDuration: metav1.Duration{Duration: time.Minute},
+			})).To(Succeed())
+		}
+		Expect(h.Add(context.TODO(), "deployment.new.8901.main", resource.ResourceSample{
+			CPU:             apiResource.MustParse("1"),
+			StartupDuration: metav1.Duration{Duration: time.Minute},
+		})).To(Succeed())
+		Expect(stored.Data).To(HaveLen(history.DefaultMaxWorkloadContainerKeys))
+		Expect(stored.Data).To(HaveKey("deployment.new.8901.main"))
+		Expect(stored.Data).NotTo(HaveKey("deployment.demo.0000.main"))
+	})
+	It("replaces the corrupted startups", func() {
+		stored = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{ResourceVersion: "5"},
+			Data:       map[string]string{"deployment.demo.0123.main": "corrupted"},
+		}
+		_, err := h.Samples(context.TODO(), "deployment.demo.0123.main")
+		Expect(err).To(HaveOccurred())
+		Expect(h.Add(context.TODO(), "deployment.demo.0123.main", resource.ResourceSample{
+			CPU:             apiResource.MustParse("1"),
+			StartupDuration: metav1.Duration{Duration: time.Minute},
+		})).To(Succeed())
+		Expect(stored.Data).To(HaveKeyWithValue("deployment.demo.0123.main", `[{"cpu":"1","startupDuration":"1m0s"}]`))
+	})
+})
@@ -23,11 +23,16 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/go-logr/logr"
+	autoscaling "github.com/google/kube-startup-cpu-boost/api/v1alpha1"
 	"github.com/google/kube-startup-cpu-boost/internal/boost/duration"
+	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resize"
 
 	"github.com/google/kube-startup-cpu-boost/internal/metrics"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -35,58 +40,83 @@ import (
 
 var (
 	errStartupCPUBoostAlreadyExists = errors.New("startupCPUBoost already exists")
+	errStartupCPUBoostNotFound      = errors.New("startupCPUBoost not found")
 )
 
 const (
 	DefaultManagerCheckInterval = time.Duration(5 * time.Second)
 	DefaultMaxGoroutines        = 10
+	// minTickInterval is the shortest time the ticker is reset to, as the ticker
+	// does not accept non-positive durations
+	minTickInterval = time.Millisecond
 )
 
 type Manager interface {
 	// AddStartupCPUBoost registers a new startup-cpu-boost is a manager.
 	AddStartupCPUBoost(ctx context.Context, boost StartupCPUBoost) error
+	// UpdateStartupCPUBoost replaces a registered startup-cpu-boost with a given one.
+	UpdateStartupCPUBoost(ctx context.Context, boost StartupCPUBoost) error
 	// RemoveStartupCPUBoost removes a startup-cpu-boost from a manager
 	RemoveStartupCPUBoost(ctx context.Context, namespace, name string)
 	// StartupCPUBoost returns a startup-cpu-boost with a given name and namespace
 	StartupCPUBoostForPod(ctx context.Context, pod *corev1.Pod) (StartupCPUBoost, bool)
 	// StartupCPUBoostForPod returns a startup-cpu-boost that matches a given pod
 	StartupCPUBoost(namespace, name string) (StartupCPUBoost, bool)
+	// StartupCPUBoostForPodResize returns a startup-cpu-boost that tracks the in-place
+	// resize of a given pod
+	StartupCPUBoostForPodResize(pod *corev1.Pod) (StartupCPUBoost, bool)
+	// StartupCPUBoostForPodStartup returns a startup-cpu-boost that tracks the startup
+	// of a given pod for the learned duration or resource policy, or the startup profile
+	StartupCPUBoostForPodStartup(pod *corev1.Pod) (StartupCPUBoost, bool)
 	SetStartupCPUBoostReconciler(reconciler reconcile.Reconciler)
+	// SetEventRecorder sets the recorder of the warnings about the resources of orphaned
+	// PODs left boosted after the reversion
+	SetEventRecorder(recorder record.EventRecorder)
 	Start(ctx context.Context) error
 }
 
 type TimeTicker interface {
 	Tick() <-chan time.Time
+	// Reset changes the ticker period to a given duration, so the next tick
+	// arrives after it elapses
+	Reset(d time.Duration)
 	Stop()
 }
 
 type timeTickerImpl struct {
-	t time.Ticker
+	t *time.Ticker
 }
 
 func (t *timeTickerImpl) Tick() <-chan time.Time {
 	return t.t.C
 }
 
+func (t *timeTickerImpl) Reset(d time.Duration) {
+	t.t.Reset(d)
+}
+
 func (t *timeTickerImpl) Stop() {
 	t.t.Stop()
 }
 
 func newTimeTickerImpl(d time.Duration) TimeTicker {
 	return &timeTickerImpl{
-		t: *time.NewTicker(d),
+		t: time.NewTicker(d),
 	}
 }
 
 type managerImpl struct {
 	sync.RWMutex
 	client           client.Client
+	resizer          resize.Strategy
+	recorder         record.EventRecorder
 	reconciler       reconcile.Reconciler
 	ticker           TimeTicker
 	checkInterval    time.Duration
 	startupCPUBoosts map[string]map[string]StartupCPUBoost
-	timePolicyBoosts map[boostKey]StartupCPUBoost
+	queue            *podDeadlineQueue
 	maxGoroutines    int
+	owners           *predictor.OwnerResolver
 	log              logr.Logger
 }
 
@@ -95,49 +125,94 @@ type boostKey struct {
 	namespace string
 }
 
-func NewManager(client client.Client) Manager {
-	return NewManagerWithTicker(client, newTimeTickerImpl(DefaultManagerCheckInterval))
+func NewManager(client client.Client, resizer resize.Strategy) Manager {
+	return NewManagerWithTicker(client, resizer, newTimeTickerImpl(DefaultManagerCheckInterval))
 }
 
-func NewManagerWithTicker(client client.Client, ticker TimeTicker) Manager {
+func NewManagerWithTicker(client client.Client, resizer resize.Strategy, ticker TimeTicker) Manager {
 	return &managerImpl{
 		client:           client,
+		resizer:          resizer,
 		ticker:           ticker,
 		checkInterval:    DefaultManagerCheckInterval,
 		startupCPUBoosts: make(map[string]map[string]StartupCPUBoost),
-		timePolicyBoosts: make(map[boostKey]StartupCPUBoost),
+		queue:            newPodDeadlineQueue(),
 		maxGoroutines:    DefaultMaxGoroutines,
+		owners:           predictor.NewOwnerResolver(client),
 		log:              ctrl.Log.WithName("boost-manager"),
 	}
 }
 
 // AddStartupCPUBoost registers a new startup-cpu-boost is a manager.
 // If a boost with a given name and namespace already exists, it returns an error.
+// The PODs that were already boosted by a given startup-cpu-boost are attached to it
+// after the registration, without the manager lock, as attaching them may revert their
+// resources with the API server calls.
 func (m *managerImpl) AddStartupCPUBoost(ctx context.Context, boost StartupCPUBoost) error {
 	m.Lock()
-	defer m.Unlock()
 	if _, ok := m.getStartupCPUBoost(boost.Namespace(), boost.Name()); ok {
+		m.Unlock()
 		return errStartupCPUBoostAlreadyExists
 	}
 	log := m.log.WithValues("boost", boost.Name(), "namespace", boost.Namespace())
 	log.V(5).Info("handling boost registration")
 	m.addStartupCPUBoost(boost)
 	metrics.NewBoostConfiguration(boost.Namespace())
+	m.Unlock()
 	log.Info("boost registered successfully")
+	if err := m.attachBoostedPods(ctx, boost); err != nil {
+		log.Error(err, "failed to attach boosted pods")
+	}
+	return nil
+}
+
+// UpdateStartupCPUBoost replaces a registered startup-cpu-boost with a given one,
+// rebuilt from the updated API spec. The tracked PODs and usage statistics are carried
+// over to the given startup-cpu-boost. If a boost with a given name and namespace does
+// not exist, it returns an error.
+func (m *managerImpl) UpdateStartupCPUBoost(ctx context.Context, boost StartupCPUBoost) error {
+	m.Lock()
+	current, ok := m.getStartupCPUBoost(boost.Namespace(), boost.Name())
+	if !ok {
+		m.Unlock()
+		return errStartupCPUBoostNotFound
+	}
+	log := m.log.WithValues("boost", boost.Name(), "namespace", boost.Namespace())
+	log.V(5).Info("handling boost update")
+	boost.TransferState(current)
+	m.addStartupCPUBoost(boost)
+	m.releasePredictors(current)
+	violated := boost.ValidatePolicy(ctx)
+	m.Unlock()
+	log.Info("boost updated successfully")
+	// the resources are reverted without the manager lock, as they require the API server calls
+	for _, pod := range violated {
+		log.V(5).Info("reverting pod resources", "pod", pod.Name)
+		if err := boost.RevertResources(ctx, pod); err != nil {
+			log.Error(err, "pod resources reversion failed", "pod", pod.Name)
+			continue
+		}
+		log.Info("pod resources reverted successfully", "pod", pod.Name)
+	}
 	return nil
 }
 
-// RemoveStartupCPUBoost removes a startup-cpu-boost from a manager if registered.
+// RemoveStartupCPUBoost removes a startup-cpu-boost from a manager if registered. The
+// predictor circuit breakers and connections no longer used by the registered boosts
+// are released.
 func (m *managerImpl) RemoveStartupCPUBoost(ctx context.Context, namespace, name string) {
 	m.Lock()
 	defer m.Unlock()
 	log := m.log.WithValues("boost", name, "namespace", namespace)
 	log.V(5).Info("handling boost deletion")
-	if boosts, ok := m.startupCPUBoosts[namespace]; ok {
-		delete(boosts, name)
+	boost, ok := m.getStartupCPUBoost(namespace, name)
+	if !ok {
+		log.V(5).Info("boost not registered, skipping deletion")
+		return
 	}
-	key := boostKey{name: name, namespace: namespace}
-	delete(m.timePolicyBoosts, key)
+	// the scheduled PODs of the startup-cpu-boost are skipped once due
+	delete(m.startupCPUBoosts[namespace], name)
+	m.releasePredictors(boost)
 	metrics.DeleteBoostConfiguration(namespace)
 	log.Info("boost deleted successfully")
 }
@@ -151,7 +226,8 @@ func (m *managerImpl) StartupCPUBoost(namespace string, name string) (StartupCPU
 }
 
 // StartupCPUBoostForPod returns a startup-cpu-boost that matches a given pod if such is registered
-// in a manager.
+// in a manager. When several startup-cpu-boosts match, the one with the highest priority is
+// returned and the ties are resolved by the name order.
 func (m *managerImpl) StartupCPUBoostForPod(ctx context.Context, pod *corev1.Pod) (StartupCPUBoost, bool) {
 	m.RLock()
 	defer m.RUnlock()
@@ -160,8 +236,41 @@ func (m *managerImpl) StartupCPUBoostForPod(ctx context.Context, pod *corev1.Pod
 	if !ok {
 		return nil, false
 	}
+	var result StartupCPUBoost
 	for _, boost := range nsBoosts {
-		if boost.Matches(pod) {
+		if !boost.Matches(pod) {
+			continue
+		}
+		if result == nil || hasPrecedence(boost, result) {
+			result = boost
+		}
+	}
+	return result, result != nil
+}
+
+// StartupCPUBoostForPodResize returns a startup-cpu-boost that tracks the in-place resize
+// of a given pod if such is registered in a manager. The resize is tracked after the pod
+// resources are reverted, when the pod no longer has the boost label.
+func (m *managerImpl) StartupCPUBoostForPodResize(pod *corev1.Pod) (StartupCPUBoost, bool) {
+	m.RLock()
+	defer m.RUnlock()
+	for _, boost := range m.startupCPUBoosts[pod.Namespace] {
+		if _, ok := boost.PodResizeStatus(pod.Name); ok {
+			return boost, true
+		}
+	}
+	return nil, false
+}
+
+// StartupCPUBoostForPodStartup returns a startup-cpu-boost that tracks the startup of
+// a given pod for the learned duration or resource policy, or the startup profile, if
+// such is registered in a manager. The startup is tracked after the pod resources are reverted, when the pod no
+// longer has the boost label.
+func (m *managerImpl) StartupCPUBoostForPodStartup(pod *corev1.Pod) (StartupCPUBoost, bool) {
+	m.RLock()
+	defer m.RUnlock()
+	for _, boost := range m.startupCPUBoosts[pod.Namespace] {
+		if boost.TracksPodStartup(pod.Name) {
 			return boost, true
 		}
 	}
@@ -172,20 +281,56 @@ func (m *managerImpl) SetStartupCPUBoostReconciler(reconciler reconcile.Reconcil
 	m.reconciler = reconciler
 }
 
+func (m *managerImpl) SetEventRecorder(recorder record.EventRecorder) {
+	m.recorder = recorder
+}
+
+// SchedulePod schedules the validation of a POD with a given name of a given
+// startup-cpu-boost at a given time
+func (m *managerImpl) SchedulePod(boost StartupCPUBoost, podName string, deadline time.Time) {
+	m.queue.Schedule(newPodKey(boost, podName), deadline)
+}
+
+// UnschedulePod cancels the scheduled validation of a POD with a given name of
+// a given startup-cpu-boost
+func (m *managerImpl) UnschedulePod(boost StartupCPUBoost, podName string) {
+	m.queue.Unschedule(newPodKey(boost, podName))
+}
+
+// Start runs the manager loop until a given context is done. The loop waits for the
+// earliest deadline of the scheduled PODs and validates the PODs that are due. The
+// ticker is re-armed whenever the earliest deadline moves, and it ticks at least every
+// check interval.
 func (m *managerImpl) Start(ctx context.Context) error {
 	defer m.ticker.Stop()
 	m.log.Info("starting")
+	if err := m.revertOrphanedPods(ctx); err != nil {
+		m.log.Error(err, "failed to revert orphaned pods")
+	}
 	for {
+		m.resetTicker()
 		select {
-		case <-m.ticker.Tick():
+		case now := <-m.ticker.Tick():
 			m.log.V(5).Info("tick...")
-			m.validateTimePolicyBoosts(ctx)
+			m.validateDuePods(ctx, now)
+		case <-m.queue.Wakeup():
+			m.log.V(5).Info("earliest pod deadline changed")
 		case <-ctx.Done():
 			return nil
 		}
 	}
 }
 
+// resetTicker resets the ticker to tick at the earliest deadline of the scheduled
+// PODs, but not later than after the check interval
+func (m *managerImpl) resetTicker() {
+	d := m.checkInterval
+	if deadline, ok := m.queue.Next(); ok {
+		d = min(max(time.Until(deadline), minTickInterval), d)
+	}
+	m.ticker.Reset(d)
+}
+
 // addStartupCPUBoost registers a new startup-cpu-boost in a manager.
 func (m *managerImpl) addStartupCPUBoost(boost StartupCPUBoost) {
 	boosts, ok := m.startupCPUBoosts[boost.Namespace()]
@@ -194,12 +339,62 @@ func (m *managerImpl) addStartupCPUBoost(boost StartupCPUBoost) {
 		m.startupCPUBoosts[boost.Namespace()] = boosts
 	}
 	boosts[boost.Name()] = boost
-	if _, ok := boost.DurationPolicies()[duration.FixedDurationPolicyName]; ok {
-		key := boostKey{name: boost.Name(), namespace: boost.Namespace()}
-		m.timePolicyBoosts[key] = boost
+	if isTimePolicyBoost(boost) {
+		boost.SetPodScheduler(m)
 	}
 }
 
+// releasePredictors releases the predictor circuit breakers and connections of a given
+// startup-cpu-boost, i.e. the removed or replaced one, that are not used by any of the
+// registered startup-cpu-boosts
+func (m *managerImpl) releasePredictors(boost StartupCPUBoost) {
+	inUse := make(map[predictor.EndpointKey]bool)
+	for _, boosts := range m.startupCPUBoosts {
+		for _, registered := range boosts {
+			for _, key := range registered.PredictorEndpoints() {
+				inUse[key] = true
+			}
+		}
+	}
+	for _, key := range boost.PredictorEndpoints() {
+		if !inUse[key] {
+			predictor.Release(key)
+		}
+	}
+}
+
+// isTimePolicyBoost returns true if the duration policy of a given startup-cpu-boost
+// or any of its ramp-down phases depends on time, thus its PODs have to be scheduled
+// for validation.
+func isTimePolicyBoost(boost StartupCPUBoost) bool {
+	policies := append([]duration.Policy{boost.DurationPolicy()}, boost.PhaseDurationPolicies()...)
+	for _, policy := range policies {
+		if duration.TimeDependent(policy) {
+			return true
+		}
+	}
+	return false
+}
+
+// newPodKey returns the deadline queue key of a POD with a given name of a given
+// startup-cpu-boost
+func newPodKey(boost StartupCPUBoost, podName string) podKey {
+	return podKey{
+		boost: boostKey{name: boost.Name(), namespace: boost.Namespace()},
+		name:  podName,
+	}
+}
+
+// hasPrecedence returns true if startup-cpu-boost a takes precedence over
+// startup-cpu-boost b, i.e. has higher priority or precedes it in the name
+// order when the priorities are equal.
+func hasPrecedence(a, b StartupCPUBoost) bool {
+	if a.Priority() != b.Priority() {
+		return a.Priority() > b.Priority()
+	}
+	return a.Name() < b.Name()
+}
+
 // getStartupCPUBoost returns the startup-cpu-boost with a given name and namespace
 // if registered in a manager.
 func (m *managerImpl) getStartupCPUBoost(namespace string, name string) (StartupCPUBoost, bool) {
@@ -210,34 +405,126 @@ func (m *managerImpl) getStartupCPUBoost(namespace string, name string) (Startup
 	return nil, false
 }
 
+// attachBoostedPods adds the PODs that were already boosted by a given
+// startup-cpu-boost to its tracking. This recovers the PODs boosted before
+// the manager was (re)started.
+func (m *managerImpl) attachBoostedPods(ctx context.Context, boost StartupCPUBoost) error {
+	pods, err := m.listBoostedPods(ctx,
+		client.InNamespace(boost.Namespace()),
+		client.MatchingLabels{bpod.BoostLabelKey: boost.Name()},
+	)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, pod := range pods {
+		if _, ok := boost.Pod(pod.Name); ok {
+			continue
+		}
+		m.log.V(5).Info("attaching boosted pod", "boost", boost.Name(),
+			"namespace", boost.Namespace(), "pod", pod.Name)
+		if err := boost.UpsertPod(ctx, pod); err != nil {
+			errs = append(errs, fmt.Errorf("pod %s/%s: %w", pod.Namespace, pod.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// revertOrphanedPods reverts the resources of boosted PODs which startup-cpu-boost
+// no longer exists, using the data from StartupCPUBoost annotation.
+func (m *managerImpl) revertOrphanedPods(ctx context.Context) error {
+	var boostList autoscaling.StartupCPUBoostList
+	if err := m.client.List(ctx, &boostList); err != nil {
+		return fmt.Errorf("failed to list startup-cpu-boosts: %w", err)
+	}
+	boosts := make(map[boostKey]bool, len(boostList.Items))
+	for _, boost := range boostList.Items {
+		boosts[boostKey{name: boost.Name, namespace: boost.Namespace}] = true
+	}
+	pods, err := m.listBoostedPods(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, pod := range pods {
+		boostName := pod.Labels[bpod.BoostLabelKey]
+		if boosts[boostKey{name: boostName, namespace: pod.Namespace}] {
+			continue
+		}
+		log := m.log.WithValues("boost", boostName, "namespace", pod.Namespace, "pod", pod.Name)
+		log.V(5).Info("reverting orphaned pod resources")
+		if err := RevertPodResources(ctx, m.resizer, m.recorder, pod); err != nil {
+			errs = append(errs, fmt.Errorf("pod %s/%s: %w", pod.Namespace, pod.Name, err))
+			continue
+		}
+		log.Info("orphaned pod resources reverted successfully")
+	}
+	return errors.Join(errs...)
+}
+
+// listBoostedPods lists the PODs that have the startup-cpu-boost label and
+// annotation and are not being deleted.
+func (m *managerImpl) listBoostedPods(ctx context.Context, opts ...client.ListOption) ([]*corev1.Pod, error) {
+	var podList corev1.PodList
+	opts = append(opts, client.HasLabels{bpod.BoostLabelKey})
+	if err := m.client.List(ctx, &podList, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list boosted pods: %w", err)
+	}
+	pods := make([]*corev1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if _, ok := pod.Annotations[bpod.BoostAnnotationKey]; !ok {
+			continue
+		}
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods, nil
+}
+
 type podRevertTask struct {
 	boost StartupCPUBoost
 	pod   *corev1.Pod
 }
 
-// validateTimePolicyBoosts validates all time policy boosts in a manager
-// and reverts the resources for violated pods.
-func (m *managerImpl) validateTimePolicyBoosts(ctx context.Context) {
+// validateDuePods validates the PODs which deadlines are not after a given time
+// and reverts the resources for violated pods. The PODs which resources reversion
+// failed are scheduled to be retried after the check interval. The PODs are validated
+// under the manager lock, and the resources are reverted without it, as the reversion
+// requires the API server calls.
+func (m *managerImpl) validateDuePods(ctx context.Context, now time.Time) {
+	keys := m.queue.PopDue(now)
+	if len(keys) == 0 {
+		return
+	}
 	m.RLock()
-	defer m.RUnlock()
+	tasks := make([]*podRevertTask, 0, len(keys))
+	for _, key := range keys {
+		boost, ok := m.getStartupCPUBoost(key.boost.namespace, key.boost.name)
+		if !ok {
+			continue
+		}
+		if pod, violated := boost.ValidatePodPolicy(ctx, key.name); violated {
+			tasks = append(tasks, &podRevertTask{
+				boost: boost,
+				pod:   pod,
+			})
+		}
+	}
+	reconciler := m.reconciler
+	m.RUnlock()
+	if len(tasks) == 0 {
+		return
+	}
 	revertTasks := make(chan *podRevertTask, m.maxGoroutines)
 	reconcileTasks := make(chan *reconcile.Request, m.maxGoroutines)
 	errors := make(chan error, m.maxGoroutines)
 
 	go func() {
-		for _, boost := range m.timePolicyBoosts {
-			for _, pod := range boost.ValidatePolicy(ctx, duration.FixedDurationPolicyName) {
-				revertTasks <- &podRevertTask{
-					boost: boost,
-					pod:   pod,
-				}
-			}
-			for _, pod := range boost.ValidatePolicy(ctx, duration.AutoDurationPolicyName) {
-				revertTasks <- &podRevertTask{
-					boost: boost,
-					pod:   pod,
-				}
-			}
+		for _, task := range tasks {
+			revertTasks <- task
 		}
 		close(revertTasks)
 	}()
@@ -251,13 +538,23 @@ func (m *managerImpl) validateTimePolicyBoosts(ctx context.Context) {
 				for task := range revertTasks {
 					log := m.log.WithValues("boost", task.boost.Name(), "namespace", task.boost.Namespace(), "pod", task.pod.Name)
 					log.V(5).Info("reverting pod resources")
+					// the auto duration policy applies only before the boost ramp-down phases
+					var notification *predictor.NotifyRequest
+					autoPolicy, ok := duration.FindPolicy(task.boost.DurationPolicy(), duration.AutoDurationPolicyName)
+					if ok && podBoostPhase(task.pod) == 0 {
+						// the notification carries the boosted resources, so it is created before the reversion
+						notification = m.newNotifyRequest(ctx, task.boost, task.pod)
+					}
 					if err := task.boost.RevertResources(ctx, task.pod); err != nil {
+						m.SchedulePod(task.boost, task.pod.Name, time.Now().Add(m.checkInterval))
 						errors <- fmt.Errorf("pod %s/%s: %w", task.pod.Namespace, task.pod.Name, err)
 					} else {
-						if autoPolicy, ok := task.boost.DurationPolicies()[duration.AutoDurationPolicyName]; ok {
+						if notification != nil {
 							log.Info("notifying about pod resource reversion under auto policy")
 							if autoPolicy, ok := autoPolicy.(*duration.AutoDurationPolicy); ok {
-								autoPolicy.NotifyReversion(task.pod)
+								if err := autoPolicy.NotifyReversion(ctx, notification); err != nil {
+									log.Error(err, "failed to notify about pod resource reversion")
+								}
 							} else {
 								log.Info("auto policy not found")
 							}
@@ -286,13 +583,29 @@ func (m *managerImpl) validateTimePolicyBoosts(ctx context.Context) {
 	}()
 
 	reconcileRequests := dedupeReconcileRequests(reconcileTasks)
-	if m.reconciler != nil {
+	if reconciler != nil {
 		for _, req := range reconcileRequests {
-			m.reconciler.Reconcile(ctx, req)
+			reconciler.Reconcile(ctx, req)
 		}
 	}
 }
 
+// newNotifyRequest returns the notification about the reversion of a given POD
+// boosted by a given boost. The POD boost time is read from the boost annotation,
+// and the POD creation time is used when it is not recorded.
+func (m *managerImpl) newNotifyRequest(ctx context.Context, boost StartupCPUBoost, pod *corev1.Pod) *predictor.NotifyRequest {
+	owner, err := m.owners.Resolve(ctx, pod)
+	if err != nil {
+		m.log.V(5).Info("failed to resolve pod owner", "pod", pod.Name, "error", err.Error())
+	}
+	boostTime := pod.CreationTimestamp.Time
+	if annotation, err := bpod.BoostAnnotationFromPod(pod); err == nil && !annotation.BoostTimestamp.IsZero() {
+		boostTime = annotation.BoostTimestamp
+	}
+	request := predictor.NewNotifyRequest(pod, boost.Name(), owner, boostTime, time.Now())
+	return &request
+}
+
 func dedupeReconcileRequests(reconcileTasks chan *reconcile.Request) []reconcile.Request {
 	result := make([]reconcile.Request, 0, len(reconcileTasks))
 	requests := make(map[reconcile.Request]bool)
@@ -16,10 +16,18 @@ package boost_test
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"time"
 
 	autoscaling "github.com/google/kube-startup-cpu-boost/api/v1alpha1"
 	cpuboost "github.com/google/kube-startup-cpu-boost/internal/boost"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/duration"
+	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor/server"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resize"
 	"github.com/google/kube-startup-cpu-boost/internal/metrics"
 	"github.com/google/kube-startup-cpu-boost/internal/mock"
 	. "github.com/onsi/ginkgo/v2"
@@ -28,6 +36,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -38,15 +47,32 @@ var _ = Describe("Manager", func() {
 	})
 	Describe("Registers startup-cpu-boost", func() {
 		var (
-			spec  *autoscaling.StartupCPUBoost
-			boost cpuboost.StartupCPUBoost
-			err   error
+			spec         *autoscaling.StartupCPUBoost
+			boost        cpuboost.StartupCPUBoost
+			err          error
+			mockCtrl     *gomock.Controller
+			mockClient   *mock.MockClient
+			boostedPods  []corev1.Pod
+			podsListCall *gomock.Call
+			listedBoost  bool
 		)
 		BeforeEach(func() {
 			spec = specTemplate.DeepCopy()
+			boostedPods = []corev1.Pod{}
+			listedBoost = false
+			mockCtrl = gomock.NewController(GinkgoT())
+			mockClient = mock.NewMockClient(mockCtrl)
+			podsListCall = mockClient.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&corev1.PodList{}), gomock.Any()).
+				DoAndReturn(func(c context.Context, list client.ObjectList, opts ...client.ListOption) error {
+					// the lookup blocks if the manager lock is held while attaching the pods
+					_, listedBoost = manager.StartupCPUBoost(spec.Namespace, spec.Name)
+					podList := list.(*corev1.PodList)
+					podList.Items = boostedPods
+					return nil
+				}).AnyTimes()
 		})
 		JustBeforeEach(func() {
-			manager = cpuboost.NewManager(nil)
+			manager = cpuboost.NewManager(mockClient, resize.NewPatchStrategy(mockClient))
 			boost, err = cpuboost.NewStartupCPUBoost(nil, spec)
 			Expect(err).ToNot(HaveOccurred())
 		})
@@ -76,19 +102,60 @@ var _ = Describe("Manager", func() {
 			It("updates boost configurations metric", func() {
 				Expect(metrics.BoostConfigurations(spec.Namespace)).To(Equal(float64(1)))
 			})
+			It("lists the boosted pods", func() {
+				podsListCall.MinTimes(1)
+			})
+			It("attaches the boosted pods after the registration", func() {
+				Expect(listedBoost).To(BeTrue())
+			})
+			When("there are pods boosted by the startup-cpu-boost", func() {
+				var pod *corev1.Pod
+				BeforeEach(func() {
+					pod = podTemplate.DeepCopy()
+					boostedPods = append(boostedPods, *pod)
+				})
+				It("does not error", func() {
+					Expect(err).NotTo(HaveOccurred())
+				})
+				It("attaches the boosted pods", func() {
+					stored, ok := manager.StartupCPUBoost(spec.Namespace, spec.Name)
+					Expect(ok).To(BeTrue())
+					_, found := stored.Pod(pod.Name)
+					Expect(found).To(BeTrue())
+				})
+			})
+			When("there are pods without the boost annotation", func() {
+				var pod *corev1.Pod
+				BeforeEach(func() {
+					pod = podTemplate.DeepCopy()
+					delete(pod.Annotations, bpod.BoostAnnotationKey)
+					boostedPods = append(boostedPods, *pod)
+				})
+				It("does not attach the pods", func() {
+					stored, ok := manager.StartupCPUBoost(spec.Namespace, spec.Name)
+					Expect(ok).To(BeTrue())
+					_, found := stored.Pod(pod.Name)
+					Expect(found).To(BeFalse())
+				})
+			})
 		})
 	})
 	Describe("De-registers startup-cpu-boost", func() {
 		var (
-			spec  *autoscaling.StartupCPUBoost
-			boost cpuboost.StartupCPUBoost
-			err   error
+			spec       *autoscaling.StartupCPUBoost
+			boost      cpuboost.StartupCPUBoost
+			err        error
+			mockCtrl   *gomock.Controller
+			mockClient *mock.MockClient
 		)
 		BeforeEach(func() {
 			spec = specTemplate.DeepCopy()
+			mockCtrl = gomock.NewController(GinkgoT())
+			mockClient = mock.NewMockClient(mockCtrl)
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 		})
 		JustBeforeEach(func() {
-			manager = cpuboost.NewManager(nil)
+			manager = cpuboost.NewManager(mockClient, resize.NewPatchStrategy(mockClient))
 			boost, err = cpuboost.NewStartupCPUBoost(nil, spec)
 			Expect(err).ToNot(HaveOccurred())
 		})
@@ -107,6 +174,89 @@ var _ = Describe("Manager", func() {
 			})
 		})
 	})
+	Describe("Updates startup-cpu-boost", func() {
+		var (
+			spec       *autoscaling.StartupCPUBoost
+			newSpec    *autoscaling.StartupCPUBoost
+			boost      cpuboost.StartupCPUBoost
+			newBoost   cpuboost.StartupCPUBoost
+			pod        *corev1.Pod
+			err        error
+			mockCtrl   *gomock.Controller
+			mockClient *mock.MockClient
+		)
+		BeforeEach(func() {
+			spec = specTemplate.DeepCopy()
+			spec.Spec.DurationPolicy.Fixed = &autoscaling.FixedDurationPolicy{
+				Unit:  autoscaling.FixedDurationPolicyUnitSec,
+				Value: 1000,
+			}
+			newSpec = specTemplate.DeepCopy()
+			newSpec.Spec.DurationPolicy.PodCondition = &autoscaling.PodConditionDurationPolicy{
+				Type:   corev1.PodReady,
+				Status: corev1.ConditionTrue,
+			}
+			pod = podTemplate.DeepCopy()
+			mockCtrl = gomock.NewController(GinkgoT())
+			mockClient = mock.NewMockClient(mockCtrl)
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		})
+		JustBeforeEach(func() {
+			manager = cpuboost.NewManager(mockClient, resize.NewPatchStrategy(mockClient))
+			boost, err = cpuboost.NewStartupCPUBoost(resize.NewPatchStrategy(mockClient), spec)
+			Expect(err).ToNot(HaveOccurred())
+			newBoost, err = cpuboost.NewStartupCPUBoost(resize.NewPatchStrategy(mockClient), newSpec)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		When("startup-cpu-boost does not exist", func() {
+			JustBeforeEach(func() {
+				err = manager.UpdateStartupCPUBoost(context.TODO(), newBoost)
+			})
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		When("startup-cpu-boost exists", func() {
+			JustBeforeEach(func() {
+				err = manager.AddStartupCPUBoost(context.TODO(), boost)
+				Expect(err).ToNot(HaveOccurred())
+				err = boost.UpsertPod(context.TODO(), pod)
+				Expect(err).ToNot(HaveOccurred())
+				err = manager.UpdateStartupCPUBoost(context.TODO(), newBoost)
+			})
+			It("does not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("stores the updated startup-cpu-boost", func() {
+				stored, ok := manager.StartupCPUBoost(spec.Namespace, spec.Name)
+				Expect(ok).To(BeTrue())
+				Expect(stored).To(BeIdenticalTo(newBoost))
+				Expect(stored.DurationPolicy()).To(BeAssignableToTypeOf(&duration.PodConditionPolicy{}))
+			})
+			It("carries over the boosted pods", func() {
+				_, found := newBoost.Pod(pod.Name)
+				Expect(found).To(BeTrue())
+			})
+			It("does not change boost configurations metric", func() {
+				Expect(metrics.BoostConfigurations(spec.Namespace)).To(Equal(float64(1)))
+			})
+			When("boosted pod meets the new duration policy", func() {
+				BeforeEach(func() {
+					pod.Status.Conditions = []corev1.PodCondition{
+						{
+							Type:   corev1.PodReady,
+							Status: corev1.ConditionTrue,
+						},
+					}
+					mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				})
+				It("reverts the pod resources", func() {
+					_, found := newBoost.Pod(pod.Name)
+					Expect(found).To(BeFalse())
+				})
+			})
+		})
+	})
 	Describe("retrieves startup-cpu-boost for a POD", func() {
 		var (
 			pod               *corev1.Pod
@@ -122,7 +272,9 @@ var _ = Describe("Manager", func() {
 			pod.Labels[podNameLabel] = podNameLabelValue
 		})
 		JustBeforeEach(func() {
-			manager = cpuboost.NewManager(nil)
+			mockClient := mock.NewMockClient(gomock.NewController(GinkgoT()))
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			manager = cpuboost.NewManager(mockClient, resize.NewPatchStrategy(mockClient))
 		})
 		When("matching startup-cpu-boost does not exist", func() {
 			JustBeforeEach(func() {
@@ -160,24 +312,165 @@ var _ = Describe("Manager", func() {
 				Expect(boost.Namespace()).To(Equal(spec.Namespace))
 			})
 		})
+		When("multiple matching startup-cpu-boosts exist", func() {
+			var specs []*autoscaling.StartupCPUBoost
+			BeforeEach(func() {
+				specs = nil
+				for _, name := range []string{"boost-c", "boost-a", "boost-b"} {
+					spec := specTemplate.DeepCopy()
+					spec.Name = name
+					spec.Selector = *metav1.AddLabelToSelector(&metav1.LabelSelector{}, podNameLabel, podNameLabelValue)
+					specs = append(specs, spec)
+				}
+			})
+			JustBeforeEach(func() {
+				for _, spec := range specs {
+					b, err := cpuboost.NewStartupCPUBoost(nil, spec)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(manager.AddStartupCPUBoost(context.TODO(), b)).To(Succeed())
+				}
+				boost, found = manager.StartupCPUBoostForPod(context.TODO(), pod)
+			})
+			When("startup-cpu-boosts have equal priorities", func() {
+				It("returns boost first in the name order", func() {
+					Expect(found).To(BeTrue())
+					Expect(boost.Name()).To(Equal("boost-a"))
+				})
+			})
+			When("startup-cpu-boosts have different priorities", func() {
+				BeforeEach(func() {
+					specs[0].Spec.Priority = 10
+					specs[2].Spec.Priority = 10
+				})
+				It("returns boost with the highest priority first in the name order", func() {
+					Expect(found).To(BeTrue())
+					Expect(boost.Name()).To(Equal("boost-b"))
+				})
+			})
+		})
+	})
+	Describe("retrieves startup-cpu-boost for a POD resize", func() {
+		var (
+			pod   *corev1.Pod
+			boost cpuboost.StartupCPUBoost
+			found bool
+		)
+		BeforeEach(func() {
+			pod = podTemplate.DeepCopy()
+			pod.Status.Conditions = []corev1.PodCondition{{
+				Type:   corev1.PodReady,
+				Status: corev1.ConditionTrue,
+			}}
+			mockClient := mock.NewMockClient(gomock.NewController(GinkgoT()))
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			manager = cpuboost.NewManager(mockClient, resize.NewPatchStrategy(mockClient))
+			spec := specTemplate.DeepCopy()
+			spec.Spec.DurationPolicy.PodCondition = &autoscaling.PodConditionDurationPolicy{
+				Type:   corev1.PodReady,
+				Status: corev1.ConditionTrue,
+			}
+			b, err := cpuboost.NewStartupCPUBoost(resize.NewPatchStrategy(mockClient), spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(manager.AddStartupCPUBoost(context.TODO(), b)).To(Succeed())
+		})
+		When("the POD resize is not tracked", func() {
+			JustBeforeEach(func() {
+				boost, found = manager.StartupCPUBoostForPodResize(pod)
+			})
+			It("returns false", func() {
+				Expect(found).To(BeFalse())
+				Expect(boost).To(BeNil())
+			})
+		})
+		When("the POD resources were reverted", func() {
+			JustBeforeEach(func() {
+				b, ok := manager.StartupCPUBoost(specTemplate.Namespace, specTemplate.Name)
+				Expect(ok).To(BeTrue())
+				Expect(b.UpsertPod(context.TODO(), pod)).To(Succeed())
+				boost, found = manager.StartupCPUBoostForPodResize(pod)
+			})
+			It("returns the boost that reverted the POD", func() {
+				Expect(found).To(BeTrue())
+				Expect(boost.Name()).To(Equal(specTemplate.Name))
+			})
+		})
+	})
+	Describe("retrieves startup-cpu-boost for a POD startup", func() {
+		var (
+			pod   *corev1.Pod
+			boost cpuboost.StartupCPUBoost
+			found bool
+		)
+		BeforeEach(func() {
+			pod = podTemplate.DeepCopy()
+			annotation := *annotTemplate
+			annotation.WorkloadKey = "deployment.demo.0123"
+			pod.Annotations[bpod.BoostAnnotationKey] = annotation.ToJSON()
+			mockClient := mock.NewMockClient(gomock.NewController(GinkgoT()))
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			manager = cpuboost.NewManager(mockClient, resize.NewPatchStrategy(mockClient))
+			spec := specTemplate.DeepCopy()
+			spec.Spec.DurationPolicy.Learned = &autoscaling.LearnedDurationPolicy{
+				Fallback: autoscaling.FixedDurationPolicy{
+					Unit:  autoscaling.FixedDurationPolicyUnitSec,
+					Value: 30,
+				},
+			}
+			b, err := cpuboost.NewStartupCPUBoost(resize.NewPatchStrategy(mockClient), spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(manager.AddStartupCPUBoost(context.TODO(), b)).To(Succeed())
+		})
+		When("the POD startup is not tracked", func() {
+			JustBeforeEach(func() {
+				boost, found = manager.StartupCPUBoostForPodStartup(pod)
+			})
+			It("returns false", func() {
+				Expect(found).To(BeFalse())
+				Expect(boost).To(BeNil())
+			})
+		})
+		When("the POD startup is tracked", func() {
+			JustBeforeEach(func() {
+				b, ok := manager.StartupCPUBoost(specTemplate.Namespace, specTemplate.Name)
+				Expect(ok).To(BeTrue())
+				b.ObservePodStartup(context.TODO(), pod)
+				boost, found = manager.StartupCPUBoostForPodStartup(pod)
+			})
+			It("returns the boost that tracks the POD startup", func() {
+				Expect(found).To(BeTrue())
+				Expect(boost.Name()).To(Equal(specTemplate.Name))
+			})
+		})
 	})
 	Describe("Runs on a time tick", func() {
 		var (
-			mockCtrl   *gomock.Controller
-			mockTicker *mock.MockTimeTicker
-			ctx        context.Context
-			cancel     context.CancelFunc
-			err        error
-			done       chan int
+			mockCtrl      *gomock.Controller
+			mockTicker    *mock.MockTimeTicker
+			mockMgrClient *mock.MockClient
+			ctx           context.Context
+			cancel        context.CancelFunc
+			err           error
+			done          chan int
+			resets        chan time.Duration
 		)
 		BeforeEach(func() {
 			mockCtrl = gomock.NewController(GinkgoT())
 			mockTicker = mock.NewMockTimeTicker(mockCtrl)
+			resets = make(chan time.Duration, 10)
+			mockTicker.EXPECT().Reset(gomock.Any()).AnyTimes().Do(func(d time.Duration) {
+				select {
+				case resets <- d:
+				default:
+				}
+			})
+			mockMgrClient = mock.NewMockClient(mockCtrl)
+			mockMgrClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 			ctx, cancel = context.WithCancel(context.TODO())
 			done = make(chan int)
 		})
 		JustBeforeEach(func() {
-			manager = cpuboost.NewManagerWithTicker(nil, mockTicker)
+			manager = cpuboost.NewManagerWithTicker(mockMgrClient, resize.NewPatchStrategy(mockMgrClient), mockTicker)
 			go func() {
 				defer GinkgoRecover()
 				err = manager.Start(ctx)
@@ -208,6 +501,7 @@ var _ = Describe("Manager", func() {
 				pod            *corev1.Pod
 				mockClient     *mock.MockClient
 				mockReconciler *mock.MockReconciler
+				reconcileCall  *gomock.Call
 				c              chan time.Time
 			)
 			BeforeEach(func() {
@@ -226,13 +520,85 @@ var _ = Describe("Manager", func() {
 				c = make(chan time.Time, 1)
 				mockTicker.EXPECT().Tick().MinTimes(1).Return(c)
 				mockTicker.EXPECT().Stop().Return()
-				mockClient.EXPECT().Update(gomock.Any(), gomock.Eq(pod)).MinTimes(1).Return(nil)
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Eq(pod), gomock.Any()).MinTimes(1).Return(nil)
+				reconcileReq := reconcile.Request{NamespacedName: types.NamespacedName{Name: spec.Name, Namespace: spec.Namespace}}
+				reconcileCall = mockReconciler.EXPECT().Reconcile(gomock.Any(), gomock.Eq(reconcileReq)).Times(1)
+			})
+			JustBeforeEach(func() {
+				manager.SetStartupCPUBoostReconciler(mockReconciler)
+				boost, err = cpuboost.NewStartupCPUBoost(resize.NewPatchStrategy(mockClient), spec)
+				Expect(err).ShouldNot(HaveOccurred())
+				err = boost.UpsertPod(ctx, pod)
+				Expect(err).ShouldNot(HaveOccurred())
+				err = manager.AddStartupCPUBoost(context.TODO(), boost)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				c <- time.Now()
+				time.Sleep(500 * time.Millisecond)
+				cancel()
+				<-done
+			})
+			It("doesn't error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			When("the reconciler removes the startup-cpu-boost", func() {
+				BeforeEach(func() {
+					reconcileCall.DoAndReturn(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+						manager.RemoveStartupCPUBoost(ctx, req.Namespace, req.Name)
+						return reconcile.Result{}, nil
+					})
+				})
+				It("removes the startup-cpu-boost without a deadlock", func() {
+					_, ok := manager.StartupCPUBoost(spec.Namespace, spec.Name)
+					Expect(ok).To(BeFalse())
+				})
+			})
+		})
+		When("There are startup-cpu-boosts with auto duration policy only", func() {
+			var (
+				spec           *autoscaling.StartupCPUBoost
+				boost          cpuboost.StartupCPUBoost
+				pod            *corev1.Pod
+				mockClient     *mock.MockClient
+				mockReconciler *mock.MockReconciler
+				server         *httptest.Server
+				notified       chan string
+				c              chan time.Time
+			)
+			BeforeEach(func() {
+				notified = make(chan string, 1)
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path == "/notify" {
+						notified <- r.URL.Path
+						return
+					}
+					w.Header().Set("Content-Type", "application/json")
+					_ = json.NewEncoder(w).Encode(predictor.PodPrediction{Duration: "10m"})
+				}))
+				spec = specTemplate.DeepCopy()
+				spec.Spec.DurationPolicy = autoscaling.DurationPolicy{
+					AutoPolicy: &autoscaling.AutoDurationPolicy{ApiEndpoint: server.URL},
+				}
+				pod = podTemplate.DeepCopy()
+				pod.CreationTimestamp = metav1.NewTime(time.Now().Add(-1 * time.Minute))
+				// the deadline predicted at the POD admission is used instead of calling the predictor
+				annotation := *annotTemplate
+				deadline := time.Now().Add(-30 * time.Second)
+				annotation.DurationDeadline = &deadline
+				pod.Annotations[bpod.BoostAnnotationKey] = annotation.ToJSON()
+				mockClient = mock.NewMockClient(mockCtrl)
+				mockReconciler = mock.NewMockReconciler(mockCtrl)
+
+				c = make(chan time.Time, 1)
+				mockTicker.EXPECT().Tick().MinTimes(1).Return(c)
+				mockTicker.EXPECT().Stop().Return()
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Eq(pod), gomock.Any()).MinTimes(1).Return(nil)
 				reconcileReq := reconcile.Request{NamespacedName: types.NamespacedName{Name: spec.Name, Namespace: spec.Namespace}}
 				mockReconciler.EXPECT().Reconcile(gomock.Any(), gomock.Eq(reconcileReq)).Times(1)
 			})
 			JustBeforeEach(func() {
 				manager.SetStartupCPUBoostReconciler(mockReconciler)
-				boost, err = cpuboost.NewStartupCPUBoost(mockClient, spec)
+				boost, err = cpuboost.NewStartupCPUBoost(resize.NewPatchStrategy(mockClient), spec)
 				Expect(err).ShouldNot(HaveOccurred())
 				err = boost.UpsertPod(ctx, pod)
 				Expect(err).ShouldNot(HaveOccurred())
@@ -244,6 +610,201 @@ var _ = Describe("Manager", func() {
 				cancel()
 				<-done
 			})
+			AfterEach(func() {
+				server.Close()
+			})
+			It("reverts the pod resources", func() {
+				Expect(err).NotTo(HaveOccurred())
+				_, found := boost.Pod(pod.Name)
+				Expect(found).To(BeFalse())
+			})
+			It("notifies about the pod resource reversion", func() {
+				Expect(notified).To(Receive(Equal("/notify")))
+			})
+		})
+		When("There are startup-cpu-boosts with auto duration policy using the reference predictor", func() {
+			var (
+				spec           *autoscaling.StartupCPUBoost
+				boost          cpuboost.StartupCPUBoost
+				pod            *corev1.Pod
+				mockClient     *mock.MockClient
+				mockReconciler *mock.MockReconciler
+				history        *server.History
+				httpServer     *httptest.Server
+				c              chan time.Time
+			)
+			BeforeEach(func() {
+				history, err = server.NewHistory("", 0)
+				Expect(err).ShouldNot(HaveOccurred())
+				httpServer = httptest.NewServer(server.New(
+					server.NewEstimatorModel(server.MovingAverage(0), server.ModelOptions{}), history))
+				spec = specTemplate.DeepCopy()
+				spec.Spec.DurationPolicy = autoscaling.DurationPolicy{
+					AutoPolicy: &autoscaling.AutoDurationPolicy{ApiEndpoint: httpServer.URL},
+				}
+				pod = podTemplate.DeepCopy()
+				pod.CreationTimestamp = metav1.NewTime(time.Now().Add(-1 * time.Minute))
+				pod.Status.Conditions = []corev1.PodCondition{{
+					Type:               corev1.PodReady,
+					Status:             corev1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(pod.CreationTimestamp.Add(45 * time.Second)),
+				}}
+				annotation := *annotTemplate
+				deadline := time.Now().Add(-5 * time.Second)
+				annotation.DurationDeadline = &deadline
+				pod.Annotations[bpod.BoostAnnotationKey] = annotation.ToJSON()
+				mockClient = mock.NewMockClient(mockCtrl)
+				mockReconciler = mock.NewMockReconciler(mockCtrl)
+
+				c = make(chan time.Time, 1)
+				mockTicker.EXPECT().Tick().MinTimes(1).Return(c)
+				mockTicker.EXPECT().Stop().Return()
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).MinTimes(1).Return(nil)
+				mockReconciler.EXPECT().Reconcile(gomock.Any(), gomock.Any()).Times(1)
+			})
+			JustBeforeEach(func() {
+				manager.SetStartupCPUBoostReconciler(mockReconciler)
+				boost, err = cpuboost.NewStartupCPUBoost(resize.NewPatchStrategy(mockClient), spec)
+				Expect(err).ShouldNot(HaveOccurred())
+				err = boost.UpsertPod(ctx, pod)
+				Expect(err).ShouldNot(HaveOccurred())
+				err = manager.AddStartupCPUBoost(context.TODO(), boost)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				c <- time.Now()
+				time.Sleep(500 * time.Millisecond)
+				cancel()
+				<-done
+			})
+			AfterEach(func() {
+				httpServer.Close()
+			})
+			It("reports the pod startup duration to the predictor", func() {
+				key := server.Key(pod.Namespace, nil, predictor.NewPodRequest(pod, "", nil).Containers)
+				samples := history.Samples(key)
+				Expect(samples).To(HaveLen(1))
+				Expect(samples[0].StartupDuration.Duration).To(Equal(45 * time.Second))
+			})
+		})
+		When("There are startup-cpu-boosts with pods before the fixed duration policy deadline", func() {
+			var (
+				spec       *autoscaling.StartupCPUBoost
+				boost      cpuboost.StartupCPUBoost
+				pod        *corev1.Pod
+				mockClient *mock.MockClient
+				c          chan time.Time
+			)
+			BeforeEach(func() {
+				spec = specTemplate.DeepCopy()
+				spec.Spec.DurationPolicy.Fixed = &autoscaling.FixedDurationPolicy{
+					Unit:  autoscaling.FixedDurationPolicyUnitSec,
+					Value: 2,
+				}
+				pod = podTemplate.DeepCopy()
+				pod.CreationTimestamp = metav1.NewTime(time.Now())
+				mockClient = mock.NewMockClient(mockCtrl)
+
+				c = make(chan time.Time, 1)
+				mockTicker.EXPECT().Tick().MinTimes(1).Return(c)
+				mockTicker.EXPECT().Stop().Return()
+			})
+			JustBeforeEach(func() {
+				boost, err = cpuboost.NewStartupCPUBoost(resize.NewPatchStrategy(mockClient), spec)
+				Expect(err).ShouldNot(HaveOccurred())
+				err = boost.UpsertPod(ctx, pod)
+				Expect(err).ShouldNot(HaveOccurred())
+				err = manager.AddStartupCPUBoost(context.TODO(), boost)
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				cancel()
+				<-done
+			})
+			It("resets the ticker to the pod deadline", func() {
+				Eventually(resets).Should(Receive(And(
+					BeNumerically(">", 0),
+					BeNumerically("<=", 2*time.Second),
+				)))
+			})
+			It("does not revert the pod resources on a tick", func() {
+				c <- time.Now()
+				time.Sleep(500 * time.Millisecond)
+				_, found := boost.Pod(pod.Name)
+				Expect(found).To(BeTrue())
+			})
+		})
+	})
+	Describe("Reverts orphaned pods on start", func() {
+		var (
+			mockCtrl   *gomock.Controller
+			mockTicker *mock.MockTimeTicker
+			mockClient *mock.MockClient
+			boosts     []autoscaling.StartupCPUBoost
+			pod        *corev1.Pod
+			updateCall *gomock.Call
+			ctx        context.Context
+			cancel     context.CancelFunc
+			err        error
+			done       chan int
+		)
+		BeforeEach(func() {
+			mockCtrl = gomock.NewController(GinkgoT())
+			mockTicker = mock.NewMockTimeTicker(mockCtrl)
+			mockClient = mock.NewMockClient(mockCtrl)
+			ctx, cancel = context.WithCancel(context.TODO())
+			done = make(chan int)
+			boosts = []autoscaling.StartupCPUBoost{}
+			pod = podTemplate.DeepCopy()
+
+			mockTicker.EXPECT().Tick().AnyTimes().Return(make(chan time.Time))
+			mockTicker.EXPECT().Reset(gomock.Any()).AnyTimes()
+			mockTicker.EXPECT().Stop().Return()
+			mockClient.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&autoscaling.StartupCPUBoostList{}), gomock.Any()).
+				DoAndReturn(func(c context.Context, list client.ObjectList, opts ...client.ListOption) error {
+					boostList := list.(*autoscaling.StartupCPUBoostList)
+					boostList.Items = boosts
+					return nil
+				}).Times(1)
+			mockClient.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&corev1.PodList{}), gomock.Any()).
+				DoAndReturn(func(c context.Context, list client.ObjectList, opts ...client.ListOption) error {
+					podList := list.(*corev1.PodList)
+					podList.Items = []corev1.Pod{*pod}
+					return nil
+				}).Times(1)
+			updateCall = mockClient.EXPECT().Patch(gomock.Any(), gomock.Cond(func(x any) bool {
+				p, ok := x.(*corev1.Pod)
+				if !ok {
+					return false
+				}
+				_, hasLabel := p.Labels[bpod.BoostLabelKey]
+				_, hasAnnot := p.Annotations[bpod.BoostAnnotationKey]
+				return p.Name == pod.Name && !hasLabel && !hasAnnot
+			}), gomock.Any())
+		})
+		JustBeforeEach(func() {
+			manager = cpuboost.NewManagerWithTicker(mockClient, resize.NewPatchStrategy(mockClient), mockTicker)
+			go func() {
+				defer GinkgoRecover()
+				err = manager.Start(ctx)
+				done <- 1
+			}()
+			time.Sleep(500 * time.Millisecond)
+			cancel()
+			<-done
+		})
+		When("startup-cpu-boost of a boosted pod does not exist", func() {
+			BeforeEach(func() {
+				updateCall.Return(nil).Times(1)
+			})
+			It("doesn't error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+		When("startup-cpu-boost of a boosted pod exists", func() {
+			BeforeEach(func() {
+				boosts = append(boosts, *specTemplate.DeepCopy())
+				updateCall.Times(0)
+			})
 			It("doesn't error", func() {
 				Expect(err).NotTo(HaveOccurred())
 			})
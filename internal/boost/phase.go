@@ -0,0 +1,63 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boost
+
+import (
+	"time"
+
+	autoscaling "github.com/google/kube-startup-cpu-boost/api/v1alpha1"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/duration"
+	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// boostPhase is a step of the CPU resource boost ramp-down
+type boostPhase struct {
+	percentage     int64
+	durationPolicy duration.Policy
+}
+
+// mapBoostPhases maps the boost phases from the API spec to their implementations.
+// The fixed duration of a phase is measured since the phase start.
+func mapBoostPhases(spec []autoscaling.BoostPhase, predictors *predictorClients) []boostPhase {
+	phases := make([]boostPhase, 0, len(spec))
+	for _, phaseSpec := range spec {
+		phases = append(phases, boostPhase{
+			percentage:     phaseSpec.PercentageIncrease.Value,
+			durationPolicy: mapDurationPolicy(phaseSpec.DurationPolicy, phaseStartTime, predictors, learnedHistories{}),
+		})
+	}
+	return phases
+}
+
+// podBoostPhase returns the boost phase recorded in the boost annotation of a given
+// POD. The phase is 0 until the POD enters the ramp-down.
+func podBoostPhase(pod *corev1.Pod) int {
+	annotation, err := bpod.BoostAnnotationFromPod(pod)
+	if err != nil {
+		return 0
+	}
+	return annotation.Phase
+}
+
+// phaseStartTime returns the start time of the boost phase of a given POD, or the
+// POD creation time if the POD has not entered the ramp-down
+func phaseStartTime(pod *corev1.Pod) time.Time {
+	annotation, err := bpod.BoostAnnotationFromPod(pod)
+	if err != nil || annotation.PhaseTimestamp == nil {
+		return pod.CreationTimestamp.Time
+	}
+	return *annotation.PhaseTimestamp
+}
@@ -20,8 +20,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resource"
 	corev1 "k8s.io/api/core/v1"
 	apiResource "k8s.io/apimachinery/pkg/api/resource"
 )
@@ -32,19 +34,52 @@ const (
 )
 
 type BoostPodAnnotation struct {
-	BoostTimestamp  time.Time         `json:"timestamp,omitempty"`
-	InitCPURequests map[string]string `json:"initCPURequests,omitempty"`
-	InitCPULimits   map[string]string `json:"initCPULimits,omitempty"`
+	BoostTimestamp     time.Time         `json:"timestamp,omitempty"`
+	InitCPURequests    map[string]string `json:"initCPURequests,omitempty"`
+	InitCPULimits      map[string]string `json:"initCPULimits,omitempty"`
+	InitMemoryRequests map[string]string `json:"initMemoryRequests,omitempty"`
+	InitMemoryLimits   map[string]string `json:"initMemoryLimits,omitempty"`
+	Phase              int               `json:"phase,omitempty"`
+	PhaseTimestamp     *time.Time        `json:"phaseTimestamp,omitempty"`
+	// Policies records the names of the resource policies applied to the containers
+	Policies map[string]string `json:"policies,omitempty"`
+	// DurationDeadline records the end of the boost predicted by the auto or learned
+	// duration policy
+	DurationDeadline *time.Time `json:"durationDeadline,omitempty"`
+	// WorkloadKey records the key of the POD workload in the durations learned by
+	// the learned duration policy
+	WorkloadKey string `json:"workloadKey,omitempty"`
 }
 
 func NewBoostAnnotation() *BoostPodAnnotation {
 	return &BoostPodAnnotation{
-		BoostTimestamp:  time.Now(),
-		InitCPURequests: make(map[string]string),
-		InitCPULimits:   make(map[string]string),
+		BoostTimestamp:     time.Now(),
+		InitCPURequests:    make(map[string]string),
+		InitCPULimits:      make(map[string]string),
+		InitMemoryRequests: make(map[string]string),
+		InitMemoryLimits:   make(map[string]string),
+		Policies:           make(map[string]string),
 	}
 }
 
+// BoostedContainers returns the sorted names of containers which boosted resources,
+// other than memory limits, are recorded in the annotation
+func (a BoostPodAnnotation) BoostedContainers() []string {
+	names := make(map[string]bool)
+	for _, values := range []map[string]string{a.InitCPURequests, a.InitCPULimits,
+		a.InitMemoryRequests} {
+		for name := range values {
+			names[name] = true
+		}
+	}
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
 func (a BoostPodAnnotation) ToJSON() string {
 	result, err := json.Marshal(a)
 	if err != nil {
@@ -65,28 +100,218 @@ func BoostAnnotationFromPod(pod *corev1.Pod) (*BoostPodAnnotation, error) {
 	return annotation, nil
 }
 
+// RevertResourceBoost reverts the POD container resources to the original values
+// from the boost annotation and removes the boost label and annotation.
+// The memory limits are not reverted, as their decrease may be refused when the
+// container memory usage is above the original value. When the annotation records
+// the memory limits of the containers, the boost label and annotation are kept, with
+// the annotation recording the memory limits only. Use RevertMemoryLimits once the
+// remaining resources are reverted.
 func RevertResourceBoost(pod *corev1.Pod) error {
 	annotation, err := BoostAnnotationFromPod(pod)
 	if err != nil {
 		return fmt.Errorf("failed to get boost annotation from pod: %s", err)
 	}
+	for _, container := range revertableContainers(pod) {
+		if err := revertContainerResources(container, annotation); err != nil {
+			return err
+		}
+	}
+	if !HasMemoryLimitsBoost(pod, annotation) {
+		RemoveResourceBoost(pod)
+		return nil
+	}
+	annotation.InitCPURequests = nil
+	annotation.InitCPULimits = nil
+	annotation.InitMemoryRequests = nil
+	pod.Annotations[BoostAnnotationKey] = annotation.ToJSON()
+	return nil
+}
+
+// RemoveResourceBoost removes the boost label and annotation from the POD, so the
+// POD is no longer considered boosted
+func RemoveResourceBoost(pod *corev1.Pod) {
 	delete(pod.Labels, BoostLabelKey)
 	delete(pod.Annotations, BoostAnnotationKey)
-	for _, container := range pod.Spec.Containers {
-		if request, ok := annotation.InitCPURequests[container.Name]; ok {
-			if reqQuantity, err := apiResource.ParseQuantity(request); err == nil {
-				container.Resources.Requests[corev1.ResourceCPU] = reqQuantity
-			} else {
-				return fmt.Errorf("failed to parse CPU request: %s", err)
-			}
+}
+
+// HasMemoryLimitsBoost returns true if a given boost annotation records the memory
+// limits of any of the POD containers which resources can be reverted in place
+func HasMemoryLimitsBoost(pod *corev1.Pod, annotation *BoostPodAnnotation) bool {
+	for _, container := range revertableContainers(pod) {
+		if _, ok := annotation.InitMemoryLimits[container.Name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// MemoryLimitsResizeRequiresRestart returns the name of the first POD container which
+// memory limits are recorded in a given boost annotation and which memory resize policy
+// requires the container restart, or an empty string if there is no such container
+func MemoryLimitsResizeRequiresRestart(pod *corev1.Pod, annotation *BoostPodAnnotation) string {
+	for _, container := range revertableContainers(pod) {
+		if _, ok := annotation.InitMemoryLimits[container.Name]; !ok {
+			continue
 		}
-		if limit, ok := annotation.InitCPULimits[container.Name]; ok {
-			if limitQuantity, err := apiResource.ParseQuantity(limit); err == nil {
-				container.Resources.Limits[corev1.ResourceCPU] = limitQuantity
-			} else {
-				return fmt.Errorf("failed to parse CPU limit: %s", err)
+		for _, policy := range container.ResizePolicy {
+			if policy.ResourceName == corev1.ResourceMemory &&
+				policy.RestartPolicy == corev1.RestartContainer {
+				return container.Name
 			}
 		}
 	}
+	return ""
+}
+
+// RevertContainerResourceBoost reverts the resources of given POD containers to the
+// original values from the boost annotation and removes the containers from the
+// annotation, so the remaining containers stay boosted. As in RevertResourceBoost,
+// the memory limits are not reverted. They are kept in the annotation until the
+// whole POD is reverted.
+func RevertContainerResourceBoost(pod *corev1.Pod, containerNames ...string) error {
+	annotation, err := BoostAnnotationFromPod(pod)
+	if err != nil {
+		return fmt.Errorf("failed to get boost annotation from pod: %s", err)
+	}
+	names := make(map[string]bool, len(containerNames))
+	for _, name := range containerNames {
+		names[name] = true
+	}
+	for _, container := range revertableContainers(pod) {
+		if !names[container.Name] {
+			continue
+		}
+		if err := revertContainerResources(container, annotation); err != nil {
+			return err
+		}
+		delete(annotation.InitCPURequests, container.Name)
+		delete(annotation.InitCPULimits, container.Name)
+		delete(annotation.InitMemoryRequests, container.Name)
+	}
+	pod.Annotations[BoostAnnotationKey] = annotation.ToJSON()
+	return nil
+}
+
+// RevertMemoryLimits reverts the POD container memory limits to the original values
+// from a given boost annotation. The function returns true if any of the limits
+// was changed.
+func RevertMemoryLimits(pod *corev1.Pod, annotation *BoostPodAnnotation) (bool, error) {
+	var reverted bool
+	for _, container := range revertableContainers(pod) {
+		if _, ok := annotation.InitMemoryLimits[container.Name]; !ok {
+			continue
+		}
+		if err := revertResource(&container.Resources.Limits, corev1.ResourceMemory,
+			annotation.InitMemoryLimits, container.Name); err != nil {
+			return false, fmt.Errorf("failed to parse memory limit: %s", err)
+		}
+		reverted = true
+	}
+	return reverted, nil
+}
+
+// ApplyBoostPhase sets the POD container CPU resources to the original values from
+// the boost annotation increased by a given percentage and records a given phase
+// with its start time in the boost annotation. The CPU limits are set only for the
+// containers that have them, as they may have been removed during the boost.
+func ApplyBoostPhase(pod *corev1.Pod, phase int, percentage int64, start time.Time) error {
+	annotation, err := BoostAnnotationFromPod(pod)
+	if err != nil {
+		return fmt.Errorf("failed to get boost annotation from pod: %s", err)
+	}
+	for _, container := range revertableContainers(pod) {
+		if err := increaseResource(container.Resources.Requests, corev1.ResourceCPU,
+			annotation.InitCPURequests, container.Name, percentage); err != nil {
+			return fmt.Errorf("failed to parse CPU request: %s", err)
+		}
+		if err := increaseResource(container.Resources.Limits, corev1.ResourceCPU,
+			annotation.InitCPULimits, container.Name, percentage); err != nil {
+			return fmt.Errorf("failed to parse CPU limit: %s", err)
+		}
+	}
+	annotation.Phase = phase
+	annotation.PhaseTimestamp = &start
+	pod.Annotations[BoostAnnotationKey] = annotation.ToJSON()
+	return nil
+}
+
+// IsSidecarContainer returns true if a given init container is a restartable
+// init container, i.e. native sidecar container, that runs alongside the main
+// containers
+func IsSidecarContainer(container *corev1.Container) bool {
+	return container.RestartPolicy != nil &&
+		*container.RestartPolicy == corev1.ContainerRestartPolicyAlways
+}
+
+// revertableContainers returns the POD containers which resources can be reverted
+// in place, i.e. main containers and sidecar containers
+func revertableContainers(pod *corev1.Pod) []*corev1.Container {
+	var containers []*corev1.Container
+	for i := range pod.Spec.InitContainers {
+		if IsSidecarContainer(&pod.Spec.InitContainers[i]) {
+			containers = append(containers, &pod.Spec.InitContainers[i])
+		}
+	}
+	for i := range pod.Spec.Containers {
+		containers = append(containers, &pod.Spec.Containers[i])
+	}
+	return containers
+}
+
+// revertContainerResources sets the CPU resources and memory requests of a given
+// container to the original values from a given boost annotation
+func revertContainerResources(container *corev1.Container, annotation *BoostPodAnnotation) error {
+	if err := revertResource(&container.Resources.Requests, corev1.ResourceCPU,
+		annotation.InitCPURequests, container.Name); err != nil {
+		return fmt.Errorf("failed to parse CPU request: %s", err)
+	}
+	if err := revertResource(&container.Resources.Limits, corev1.ResourceCPU,
+		annotation.InitCPULimits, container.Name); err != nil {
+		return fmt.Errorf("failed to parse CPU limit: %s", err)
+	}
+	if err := revertResource(&container.Resources.Requests, corev1.ResourceMemory,
+		annotation.InitMemoryRequests, container.Name); err != nil {
+		return fmt.Errorf("failed to parse memory request: %s", err)
+	}
+	return nil
+}
+
+// revertResource sets the resource in a given resource list to the original value
+// of a given container, if present. The resource list is created if needed, i.e.
+// when the limits were removed during the boost.
+func revertResource(resources *corev1.ResourceList, name corev1.ResourceName,
+	initValues map[string]string, containerName string) error {
+	value, ok := initValues[containerName]
+	if !ok {
+		return nil
+	}
+	quantity, err := apiResource.ParseQuantity(value)
+	if err != nil {
+		return err
+	}
+	if *resources == nil {
+		*resources = make(corev1.ResourceList)
+	}
+	(*resources)[name] = quantity
+	return nil
+}
+
+// increaseResource sets the resource in a given resource list to the original value
+// of a given container increased by a given percentage, if both are present.
+func increaseResource(resources corev1.ResourceList, name corev1.ResourceName,
+	initValues map[string]string, containerName string, percentage int64) error {
+	value, ok := initValues[containerName]
+	if !ok {
+		return nil
+	}
+	if _, ok := resources[name]; !ok {
+		return nil
+	}
+	quantity, err := apiResource.ParseQuantity(value)
+	if err != nil {
+		return err
+	}
+	resources[name] = *resource.IncreaseQuantity(quantity, percentage)
 	return nil
 }
@@ -125,5 +125,184 @@ var _ = Describe("Pod", func() {
 				Expect(cpuReqTwo.String()).Should(Equal(annot.InitCPULimits[containerTwo]))
 			})
 		})
+		When("POD has startup-cpu-boost annotation with memory resources", func() {
+			BeforeEach(func() {
+				annot.InitMemoryRequests = map[string]string{containerOne: "100Mi"}
+				annot.InitMemoryLimits = map[string]string{containerOne: "200Mi"}
+				pod.Annotations[bpod.BoostAnnotationKey] = annot.ToJSON()
+				pod.Spec.Containers[0].Resources.Requests[corev1.ResourceMemory] = apiResource.MustParse("150Mi")
+				pod.Spec.Containers[0].Resources.Limits[corev1.ResourceMemory] = apiResource.MustParse("300Mi")
+				err = bpod.RevertResourceBoost(pod)
+			})
+			It("does not error", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+			It("reverts memory requests to initial values", func() {
+				memReq := pod.Spec.Containers[0].Resources.Requests[corev1.ResourceMemory]
+				Expect(memReq.String()).Should(Equal("100Mi"))
+			})
+			It("does not revert memory limits", func() {
+				memLimit := pod.Spec.Containers[0].Resources.Limits[corev1.ResourceMemory]
+				Expect(memLimit.String()).Should(Equal("300Mi"))
+			})
+			It("keeps startup-cpu-boost label", func() {
+				Expect(pod.Labels).To(HaveKey(bpod.BoostLabelKey))
+			})
+			It("keeps only memory limits in startup-cpu-boost annotation", func() {
+				result, err := bpod.BoostAnnotationFromPod(pod)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(result.InitCPURequests).To(BeEmpty())
+				Expect(result.InitCPULimits).To(BeEmpty())
+				Expect(result.InitMemoryRequests).To(BeEmpty())
+				Expect(result.InitMemoryLimits).To(Equal(annot.InitMemoryLimits))
+			})
+		})
+		When("POD has boosted init and sidecar containers", func() {
+			BeforeEach(func() {
+				restartAlways := corev1.ContainerRestartPolicyAlways
+				pod.Spec.InitContainers = []corev1.Container{
+					*pod.Spec.Containers[0].DeepCopy(),
+					*pod.Spec.Containers[0].DeepCopy(),
+				}
+				pod.Spec.InitContainers[0].Name = "init"
+				pod.Spec.InitContainers[1].Name = "sidecar"
+				pod.Spec.InitContainers[1].RestartPolicy = &restartAlways
+				annot.InitCPURequests["init"] = "500m"
+				annot.InitCPURequests["sidecar"] = "500m"
+				pod.Annotations[bpod.BoostAnnotationKey] = annot.ToJSON()
+				err = bpod.RevertResourceBoost(pod)
+			})
+			It("does not error", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+			It("reverts sidecar container CPU requests to initial values", func() {
+				cpuReq := pod.Spec.InitContainers[1].Resources.Requests[corev1.ResourceCPU]
+				Expect(cpuReq.String()).Should(Equal("500m"))
+			})
+			It("does not revert init container CPU requests", func() {
+				cpuReq := pod.Spec.InitContainers[0].Resources.Requests[corev1.ResourceCPU]
+				Expect(cpuReq.String()).Should(Equal(reqQuantity.String()))
+			})
+		})
+		When("POD has no limits after the boost", func() {
+			BeforeEach(func() {
+				pod.Spec.Containers[0].Resources.Limits = nil
+				err = bpod.RevertResourceBoost(pod)
+			})
+			It("does not error", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+			It("reverts CPU limits to initial values", func() {
+				cpuLimit := pod.Spec.Containers[0].Resources.Limits[corev1.ResourceCPU]
+				Expect(cpuLimit.String()).Should(Equal(annot.InitCPULimits[containerOne]))
+			})
+		})
+	})
+	Describe("Reverts the POD container memory limits to original values", func() {
+		var reverted bool
+		JustBeforeEach(func() {
+			reverted, err = bpod.RevertMemoryLimits(pod, annot)
+		})
+		When("annotation has no memory limits", func() {
+			It("does not error", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+			It("returns false", func() {
+				Expect(reverted).To(BeFalse())
+			})
+		})
+		When("annotation has memory limits", func() {
+			BeforeEach(func() {
+				annot.InitMemoryLimits = map[string]string{containerOne: "200Mi"}
+				pod.Spec.Containers[0].Resources.Limits[corev1.ResourceMemory] = apiResource.MustParse("300Mi")
+			})
+			It("does not error", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+			It("returns true", func() {
+				Expect(reverted).To(BeTrue())
+			})
+			It("reverts memory limits to initial values", func() {
+				memLimit := pod.Spec.Containers[0].Resources.Limits[corev1.ResourceMemory]
+				Expect(memLimit.String()).Should(Equal("200Mi"))
+			})
+		})
+	})
+	Describe("Applies the boost phase to the POD container resources", func() {
+		var start time.Time
+		BeforeEach(func() {
+			start = time.Now()
+			pod.Spec.Containers[1].Resources.Limits = nil
+		})
+		JustBeforeEach(func() {
+			err = bpod.ApplyBoostPhase(pod, 1, 50, start)
+		})
+		It("does not error", func() {
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		It("increases CPU requests of initial values by phase percentage", func() {
+			cpuReq := pod.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]
+			Expect(cpuReq.String()).Should(Equal("750m"))
+		})
+		It("increases CPU limits of initial values by phase percentage", func() {
+			cpuLimit := pod.Spec.Containers[0].Resources.Limits[corev1.ResourceCPU]
+			Expect(cpuLimit.String()).Should(Equal("1500m"))
+		})
+		It("does not set removed CPU limits", func() {
+			Expect(pod.Spec.Containers[1].Resources.Limits).NotTo(HaveKey(corev1.ResourceCPU))
+		})
+		It("keeps startup-cpu-boost label", func() {
+			Expect(pod.Labels).To(HaveKey(bpod.BoostLabelKey))
+		})
+		It("records the phase in startup-cpu-boost annotation", func() {
+			podAnnot, err := bpod.BoostAnnotationFromPod(pod)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(podAnnot.Phase).To(Equal(1))
+			Expect(podAnnot.PhaseTimestamp).NotTo(BeNil())
+			Expect(podAnnot.PhaseTimestamp.Equal(start)).To(BeTrue())
+			Expect(podAnnot.InitCPURequests).To(Equal(annot.InitCPURequests))
+		})
+		When("POD is missing startup-cpu-boost annotation", func() {
+			BeforeEach(func() {
+				delete(pod.ObjectMeta.Annotations, bpod.BoostAnnotationKey)
+			})
+			It("errors", func() {
+				Expect(err).Should(HaveOccurred())
+			})
+		})
+	})
+	Describe("Reverts the resources of given POD containers to original values", func() {
+		BeforeEach(func() {
+			annot.InitCPURequests = map[string]string{"container-one": "500m", "container-two": "250m"}
+			annot.InitCPULimits = map[string]string{"container-one": "1"}
+			annot.InitMemoryLimits = map[string]string{"container-one": "200Mi"}
+			pod.Annotations[bpod.BoostAnnotationKey] = annot.ToJSON()
+			pod.Spec.Containers[1].Name = "container-two"
+		})
+		JustBeforeEach(func() {
+			err = bpod.RevertContainerResourceBoost(pod, "container-one")
+		})
+		It("does not error", func() {
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		It("reverts CPU resources of given container to initial values", func() {
+			cpuReq := pod.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]
+			Expect(cpuReq.String()).Should(Equal("500m"))
+			cpuLimit := pod.Spec.Containers[0].Resources.Limits[corev1.ResourceCPU]
+			Expect(cpuLimit.String()).Should(Equal("1"))
+		})
+		It("does not revert CPU resources of other container", func() {
+			cpuReq := pod.Spec.Containers[1].Resources.Requests[corev1.ResourceCPU]
+			Expect(cpuReq.String()).Should(Equal(reqQuantity.String()))
+		})
+		It("keeps startup-cpu-boost label", func() {
+			Expect(pod.Labels).To(HaveKey(bpod.BoostLabelKey))
+		})
+		It("removes given container from startup-cpu-boost annotation", func() {
+			podAnnot, err := bpod.BoostAnnotationFromPod(pod)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(podAnnot.BoostedContainers()).To(Equal([]string{"container-two"}))
+			Expect(podAnnot.InitMemoryLimits).To(HaveKey("container-one"))
+		})
 	})
 })
@@ -0,0 +1,143 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pod
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ResizeState is the state of the in-place resize of the POD container resources
+type ResizeState string
+
+const (
+	// ResizeStateCompleted means that the resized resources are applied to the containers
+	ResizeStateCompleted ResizeState = "Completed"
+	// ResizeStatePending means that the resize was not processed by the kubelet yet
+	ResizeStatePending ResizeState = "Pending"
+	// ResizeStateInProgress means that the kubelet is applying the resized resources
+	ResizeStateInProgress ResizeState = "InProgress"
+	// ResizeStateDeferred means that the resize is not possible now, but may be later
+	ResizeStateDeferred ResizeState = "Deferred"
+	// ResizeStateInfeasible means that the resize is not possible on the node
+	ResizeStateInfeasible ResizeState = "Infeasible"
+	// ResizeStateError means that the kubelet failed to apply the resized resources
+	ResizeStateError ResizeState = "Error"
+)
+
+const (
+	// PodResizePending is the POD condition reporting the resize that the kubelet
+	// cannot grant yet, i.e. deferred or infeasible one
+	PodResizePending corev1.PodConditionType = "PodResizePending"
+	// PodResizeInProgress is the POD condition reporting the resize that is granted
+	// and is being applied by the kubelet
+	PodResizeInProgress corev1.PodConditionType = "PodResizeInProgress"
+
+	podResizeReasonInfeasible = "Infeasible"
+	podResizeReasonError      = "Error"
+
+	// podResizeStatusProposed is the POD resize status of the resize not processed
+	// by the kubelet yet, as reported by the clusters without the resize conditions
+	podResizeStatusProposed corev1.PodResizeStatus = "Proposed"
+)
+
+// ResizeStateFromPod returns the state of the in-place resize of a given POD container
+// resources with the message reported by the kubelet, if any. The state is taken from
+// the PodResizePending and PodResizeInProgress conditions, or from the POD resize status
+// on the clusters without them. The resize is completed once the resources allocated to
+// the containers and applied on them match the POD specification.
+func ResizeStateFromPod(pod *corev1.Pod) (ResizeState, string) {
+	if cond := findPodCondition(pod, PodResizePending); cond != nil {
+		if cond.Reason == podResizeReasonInfeasible {
+			return ResizeStateInfeasible, cond.Message
+		}
+		return ResizeStateDeferred, cond.Message
+	}
+	if cond := findPodCondition(pod, PodResizeInProgress); cond != nil {
+		if cond.Reason == podResizeReasonError {
+			return ResizeStateError, cond.Message
+		}
+		return ResizeStateInProgress, cond.Message
+	}
+	switch pod.Status.Resize {
+	case corev1.PodResizeStatusInfeasible:
+		return ResizeStateInfeasible, ""
+	case corev1.PodResizeStatusDeferred:
+		return ResizeStateDeferred, ""
+	case corev1.PodResizeStatusInProgress:
+		return ResizeStateInProgress, ""
+	case podResizeStatusProposed:
+		return ResizeStatePending, ""
+	}
+	statuses := containerStatuses(pod)
+	for _, container := range revertableContainers(pod) {
+		status, ok := statuses[container.Name]
+		if !ok {
+			continue
+		}
+		requests := container.Resources.Requests
+		if resourceMismatch(requests, status.AllocatedResources, corev1.ResourceCPU) ||
+			resourceMismatch(requests, status.AllocatedResources, corev1.ResourceMemory) {
+			return ResizeStatePending, ""
+		}
+		if status.Resources == nil {
+			continue
+		}
+		if resourceMismatch(requests, status.Resources.Requests, corev1.ResourceCPU) ||
+			resourceMismatch(requests, status.Resources.Requests, corev1.ResourceMemory) ||
+			resourceMismatch(container.Resources.Limits, status.Resources.Limits, corev1.ResourceCPU) {
+			return ResizeStateInProgress, ""
+		}
+	}
+	return ResizeStateCompleted, ""
+}
+
+// findPodCondition returns the POD condition of a given type if its status is true
+func findPodCondition(pod *corev1.Pod, condType corev1.PodConditionType) *corev1.PodCondition {
+	for i := range pod.Status.Conditions {
+		cond := &pod.Status.Conditions[i]
+		if cond.Type == condType && cond.Status == corev1.ConditionTrue {
+			return cond
+		}
+	}
+	return nil
+}
+
+// containerStatuses returns the statuses of the POD containers and init containers
+// by the container name
+func containerStatuses(pod *corev1.Pod) map[string]*corev1.ContainerStatus {
+	statuses := make(map[string]*corev1.ContainerStatus)
+	for i := range pod.Status.InitContainerStatuses {
+		statuses[pod.Status.InitContainerStatuses[i].Name] = &pod.Status.InitContainerStatuses[i]
+	}
+	for i := range pod.Status.ContainerStatuses {
+		statuses[pod.Status.ContainerStatuses[i].Name] = &pod.Status.ContainerStatuses[i]
+	}
+	return statuses
+}
+
+// resourceMismatch returns true if a given resource differs between the resource
+// lists. The resources missing in any of the lists are not compared, as the kubelet
+// does not report them when the in-place resize is not enabled.
+func resourceMismatch(spec, status corev1.ResourceList, name corev1.ResourceName) bool {
+	specValue, ok := spec[name]
+	if !ok {
+		return false
+	}
+	statusValue, ok := status[name]
+	if !ok {
+		return false
+	}
+	return !specValue.Equal(statusValue)
+}
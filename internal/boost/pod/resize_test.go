@@ -0,0 +1,161 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pod_test
+
+import (
+	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apiResource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ResizeStateFromPod", func() {
+	var (
+		pod     *corev1.Pod
+		state   bpod.ResizeState
+		message string
+	)
+	BeforeEach(func() {
+		resources := corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    apiResource.MustParse("500m"),
+				corev1.ResourceMemory: apiResource.MustParse("100Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU: apiResource.MustParse("1"),
+			},
+		}
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-pod"},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:      "container-one",
+					Resources: resources,
+				}},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:               "container-one",
+					AllocatedResources: resources.Requests.DeepCopy(),
+					Resources:          resources.DeepCopy(),
+				}},
+			},
+		}
+	})
+	JustBeforeEach(func() {
+		state, message = bpod.ResizeStateFromPod(pod)
+	})
+	When("container resources match the POD spec", func() {
+		It("returns completed state", func() {
+			Expect(state).To(Equal(bpod.ResizeStateCompleted))
+			Expect(message).To(BeEmpty())
+		})
+	})
+	When("container status does not report resources", func() {
+		BeforeEach(func() {
+			pod.Status.ContainerStatuses[0].AllocatedResources = nil
+			pod.Status.ContainerStatuses[0].Resources = nil
+			pod.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = apiResource.MustParse("2")
+		})
+		It("returns completed state", func() {
+			Expect(state).To(Equal(bpod.ResizeStateCompleted))
+		})
+	})
+	When("allocated resources do not match the POD spec", func() {
+		BeforeEach(func() {
+			pod.Status.ContainerStatuses[0].AllocatedResources[corev1.ResourceCPU] = apiResource.MustParse("2")
+		})
+		It("returns pending state", func() {
+			Expect(state).To(Equal(bpod.ResizeStatePending))
+		})
+	})
+	When("applied resources do not match the POD spec", func() {
+		BeforeEach(func() {
+			pod.Status.ContainerStatuses[0].Resources.Limits[corev1.ResourceCPU] = apiResource.MustParse("2")
+		})
+		It("returns in progress state", func() {
+			Expect(state).To(Equal(bpod.ResizeStateInProgress))
+		})
+	})
+	When("POD resize status is set", func() {
+		DescribeTable("returns the corresponding state",
+			func(status corev1.PodResizeStatus, expected bpod.ResizeState) {
+				pod.Status.Resize = status
+				state, _ = bpod.ResizeStateFromPod(pod)
+				Expect(state).To(Equal(expected))
+			},
+			Entry("proposed", corev1.PodResizeStatus("Proposed"), bpod.ResizeStatePending),
+			Entry("in progress", corev1.PodResizeStatusInProgress, bpod.ResizeStateInProgress),
+			Entry("deferred", corev1.PodResizeStatusDeferred, bpod.ResizeStateDeferred),
+			Entry("infeasible", corev1.PodResizeStatusInfeasible, bpod.ResizeStateInfeasible),
+		)
+	})
+	When("POD has resize pending condition", func() {
+		var reason string
+		BeforeEach(func() {
+			reason = "Deferred"
+		})
+		JustBeforeEach(func() {
+			pod.Status.Conditions = []corev1.PodCondition{{
+				Type:    bpod.PodResizePending,
+				Status:  corev1.ConditionTrue,
+				Reason:  reason,
+				Message: "Node didn't have enough capacity",
+			}}
+			state, message = bpod.ResizeStateFromPod(pod)
+		})
+		It("returns deferred state with message", func() {
+			Expect(state).To(Equal(bpod.ResizeStateDeferred))
+			Expect(message).To(Equal("Node didn't have enough capacity"))
+		})
+		When("the reason is infeasible", func() {
+			BeforeEach(func() {
+				reason = "Infeasible"
+			})
+			It("returns infeasible state", func() {
+				Expect(state).To(Equal(bpod.ResizeStateInfeasible))
+			})
+		})
+	})
+	When("POD has resize in progress condition", func() {
+		var reason string
+		BeforeEach(func() {
+			reason = ""
+		})
+		JustBeforeEach(func() {
+			pod.Status.Conditions = []corev1.PodCondition{{
+				Type:    bpod.PodResizeInProgress,
+				Status:  corev1.ConditionTrue,
+				Reason:  reason,
+				Message: "resize failed",
+			}}
+			state, message = bpod.ResizeStateFromPod(pod)
+		})
+		It("returns in progress state", func() {
+			Expect(state).To(Equal(bpod.ResizeStateInProgress))
+		})
+		When("the reason is error", func() {
+			BeforeEach(func() {
+				reason = "Error"
+			})
+			It("returns error state with message", func() {
+				Expect(state).To(Equal(bpod.ResizeStateError))
+				Expect(message).To(Equal("resize failed"))
+			})
+		})
+	})
+})
@@ -0,0 +1,160 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boost
+
+import (
+	"time"
+
+	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	"github.com/google/kube-startup-cpu-boost/internal/metrics"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+const (
+	// ResizeStuckTimeout is the time after which the in-place resize of the POD
+	// resources that did not complete is considered stuck
+	ResizeStuckTimeout = 2 * time.Minute
+	// ResizeStuckReason is the failure reason of the stuck in-place resize
+	ResizeStuckReason = "Stuck"
+)
+
+// PodResizeStatus is the status of the in-place resize of POD resources done by
+// a startup-cpu-boost, i.e. a resource revert or a ramp-down phase
+type PodResizeStatus struct {
+	// State is the resize state reported by the kubelet
+	State bpod.ResizeState
+	// Message is the resize message reported by the kubelet
+	Message string
+	// Started is the time when the resize was requested
+	Started time.Time
+	// Stuck is true when the resize did not complete within the ResizeStuckTimeout
+	Stuck bool
+}
+
+// Failed returns true if the resize was refused by the kubelet or is stuck
+func (s PodResizeStatus) Failed() bool {
+	return s.Stuck || s.State == bpod.ResizeStateInfeasible || s.State == bpod.ResizeStateError
+}
+
+// PodResize is the tracked in-place resize of POD resources
+type PodResize struct {
+	Pod    *corev1.Pod
+	Status PodResizeStatus
+}
+
+// ObservePodResize updates the tracked in-place resize of a given POD with the state
+// reported in the POD status. The POD that does not have the resized resources in its
+// specification, i.e. the outdated one, is ignored. The function returns the resize
+// status and true if the status changed. The completed resize is no longer tracked.
+func (b *StartupCPUBoostImpl) ObservePodResize(pod *corev1.Pod) (PodResizeStatus, bool) {
+	b.Lock()
+	if next := b.replacement; next != nil {
+		b.Unlock()
+		return next.ObservePodResize(pod)
+	}
+	defer b.Unlock()
+	resize, ok := b.resizes[pod.Name]
+	if !ok || !containerResourcesEqual(resize.Pod, pod) {
+		return PodResizeStatus{}, false
+	}
+	state, message := bpod.ResizeStateFromPod(pod)
+	if state == resize.Status.State && message == resize.Status.Message {
+		return resize.Status, false
+	}
+	resize.Pod = pod
+	resize.Status.State = state
+	resize.Status.Message = message
+	switch state {
+	case bpod.ResizeStateCompleted:
+		delete(b.resizes, pod.Name)
+	case bpod.ResizeStateInfeasible, bpod.ResizeStateError:
+		metrics.AddResizeFailure(b.namespace, b.name, string(state))
+	}
+	return resize.Status, true
+}
+
+// PodResizeStatus returns the status of the tracked in-place resize of a POD with
+// a given name
+func (b *StartupCPUBoostImpl) PodResizeStatus(podName string) (PodResizeStatus, bool) {
+	b.RLock()
+	defer b.RUnlock()
+	resize, ok := b.resizes[podName]
+	if !ok {
+		return PodResizeStatus{}, false
+	}
+	return resize.Status, true
+}
+
+// CheckPodResizes marks the tracked in-place resizes that did not complete within
+// the ResizeStuckTimeout as stuck. The function returns the resizes that became stuck.
+func (b *StartupCPUBoostImpl) CheckPodResizes(now time.Time) []PodResize {
+	b.Lock()
+	defer b.Unlock()
+	var stuck []PodResize
+	for _, resize := range b.resizes {
+		if resize.Status.Stuck || now.Sub(resize.Status.Started) < ResizeStuckTimeout {
+			continue
+		}
+		resize.Status.Stuck = true
+		metrics.AddResizeFailure(b.namespace, b.name, ResizeStuckReason)
+		stuck = append(stuck, *resize)
+	}
+	return stuck
+}
+
+// trackPodResize starts tracking of the in-place resize of a given POD, replacing
+// the previous resize of that POD, if any
+func (b *StartupCPUBoostImpl) trackPodResize(pod *corev1.Pod) {
+	b.resizes[pod.Name] = &PodResize{
+		Pod: pod,
+		Status: PodResizeStatus{
+			State:   bpod.ResizeStatePending,
+			Started: time.Now(),
+		},
+	}
+}
+
+// resizeStats returns the number of the tracked in-place resizes that did not
+// complete and the number of the ones that failed
+func (b *StartupCPUBoostImpl) resizeStats() (pending int, failed int) {
+	for _, resize := range b.resizes {
+		pending++
+		if resize.Status.Failed() {
+			failed++
+		}
+	}
+	return
+}
+
+// containerResourcesEqual returns true if the resources of the containers and init
+// containers of given PODs are equal
+func containerResourcesEqual(a, b *corev1.Pod) bool {
+	if len(a.Spec.Containers) != len(b.Spec.Containers) ||
+		len(a.Spec.InitContainers) != len(b.Spec.InitContainers) {
+		return false
+	}
+	for i := range a.Spec.InitContainers {
+		if !equality.Semantic.DeepEqual(a.Spec.InitContainers[i].Resources, b.Spec.InitContainers[i].Resources) {
+			return false
+		}
+	}
+	for i := range a.Spec.Containers {
+		if !equality.Semantic.DeepEqual(a.Spec.Containers[i].Resources, b.Spec.Containers[i].Resources) {
+			return false
+		}
+	}
+	return true
+}
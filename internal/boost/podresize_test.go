@@ -0,0 +1,176 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boost_test
+
+import (
+	"context"
+	"time"
+
+	autoscaling "github.com/google/kube-startup-cpu-boost/api/v1alpha1"
+	cpuboost "github.com/google/kube-startup-cpu-boost/internal/boost"
+	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resize"
+	"github.com/google/kube-startup-cpu-boost/internal/metrics"
+	"github.com/google/kube-startup-cpu-boost/internal/mock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("StartupCPUBoost in-place resize tracking", func() {
+	var (
+		spec     *autoscaling.StartupCPUBoost
+		boost    cpuboost.StartupCPUBoost
+		pod      *corev1.Pod
+		reverted *corev1.Pod
+		err      error
+	)
+	BeforeEach(func() {
+		spec = specTemplate.DeepCopy()
+		spec.Spec.DurationPolicy.PodCondition = &autoscaling.PodConditionDurationPolicy{
+			Type:   corev1.PodReady,
+			Status: corev1.ConditionTrue,
+		}
+		metrics.ClearBoostMetrics(spec.Namespace, spec.Name)
+		pod = podTemplate.DeepCopy()
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type:   corev1.PodReady,
+			Status: corev1.ConditionTrue,
+		}}
+		mockClient := mock.NewMockClient(gomock.NewController(GinkgoT()))
+		mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, p *corev1.Pod, patch client.Patch, opts ...client.PatchOption) error {
+				reverted = p.DeepCopy()
+				return nil
+			}).Times(1)
+		boost, err = cpuboost.NewStartupCPUBoost(resize.NewPatchStrategy(mockClient), spec)
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+	JustBeforeEach(func() {
+		err = boost.UpsertPod(context.TODO(), pod)
+	})
+	It("tracks the resize of the reverted POD", func() {
+		Expect(err).ShouldNot(HaveOccurred())
+		status, ok := boost.PodResizeStatus(pod.Name)
+		Expect(ok).To(BeTrue())
+		Expect(status.State).To(Equal(bpod.ResizeStatePending))
+		Expect(status.Failed()).To(BeFalse())
+		Expect(boost.Stats().PendingResizes).To(Equal(1))
+	})
+	When("the observed POD does not have the reverted resources", func() {
+		It("ignores the POD", func() {
+			stale := podTemplate.DeepCopy()
+			stale.Status.Resize = corev1.PodResizeStatusInfeasible
+			_, changed := boost.ObservePodResize(stale)
+			Expect(changed).To(BeFalse())
+			status, _ := boost.PodResizeStatus(pod.Name)
+			Expect(status.State).To(Equal(bpod.ResizeStatePending))
+		})
+	})
+	When("the kubelet reports the resize as infeasible", func() {
+		var (
+			status  cpuboost.PodResizeStatus
+			changed bool
+		)
+		JustBeforeEach(func() {
+			observed := reverted.DeepCopy()
+			observed.Status.Conditions = append(observed.Status.Conditions, corev1.PodCondition{
+				Type:    bpod.PodResizePending,
+				Status:  corev1.ConditionTrue,
+				Reason:  "Infeasible",
+				Message: "Node didn't have enough capacity",
+			})
+			status, changed = boost.ObservePodResize(observed)
+		})
+		It("returns the changed status", func() {
+			Expect(changed).To(BeTrue())
+			Expect(status.State).To(Equal(bpod.ResizeStateInfeasible))
+			Expect(status.Message).To(Equal("Node didn't have enough capacity"))
+			Expect(status.Failed()).To(BeTrue())
+		})
+		It("reports the failed resize in the statistics", func() {
+			stats := boost.Stats()
+			Expect(stats.PendingResizes).To(Equal(1))
+			Expect(stats.FailedResizes).To(Equal(1))
+		})
+		It("updates the resize failures metric", func() {
+			Expect(metrics.ResizeFailures(spec.Namespace, spec.Name, "Infeasible")).To(Equal(float64(1)))
+		})
+		When("the same state is observed again", func() {
+			It("returns the unchanged status", func() {
+				observed := reverted.DeepCopy()
+				observed.Status.Conditions = []corev1.PodCondition{{
+					Type:    bpod.PodResizePending,
+					Status:  corev1.ConditionTrue,
+					Reason:  "Infeasible",
+					Message: "Node didn't have enough capacity",
+				}}
+				_, changed = boost.ObservePodResize(observed)
+				Expect(changed).To(BeFalse())
+				Expect(metrics.ResizeFailures(spec.Namespace, spec.Name, "Infeasible")).To(Equal(float64(1)))
+			})
+		})
+	})
+	When("the kubelet applies the resize", func() {
+		var (
+			status  cpuboost.PodResizeStatus
+			changed bool
+		)
+		JustBeforeEach(func() {
+			status, changed = boost.ObservePodResize(reverted.DeepCopy())
+		})
+		It("returns the completed status", func() {
+			Expect(changed).To(BeTrue())
+			Expect(status.State).To(Equal(bpod.ResizeStateCompleted))
+		})
+		It("stops tracking the resize", func() {
+			_, ok := boost.PodResizeStatus(pod.Name)
+			Expect(ok).To(BeFalse())
+			Expect(boost.Stats().PendingResizes).To(Equal(0))
+		})
+	})
+	When("the resize does not complete in time", func() {
+		var stuck []cpuboost.PodResize
+		JustBeforeEach(func() {
+			Expect(boost.CheckPodResizes(time.Now())).To(BeEmpty())
+			stuck = boost.CheckPodResizes(time.Now().Add(cpuboost.ResizeStuckTimeout))
+		})
+		It("returns the stuck resize", func() {
+			Expect(stuck).To(HaveLen(1))
+			Expect(stuck[0].Pod.Name).To(Equal(pod.Name))
+			Expect(stuck[0].Status.Stuck).To(BeTrue())
+		})
+		It("reports the failed resize in the statistics", func() {
+			Expect(boost.Stats().FailedResizes).To(Equal(1))
+		})
+		It("updates the resize failures metric", func() {
+			Expect(metrics.ResizeFailures(spec.Namespace, spec.Name, cpuboost.ResizeStuckReason)).To(Equal(float64(1)))
+		})
+		It("returns the stuck resize only once", func() {
+			Expect(boost.CheckPodResizes(time.Now().Add(cpuboost.ResizeStuckTimeout))).To(BeEmpty())
+		})
+	})
+	When("the POD is deleted", func() {
+		JustBeforeEach(func() {
+			err = boost.DeletePod(context.TODO(), reverted)
+		})
+		It("stops tracking the resize", func() {
+			_, ok := boost.PodResizeStatus(pod.Name)
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
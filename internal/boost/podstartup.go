@@ -0,0 +1,401 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boost
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	autoscaling "github.com/google/kube-startup-cpu-boost/api/v1alpha1"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/duration"
+	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resource"
+	corev1 "k8s.io/api/core/v1"
+	apiResource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// startupProfiles records the startups of the PODs boosted by the startup-cpu-boost in
+// the startup profiles of their workloads
+type startupProfiles interface {
+	// Record adds a given startup sample of a given POD to the profile of its workload
+	Record(ctx context.Context, pod *corev1.Pod, sample autoscaling.StartupSample) error
+}
+
+// startupWriter runs the history, CPU usage and startup profile API calls of the observed
+// POD startups in the background, one at a time and in the order they were queued, so
+// the POD event handling does not wait for them. The goroutine runs only while there
+// are queued writes.
+type startupWriter struct {
+	mu      sync.Mutex
+	queue   []func()
+	running bool
+	pending sync.WaitGroup
+}
+
+// enqueue queues a given write and starts the goroutine, if not running
+func (w *startupWriter) enqueue(write func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending.Add(1)
+	w.queue = append(w.queue, write)
+	if !w.running {
+		w.running = true
+		go w.run()
+	}
+}
+
+// run runs the queued writes until the queue is empty
+func (w *startupWriter) run() {
+	for {
+		w.mu.Lock()
+		if len(w.queue) == 0 {
+			w.running = false
+			w.mu.Unlock()
+			return
+		}
+		write := w.queue[0]
+		w.queue = w.queue[1:]
+		w.mu.Unlock()
+		write()
+		w.pending.Done()
+	}
+}
+
+// wait waits until the queued writes are done
+func (w *startupWriter) wait() {
+	w.pending.Wait()
+}
+
+// podStartup is the startup of a POD boosted by the startup-cpu-boost, tracked until
+// it is learned by the learned duration and resource policies and recorded in the
+// startup profile
+type podStartup struct {
+	workloadKey string
+	// duration is true until the POD meets the condition of the learned duration
+	// policy
+	duration bool
+	// resources holds the boosted CPU requests of the containers with the learned
+	// resource policy until the POD is ready
+	resources map[string]apiResource.Quantity
+	// sample holds the startup recorded in the startup profile until the POD is
+	// ready and its resources are reverted
+	sample *autoscaling.StartupSample
+}
+
+// ObservePodStartup tracks the startup of a given POD boosted by the startup-cpu-boost
+// until it is learned by the learned duration and resource policies and recorded in the
+// startup profile. The startup is tracked by the POD name, so it is tracked after the POD
+// resources are reverted as well. When the tracked POD meets the condition of the learned
+// duration policy, its duration is learned. When the tracked POD is ready, its startup
+// is learned for the boosted CPU of the containers with the learned resource policy,
+// recorded when the startup was first observed. The POD that is already past its startup
+// when first observed, i.e. after the controller restart, is not learned. When the tracked
+// POD is ready and its resources are reverted, its startup is recorded in the startup
+// profile. The learned startups are written in the background. The function returns true
+// if anything is learned.
+func (b *StartupCPUBoostImpl) ObservePodStartup(ctx context.Context, pod *corev1.Pod) bool {
+	durationPolicy, _ := b.learnedDurationPolicy()
+	b.Lock()
+	if next := b.replacement; next != nil {
+		b.Unlock()
+		return next.ObservePodStartup(ctx, pod)
+	}
+	startup, tracked := b.startups[pod.Name]
+	if !tracked {
+		if startup, tracked = b.newPodStartup(pod, durationPolicy); tracked {
+			b.startups[pod.Name] = startup
+		}
+		b.Unlock()
+		return false
+	}
+	var learnDuration bool
+	if startup.duration {
+		if durationPolicy == nil {
+			startup.duration = false
+		} else if _, met := durationPolicy.ConditionDuration(pod); met {
+			learnDuration, startup.duration = true, false
+		}
+	}
+	var resources map[string]apiResource.Quantity
+	startupDuration, ready := conditionDuration(pod, corev1.PodReady)
+	if ready {
+		resources, startup.resources = startup.resources, nil
+	}
+	var sample *autoscaling.StartupSample
+	if startup.sample != nil {
+		updateStartupSample(startup.sample, pod)
+		if startup.sample.TimeToReady != nil && startup.sample.RevertTime != nil {
+			sample, startup.sample = startup.sample, nil
+		}
+	}
+	if !startup.duration && len(startup.resources) == 0 && startup.sample == nil {
+		delete(b.startups, pod.Name)
+	}
+	b.Unlock()
+	if !learnDuration && len(resources) == 0 && sample == nil {
+		return false
+	}
+	// the histories and profiles are written in the background, as they call the API server
+	workloadKey := startup.workloadKey
+	b.writer.enqueue(func() {
+		log := b.loggerFromContext(ctx).WithValues("pod", pod.Name, "workloadKey", workloadKey)
+		if learnDuration {
+			if _, err := durationPolicy.Learn(ctx, workloadKey, pod); err != nil {
+				log.Error(err, "failed to learn pod startup duration")
+			} else {
+				log.V(5).Info("pod startup duration learned")
+			}
+		}
+		if len(resources) > 0 && b.learnPodResources(ctx, workloadKey, pod, resources, startupDuration) {
+			log.V(5).Info("pod startup resources learned")
+		}
+		if sample != nil {
+			b.recordPodStartup(ctx, pod, *sample)
+		}
+	})
+	return learnDuration || len(resources) > 0
+}
+
+// TracksPodStartup returns true if the startup of a POD with a given name is tracked
+// by the learned duration or resource policy, or for the startup profile
+func (b *StartupCPUBoostImpl) TracksPodStartup(podName string) bool {
+	b.RLock()
+	defer b.RUnlock()
+	_, ok := b.startups[podName]
+	return ok
+}
+
+// newPodStartup returns the startup of a given POD to track and true if the POD
+// is boosted by the startup-cpu-boost and has anything to record, i.e. is not in
+// a ramp-down phase and the startup profile is configured, or has the workload
+// key and has anything to learn, i.e. did not meet the condition of a given
+// learned duration policy, if not nil, or is not ready and has containers boosted
+// by the learned resource policy. The containers that are already reverted or in
+// a ramp-down phase are not tracked, as they no longer have the boosted CPU.
+func (b *StartupCPUBoostImpl) newPodStartup(pod *corev1.Pod, durationPolicy *duration.LearnedDurationPolicy) (*podStartup, bool) {
+	if pod.Labels[bpod.BoostLabelKey] != b.name {
+		return nil, false
+	}
+	annotation, err := bpod.BoostAnnotationFromPod(pod)
+	if err != nil {
+		return nil, false
+	}
+	startup := &podStartup{workloadKey: annotation.WorkloadKey}
+	if b.profiles != nil && annotation.Phase == 0 {
+		startup.sample = newStartupSample(pod, annotation)
+	}
+	if annotation.WorkloadKey == "" {
+		return startup, startup.sample != nil
+	}
+	if durationPolicy != nil {
+		_, met := durationPolicy.ConditionDuration(pod)
+		startup.duration = !met
+	}
+	if _, ready := conditionDuration(pod, corev1.PodReady); !ready && annotation.Phase == 0 {
+		for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+			for _, container := range containers {
+				policies := strings.Split(annotation.Policies[container.Name], ",")
+				_, boosted := annotation.InitCPURequests[container.Name]
+				cpuRequests, ok := container.Resources.Requests[corev1.ResourceCPU]
+				if !ok || !boosted || !slices.Contains(policies, resource.LearnedPolicyName) {
+					continue
+				}
+				if startup.resources == nil {
+					startup.resources = make(map[string]apiResource.Quantity)
+				}
+				startup.resources[container.Name] = cpuRequests
+			}
+		}
+	}
+	return startup, startup.duration || len(startup.resources) > 0 || startup.sample != nil
+}
+
+// learnPodResources records the startup of a given POD, that took a given duration,
+// for the containers with given boosted CPU requests in the histories of their learned
+// resource policies. The CPU usage of the containers is read once for all of them, if
+// any policy uses it. The function returns true if the startup was learned for any
+// container.
+func (b *StartupCPUBoostImpl) learnPodResources(ctx context.Context, workloadKey string, pod *corev1.Pod,
+	resources map[string]apiResource.Quantity, startupDuration time.Duration) bool {
+	log := b.loggerFromContext(ctx).WithValues("pod", pod.Name, "workloadKey", workloadKey)
+	policies := make(map[string]*resource.LearnedPolicy, len(resources))
+	var useCPUUsage bool
+	for name := range resources {
+		policy, ok := b.ResourcePolicy(name)
+		if !ok {
+			continue
+		}
+		if learnedPolicy, ok := resource.FindLearnedPolicy(policy); ok {
+			policies[name] = learnedPolicy
+			useCPUUsage = useCPUUsage || learnedPolicy.UseCPUUsage()
+		}
+	}
+	var usage map[string]apiResource.Quantity
+	if useCPUUsage && b.usage != nil {
+		var err error
+		if usage, err = b.usage.ContainerCPUUsage(ctx, pod); err != nil {
+			log.Error(err, "failed to read pod CPU usage")
+		}
+	}
+	var learned bool
+	for name, policy := range policies {
+		sample := resource.ResourceSample{
+			CPU:             resources[name],
+			StartupDuration: metav1.Duration{Duration: startupDuration},
+		}
+		if cpuUsage, ok := usage[name]; ok {
+			sample.CPUUsage = &cpuUsage
+		}
+		if err := policy.Learn(ctx, workloadKey, name, sample); err != nil {
+			log.Error(err, "failed to learn pod startup resources", "container", name)
+			continue
+		}
+		learned = true
+	}
+	return learned
+}
+
+// recordPodStartup records a given startup sample of a given POD in the startup profile
+// of the POD workload, if configured
+func (b *StartupCPUBoostImpl) recordPodStartup(ctx context.Context, pod *corev1.Pod, sample autoscaling.StartupSample) {
+	if b.profiles == nil {
+		return
+	}
+	log := b.loggerFromContext(ctx).WithValues("pod", pod.Name)
+	if err := b.profiles.Record(ctx, pod, sample); err != nil {
+		log.Error(err, "failed to record pod startup profile")
+		return
+	}
+	log.V(5).Info("pod startup recorded in profile")
+}
+
+// observePodRevert records the time the resources of a POD with a given name were
+// reverted in its tracked startup sample, if any
+func (b *StartupCPUBoostImpl) observePodRevert(podName string, now time.Time) {
+	startup, ok := b.startups[podName]
+	if !ok || startup.sample == nil || startup.sample.RevertTime != nil {
+		return
+	}
+	revertTime := metav1.NewTime(now)
+	startup.sample.RevertTime = &revertTime
+}
+
+// learnedDurationPolicy returns the learned duration policy of the startup-cpu-boost
+// and true if such is configured
+func (b *StartupCPUBoostImpl) learnedDurationPolicy() (*duration.LearnedDurationPolicy, bool) {
+	policy, ok := duration.FindPolicy(b.durationPolicy, duration.LearnedDurationPolicyName)
+	if !ok {
+		return nil, false
+	}
+	learnedPolicy, ok := policy.(*duration.LearnedDurationPolicy)
+	return learnedPolicy, ok
+}
+
+// conditionDuration returns the time a given POD took to meet a given condition,
+// measured since the POD creation, and true if the POD meets the condition
+func conditionDuration(pod *corev1.Pod, conditionType corev1.PodConditionType) (time.Duration, bool) {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return condition.LastTransitionTime.Sub(pod.CreationTimestamp.Time), true
+		}
+	}
+	return 0, false
+}
+
+// newStartupSample returns the startup sample of a given POD with the CPU resources
+// of the containers boosted according to a given boost annotation
+func newStartupSample(pod *corev1.Pod, annotation *bpod.BoostPodAnnotation) *autoscaling.StartupSample {
+	sample := &autoscaling.StartupSample{
+		PodName:      pod.Name,
+		CreationTime: pod.CreationTimestamp,
+	}
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range containers {
+			cpuRequests, boostedRequests := annotation.InitCPURequests[container.Name]
+			cpuLimits, boostedLimits := annotation.InitCPULimits[container.Name]
+			if !boostedRequests && !boostedLimits {
+				continue
+			}
+			containerSample := autoscaling.ContainerBoostSample{
+				Name:                container.Name,
+				Policy:              annotation.Policies[container.Name],
+				OriginalCPURequests: parseQuantity(cpuRequests),
+				OriginalCPULimits:   parseQuantity(cpuLimits),
+			}
+			if quantity, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
+				containerSample.CPURequests = &quantity
+			}
+			if quantity, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
+				containerSample.CPULimits = &quantity
+			}
+			sample.Containers = append(sample.Containers, containerSample)
+		}
+	}
+	updateStartupSample(sample, pod)
+	return sample
+}
+
+// updateStartupSample sets the node of a given startup sample and the times of the
+// startup stages that a given POD reached, unless already set
+func updateStartupSample(sample *autoscaling.StartupSample, pod *corev1.Pod) {
+	if pod.Spec.NodeName != "" {
+		sample.NodeName = pod.Spec.NodeName
+	}
+	if d, ok := conditionDuration(pod, corev1.PodScheduled); ok && sample.TimeToScheduled == nil {
+		sample.TimeToScheduled = &metav1.Duration{Duration: d}
+	}
+	if d, ok := containersStartedDuration(pod); ok && sample.TimeToStarted == nil {
+		sample.TimeToStarted = &metav1.Duration{Duration: d}
+	}
+	if d, ok := conditionDuration(pod, corev1.PodReady); ok && sample.TimeToReady == nil {
+		sample.TimeToReady = &metav1.Duration{Duration: d}
+	}
+}
+
+// containersStartedDuration returns the time a given POD took to get all of its
+// containers running, measured since the POD creation, and true if all of them
+// are running
+func containersStartedDuration(pod *corev1.Pod) (time.Duration, bool) {
+	if len(pod.Spec.Containers) == 0 || len(pod.Status.ContainerStatuses) < len(pod.Spec.Containers) {
+		return 0, false
+	}
+	var started time.Time
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Running == nil {
+			return 0, false
+		}
+		if status.State.Running.StartedAt.After(started) {
+			started = status.State.Running.StartedAt.Time
+		}
+	}
+	return started.Sub(pod.CreationTimestamp.Time), true
+}
+
+// parseQuantity returns the quantity parsed from a given value or nil if the value
+// is empty or invalid
+func parseQuantity(value string) *apiResource.Quantity {
+	if value == "" {
+		return nil
+	}
+	quantity, err := apiResource.ParseQuantity(value)
+	if err != nil {
+		return nil
+	}
+	return &quantity
+}
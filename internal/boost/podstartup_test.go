@@ -0,0 +1,432 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boost_test
+
+import (
+	"context"
+	"time"
+
+	autoscaling "github.com/google/kube-startup-cpu-boost/api/v1alpha1"
+	cpuboost "github.com/google/kube-startup-cpu-boost/internal/boost"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/history"
+	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resize"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resource"
+	"github.com/google/kube-startup-cpu-boost/internal/mock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apiResource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("StartupCPUBoost startup tracking", func() {
+	var (
+		spec    *autoscaling.StartupCPUBoost
+		boost   cpuboost.StartupCPUBoost
+		pod     *corev1.Pod
+		stored  *corev1.ConfigMap
+		learned bool
+		release chan struct{}
+	)
+	BeforeEach(func() {
+		spec = specTemplate.DeepCopy()
+		spec.Spec.DurationPolicy.Learned = &autoscaling.LearnedDurationPolicy{
+			Fallback: autoscaling.FixedDurationPolicy{
+				Unit:  autoscaling.FixedDurationPolicyUnitSec,
+				Value: 30,
+			},
+		}
+		stored = nil
+		release = nil
+		mockClient := mock.NewMockClient(gomock.NewController(GinkgoT()))
+		mockClient.EXPECT().Get(gomock.Any(),
+			gomock.Eq(types.NamespacedName{Namespace: spec.Namespace, Name: history.ConfigMapName(spec.Name)}),
+			gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
+			DoAndReturn(func(ctx context.Context, key types.NamespacedName, obj client.Object, opts ...client.GetOption) error {
+				if release != nil {
+					<-release
+				}
+				if stored == nil {
+					return apierrors.NewNotFound(corev1.Resource("configmaps"), key.Name)
+				}
+				stored.DeepCopyInto(obj.(*corev1.ConfigMap))
+				return nil
+			}).AnyTimes()
+		mockClient.EXPECT().Create(gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
+			DoAndReturn(func(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+				stored = obj.(*corev1.ConfigMap).DeepCopy()
+				stored.ResourceVersion = "1"
+				return nil
+			}).AnyTimes()
+		var err error
+		boost, err = cpuboost.NewStartupCPUBoostWithClient(resize.NewPatchStrategy(mockClient), mockClient, spec)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		pod = podTemplate.DeepCopy()
+		pod.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Minute))
+		annotation := *annotTemplate
+		annotation.WorkloadKey = "deployment.demo.0123"
+		pod.Annotations[bpod.BoostAnnotationKey] = annotation.ToJSON()
+	})
+	JustBeforeEach(func() {
+		learned = boost.ObservePodStartup(context.TODO(), pod)
+		cpuboost.WaitForStartupWrites(boost)
+	})
+	It("tracks the startup of the boosted POD", func() {
+		Expect(learned).To(BeFalse())
+		Expect(boost.TracksPodStartup(pod.Name)).To(BeTrue())
+	})
+	When("the reverted POD meets the condition", func() {
+		JustBeforeEach(func() {
+			ready := pod.DeepCopy()
+			delete(ready.Labels, bpod.BoostLabelKey)
+			delete(ready.Annotations, bpod.BoostAnnotationKey)
+			ready.Status.Conditions = []corev1.PodCondition{{
+				Type:               corev1.PodReady,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(pod.CreationTimestamp.Add(42 * time.Second)),
+			}}
+			learned = boost.ObservePodStartup(context.TODO(), ready)
+			if release == nil {
+				cpuboost.WaitForStartupWrites(boost)
+			}
+		})
+		It("learns the POD startup duration", func() {
+			Expect(learned).To(BeTrue())
+			Expect(stored).NotTo(BeNil())
+			Expect(stored.Data).To(HaveKeyWithValue("deployment.demo.0123", `["42s"]`))
+		})
+		When("the history is written slowly", func() {
+			BeforeEach(func() {
+				release = make(chan struct{})
+			})
+			It("does not wait for the history write", func() {
+				Expect(learned).To(BeTrue())
+				Expect(stored).To(BeNil())
+				close(release)
+				cpuboost.WaitForStartupWrites(boost)
+				Expect(stored.Data).To(HaveKeyWithValue("deployment.demo.0123", `["42s"]`))
+			})
+		})
+		It("stops tracking the POD startup", func() {
+			Expect(boost.TracksPodStartup(pod.Name)).To(BeFalse())
+		})
+	})
+	When("the POD meets the condition when first observed", func() {
+		BeforeEach(func() {
+			pod.Status.Conditions = []corev1.PodCondition{{
+				Type:   corev1.PodReady,
+				Status: corev1.ConditionTrue,
+			}}
+		})
+		It("ignores the POD", func() {
+			Expect(learned).To(BeFalse())
+			Expect(boost.TracksPodStartup(pod.Name)).To(BeFalse())
+			Expect(stored).To(BeNil())
+		})
+	})
+	When("the POD has no workload key", func() {
+		BeforeEach(func() {
+			pod.Annotations[bpod.BoostAnnotationKey] = annotTemplate.ToJSON()
+		})
+		It("does not track the POD startup", func() {
+			Expect(boost.TracksPodStartup(pod.Name)).To(BeFalse())
+		})
+	})
+	When("the POD is deleted", func() {
+		JustBeforeEach(func() {
+			Expect(boost.DeletePod(context.TODO(), pod)).To(Succeed())
+			cpuboost.WaitForStartupWrites(boost)
+		})
+		It("stops tracking the POD startup", func() {
+			Expect(boost.TracksPodStartup(pod.Name)).To(BeFalse())
+		})
+	})
+	When("the boost is replaced", func() {
+		var replacement cpuboost.StartupCPUBoost
+		JustBeforeEach(func() {
+			var err error
+			replacement, err = cpuboost.NewStartupCPUBoost(nil, spec)
+			Expect(err).ShouldNot(HaveOccurred())
+			replacement.TransferState(boost)
+		})
+		It("transfers the tracked POD startup", func() {
+			Expect(replacement.TracksPodStartup(pod.Name)).To(BeTrue())
+			Expect(boost.TracksPodStartup(pod.Name)).To(BeFalse())
+		})
+	})
+	When("the boost has no learned duration policy", func() {
+		BeforeEach(func() {
+			spec.Spec.DurationPolicy.Learned = nil
+			var err error
+			boost, err = cpuboost.NewStartupCPUBoost(nil, spec)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		It("does not track the POD startup", func() {
+			Expect(boost.TracksPodStartup(pod.Name)).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("StartupCPUBoost startup resources tracking", func() {
+	var (
+		spec    *autoscaling.StartupCPUBoost
+		boost   cpuboost.StartupCPUBoost
+		pod     *corev1.Pod
+		stored  *corev1.ConfigMap
+		policy  string
+		learned bool
+	)
+	BeforeEach(func() {
+		spec = specTemplate.DeepCopy()
+		spec.Spec.ResourcePolicy = autoscaling.ResourcePolicy{
+			ContainerPolicies: []autoscaling.ContainerPolicy{{
+				ContainerName: "container-one",
+				LearnedResources: &autoscaling.LearnedResourcePolicy{
+					Min:         apiResource.MustParse("1"),
+					Max:         apiResource.MustParse("4"),
+					UseCPUUsage: true,
+				},
+			}},
+		}
+		stored = nil
+		policy = resource.LearnedPolicyName
+		mockClient := mock.NewMockClient(gomock.NewController(GinkgoT()))
+		mockClient.EXPECT().Get(gomock.Any(),
+			gomock.Eq(types.NamespacedName{Namespace: spec.Namespace, Name: history.ResourceConfigMapName(spec.Name)}),
+			gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
+			DoAndReturn(func(ctx context.Context, key types.NamespacedName, obj client.Object, opts ...client.GetOption) error {
+				if stored == nil {
+					return apierrors.NewNotFound(corev1.Resource("configmaps"), key.Name)
+				}
+				stored.DeepCopyInto(obj.(*corev1.ConfigMap))
+				return nil
+			}).AnyTimes()
+		mockClient.EXPECT().Create(gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
+			DoAndReturn(func(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+				stored = obj.(*corev1.ConfigMap).DeepCopy()
+				stored.ResourceVersion = "1"
+				return nil
+			}).AnyTimes()
+		mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&unstructured.Unstructured{})).
+			DoAndReturn(func(ctx context.Context, key types.NamespacedName, obj client.Object, opts ...client.GetOption) error {
+				obj.(*unstructured.Unstructured).Object["containers"] = []interface{}{
+					map[string]interface{}{"name": "container-one", "usage": map[string]interface{}{"cpu": "800m"}},
+				}
+				return nil
+			}).AnyTimes()
+		var err error
+		boost, err = cpuboost.NewStartupCPUBoostWithClient(resize.NewPatchStrategy(mockClient), mockClient, spec)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		pod = podTemplate.DeepCopy()
+		pod.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Minute))
+	})
+	JustBeforeEach(func() {
+		annotation := *annotTemplate
+		annotation.WorkloadKey = "deployment.demo.0123"
+		annotation.Policies = map[string]string{"container-one": policy}
+		pod.Annotations[bpod.BoostAnnotationKey] = annotation.ToJSON()
+		learned = boost.ObservePodStartup(context.TODO(), pod)
+		cpuboost.WaitForStartupWrites(boost)
+	})
+	It("tracks the startup of the boosted POD", func() {
+		Expect(learned).To(BeFalse())
+		Expect(boost.TracksPodStartup(pod.Name)).To(BeTrue())
+	})
+	When("the POD is ready", func() {
+		JustBeforeEach(func() {
+			ready := pod.DeepCopy()
+			ready.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = apiResource.MustParse("500m")
+			ready.Status.Conditions = []corev1.PodCondition{{
+				Type:               corev1.PodReady,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(pod.CreationTimestamp.Add(42 * time.Second)),
+			}}
+			learned = boost.ObservePodStartup(context.TODO(), ready)
+			cpuboost.WaitForStartupWrites(boost)
+		})
+		It("learns the POD startup with the boosted CPU and the CPU usage", func() {
+			Expect(learned).To(BeTrue())
+			Expect(stored).NotTo(BeNil())
+			Expect(stored.Data).To(HaveKeyWithValue("deployment.demo.0123.container-one",
+				`[{"cpu":"1","startupDuration":"42s","cpuUsage":"800m"}]`))
+		})
+		It("stops tracking the POD startup", func() {
+			Expect(boost.TracksPodStartup(pod.Name)).To(BeFalse())
+		})
+	})
+	When("the POD container is boosted by other policy", func() {
+		BeforeEach(func() {
+			policy = resource.PercentagePolicyName
+		})
+		It("does not track the POD startup", func() {
+			Expect(boost.TracksPodStartup(pod.Name)).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("StartupCPUBoost startup profile", func() {
+	var (
+		spec   *autoscaling.StartupCPUBoost
+		boost  cpuboost.StartupCPUBoost
+		pod    *corev1.Pod
+		stored *autoscaling.StartupProfile
+	)
+	BeforeEach(func() {
+		spec = specTemplate.DeepCopy()
+		spec.Spec.StartupProfile = &autoscaling.StartupProfilePolicy{}
+		stored = nil
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockClient := mock.NewMockClient(mockCtrl)
+		mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&autoscaling.StartupProfile{})).
+			DoAndReturn(func(ctx context.Context, key types.NamespacedName, obj client.Object, opts ...client.GetOption) error {
+				if stored == nil {
+					return apierrors.NewNotFound(autoscaling.GroupVersion.WithResource("startupprofiles").GroupResource(), key.Name)
+				}
+				stored.DeepCopyInto(obj.(*autoscaling.StartupProfile))
+				return nil
+			}).AnyTimes()
+		mockClient.EXPECT().Create(gomock.Any(), gomock.AssignableToTypeOf(&autoscaling.StartupProfile{})).
+			DoAndReturn(func(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+				obj.SetResourceVersion("1")
+				stored = obj.(*autoscaling.StartupProfile).DeepCopy()
+				return nil
+			}).AnyTimes()
+		mockSubResWriter := mock.NewMockSubResourceWriter(mockCtrl)
+		mockSubResWriter.EXPECT().Update(gomock.Any(), gomock.AssignableToTypeOf(&autoscaling.StartupProfile{})).
+			DoAndReturn(func(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				stored = obj.(*autoscaling.StartupProfile).DeepCopy()
+				return nil
+			}).AnyTimes()
+		mockClient.EXPECT().Status().Return(mockSubResWriter).AnyTimes()
+		mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		var err error
+		boost, err = cpuboost.NewStartupCPUBoostWithClient(resize.NewPatchStrategy(mockClient), mockClient, spec)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		pod = podTemplate.DeepCopy()
+		pod.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Minute))
+		annotation := *annotTemplate
+		annotation.Policies = map[string]string{"container-one": resource.PercentagePolicyName}
+		pod.Annotations[bpod.BoostAnnotationKey] = annotation.ToJSON()
+	})
+	JustBeforeEach(func() {
+		boost.ObservePodStartup(context.TODO(), pod)
+		cpuboost.WaitForStartupWrites(boost)
+	})
+	It("tracks the startup of the boosted POD", func() {
+		Expect(boost.TracksPodStartup(pod.Name)).To(BeTrue())
+		Expect(stored).To(BeNil())
+	})
+	When("the POD is ready", func() {
+		var ready *corev1.Pod
+		JustBeforeEach(func() {
+			ready = pod.DeepCopy()
+			ready.Spec.NodeName = "node-001"
+			ready.Status.Conditions = []corev1.PodCondition{
+				{
+					Type:               corev1.PodScheduled,
+					Status:             corev1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(pod.CreationTimestamp.Add(2 * time.Second)),
+				},
+				{
+					Type:               corev1.PodReady,
+					Status:             corev1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(pod.CreationTimestamp.Add(42 * time.Second)),
+				},
+			}
+			for _, container := range ready.Spec.Containers {
+				ready.Status.ContainerStatuses = append(ready.Status.ContainerStatuses, corev1.ContainerStatus{
+					Name: container.Name,
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{
+						StartedAt: metav1.NewTime(pod.CreationTimestamp.Add(30 * time.Second)),
+					}},
+				})
+			}
+			boost.ObservePodStartup(context.TODO(), ready)
+			cpuboost.WaitForStartupWrites(boost)
+		})
+		It("does not record the POD startup before the revert", func() {
+			Expect(boost.TracksPodStartup(pod.Name)).To(BeTrue())
+			Expect(stored).To(BeNil())
+		})
+		When("the POD resources are reverted", func() {
+			JustBeforeEach(func() {
+				Expect(boost.RevertResources(context.TODO(), ready)).To(Succeed())
+				reverted := ready.DeepCopy()
+				delete(reverted.Labels, bpod.BoostLabelKey)
+				delete(reverted.Annotations, bpod.BoostAnnotationKey)
+				boost.ObservePodStartup(context.TODO(), reverted)
+				cpuboost.WaitForStartupWrites(boost)
+			})
+			It("records the POD startup in the profile", func() {
+				Expect(stored).NotTo(BeNil())
+				Expect(stored.Spec.BoostName).To(Equal(spec.Name))
+				Expect(stored.Status.Samples).To(HaveLen(1))
+				sample := stored.Status.Samples[0]
+				Expect(sample.PodName).To(Equal(pod.Name))
+				Expect(sample.NodeName).To(Equal("node-001"))
+				Expect(sample.TimeToScheduled.Duration).To(Equal(2 * time.Second))
+				Expect(sample.TimeToStarted.Duration).To(Equal(30 * time.Second))
+				Expect(sample.TimeToReady.Duration).To(Equal(42 * time.Second))
+				Expect(sample.RevertTime).NotTo(BeNil())
+				Expect(sample.Containers).To(HaveLen(1))
+				Expect(sample.Containers[0].Name).To(Equal("container-one"))
+				Expect(sample.Containers[0].Policy).To(Equal(resource.PercentagePolicyName))
+				Expect(sample.Containers[0].CPURequests.String()).To(Equal("1"))
+				Expect(sample.Containers[0].CPULimits.String()).To(Equal("2"))
+				Expect(sample.Containers[0].OriginalCPURequests.String()).To(Equal("500m"))
+				Expect(sample.Containers[0].OriginalCPULimits.String()).To(Equal("1"))
+				Expect(stored.Status.Summary.TimeToReady.Median.Duration).To(Equal(42 * time.Second))
+			})
+			It("stops tracking the POD startup", func() {
+				Expect(boost.TracksPodStartup(pod.Name)).To(BeFalse())
+			})
+		})
+	})
+	When("the POD is deleted", func() {
+		JustBeforeEach(func() {
+			Expect(boost.DeletePod(context.TODO(), pod)).To(Succeed())
+			cpuboost.WaitForStartupWrites(boost)
+		})
+		It("records the POD startup with the reached stages", func() {
+			Expect(stored).NotTo(BeNil())
+			Expect(stored.Status.Samples).To(HaveLen(1))
+			Expect(stored.Status.Samples[0].TimeToReady).To(BeNil())
+			Expect(stored.Status.Samples[0].RevertTime).To(BeNil())
+		})
+		It("stops tracking the POD startup", func() {
+			Expect(boost.TracksPodStartup(pod.Name)).To(BeFalse())
+		})
+	})
+	When("the boost has no startup profile policy", func() {
+		BeforeEach(func() {
+			spec.Spec.StartupProfile = nil
+			var err error
+			boost, err = cpuboost.NewStartupCPUBoost(nil, spec)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		It("does not track the POD startup", func() {
+			Expect(boost.TracksPodStartup(pod.Name)).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,141 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predictor
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// breakers are the circuit breakers shared by the clients of the same
+	// predictor endpoint created with the same options
+	breakers   = make(map[EndpointKey]*circuitBreaker)
+	breakersMu sync.Mutex
+)
+
+// EndpointKey identifies the circuit breaker and the gRPC connection shared by the
+// predictor clients of the same endpoint created with the same options
+type EndpointKey struct {
+	Endpoint string
+	Options  Options
+}
+
+// NewEndpointKey returns the key of a given predictor endpoint and options
+func NewEndpointKey(endpoint string, options Options) EndpointKey {
+	return EndpointKey{
+		Endpoint: strings.TrimSuffix(endpoint, "/"),
+		Options:  options,
+	}
+}
+
+// Release removes the circuit breaker and closes the gRPC connection shared by the
+// predictor clients with a given key. The calls in progress are given time to complete
+// before the connection is closed.
+func Release(key EndpointKey) {
+	breakersMu.Lock()
+	delete(breakers, key)
+	breakersMu.Unlock()
+	connectionsMu.Lock()
+	conn, ok := connections[key]
+	delete(connections, key)
+	connectionsMu.Unlock()
+	if ok {
+		time.AfterFunc(key.Options.Timeout, func() { _ = conn.Close() })
+	}
+}
+
+// circuitBreaker refuses the predictor calls for a given time once a given number
+// of consecutive calls failed. When the time elapses, a single trial call is
+// allowed, and the breaker closes if it succeeds or opens again otherwise.
+type circuitBreaker struct {
+	sync.Mutex
+	timeFunc         func() time.Time
+	failureThreshold int
+	openDuration     time.Duration
+	failures         int
+	openUntil        time.Time
+	trial            bool
+}
+
+func newCircuitBreaker(failureThreshold int, openDuration time.Duration) *circuitBreaker {
+	return newCircuitBreakerWithTimeFunc(time.Now, failureThreshold, openDuration)
+}
+
+func newCircuitBreakerWithTimeFunc(timeFunc func() time.Time, failureThreshold int,
+	openDuration time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		timeFunc:         timeFunc,
+		failureThreshold: failureThreshold,
+		openDuration:     openDuration,
+	}
+}
+
+// sharedCircuitBreaker returns the circuit breaker of a given predictor endpoint and
+// options, created if it does not exist
+func sharedCircuitBreaker(key EndpointKey) *circuitBreaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	breaker, ok := breakers[key]
+	if !ok {
+		breaker = newCircuitBreaker(key.Options.FailureThreshold, key.Options.OpenDuration)
+		breakers[key] = breaker
+	}
+	return breaker
+}
+
+// Allow returns true if the predictor call is allowed, i.e. the breaker is closed
+// or the open time elapsed and no other trial call is in progress
+func (b *circuitBreaker) Allow() bool {
+	b.Lock()
+	defer b.Unlock()
+	if b.failureThreshold <= 0 || b.failures < b.failureThreshold {
+		return true
+	}
+	if b.trial || b.timeFunc().Before(b.openUntil) {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// Success records the successful predictor call and closes the breaker
+func (b *circuitBreaker) Success() {
+	b.Lock()
+	defer b.Unlock()
+	b.failures = 0
+	b.trial = false
+}
+
+// Failure records the failed predictor call. The breaker opens when the number of
+// consecutive failed calls reaches the threshold or the trial call failed.
+func (b *circuitBreaker) Failure() {
+	b.Lock()
+	defer b.Unlock()
+	b.failures++
+	b.trial = false
+	if b.failureThreshold > 0 && b.failures >= b.failureThreshold {
+		b.openUntil = b.timeFunc().Add(b.openDuration)
+	}
+}
+
+// Open returns true if the breaker refuses the predictor calls
+func (b *circuitBreaker) Open() bool {
+	b.Lock()
+	defer b.Unlock()
+	return b.failureThreshold > 0 && b.failures >= b.failureThreshold &&
+		(b.trial || b.timeFunc().Before(b.openUntil))
+}
@@ -0,0 +1,120 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predictor
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+var _ = Describe("Circuit breaker", func() {
+	var (
+		breaker *circuitBreaker
+		now     time.Time
+	)
+	BeforeEach(func() {
+		now = time.Now()
+		breaker = newCircuitBreakerWithTimeFunc(func() time.Time { return now }, 2, 10*time.Second)
+	})
+	It("allows the calls", func() {
+		Expect(breaker.Allow()).To(BeTrue())
+		Expect(breaker.Open()).To(BeFalse())
+	})
+	When("the failures do not reach the threshold", func() {
+		BeforeEach(func() {
+			breaker.Failure()
+			breaker.Success()
+			breaker.Failure()
+		})
+		It("allows the calls", func() {
+			Expect(breaker.Allow()).To(BeTrue())
+		})
+	})
+	When("the consecutive failures reach the threshold", func() {
+		BeforeEach(func() {
+			breaker.Failure()
+			breaker.Failure()
+		})
+		It("refuses the calls", func() {
+			Expect(breaker.Open()).To(BeTrue())
+			Expect(breaker.Allow()).To(BeFalse())
+		})
+		When("the open duration elapses", func() {
+			BeforeEach(func() {
+				now = now.Add(10 * time.Second)
+			})
+			It("allows a single trial call", func() {
+				Expect(breaker.Allow()).To(BeTrue())
+				Expect(breaker.Allow()).To(BeFalse())
+			})
+			It("closes when the trial call succeeds", func() {
+				Expect(breaker.Allow()).To(BeTrue())
+				breaker.Success()
+				Expect(breaker.Open()).To(BeFalse())
+				Expect(breaker.Allow()).To(BeTrue())
+			})
+			It("opens again when the trial call fails", func() {
+				Expect(breaker.Allow()).To(BeTrue())
+				breaker.Failure()
+				Expect(breaker.Open()).To(BeTrue())
+				Expect(breaker.Allow()).To(BeFalse())
+			})
+		})
+	})
+})
+
+var _ = Describe("Shared circuit breakers and connections", func() {
+	var (
+		key     EndpointKey
+		breaker *circuitBreaker
+	)
+	BeforeEach(func() {
+		key = NewEndpointKey("grpc://predictor.local:9090/", DefaultOptions())
+		key.Options.Timeout = 0
+		breaker = sharedCircuitBreaker(key)
+	})
+	AfterEach(func() {
+		Release(key)
+	})
+	It("shares the circuit breaker of the same endpoint and options", func() {
+		Expect(sharedCircuitBreaker(NewEndpointKey("grpc://predictor.local:9090", key.Options))).To(BeIdenticalTo(breaker))
+	})
+	It("does not share the circuit breaker of the same endpoint with other options", func() {
+		options := key.Options
+		options.FailureThreshold++
+		other := NewEndpointKey(key.Endpoint, options)
+		defer Release(other)
+		Expect(sharedCircuitBreaker(other)).NotTo(BeIdenticalTo(breaker))
+	})
+	When("the endpoint is released", func() {
+		var conn *grpc.ClientConn
+		BeforeEach(func() {
+			var err error
+			conn, err = NewGRPCClient(key.Endpoint, key.Options, nil).sharedConnection()
+			Expect(err).NotTo(HaveOccurred())
+			Release(key)
+		})
+		It("creates a new circuit breaker", func() {
+			Expect(sharedCircuitBreaker(key)).NotTo(BeIdenticalTo(breaker))
+		})
+		It("closes the connection", func() {
+			Eventually(conn.GetState).Should(Equal(connectivity.Shutdown))
+		})
+	})
+})
@@ -0,0 +1,343 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package predictor contains implementation of the client of the predictor API
+// used by the auto resource and duration policies
+package predictor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/kube-startup-cpu-boost/internal/metrics"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultTimeout is the default time limit of a predictor call, including
+	// the retries. It fits in the time limit of the POD admission webhook.
+	DefaultTimeout = time.Second
+	// DefaultMaxRetries is the default number of retries of a failed predictor call
+	DefaultMaxRetries = 2
+	// DefaultBackoff is the default time before the first retry of a failed
+	// predictor call. The time is doubled with every subsequent retry.
+	DefaultBackoff = 50 * time.Millisecond
+	// DefaultFailureThreshold is the default number of consecutive failed predictor
+	// calls that open the circuit breaker
+	DefaultFailureThreshold = 5
+	// DefaultOpenDuration is the default time the circuit breaker stays open before
+	// a trial predictor call is allowed
+	DefaultOpenDuration = 30 * time.Second
+	// DefaultNotifyBatchSize is the default maximum number of the reversion
+	// notifications sent in a batch by the gRPC predictor client
+	DefaultNotifyBatchSize = 100
+	// DefaultNotifyBatchInterval is the default time the gRPC predictor client
+	// collects the reversion notifications before sending them in a batch
+	DefaultNotifyBatchInterval = time.Second
+
+	// ErrorReasonTimeout is the metric reason of the predictor calls that timed out
+	ErrorReasonTimeout = "Timeout"
+	// ErrorReasonConnection is the metric reason of the predictor calls that failed
+	// to connect or to receive the response
+	ErrorReasonConnection = "Connection"
+	// ErrorReasonStatus is the metric reason of the predictor calls that returned
+	// an unexpected status code
+	ErrorReasonStatus = "Status"
+	// ErrorReasonDecode is the metric reason of the predictor calls which response
+	// could not be decoded
+	ErrorReasonDecode = "Decode"
+	// ErrorReasonCircuitOpen is the metric reason of the predictor calls that were
+	// refused by the open circuit breaker
+	ErrorReasonCircuitOpen = "CircuitOpen"
+	// ErrorReasonCredentials is the metric reason of the predictor calls that were
+	// not sent, as the credentials could not be loaded
+	ErrorReasonCredentials = "Credentials"
+)
+
+// ErrCircuitOpen is returned when a predictor call is refused, as the predictor
+// failed recently and the circuit breaker is open
+var ErrCircuitOpen = errors.New("predictor circuit breaker is open")
+
+// Options defines the behavior of the predictor client
+type Options struct {
+	// Timeout is the time limit of a predictor call, including the retries
+	Timeout time.Duration
+	// MaxRetries is the number of retries of a failed predictor call
+	MaxRetries int
+	// Backoff is the time before the first retry, doubled with every retry
+	Backoff time.Duration
+	// FailureThreshold is the number of consecutive failed predictor calls
+	// that open the circuit breaker
+	FailureThreshold int
+	// OpenDuration is the time the circuit breaker stays open
+	OpenDuration time.Duration
+	// NotifyBatchSize is the maximum number of the reversion notifications sent
+	// in a batch. It applies to the gRPC predictor client only.
+	NotifyBatchSize int
+	// NotifyBatchInterval is the time the reversion notifications are collected
+	// before being sent in a batch. It applies to the gRPC predictor client only.
+	NotifyBatchInterval time.Duration
+}
+
+// DefaultOptions returns the default predictor client options
+func DefaultOptions() Options {
+	return Options{
+		Timeout:             DefaultTimeout,
+		MaxRetries:          DefaultMaxRetries,
+		Backoff:             DefaultBackoff,
+		FailureThreshold:    DefaultFailureThreshold,
+		OpenDuration:        DefaultOpenDuration,
+		NotifyBatchSize:     DefaultNotifyBatchSize,
+		NotifyBatchInterval: DefaultNotifyBatchInterval,
+	}
+}
+
+// Client calls the predictor API over HTTP. The calls are limited in time and
+// retried with backoff. The clients of the same predictor endpoint share the
+// circuit breaker, so all of them fail fast when the predictor is down.
+type Client struct {
+	endpoint    string
+	httpClient  *http.Client
+	credentials *Credentials
+	options     Options
+	breaker     *circuitBreaker
+}
+
+// NewClient returns the predictor client for a given endpoint with given options
+func NewClient(endpoint string, options Options) *Client {
+	return NewClientWithHTTPClient(endpoint, options, http.DefaultClient)
+}
+
+// NewClientWithHTTPClient returns the predictor client for a given endpoint with given
+// options that sends the requests with a given HTTP client
+func NewClientWithHTTPClient(endpoint string, options Options, httpClient *http.Client) *Client {
+	return &Client{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		httpClient: httpClient,
+		options:    options,
+		breaker:    sharedCircuitBreaker(NewEndpointKey(endpoint, options)),
+	}
+}
+
+// NewClientWithCredentials returns the predictor client for a given endpoint with
+// given options that sends the requests with the HTTP client and the bearer token
+// from given credentials
+func NewClientWithCredentials(endpoint string, options Options, credentials *Credentials) *Client {
+	client := NewClient(endpoint, options)
+	client.credentials = credentials
+	return client
+}
+
+// Endpoint returns the predictor endpoint
+func (c *Client) Endpoint() string {
+	return c.endpoint
+}
+
+// Post sends a given request encoded in JSON to a given path of the predictor
+// endpoint and decodes the JSON response into a given value, unless it is nil.
+// The failed calls are retried until the timeout, and the server errors are
+// retried as well.
+func (c *Client) Post(ctx context.Context, path string, request, response any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal predictor request: %w", err)
+	}
+	httpClient, token := c.httpClient, ""
+	if c.credentials != nil {
+		if httpClient, token, err = c.credentials.Load(ctx); err != nil {
+			metrics.AddPredictorError(path, ErrorReasonCredentials)
+			return err
+		}
+	}
+	return call(ctx, path, c.options, c.breaker, func(ctx context.Context) error {
+		return c.post(ctx, httpClient, token, path, body, response)
+	})
+}
+
+// Predict returns the prediction of a given POD request from the predict path of
+// the predictor endpoint
+func (c *Client) Predict(ctx context.Context, request *PodRequest) (*PodPrediction, error) {
+	prediction := &PodPrediction{}
+	if err := c.Post(ctx, PredictPath, request, prediction); err != nil {
+		return nil, err
+	}
+	return prediction, nil
+}
+
+// NotifyReversion sends a given notification to the notify path of the predictor
+// endpoint
+func (c *Client) NotifyReversion(ctx context.Context, request *NotifyRequest) error {
+	return c.Post(ctx, NotifyPath, request, nil)
+}
+
+// call makes the predictor call with a given function, unless refused by a given
+// circuit breaker. The call is limited in time and retried with backoff according to
+// given options. The call latency and errors are recorded with a given path.
+func call(ctx context.Context, path string, options Options, breaker *circuitBreaker,
+	attempt func(ctx context.Context) error) error {
+	if !breaker.Allow() {
+		metrics.AddPredictorError(path, ErrorReasonCircuitOpen)
+		return ErrCircuitOpen
+	}
+	ctx, cancel := context.WithTimeout(ctx, options.Timeout)
+	defer cancel()
+	start := time.Now()
+	err := callWithRetries(ctx, options, attempt)
+	metrics.ObservePredictorRequest(path, time.Since(start).Seconds())
+	switch {
+	case err == nil:
+		breaker.Success()
+	case retryable(err):
+		// only the errors that indicate the predictor is unavailable open the breaker
+		breaker.Failure()
+	default:
+		breaker.Success()
+	}
+	if err != nil {
+		metrics.AddPredictorError(path, errorReason(ctx, err))
+	}
+	return err
+}
+
+// callWithRetries makes the call until it succeeds, fails with an error that is not
+// retryable, the retries are exhausted or a given context is done
+func callWithRetries(ctx context.Context, options Options, attempt func(ctx context.Context) error) error {
+	backoff := options.Backoff
+	for i := 0; ; i++ {
+		err := attempt(ctx)
+		if err == nil || !retryable(err) || i >= options.MaxRetries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post sends the request with a given HTTP client and a given bearer token, if set
+func (c *Client) post(ctx context.Context, httpClient *http.Client, token, path string, body []byte, response any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create predictor request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return &requestError{err: err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+	if response == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return &decodeError{err: err}
+	}
+	return nil
+}
+
+// StatusError is returned when the predictor responds with an unexpected
+// status code
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected predictor status code: %d", e.StatusCode)
+}
+
+// requestError is returned when the predictor request could not be sent or the
+// response could not be received
+type requestError struct {
+	err error
+}
+
+func (e *requestError) Error() string {
+	return fmt.Sprintf("failed to send predictor request: %s", e.err)
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+// decodeError is returned when the predictor response could not be decoded
+type decodeError struct {
+	err error
+}
+
+func (e *decodeError) Error() string {
+	return fmt.Sprintf("failed to decode predictor response: %s", e.err)
+}
+
+func (e *decodeError) Unwrap() error {
+	return e.err
+}
+
+// retryable returns true if the predictor call that failed with a given error
+// may succeed when retried, i.e. on the connection and server errors
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError ||
+			statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	if grpcStatus, ok := status.FromError(err); ok && grpcStatus.Code() != codes.OK {
+		switch grpcStatus.Code() {
+		case codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.Internal:
+			return true
+		}
+		return false
+	}
+	var reqErr *requestError
+	return errors.As(err, &reqErr)
+}
+
+// errorReason returns the metric reason of a given predictor call error
+func errorReason(ctx context.Context, err error) string {
+	var statusErr *StatusError
+	var decodeErr *decodeError
+	switch {
+	case errors.As(err, &statusErr):
+		return ErrorReasonStatus
+	case errors.As(err, &decodeErr):
+		return ErrorReasonDecode
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return ErrorReasonTimeout
+	}
+	if grpcStatus, ok := status.FromError(err); ok {
+		switch grpcStatus.Code() {
+		case codes.Unavailable:
+			return ErrorReasonConnection
+		case codes.DeadlineExceeded:
+			return ErrorReasonTimeout
+		}
+		return ErrorReasonStatus
+	}
+	return ErrorReasonConnection
+}
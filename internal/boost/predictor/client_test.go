@@ -0,0 +1,161 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predictor_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
+	"github.com/google/kube-startup-cpu-boost/internal/metrics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type prediction struct {
+	Value string `json:"value"`
+}
+
+var _ = Describe("Predictor client", func() {
+	var (
+		server   *httptest.Server
+		handler  http.HandlerFunc
+		calls    atomic.Int32
+		options  predictor.Options
+		client   *predictor.Client
+		response prediction
+		err      error
+	)
+	BeforeEach(func() {
+		metrics.ClearSystemMetrics()
+		calls.Store(0)
+		options = predictor.DefaultOptions()
+		options.Backoff = time.Millisecond
+		response = prediction{}
+	})
+	JustBeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			handler(w, r)
+		}))
+		client = predictor.NewClient(server.URL, options)
+		err = client.Post(context.TODO(), "/cpu", map[string]string{"podName": "demo"}, &response)
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+	When("the predictor responds", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+				_ = json.NewEncoder(w).Encode(prediction{Value: "500m"})
+			}
+		})
+		It("doesn't error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("decodes the response", func() {
+			Expect(response.Value).To(Equal("500m"))
+		})
+		It("records the call latency", func() {
+			Expect(metrics.PredictorRequests("/cpu")).To(Equal(uint64(1)))
+		})
+	})
+	When("the predictor fails temporarily", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				if calls.Load() == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(prediction{Value: "500m"})
+			}
+		})
+		It("retries the call", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(calls.Load()).To(Equal(int32(2)))
+			Expect(response.Value).To(Equal("500m"))
+		})
+	})
+	When("the predictor keeps failing", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		})
+		It("errors after the retries are exhausted", func() {
+			Expect(err).To(MatchError(&predictor.StatusError{StatusCode: http.StatusInternalServerError}))
+			Expect(calls.Load()).To(Equal(int32(options.MaxRetries + 1)))
+		})
+		It("records the error", func() {
+			Expect(metrics.PredictorErrors("/cpu", predictor.ErrorReasonStatus)).To(Equal(float64(1)))
+		})
+	})
+	When("the predictor refuses the request", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		})
+		It("does not retry the call", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(calls.Load()).To(Equal(int32(1)))
+		})
+	})
+	When("the predictor does not respond in time", func() {
+		BeforeEach(func() {
+			options.Timeout = 50 * time.Millisecond
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(200 * time.Millisecond):
+				}
+			}
+		})
+		It("errors", func() {
+			Expect(err).To(HaveOccurred())
+		})
+		It("records the timeout", func() {
+			Expect(metrics.PredictorErrors("/cpu", predictor.ErrorReasonTimeout)).To(Equal(float64(1)))
+		})
+	})
+	When("the predictor is down", func() {
+		BeforeEach(func() {
+			options.MaxRetries = 0
+			options.FailureThreshold = 2
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			}
+		})
+		JustBeforeEach(func() {
+			err = client.Post(context.TODO(), "/cpu", nil, &response)
+			Expect(err).To(HaveOccurred())
+			err = client.Post(context.TODO(), "/cpu", nil, &response)
+		})
+		It("fails fast once the failure threshold is reached", func() {
+			Expect(err).To(MatchError(predictor.ErrCircuitOpen))
+			Expect(calls.Load()).To(Equal(int32(2)))
+		})
+		It("fails fast for other clients of the predictor", func() {
+			other := predictor.NewClient(server.URL, options)
+			Expect(other.Post(context.TODO(), "/duration", nil, nil)).To(MatchError(predictor.ErrCircuitOpen))
+			Expect(metrics.PredictorErrors("/duration", predictor.ErrorReasonCircuitOpen)).To(Equal(float64(1)))
+		})
+	})
+})
@@ -0,0 +1,182 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predictor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SecretTokenKey is the key of the bearer token in the predictor credentials Secret
+	SecretTokenKey = "token"
+	// SecretCertKey is the key of the client certificate in the predictor credentials Secret
+	SecretCertKey = corev1.TLSCertKey
+	// SecretKeyKey is the key of the client certificate key in the predictor credentials Secret
+	SecretKeyKey = corev1.TLSPrivateKeyKey
+	// SecretCAKey is the key of the CA bundle in the predictor credentials Secret
+	SecretCAKey = "ca.crt"
+	// DefaultServicePort is the default port of the predictor Service
+	DefaultServicePort = 443
+)
+
+// ServiceEndpoint returns the HTTPS endpoint of the predictor served by a Service
+// with a given namespace, name and port, under a given path prefix
+func ServiceEndpoint(namespace, name string, port int32, path string) string {
+	if path != "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return fmt.Sprintf("https://%s.%s.svc:%d%s", name, namespace, port, path)
+}
+
+// Credentials provide the TLS configuration, the HTTP client and the bearer token of
+// the predictor calls. The predictor certificate is verified with a given CA bundle,
+// if any.
+// The bearer token, client certificate and additional CA bundle are read from a
+// given Secret, if any, with a given reader, which is expected to read the Secrets
+// from the API server, so the Secrets of the whole cluster are not cached. The Secret
+// is read on every call, and the TLS configuration and the HTTP client are rebuilt
+// only when the Secret resource version changes, so the rotated credentials are
+// picked up.
+type Credentials struct {
+	sync.Mutex
+	reader          client.Reader
+	secret          *types.NamespacedName
+	caBundle        []byte
+	loaded          bool
+	resourceVersion string
+	tlsConfig       *tls.Config
+	httpClient      *http.Client
+	token           string
+}
+
+// NewCredentials returns the predictor credentials with a given CA bundle, which
+// are loaded from a given Secret, if not nil, with a given reader
+func NewCredentials(reader client.Reader, secret *types.NamespacedName, caBundle []byte) *Credentials {
+	return &Credentials{
+		reader:   reader,
+		secret:   secret,
+		caBundle: caBundle,
+	}
+}
+
+// Load returns the HTTP client and the bearer token, which is empty if not set in
+// the Secret. The HTTP client is reused until the Secret resource version changes.
+func (c *Credentials) Load(ctx context.Context) (*http.Client, string, error) {
+	c.Lock()
+	defer c.Unlock()
+	if err := c.load(ctx); err != nil {
+		return nil, "", err
+	}
+	return c.httpClient, c.token, nil
+}
+
+// LoadTLS returns the TLS configuration and the bearer token, which is empty if not
+// set in the Secret. The same TLS configuration is returned until the Secret resource
+// version changes, so the connections using it can be reused.
+func (c *Credentials) LoadTLS(ctx context.Context) (*tls.Config, string, error) {
+	c.Lock()
+	defer c.Unlock()
+	if err := c.load(ctx); err != nil {
+		return nil, "", err
+	}
+	return c.tlsConfig, c.token, nil
+}
+
+// load reads the Secret, if set, and rebuilds the TLS configuration and the HTTP
+// client when not loaded yet or when the Secret resource version changed
+func (c *Credentials) load(ctx context.Context) error {
+	if c.secret == nil {
+		if !c.loaded {
+			if err := c.build(nil, nil, ""); err != nil {
+				return err
+			}
+			c.loaded = true
+		}
+		return nil
+	}
+	if c.reader == nil {
+		return errors.New("predictor secret reader is not configured")
+	}
+	secret := &corev1.Secret{}
+	if err := c.reader.Get(ctx, *c.secret, secret); err != nil {
+		return fmt.Errorf("failed to get predictor secret %s: %w", c.secret, err)
+	}
+	if c.loaded && secret.ResourceVersion == c.resourceVersion {
+		return nil
+	}
+	if err := c.fromSecret(secret); err != nil {
+		return fmt.Errorf("invalid predictor secret %s: %w", c.secret, err)
+	}
+	c.resourceVersion, c.loaded = secret.ResourceVersion, true
+	return nil
+}
+
+// fromSecret builds the credentials from the data of a given Secret. The Secret
+// should hold a bearer token, a client certificate or both.
+func (c *Credentials) fromSecret(secret *corev1.Secret) error {
+	token := strings.TrimSpace(string(secret.Data[SecretTokenKey]))
+	cert, key := secret.Data[SecretCertKey], secret.Data[SecretKeyKey]
+	if token == "" && len(cert) == 0 && len(key) == 0 {
+		return fmt.Errorf("neither %s nor %s and %s are set", SecretTokenKey, SecretCertKey, SecretKeyKey)
+	}
+	var certificates []tls.Certificate
+	if len(cert) > 0 || len(key) > 0 {
+		certificate, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		certificates = append(certificates, certificate)
+	}
+	return c.build(secret.Data[SecretCAKey], certificates, token)
+}
+
+// build sets the TLS configuration that presents given client certificates and
+// verifies the server certificates with the CA bundle and a given additional CA
+// bundle, or with the system trust roots when none is set. The HTTP client using
+// the configuration replaces the previous one, which idle connections are closed.
+func (c *Credentials) build(secretCABundle []byte, certificates []tls.Certificate, token string) error {
+	tlsConfig := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: certificates,
+	}
+	if len(c.caBundle) > 0 || len(secretCABundle) > 0 {
+		pool := x509.NewCertPool()
+		for _, bundle := range [][]byte{c.caBundle, secretCABundle} {
+			if len(bundle) > 0 && !pool.AppendCertsFromPEM(bundle) {
+				return errors.New("failed to parse CA bundle")
+			}
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	if c.httpClient != nil {
+		c.httpClient.CloseIdleConnections()
+	}
+	c.tlsConfig, c.token = tlsConfig, token
+	c.httpClient = &http.Client{Transport: transport}
+	return nil
+}
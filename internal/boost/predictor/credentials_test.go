@@ -0,0 +1,264 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predictor_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
+	"github.com/google/kube-startup-cpu-boost/internal/metrics"
+	"github.com/google/kube-startup-cpu-boost/internal/mock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Predictor credentials", func() {
+	var (
+		mockCtrl          *gomock.Controller
+		reader            *mock.MockClient
+		secret            *corev1.Secret
+		secretName        *types.NamespacedName
+		withCABundle      bool
+		requireClientCert bool
+		server            *httptest.Server
+		calls             atomic.Int32
+		authorization     atomic.Value
+		clientCommonName  atomic.Value
+		predictorClient   *predictor.Client
+		err               error
+	)
+	BeforeEach(func() {
+		metrics.ClearSystemMetrics()
+		mockCtrl = gomock.NewController(GinkgoT())
+		reader = mock.NewMockClient(mockCtrl)
+		secret = nil
+		secretName = &types.NamespacedName{Namespace: "demo-ns", Name: "predictor-credentials"}
+		withCABundle = false
+		requireClientCert = false
+		calls.Store(0)
+		authorization.Store("")
+		clientCommonName.Store("")
+		reader.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.Secret{})).
+			AnyTimes().
+			DoAndReturn(func(ctx context.Context, key types.NamespacedName, obj client.Object, opts ...client.GetOption) error {
+				if secret == nil || key != *secretName {
+					return apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, key.Name)
+				}
+				secret.DeepCopyInto(obj.(*corev1.Secret))
+				return nil
+			})
+		server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			authorization.Store(r.Header.Get("Authorization"))
+			if len(r.TLS.PeerCertificates) > 0 {
+				clientCommonName.Store(r.TLS.PeerCertificates[0].Subject.CommonName)
+			}
+		}))
+	})
+	JustBeforeEach(func() {
+		if requireClientCert {
+			server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+		}
+		server.StartTLS()
+		var caBundle []byte
+		if withCABundle {
+			caBundle = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		}
+		credentials := predictor.NewCredentials(reader, secretName, caBundle)
+		predictorClient = predictor.NewClientWithCredentials(server.URL, predictor.DefaultOptions(), credentials)
+		err = predictorClient.Post(context.TODO(), predictor.PredictPath, map[string]string{"podName": "demo"}, nil)
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+	When("the Secret is not set", func() {
+		BeforeEach(func() {
+			secretName = nil
+		})
+		When("the CA bundle is set", func() {
+			BeforeEach(func() {
+				withCABundle = true
+			})
+			It("doesn't error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("doesn't send the bearer token", func() {
+				Expect(authorization.Load()).To(BeEmpty())
+			})
+		})
+		When("the CA bundle is not set", func() {
+			It("fails to verify the predictor certificate", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(calls.Load()).To(BeZero())
+			})
+		})
+	})
+	When("the Secret is set", func() {
+		BeforeEach(func() {
+			withCABundle = true
+		})
+		When("the Secret does not exist", func() {
+			It("errors", func() {
+				Expect(err).To(MatchError(ContainSubstring("failed to get predictor secret")))
+			})
+			It("doesn't call the predictor", func() {
+				Expect(calls.Load()).To(BeZero())
+			})
+			It("records the credentials error", func() {
+				Expect(metrics.PredictorErrors(predictor.PredictPath, predictor.ErrorReasonCredentials)).To(Equal(float64(1)))
+			})
+		})
+		When("the Secret has no credentials", func() {
+			BeforeEach(func() {
+				secret = newSecret("1", map[string][]byte{"other": []byte("value")})
+			})
+			It("errors", func() {
+				Expect(err).To(MatchError(ContainSubstring("invalid predictor secret")))
+				Expect(calls.Load()).To(BeZero())
+			})
+		})
+		When("the Secret has the bearer token", func() {
+			BeforeEach(func() {
+				secret = newSecret("1", map[string][]byte{predictor.SecretTokenKey: []byte("s3cr3t\n")})
+			})
+			It("doesn't error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("sends the bearer token", func() {
+				Expect(authorization.Load()).To(Equal("Bearer s3cr3t"))
+			})
+			When("the Secret is updated", func() {
+				JustBeforeEach(func() {
+					secret = newSecret("2", map[string][]byte{predictor.SecretTokenKey: []byte("rotated")})
+					err = predictorClient.Post(context.TODO(), predictor.PredictPath, map[string]string{"podName": "demo"}, nil)
+				})
+				It("sends the new bearer token", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(authorization.Load()).To(Equal("Bearer rotated"))
+				})
+			})
+			When("the Secret resource version did not change", func() {
+				JustBeforeEach(func() {
+					secret = newSecret("1", map[string][]byte{predictor.SecretTokenKey: []byte("rotated")})
+					err = predictorClient.Post(context.TODO(), predictor.PredictPath, map[string]string{"podName": "demo"}, nil)
+				})
+				It("reuses the loaded credentials", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(authorization.Load()).To(Equal("Bearer s3cr3t"))
+				})
+			})
+		})
+		When("the Secret has the client certificate", func() {
+			BeforeEach(func() {
+				requireClientCert = true
+				cert, key := newCertificate("boost-manager", x509.ExtKeyUsageClientAuth)
+				secret = newSecret("1", map[string][]byte{
+					predictor.SecretCertKey: cert,
+					predictor.SecretKeyKey:  key,
+				})
+			})
+			It("doesn't error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("presents the client certificate", func() {
+				Expect(clientCommonName.Load()).To(Equal("boost-manager"))
+			})
+			It("doesn't send the bearer token", func() {
+				Expect(authorization.Load()).To(BeEmpty())
+			})
+		})
+		When("the Secret has the CA bundle", func() {
+			BeforeEach(func() {
+				secret = newSecret("1", map[string][]byte{predictor.SecretTokenKey: []byte("s3cr3t")})
+			})
+			JustBeforeEach(func() {
+				secret.Data[predictor.SecretCAKey] = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE",
+					Bytes: server.Certificate().Raw})
+				secret.ResourceVersion = "2"
+				credentials := predictor.NewCredentials(reader, secretName, nil)
+				predictorClient = predictor.NewClientWithCredentials(server.URL, predictor.DefaultOptions(), credentials)
+				err = predictorClient.Post(context.TODO(), predictor.PredictPath, map[string]string{"podName": "demo"}, nil)
+			})
+			It("verifies the predictor certificate with the CA bundle", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(authorization.Load()).To(Equal("Bearer s3cr3t"))
+			})
+		})
+	})
+})
+
+var _ = Describe("Predictor service endpoint", func() {
+	DescribeTable("returns the endpoint",
+		func(path, expected string) {
+			Expect(predictor.ServiceEndpoint("predictor-ns", "predictor", 8443, path)).To(Equal(expected))
+		},
+		Entry("without path", "", "https://predictor.predictor-ns.svc:8443"),
+		Entry("with path", "/api/v1", "https://predictor.predictor-ns.svc:8443/api/v1"),
+		Entry("with relative path", "api/v1", "https://predictor.predictor-ns.svc:8443/api/v1"),
+	)
+})
+
+func newSecret(resourceVersion string, data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "demo-ns",
+			Name:            "predictor-credentials",
+			ResourceVersion: resourceVersion,
+		},
+		Data: data,
+	}
+}
+
+// newCertificate returns the PEM encoded self-signed certificate and key with a given
+// common name, extended key usage and IP addresses
+func newCertificate(commonName string, usage x509.ExtKeyUsage, ips ...net.IP) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  ips,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
@@ -0,0 +1,382 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predictor
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	predictorv1 "github.com/google/kube-startup-cpu-boost/api/predictor/v1"
+	"github.com/google/kube-startup-cpu-boost/internal/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpccredentials "google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var (
+	connectionsMu sync.Mutex
+	connections   = make(map[EndpointKey]*grpc.ClientConn)
+)
+
+// GRPCClient calls the predictor with the gRPC protocol. As the HTTP client, it limits
+// the calls in time, retries them with backoff and shares the circuit breaker of the
+// endpoint. The reversion notifications are collected and sent in batches over the
+// client stream, so they are delivered asynchronously and at least once.
+type GRPCClient struct {
+	sync.Mutex
+	endpoint     string
+	target       string
+	secure       bool
+	credentials  *Credentials
+	options      Options
+	breaker      *circuitBreaker
+	conn         *grpc.ClientConn
+	connTLS      *tls.Config
+	durationOnly atomic.Bool
+	pending      []*predictorv1.NotifyReversionRequest
+	sending      bool
+	flush        chan struct{}
+}
+
+// NewGRPCClient returns the gRPC predictor client for a given endpoint with given
+// options and credentials, if not nil. The endpoint with the grpcs scheme is called
+// over TLS, and the one with the grpc scheme in plaintext. The connection is made on
+// the first call.
+func NewGRPCClient(endpoint string, options Options, credentials *Credentials) *GRPCClient {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	scheme, target, _ := strings.Cut(endpoint, "://")
+	return &GRPCClient{
+		endpoint:    endpoint,
+		target:      target,
+		secure:      strings.EqualFold(scheme, GRPCSecureScheme),
+		credentials: credentials,
+		options:     options,
+		breaker:     sharedCircuitBreaker(NewEndpointKey(endpoint, options)),
+		flush:       make(chan struct{}, 1),
+	}
+}
+
+// Endpoint returns the predictor endpoint
+func (c *GRPCClient) Endpoint() string {
+	return c.endpoint
+}
+
+// Predict returns the prediction of a given POD request. When the predictor does
+// not implement the Predict method, the POD boost duration is predicted with the
+// PredictDuration method instead.
+func (c *GRPCClient) Predict(ctx context.Context, request *PodRequest) (*PodPrediction, error) {
+	ctx, client, err := c.connect(ctx, PredictPath)
+	if err != nil {
+		return nil, err
+	}
+	protoRequest := toPredictRequest(request)
+	if !c.durationOnly.Load() {
+		var response *predictorv1.PredictResponse
+		err = call(ctx, PredictPath, c.options, c.breaker, func(ctx context.Context) error {
+			response, err = client.Predict(ctx, protoRequest)
+			return err
+		})
+		if status.Code(err) != codes.Unimplemented {
+			if err != nil {
+				return nil, err
+			}
+			return fromPredictResponse(response), nil
+		}
+		c.durationOnly.Store(true)
+	}
+	var response *predictorv1.PredictDurationResponse
+	err = call(ctx, PredictPath, c.options, c.breaker, func(ctx context.Context) error {
+		response, err = client.PredictDuration(ctx, protoRequest)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	prediction := &PodPrediction{}
+	if response.Duration != nil {
+		prediction.Duration = response.Duration.AsDuration().String()
+	}
+	return prediction, nil
+}
+
+// NotifyReversion adds a given notification to the batch sent to the predictor.
+// The batch is sent once the batch interval passes or the batch size is reached.
+func (c *GRPCClient) NotifyReversion(ctx context.Context, request *NotifyRequest) error {
+	c.Lock()
+	c.pending = append(c.pending, toNotifyReversionRequest(request))
+	full := len(c.pending) >= c.batchSize()
+	if !c.sending {
+		c.sending = true
+		go c.sendNotifications()
+	}
+	c.Unlock()
+	if full {
+		select {
+		case c.flush <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// sendNotifications sends the pending notifications in batches until there are no
+// more pending notifications
+func (c *GRPCClient) sendNotifications() {
+	log := ctrl.Log.WithName("predictor").WithValues("endpoint", c.endpoint)
+	for {
+		timer := time.NewTimer(c.options.NotifyBatchInterval)
+		select {
+		case <-timer.C:
+		case <-c.flush:
+			timer.Stop()
+		}
+		c.Lock()
+		batch := c.pending
+		if size := c.batchSize(); len(batch) > size {
+			batch, c.pending = batch[:size], batch[size:]
+		} else {
+			c.pending = nil
+		}
+		if len(batch) == 0 {
+			c.sending = false
+			c.Unlock()
+			return
+		}
+		c.Unlock()
+		if err := c.sendBatch(context.Background(), batch); err != nil {
+			log.Error(err, "failed to notify predictor about pod reversions", "count", len(batch))
+		}
+	}
+}
+
+// sendBatch sends given notifications over the client stream
+func (c *GRPCClient) sendBatch(ctx context.Context, batch []*predictorv1.NotifyReversionRequest) error {
+	ctx, client, err := c.connect(ctx, NotifyPath)
+	if err != nil {
+		return err
+	}
+	return call(ctx, NotifyPath, c.options, c.breaker, func(ctx context.Context) error {
+		stream, err := client.NotifyReversions(ctx)
+		if err != nil {
+			return err
+		}
+		for _, request := range batch {
+			// the stream error is returned when closing the stream
+			if err := stream.Send(request); err != nil {
+				break
+			}
+		}
+		_, err = stream.CloseAndRecv()
+		return err
+	})
+}
+
+func (c *GRPCClient) batchSize() int {
+	if c.options.NotifyBatchSize > 0 {
+		return c.options.NotifyBatchSize
+	}
+	return DefaultNotifyBatchSize
+}
+
+// connect returns the predictor client using the connection for the current
+// credentials and a given context with the bearer token, if set. The connection
+// is replaced when the credentials change. The credentials errors are recorded
+// with a given path.
+func (c *GRPCClient) connect(ctx context.Context, path string) (context.Context, predictorv1.PredictorClient, error) {
+	var tlsConfig *tls.Config
+	if c.credentials != nil {
+		var token string
+		var err error
+		if tlsConfig, token, err = c.credentials.LoadTLS(ctx); err != nil {
+			metrics.AddPredictorError(path, ErrorReasonCredentials)
+			return ctx, nil, err
+		}
+		if token != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+		}
+	}
+	if c.credentials == nil {
+		conn, err := c.sharedConnection()
+		if err != nil {
+			metrics.AddPredictorError(path, ErrorReasonConnection)
+			return ctx, nil, err
+		}
+		return ctx, predictorv1.NewPredictorClient(conn), nil
+	}
+	c.Lock()
+	defer c.Unlock()
+	if c.conn != nil && c.connTLS == tlsConfig {
+		return ctx, predictorv1.NewPredictorClient(c.conn), nil
+	}
+	conn, err := c.newConnection(tlsConfig)
+	if err != nil {
+		metrics.AddPredictorError(path, ErrorReasonConnection)
+		return ctx, nil, err
+	}
+	if old := c.conn; old != nil {
+		// the calls in progress are given time to complete
+		time.AfterFunc(c.options.Timeout, func() { _ = old.Close() })
+	}
+	c.conn, c.connTLS = conn, tlsConfig
+	return ctx, predictorv1.NewPredictorClient(conn), nil
+}
+
+// sharedConnection returns the connection of the predictor endpoint called without
+// credentials, created if it does not exist. The connection is shared by the clients
+// of the endpoint with the same options, as the clients are created again on each boost
+// update, until it is released.
+func (c *GRPCClient) sharedConnection() (*grpc.ClientConn, error) {
+	key := NewEndpointKey(c.endpoint, c.options)
+	connectionsMu.Lock()
+	defer connectionsMu.Unlock()
+	if conn, ok := connections[key]; ok {
+		return conn, nil
+	}
+	conn, err := c.newConnection(nil)
+	if err != nil {
+		return nil, err
+	}
+	connections[key] = conn
+	return conn, nil
+}
+
+// newConnection returns the new connection to the predictor endpoint using a given
+// TLS config, if not nil, for the endpoint with the grpcs scheme
+func (c *GRPCClient) newConnection(tlsConfig *tls.Config) (*grpc.ClientConn, error) {
+	transportCredentials := insecure.NewCredentials()
+	if c.secure {
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		transportCredentials = grpccredentials.NewTLS(tlsConfig)
+	}
+	conn, err := grpc.NewClient(c.target, grpc.WithTransportCredentials(transportCredentials))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create predictor connection: %w", err)
+	}
+	return conn, nil
+}
+
+// toPredictRequest maps the POD request to the protocol message
+func toPredictRequest(request *PodRequest) *predictorv1.PredictRequest {
+	protoRequest := &predictorv1.PredictRequest{
+		BoostName:    request.BoostName,
+		PodName:      request.PodName,
+		PodNamespace: request.PodNamespace,
+		Owner:        toOwner(request.Owner),
+		NodeSelector: request.NodeSelector,
+		Tolerations:  make([]*predictorv1.Toleration, 0, len(request.Tolerations)),
+		Containers:   toContainers(request.Containers),
+	}
+	for _, toleration := range request.Tolerations {
+		protoRequest.Tolerations = append(protoRequest.Tolerations, &predictorv1.Toleration{
+			Key:               toleration.Key,
+			Operator:          string(toleration.Operator),
+			Value:             toleration.Value,
+			Effect:            string(toleration.Effect),
+			TolerationSeconds: toleration.TolerationSeconds,
+		})
+	}
+	return protoRequest
+}
+
+// toNotifyReversionRequest maps the notification to the protocol message. The
+// durations that cannot be parsed are omitted.
+func toNotifyReversionRequest(request *NotifyRequest) *predictorv1.NotifyReversionRequest {
+	return &predictorv1.NotifyReversionRequest{
+		PodName:         request.PodName,
+		PodNamespace:    request.PodNamespace,
+		BoostName:       request.BoostName,
+		Owner:           toOwner(request.Owner),
+		Containers:      toContainers(request.Containers),
+		StartupDuration: toDuration(request.StartupDuration),
+		BoostDuration:   toDuration(request.BoostDuration),
+	}
+}
+
+func toOwner(owner *Owner) *predictorv1.Owner {
+	if owner == nil {
+		return nil
+	}
+	return &predictorv1.Owner{
+		ApiVersion: owner.APIVersion,
+		Kind:       owner.Kind,
+		Name:       owner.Name,
+	}
+}
+
+func toContainers(containers []ContainerRequest) []*predictorv1.Container {
+	protoContainers := make([]*predictorv1.Container, 0, len(containers))
+	for _, container := range containers {
+		protoContainers = append(protoContainers, &predictorv1.Container{
+			Name:        container.Name,
+			Image:       container.Image,
+			ImageDigest: container.ImageDigest,
+			Requests:    toQuantities(container.Resources.Requests),
+			Limits:      toQuantities(container.Resources.Limits),
+		})
+	}
+	return protoContainers
+}
+
+func toDuration(value string) *durationpb.Duration {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return nil
+	}
+	return durationpb.New(duration)
+}
+
+// toQuantities maps a given resource list to the resource quantities by the resource
+// name. It returns nil if the list is empty.
+func toQuantities(resources corev1.ResourceList) map[string]string {
+	if len(resources) == 0 {
+		return nil
+	}
+	quantities := make(map[string]string, len(resources))
+	for name, quantity := range resources {
+		quantities[string(name)] = quantity.String()
+	}
+	return quantities
+}
+
+// fromPredictResponse maps the protocol message to the POD prediction
+func fromPredictResponse(response *predictorv1.PredictResponse) *PodPrediction {
+	prediction := &PodPrediction{
+		Containers: make([]ContainerPrediction, 0, len(response.Containers)),
+	}
+	for _, container := range response.Containers {
+		prediction.Containers = append(prediction.Containers, ContainerPrediction{
+			Name:        container.Name,
+			CPURequests: container.CpuRequests,
+			CPULimits:   container.CpuLimits,
+		})
+	}
+	if response.Duration != nil {
+		prediction.Duration = response.Duration.AsDuration().String()
+	}
+	return prediction
+}
@@ -0,0 +1,330 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predictor_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	predictorv1 "github.com/google/kube-startup-cpu-boost/api/predictor/v1"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
+	"github.com/google/kube-startup-cpu-boost/internal/mock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpccredentials "google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	corev1 "k8s.io/api/core/v1"
+	apiResource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakePredictorServer is the gRPC predictor server that records the requests and
+// responds with the configured predictions or errors
+type fakePredictorServer struct {
+	predictorv1.UnimplementedPredictorServer
+	sync.Mutex
+	predictErrors   []error
+	predictCalls    atomic.Int32
+	request         *predictorv1.PredictRequest
+	authorization   string
+	unimplemented   bool
+	durationCalls   atomic.Int32
+	batches         [][]*predictorv1.NotifyReversionRequest
+	notifyReceived  atomic.Int32
+	predictResponse *predictorv1.PredictResponse
+}
+
+func (s *fakePredictorServer) Predict(ctx context.Context, request *predictorv1.PredictRequest) (*predictorv1.PredictResponse, error) {
+	s.predictCalls.Add(1)
+	s.Lock()
+	defer s.Unlock()
+	if s.unimplemented {
+		return nil, status.Error(codes.Unimplemented, "not implemented")
+	}
+	s.request = request
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("authorization")) > 0 {
+		s.authorization = md.Get("authorization")[0]
+	}
+	if len(s.predictErrors) > 0 {
+		err := s.predictErrors[0]
+		s.predictErrors = s.predictErrors[1:]
+		return nil, err
+	}
+	return s.predictResponse, nil
+}
+
+func (s *fakePredictorServer) PredictDuration(ctx context.Context, request *predictorv1.PredictRequest) (*predictorv1.PredictDurationResponse, error) {
+	s.durationCalls.Add(1)
+	return &predictorv1.PredictDurationResponse{Duration: durationpb.New(30 * time.Second)}, nil
+}
+
+func (s *fakePredictorServer) NotifyReversions(stream predictorv1.Predictor_NotifyReversionsServer) error {
+	var batch []*predictorv1.NotifyReversionRequest
+	for {
+		request, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		batch = append(batch, request)
+	}
+	s.Lock()
+	s.batches = append(s.batches, batch)
+	s.Unlock()
+	s.notifyReceived.Add(int32(len(batch)))
+	return stream.SendAndClose(&predictorv1.NotifyReversionsResponse{Count: int32(len(batch))})
+}
+
+func (s *fakePredictorServer) Batches() [][]*predictorv1.NotifyReversionRequest {
+	s.Lock()
+	defer s.Unlock()
+	return s.batches
+}
+
+var _ = Describe("Predictor gRPC client", func() {
+	var (
+		fakeServer  *fakePredictorServer
+		grpcServer  *grpc.Server
+		serverOpts  []grpc.ServerOption
+		endpoint    string
+		options     predictor.Options
+		credentials *predictor.Credentials
+		client      *predictor.GRPCClient
+		request     *predictor.PodRequest
+		prediction  *predictor.PodPrediction
+		err         error
+	)
+	BeforeEach(func() {
+		fakeServer = &fakePredictorServer{
+			predictResponse: &predictorv1.PredictResponse{
+				Containers: []*predictorv1.ContainerPrediction{
+					{Name: "container-one", CpuRequests: "2", CpuLimits: "3"},
+				},
+				Duration: durationpb.New(45 * time.Second),
+			},
+		}
+		serverOpts = nil
+		credentials = nil
+		options = predictor.DefaultOptions()
+		options.Backoff = time.Millisecond
+		options.NotifyBatchInterval = 10 * time.Millisecond
+		tolerationSeconds := int64(300)
+		request = &predictor.PodRequest{
+			SchemaVersion: predictor.PodRequestSchemaVersion,
+			BoostName:     "boost-001",
+			PodName:       "demo-",
+			PodNamespace:  "demo-ns",
+			Owner:         &predictor.Owner{APIVersion: "apps/v1", Kind: "Deployment", Name: "demo"},
+			NodeSelector:  map[string]string{"pool": "default"},
+			Tolerations: []corev1.Toleration{{
+				Key:               "dedicated",
+				Operator:          corev1.TolerationOpEqual,
+				Value:             "boost",
+				Effect:            corev1.TaintEffectNoExecute,
+				TolerationSeconds: &tolerationSeconds,
+			}},
+			Containers: []predictor.ContainerRequest{{
+				Name:        "container-one",
+				Image:       "example.com/demo@sha256:4a1c",
+				ImageDigest: "sha256:4a1c",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: apiResource.MustParse("500m")},
+					Limits:   corev1.ResourceList{corev1.ResourceCPU: apiResource.MustParse("1")},
+				},
+			}},
+		}
+	})
+	JustBeforeEach(func() {
+		listener, listenErr := net.Listen("tcp", "127.0.0.1:0")
+		Expect(listenErr).NotTo(HaveOccurred())
+		grpcServer = grpc.NewServer(serverOpts...)
+		predictorv1.RegisterPredictorServer(grpcServer, fakeServer)
+		go func() {
+			_ = grpcServer.Serve(listener)
+		}()
+		scheme := predictor.GRPCScheme
+		if serverOpts != nil {
+			scheme = predictor.GRPCSecureScheme
+		}
+		endpoint = scheme + "://" + listener.Addr().String()
+		client = predictor.NewGRPCClient(endpoint, options, credentials)
+		prediction, err = client.Predict(context.TODO(), request)
+	})
+	AfterEach(func() {
+		grpcServer.Stop()
+	})
+	It("doesn't error", func() {
+		Expect(err).NotTo(HaveOccurred())
+	})
+	It("returns the prediction", func() {
+		Expect(prediction).To(Equal(&predictor.PodPrediction{
+			Containers: []predictor.ContainerPrediction{
+				{Name: "container-one", CPURequests: "2", CPULimits: "3"},
+			},
+			Duration: "45s",
+		}))
+	})
+	It("sends the request", func() {
+		sent := fakeServer.request
+		Expect(sent.BoostName).To(Equal("boost-001"))
+		Expect(sent.PodName).To(Equal("demo-"))
+		Expect(sent.PodNamespace).To(Equal("demo-ns"))
+		Expect(sent.Owner.Kind).To(Equal("Deployment"))
+		Expect(sent.Owner.Name).To(Equal("demo"))
+		Expect(sent.NodeSelector).To(HaveKeyWithValue("pool", "default"))
+		Expect(sent.Tolerations).To(HaveLen(1))
+		Expect(sent.Tolerations[0].Operator).To(Equal("Equal"))
+		Expect(sent.Tolerations[0].TolerationSeconds).To(HaveValue(Equal(int64(300))))
+		Expect(sent.Containers).To(HaveLen(1))
+		Expect(sent.Containers[0].ImageDigest).To(Equal("sha256:4a1c"))
+		Expect(sent.Containers[0].Requests).To(HaveKeyWithValue("cpu", "500m"))
+		Expect(sent.Containers[0].Limits).To(HaveKeyWithValue("cpu", "1"))
+	})
+	It("returns the endpoint", func() {
+		Expect(client.Endpoint()).To(Equal(endpoint))
+	})
+	When("the predictor is temporarily unavailable", func() {
+		BeforeEach(func() {
+			fakeServer.predictErrors = []error{status.Error(codes.Unavailable, "unavailable")}
+		})
+		It("retries the call", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeServer.predictCalls.Load()).To(Equal(int32(2)))
+		})
+	})
+	When("the predictor refuses the request", func() {
+		BeforeEach(func() {
+			fakeServer.predictErrors = []error{status.Error(codes.InvalidArgument, "invalid")}
+		})
+		It("errors without retries", func() {
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+			Expect(fakeServer.predictCalls.Load()).To(Equal(int32(1)))
+		})
+	})
+	When("the predictor predicts the duration only", func() {
+		BeforeEach(func() {
+			fakeServer.unimplemented = true
+		})
+		It("returns the predicted duration", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(prediction.Containers).To(BeEmpty())
+			Expect(prediction.Duration).To(Equal("30s"))
+		})
+		It("calls the duration prediction only on subsequent predictions", func() {
+			_, err = client.Predict(context.TODO(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeServer.predictCalls.Load()).To(Equal(int32(1)))
+			Expect(fakeServer.durationCalls.Load()).To(Equal(int32(2)))
+		})
+	})
+	When("the reversions are notified", func() {
+		JustBeforeEach(func() {
+			for _, name := range []string{"demo-1", "demo-2", "demo-3"} {
+				Expect(client.NotifyReversion(context.TODO(), &predictor.NotifyRequest{
+					PodName:         name,
+					PodNamespace:    "demo-ns",
+					Owner:           request.Owner,
+					Containers:      request.Containers,
+					StartupDuration: "45s",
+					BoostDuration:   "1m",
+				})).To(Succeed())
+			}
+		})
+		It("sends the notifications in a batch", func() {
+			Eventually(fakeServer.notifyReceived.Load).Should(Equal(int32(3)))
+			Expect(fakeServer.Batches()).To(HaveLen(1))
+			Expect(fakeServer.Batches()[0][2].PodName).To(Equal("demo-3"))
+		})
+		It("sends the POD boost outcome", func() {
+			Eventually(fakeServer.notifyReceived.Load).Should(Equal(int32(3)))
+			sent := fakeServer.Batches()[0][0]
+			Expect(sent.Owner.Name).To(Equal("demo"))
+			Expect(sent.Containers).To(HaveLen(1))
+			Expect(sent.Containers[0].Requests).To(HaveKeyWithValue("cpu", "500m"))
+			Expect(sent.StartupDuration.AsDuration()).To(Equal(45 * time.Second))
+			Expect(sent.BoostDuration.AsDuration()).To(Equal(time.Minute))
+		})
+		When("the batch size is reached", func() {
+			BeforeEach(func() {
+				options.NotifyBatchSize = 2
+				options.NotifyBatchInterval = time.Hour
+			})
+			It("sends the full batch without waiting", func() {
+				Eventually(fakeServer.notifyReceived.Load).Should(Equal(int32(2)))
+				Expect(fakeServer.Batches()[0]).To(HaveLen(2))
+			})
+		})
+	})
+	When("the predictor is called over TLS with the bearer token", func() {
+		var reader *mock.MockClient
+		BeforeEach(func() {
+			cert, key := newCertificate("predictor", x509.ExtKeyUsageServerAuth, net.ParseIP("127.0.0.1"))
+			certificate, certErr := tls.X509KeyPair(cert, key)
+			Expect(certErr).NotTo(HaveOccurred())
+			serverOpts = []grpc.ServerOption{grpc.Creds(grpccredentials.NewServerTLSFromCert(&certificate))}
+			reader = mock.NewMockClient(gomock.NewController(GinkgoT()))
+			secret := newSecret("1", map[string][]byte{predictor.SecretTokenKey: []byte("s3cr3t")})
+			reader.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.Secret{})).
+				AnyTimes().
+				DoAndReturn(func(ctx context.Context, key types.NamespacedName, obj ctrlclient.Object, opts ...ctrlclient.GetOption) error {
+					secret.DeepCopyInto(obj.(*corev1.Secret))
+					return nil
+				})
+			credentials = predictor.NewCredentials(reader,
+				&types.NamespacedName{Namespace: "demo-ns", Name: "predictor-credentials"}, cert)
+		})
+		It("doesn't error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("sends the bearer token", func() {
+			Expect(fakeServer.authorization).To(Equal("Bearer s3cr3t"))
+		})
+	})
+})
+
+var _ = Describe("Predictor", func() {
+	DescribeTable("selects the protocol by the endpoint scheme",
+		func(endpoint string, grpcEndpoint bool) {
+			Expect(predictor.IsGRPCEndpoint(endpoint)).To(Equal(grpcEndpoint))
+			p := predictor.New(endpoint, predictor.DefaultOptions(), nil)
+			if grpcEndpoint {
+				Expect(p).To(BeAssignableToTypeOf(&predictor.GRPCClient{}))
+			} else {
+				Expect(p).To(BeAssignableToTypeOf(&predictor.Client{}))
+			}
+			Expect(p.Endpoint()).To(Equal(endpoint))
+		},
+		Entry("http", "http://predictor.local", false),
+		Entry("https", "https://predictor.local", false),
+		Entry("grpc", "grpc://predictor.local:9090", true),
+		Entry("grpcs", "grpcs://predictor.local:9443", true),
+		Entry("GRPC in upper case", "GRPC://predictor.local:9090", true),
+	)
+})
@@ -0,0 +1,117 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predictor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Owner is the top-level controller of the POD, i.e. Deployment or StatefulSet,
+// that identifies the POD workload
+type Owner struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+}
+
+// OwnerResolver resolves the top-level owners of the PODs. The intermediate owners,
+// i.e. ReplicaSets, are read with a given reader, which is expected to be backed by
+// the informer cache, so the resolution does not call the API server.
+type OwnerResolver struct {
+	reader client.Reader
+}
+
+// NewOwnerResolver returns the POD owner resolver that reads the intermediate owners
+// with a given reader
+func NewOwnerResolver(reader client.Reader) *OwnerResolver {
+	return &OwnerResolver{
+		reader: reader,
+	}
+}
+
+// Resolve returns the top-level owner of a given POD. The ReplicaSet owner reference
+// is followed to its Deployment, if any. It returns nil if the POD has no controller.
+// When the ReplicaSet cannot be read, it returns the ReplicaSet with the error.
+func (r *OwnerResolver) Resolve(ctx context.Context, pod *corev1.Pod) (*Owner, error) {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return nil, nil
+	}
+	owner := ownerFromReference(ref)
+	if !isReplicaSet(ref) {
+		return owner, nil
+	}
+	replicaSet := &appsv1.ReplicaSet{}
+	key := types.NamespacedName{Namespace: pod.Namespace, Name: ref.Name}
+	if err := r.reader.Get(ctx, key, replicaSet); err != nil {
+		return owner, err
+	}
+	if rsRef := metav1.GetControllerOf(replicaSet); rsRef != nil {
+		return ownerFromReference(rsRef), nil
+	}
+	return owner, nil
+}
+
+// WorkloadKey returns the key of the workload of a given POD, i.e. the POD top-level
+// owner, if known, and the images of all the POD containers. The key identifies the
+// previous startups of the same workload and is a valid ConfigMap key.
+func WorkloadKey(owner *Owner, pod *corev1.Pod) string {
+	images := ImagesHash(pod)
+	if owner == nil {
+		return images
+	}
+	key := strings.ToLower(owner.Kind) + "." + owner.Name + "." + images
+	if len(validation.IsConfigMapKey(key)) > 0 {
+		return images
+	}
+	return key
+}
+
+// ImagesHash returns the hash of the images of all the containers of a given POD
+func ImagesHash(pod *corev1.Pod) string {
+	hash := sha256.New()
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range containers {
+			hash.Write([]byte(container.Image))
+			hash.Write([]byte{0})
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// isReplicaSet returns true if a given owner reference points to the ReplicaSet
+func isReplicaSet(ref *metav1.OwnerReference) bool {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	return err == nil && gv.Group == appsv1.GroupName && ref.Kind == "ReplicaSet"
+}
+
+func ownerFromReference(ref *metav1.OwnerReference) *Owner {
+	return &Owner{
+		APIVersion: ref.APIVersion,
+		Kind:       ref.Kind,
+		Name:       ref.Name,
+	}
+}
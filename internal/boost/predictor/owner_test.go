@@ -0,0 +1,150 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predictor_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
+	"github.com/google/kube-startup-cpu-boost/internal/mock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("POD owner resolver", func() {
+	var (
+		mockCtrl     *gomock.Controller
+		reader       *mock.MockClient
+		pod          *corev1.Pod
+		isController bool
+		owner        *predictor.Owner
+		err          error
+	)
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		reader = mock.NewMockClient(mockCtrl)
+		isController = true
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "demo-5d8f-",
+				Namespace:    "demo-ns",
+			},
+		}
+	})
+	JustBeforeEach(func() {
+		owner, err = predictor.NewOwnerResolver(reader).Resolve(context.TODO(), pod)
+	})
+	When("the POD has no controller", func() {
+		It("returns nil owner", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(owner).To(BeNil())
+		})
+	})
+	When("the POD is controlled by the StatefulSet", func() {
+		BeforeEach(func() {
+			pod.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "StatefulSet",
+				Name:       "demo",
+				Controller: &isController,
+			}}
+		})
+		It("returns the StatefulSet", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(owner).To(Equal(&predictor.Owner{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "demo"}))
+		})
+	})
+	When("the POD is controlled by the ReplicaSet", func() {
+		var getCall *gomock.Call
+		BeforeEach(func() {
+			pod.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "ReplicaSet",
+				Name:       "demo-5d8f",
+				Controller: &isController,
+			}}
+			getCall = reader.EXPECT().Get(gomock.Any(),
+				gomock.Eq(types.NamespacedName{Namespace: "demo-ns", Name: "demo-5d8f"}),
+				gomock.AssignableToTypeOf(&appsv1.ReplicaSet{})).Times(1)
+		})
+		When("the ReplicaSet is controlled by the Deployment", func() {
+			BeforeEach(func() {
+				getCall.DoAndReturn(func(ctx context.Context, key types.NamespacedName, obj client.Object, opts ...client.GetOption) error {
+					obj.SetOwnerReferences([]metav1.OwnerReference{{
+						APIVersion: "apps/v1",
+						Kind:       "Deployment",
+						Name:       "demo",
+						Controller: &isController,
+					}})
+					return nil
+				})
+			})
+			It("returns the Deployment", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(owner).To(Equal(&predictor.Owner{APIVersion: "apps/v1", Kind: "Deployment", Name: "demo"}))
+			})
+		})
+		When("the ReplicaSet has no controller", func() {
+			BeforeEach(func() {
+				getCall.Return(nil)
+			})
+			It("returns the ReplicaSet", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(owner).To(Equal(&predictor.Owner{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "demo-5d8f"}))
+			})
+		})
+		When("the ReplicaSet cannot be read", func() {
+			BeforeEach(func() {
+				getCall.Return(errors.New("not found"))
+			})
+			It("returns the ReplicaSet with error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(owner).To(Equal(&predictor.Owner{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "demo-5d8f"}))
+			})
+		})
+	})
+})
+
+var _ = Describe("POD workload key", func() {
+	var keyPod *corev1.Pod
+	BeforeEach(func() {
+		keyPod = &corev1.Pod{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "main", Image: "registry.example.com/demo:1.0"}},
+			},
+		}
+	})
+	It("identifies the owner and the images", func() {
+		key := predictor.WorkloadKey(&predictor.Owner{Kind: "Deployment", Name: "demo"}, keyPod)
+		Expect(key).To(HavePrefix("deployment.demo."))
+		Expect(validation.IsConfigMapKey(key)).To(BeEmpty())
+		keyPod.Spec.Containers[0].Image = "registry.example.com/demo:1.1"
+		Expect(predictor.WorkloadKey(&predictor.Owner{Kind: "Deployment", Name: "demo"}, keyPod)).
+			NotTo(Equal(key))
+	})
+	It("identifies the images when the owner is not known", func() {
+		key := predictor.WorkloadKey(nil, keyPod)
+		Expect(key).To(HaveLen(16))
+		Expect(validation.IsConfigMapKey(key)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,261 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predictor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// PredictPath is the path of the predictor endpoint that predicts the boost
+	// of a POD
+	PredictPath = "/predict"
+	// NotifyPath is the path of the predictor endpoint that is notified about the
+	// POD boost end
+	NotifyPath = "/notify"
+	// PodRequestSchemaVersion is the version of the POD prediction request schema
+	PodRequestSchemaVersion = "v1"
+	// NotifyRequestSchemaVersion is the version of the POD boost end notification schema
+	NotifyRequestSchemaVersion = "v1"
+)
+
+// ErrNoDuration is returned when the prediction has no boost duration
+var ErrNoDuration = errors.New("prediction has no duration")
+
+// PodRequest is the prediction request of a POD. It identifies the POD workload
+// with the top-level owner and carries the scheduling constraints and all POD
+// containers with their resources before the boost.
+type PodRequest struct {
+	SchemaVersion string              `json:"schemaVersion"`
+	BoostName     string              `json:"boostName,omitempty"`
+	PodName       string              `json:"podName"`
+	PodNamespace  string              `json:"podNamespace"`
+	Owner         *Owner              `json:"owner,omitempty"`
+	NodeSelector  map[string]string   `json:"nodeSelector,omitempty"`
+	Tolerations   []corev1.Toleration `json:"tolerations,omitempty"`
+	Containers    []ContainerRequest  `json:"containers"`
+}
+
+// ContainerRequest is the container of the POD prediction request. The image digest
+// is set only if the image is referenced by the digest.
+type ContainerRequest struct {
+	Name        string                      `json:"name"`
+	Image       string                      `json:"image"`
+	ImageDigest string                      `json:"imageDigest,omitempty"`
+	Resources   corev1.ResourceRequirements `json:"resources"`
+}
+
+// PodPrediction is the prediction of the POD boost. It carries the CPU targets of
+// the POD containers and, optionally, the boost duration.
+type PodPrediction struct {
+	Containers []ContainerPrediction `json:"containers"`
+	Duration   string                `json:"duration,omitempty"`
+}
+
+// ContainerPrediction is the CPU target of the POD container
+type ContainerPrediction struct {
+	Name        string `json:"name"`
+	CPURequests string `json:"cpuRequests"`
+	CPULimits   string `json:"cpuLimits"`
+}
+
+// NotifyRequest is the request that notifies the predictor about the POD boost end
+type NotifyRequest struct {
+	SchemaVersion string             `json:"schemaVersion"`
+	PodName       string             `json:"podName"`
+	PodNamespace  string             `json:"podNamespace"`
+	BoostName     string             `json:"boostName,omitempty"`
+	Owner         *Owner             `json:"owner,omitempty"`
+	Containers    []ContainerRequest `json:"containers,omitempty"`
+	// StartupDuration is the time from the POD creation until the POD is ready. It
+	// is empty when the POD is not ready when reverted.
+	StartupDuration string `json:"startupDuration,omitempty"`
+	// BoostDuration is the time from the POD boost until the reversion
+	BoostDuration string `json:"boostDuration,omitempty"`
+}
+
+// NewPodRequest returns the prediction request of a given POD boosted by a boost with
+// a given name and owned by a given top-level owner. The POD name may not be generated
+// yet when the POD is admitted, so the generate name is used in such case.
+func NewPodRequest(pod *corev1.Pod, boostName string, owner *Owner) PodRequest {
+	podName := pod.Name
+	if podName == "" {
+		podName = pod.GenerateName
+	}
+	request := PodRequest{
+		SchemaVersion: PodRequestSchemaVersion,
+		BoostName:     boostName,
+		PodName:       podName,
+		PodNamespace:  pod.Namespace,
+		Owner:         owner,
+		NodeSelector:  pod.Spec.NodeSelector,
+		Tolerations:   pod.Spec.Tolerations,
+		Containers:    make([]ContainerRequest, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers)),
+	}
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for i := range containers {
+			request.Containers = append(request.Containers, ContainerRequest{
+				Name:        containers[i].Name,
+				Image:       containers[i].Image,
+				ImageDigest: imageDigest(containers[i].Image),
+				Resources:   *containers[i].Resources.DeepCopy(),
+			})
+		}
+	}
+	return request
+}
+
+// NewNotifyRequest returns the notification about the resources of a given POD
+// boosted at a given boost time and reverted at a given revert time. The POD is
+// boosted by a boost with a given name and owned by a given top-level owner. The
+// containers carry the boosted resources, so the notification is expected to be
+// created before the POD resources are reverted.
+func NewNotifyRequest(pod *corev1.Pod, boostName string, owner *Owner, boostTime, revertTime time.Time) NotifyRequest {
+	request := NotifyRequest{
+		SchemaVersion: NotifyRequestSchemaVersion,
+		PodName:       pod.Name,
+		PodNamespace:  pod.Namespace,
+		BoostName:     boostName,
+		Owner:         owner,
+		Containers:    NewPodRequest(pod, boostName, owner).Containers,
+		BoostDuration: revertTime.Sub(boostTime).String(),
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+			request.StartupDuration = condition.LastTransitionTime.Sub(pod.CreationTimestamp.Time).String()
+		}
+	}
+	return request
+}
+
+// imageDigest returns the digest of a given image reference or empty string if the
+// image is not referenced by the digest
+func imageDigest(image string) string {
+	if i := strings.LastIndex(image, "@"); i >= 0 {
+		return image[i+1:]
+	}
+	return ""
+}
+
+// Container returns the prediction of a container with a given name
+func (p *PodPrediction) Container(name string) (ContainerPrediction, bool) {
+	for _, container := range p.Containers {
+		if container.Name == name {
+			return container, true
+		}
+	}
+	return ContainerPrediction{}, false
+}
+
+// GetDuration returns the predicted boost duration or ErrNoDuration if the
+// prediction has no duration
+func (p *PodPrediction) GetDuration() (time.Duration, error) {
+	if p.Duration == "" {
+		return 0, ErrNoDuration
+	}
+	return time.ParseDuration(p.Duration)
+}
+
+// PodPredictions makes the prediction request of a POD, at most once per predictor
+// endpoint, and keeps the results, so the policies of all POD containers share
+// a single prediction. The request is created on the first prediction, so the POD
+// owner is resolved only when a predictor is used.
+type PodPredictions struct {
+	sync.Mutex
+	pod       *corev1.Pod
+	boostName string
+	owners    *OwnerResolver
+	owner     *Owner
+	resolved  bool
+	request   *PodRequest
+	results   map[string]podPredictionResult
+}
+
+type podPredictionResult struct {
+	prediction *PodPrediction
+	err        error
+}
+
+// NewPodPredictions returns the predictions of a given POD boosted by a boost with
+// a given name. The POD is copied, so the request carries the POD resources before
+// the boost. The POD owner is resolved with a given resolver, unless nil.
+func NewPodPredictions(pod *corev1.Pod, boostName string, owners *OwnerResolver) *PodPredictions {
+	return &PodPredictions{
+		pod:       pod.DeepCopy(),
+		boostName: boostName,
+		owners:    owners,
+		results:   make(map[string]podPredictionResult),
+	}
+}
+
+// Predict returns the POD prediction from the endpoint of a given predictor. The
+// predictor is called only on the first request for the endpoint, the subsequent
+// requests return the same prediction or error.
+func (p *PodPredictions) Predict(ctx context.Context, client Predictor) (*PodPrediction, error) {
+	p.Lock()
+	defer p.Unlock()
+	if result, ok := p.results[client.Endpoint()]; ok {
+		return result.prediction, result.err
+	}
+	if p.request == nil {
+		p.request = p.newRequest(ctx)
+	}
+	var result podPredictionResult
+	result.prediction, result.err = client.Predict(ctx, p.request)
+	p.results[client.Endpoint()] = result
+	return result.prediction, result.err
+}
+
+// Owner returns the top-level owner of the POD. The owner is resolved only on the
+// first call and shared with the prediction request.
+func (p *PodPredictions) Owner(ctx context.Context) *Owner {
+	p.Lock()
+	defer p.Unlock()
+	return p.resolveOwner(ctx)
+}
+
+// WorkloadKey returns the key of the POD workload, i.e. the POD top-level owner and
+// the images of the POD containers
+func (p *PodPredictions) WorkloadKey(ctx context.Context) string {
+	return WorkloadKey(p.Owner(ctx), p.pod)
+}
+
+// newRequest returns the prediction request of the POD
+func (p *PodPredictions) newRequest(ctx context.Context) *PodRequest {
+	request := NewPodRequest(p.pod, p.boostName, p.resolveOwner(ctx))
+	return &request
+}
+
+// resolveOwner returns the top-level owner of the POD, resolving it on the first
+// call. When the POD owner cannot be resolved, it returns the owner known so far,
+// if any.
+func (p *PodPredictions) resolveOwner(ctx context.Context) *Owner {
+	if p.resolved || p.owners == nil {
+		return p.owner
+	}
+	p.resolved = true
+	var err error
+	if p.owner, err = p.owners.Resolve(ctx, p.pod); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to resolve pod owner")
+	}
+	return p.owner
+}
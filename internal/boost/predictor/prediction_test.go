@@ -0,0 +1,230 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predictor_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apiResource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("POD predictions", func() {
+	var (
+		pod *corev1.Pod
+	)
+	BeforeEach(func() {
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "demo-",
+				Namespace:    "demo-ns",
+			},
+			Spec: corev1.PodSpec{
+				NodeSelector: map[string]string{"pool": "demo"},
+				Tolerations: []corev1.Toleration{{
+					Key:      "dedicated",
+					Operator: corev1.TolerationOpExists,
+				}},
+				InitContainers: []corev1.Container{{Name: "init", Image: "busybox:1.36"}},
+				Containers: []corev1.Container{{
+					Name:  "main",
+					Image: "registry.example.com/demo@sha256:0123abcd",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: apiResource.MustParse("500m")},
+					},
+				}},
+			},
+		}
+	})
+	Describe("Creates the POD request", func() {
+		var request predictor.PodRequest
+		JustBeforeEach(func() {
+			request = predictor.NewPodRequest(pod, "boost-one", &predictor.Owner{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "demo",
+			})
+		})
+		It("has schema version", func() {
+			Expect(request.SchemaVersion).To(Equal(predictor.PodRequestSchemaVersion))
+		})
+		It("identifies the boost and the workload", func() {
+			Expect(request.BoostName).To(Equal("boost-one"))
+			Expect(request.Owner).NotTo(BeNil())
+			Expect(request.Owner.Kind).To(Equal("Deployment"))
+			Expect(request.Owner.Name).To(Equal("demo"))
+		})
+		It("carries the scheduling constraints", func() {
+			Expect(request.NodeSelector).To(HaveKeyWithValue("pool", "demo"))
+			Expect(request.Tolerations).To(HaveLen(1))
+		})
+		It("carries the container images with digests", func() {
+			Expect(request.Containers[0].Image).To(Equal("busybox:1.36"))
+			Expect(request.Containers[0].ImageDigest).To(BeEmpty())
+			Expect(request.Containers[1].ImageDigest).To(Equal("sha256:0123abcd"))
+		})
+		It("uses the generate name when the POD name is not set", func() {
+			Expect(request.PodName).To(Equal("demo-"))
+			Expect(request.PodNamespace).To(Equal("demo-ns"))
+		})
+		It("carries all containers with their resources", func() {
+			Expect(request.Containers).To(HaveLen(2))
+			Expect(request.Containers[0].Name).To(Equal("init"))
+			Expect(request.Containers[1].Name).To(Equal("main"))
+			Expect(request.Containers[1].Resources.Requests.Cpu().String()).To(Equal("500m"))
+		})
+		It("is not affected by the POD changes", func() {
+			pod.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = apiResource.MustParse("2")
+			Expect(request.Containers[1].Resources.Requests.Cpu().String()).To(Equal("500m"))
+		})
+	})
+	Describe("Creates the notification", func() {
+		var (
+			creationTime time.Time
+			boostTime    time.Time
+			notification predictor.NotifyRequest
+		)
+		BeforeEach(func() {
+			creationTime = time.Now().Add(-2 * time.Minute)
+			boostTime = creationTime.Add(time.Second)
+			pod.Name = "demo-7f6d"
+			pod.CreationTimestamp = metav1.NewTime(creationTime)
+		})
+		JustBeforeEach(func() {
+			notification = predictor.NewNotifyRequest(pod, "boost-one", &predictor.Owner{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "demo",
+			}, boostTime, boostTime.Add(90*time.Second))
+		})
+		It("has the schema version", func() {
+			Expect(notification.SchemaVersion).To(Equal(predictor.NotifyRequestSchemaVersion))
+		})
+		It("identifies the POD, the boost and the workload", func() {
+			Expect(notification.PodName).To(Equal("demo-7f6d"))
+			Expect(notification.PodNamespace).To(Equal("demo-ns"))
+			Expect(notification.BoostName).To(Equal("boost-one"))
+			Expect(notification.Owner.Name).To(Equal("demo"))
+		})
+		It("carries all containers with their resources", func() {
+			Expect(notification.Containers).To(HaveLen(2))
+			Expect(notification.Containers[1].Resources.Requests.Cpu().String()).To(Equal("500m"))
+		})
+		It("has the boost duration", func() {
+			Expect(notification.BoostDuration).To(Equal("1m30s"))
+		})
+		It("has no startup duration", func() {
+			Expect(notification.StartupDuration).To(BeEmpty())
+		})
+		When("the POD is ready", func() {
+			BeforeEach(func() {
+				pod.Status.Conditions = []corev1.PodCondition{{
+					Type:               corev1.PodReady,
+					Status:             corev1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(creationTime.Add(45 * time.Second)),
+				}}
+			})
+			It("has the startup duration", func() {
+				Expect(notification.StartupDuration).To(Equal("45s"))
+			})
+		})
+	})
+	Describe("Predicts the POD boost", func() {
+		var (
+			server      *httptest.Server
+			calls       atomic.Int32
+			status      int
+			predictions *predictor.PodPredictions
+			client      *predictor.Client
+			prediction  *predictor.PodPrediction
+			err         error
+		)
+		BeforeEach(func() {
+			calls.Store(0)
+			status = http.StatusOK
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				Expect(r.URL.Path).To(Equal(predictor.PredictPath))
+				if status != http.StatusOK {
+					w.WriteHeader(status)
+					return
+				}
+				var request predictor.PodRequest
+				Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(predictor.PodPrediction{
+					Containers: []predictor.ContainerPrediction{{
+						Name:        request.Containers[1].Name,
+						CPURequests: "2",
+						CPULimits:   "3",
+					}},
+					Duration: "45s",
+				})
+			}))
+		})
+		JustBeforeEach(func() {
+			options := predictor.DefaultOptions()
+			options.MaxRetries = 0
+			client = predictor.NewClient(server.URL, options)
+			predictions = predictor.NewPodPredictions(pod, "boost-one", nil)
+			prediction, err = predictions.Predict(context.TODO(), client)
+		})
+		AfterEach(func() {
+			server.Close()
+		})
+		It("returns the container predictions", func() {
+			Expect(err).NotTo(HaveOccurred())
+			container, ok := prediction.Container("main")
+			Expect(ok).To(BeTrue())
+			Expect(container.CPURequests).To(Equal("2"))
+			Expect(container.CPULimits).To(Equal("3"))
+			_, ok = prediction.Container("init")
+			Expect(ok).To(BeFalse())
+		})
+		It("returns the duration", func() {
+			Expect(prediction.GetDuration()).To(Equal(45 * time.Second))
+		})
+		It("calls the predictor once per endpoint", func() {
+			again, err := predictions.Predict(context.TODO(), client)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(again).To(BeIdenticalTo(prediction))
+			Expect(calls.Load()).To(Equal(int32(1)))
+		})
+		When("the predictor fails", func() {
+			BeforeEach(func() {
+				status = http.StatusBadRequest
+			})
+			It("returns the same error without calling the predictor again", func() {
+				Expect(err).To(HaveOccurred())
+				_, againErr := predictions.Predict(context.TODO(), client)
+				Expect(againErr).To(Equal(err))
+				Expect(calls.Load()).To(Equal(int32(1)))
+			})
+		})
+	})
+	It("returns error when the prediction has no duration", func() {
+		_, err := (&predictor.PodPrediction{}).GetDuration()
+		Expect(err).To(MatchError(predictor.ErrNoDuration))
+	})
+})
@@ -0,0 +1,64 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predictor
+
+import (
+	"context"
+	"strings"
+)
+
+const (
+	// GRPCScheme is the scheme of the predictor endpoints called with the gRPC
+	// protocol in plaintext
+	GRPCScheme = "grpc"
+	// GRPCSecureScheme is the scheme of the predictor endpoints called with the
+	// gRPC protocol over TLS
+	GRPCSecureScheme = "grpcs"
+)
+
+// Predictor predicts the resource boost of the PODs and learns from the boost
+// outcome. It is implemented by the JSON over HTTP and gRPC predictor clients.
+type Predictor interface {
+	// Endpoint returns the predictor endpoint
+	Endpoint() string
+	// Predict returns the prediction of a given POD request
+	Predict(ctx context.Context, request *PodRequest) (*PodPrediction, error)
+	// NotifyReversion notifies the predictor that the resources of a POD were reverted
+	NotifyReversion(ctx context.Context, request *NotifyRequest) error
+}
+
+// New returns the predictor client for a given endpoint with given options and
+// credentials, if not nil. The endpoints with the grpc and grpcs schemes are called
+// with the gRPC protocol, in plaintext and over TLS respectively, and the others
+// with JSON over HTTP.
+func New(endpoint string, options Options, credentials *Credentials) Predictor {
+	if IsGRPCEndpoint(endpoint) {
+		return NewGRPCClient(endpoint, options, credentials)
+	}
+	if credentials != nil {
+		return NewClientWithCredentials(endpoint, options, credentials)
+	}
+	return NewClient(endpoint, options)
+}
+
+// IsGRPCEndpoint returns true if a given endpoint is called with the gRPC protocol
+func IsGRPCEndpoint(endpoint string) bool {
+	scheme, _, found := strings.Cut(endpoint, "://")
+	if !found {
+		return false
+	}
+	scheme = strings.ToLower(scheme)
+	return scheme == GRPCScheme || scheme == GRPCSecureScheme
+}
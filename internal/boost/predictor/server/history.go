@@ -0,0 +1,151 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultMaxSamples is the default number of the samples kept per workload
+	DefaultMaxSamples = 100
+)
+
+// Sample is the outcome of a single POD boost reported with the reversion
+// notification
+type Sample struct {
+	Timestamp       metav1.Time     `json:"timestamp"`
+	StartupDuration metav1.Duration `json:"startupDuration"`
+	BoostDuration   metav1.Duration `json:"boostDuration"`
+}
+
+// History keeps the latest samples of the POD boosts per workload. When the file
+// path is set, the history is loaded from the file and saved to it on each change,
+// so the samples survive the server restarts.
+type History struct {
+	sync.RWMutex
+	path       string
+	maxSamples int
+	samples    map[string][]Sample
+}
+
+// NewHistory returns the history that keeps a given number of the latest samples
+// per workload and persists them to a file under a given path, unless it is
+// empty. The samples are loaded from the file if it exists.
+func NewHistory(path string, maxSamples int) (*History, error) {
+	if maxSamples <= 0 {
+		maxSamples = DefaultMaxSamples
+	}
+	h := &History{
+		path:       path,
+		maxSamples: maxSamples,
+		samples:    make(map[string][]Sample),
+	}
+	if path == "" {
+		return h, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	if err := json.Unmarshal(data, &h.samples); err != nil {
+		return nil, fmt.Errorf("failed to decode history: %w", err)
+	}
+	for key, samples := range h.samples {
+		h.samples[key] = h.trim(samples)
+	}
+	return h, nil
+}
+
+// Add adds a given sample to the samples of a workload with a given key, drops
+// the oldest samples above the limit and saves the history
+func (h *History) Add(key string, sample Sample) error {
+	h.Lock()
+	defer h.Unlock()
+	h.samples[key] = h.trim(append(h.samples[key], sample))
+	return h.save()
+}
+
+// Samples returns the copy of the samples of a workload with a given key, from
+// the oldest to the latest
+func (h *History) Samples(key string) []Sample {
+	h.RLock()
+	defer h.RUnlock()
+	return append([]Sample(nil), h.samples[key]...)
+}
+
+// trim returns the latest samples within the limit
+func (h *History) trim(samples []Sample) []Sample {
+	if len(samples) <= h.maxSamples {
+		return samples
+	}
+	return append([]Sample(nil), samples[len(samples)-h.maxSamples:]...)
+}
+
+// save writes the history to a temporary file and renames it to the history file,
+// so the file is not left partially written
+func (h *History) save() error {
+	if h.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(h.samples)
+	if err != nil {
+		return fmt.Errorf("failed to encode history: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(h.path), filepath.Base(h.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), h.path); err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+	return nil
+}
+
+// Key returns the key of the workload of the POD with a given namespace, top-level
+// owner and containers. The workload is identified with the owner or, when the
+// POD has no owner, with the container images.
+func Key(namespace string, owner *predictor.Owner, containers []predictor.ContainerRequest) string {
+	if owner != nil {
+		return strings.Join([]string{namespace, owner.Kind, owner.Name}, "/")
+	}
+	images := make([]string, 0, len(containers))
+	for _, container := range containers {
+		images = append(images, container.Image)
+	}
+	sort.Strings(images)
+	return namespace + "/" + strings.Join(images, ",")
+}
@@ -0,0 +1,112 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor/server"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("History", func() {
+	var (
+		path    string
+		history *server.History
+		err     error
+	)
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "history.json")
+	})
+	JustBeforeEach(func() {
+		history, err = server.NewHistory(path, 2)
+	})
+	It("doesn't error", func() {
+		Expect(err).NotTo(HaveOccurred())
+	})
+	It("has no samples", func() {
+		Expect(history.Samples("demo-ns/Deployment/demo")).To(BeEmpty())
+	})
+	When("the samples are added", func() {
+		JustBeforeEach(func() {
+			for _, seconds := range []int{10, 20, 30} {
+				Expect(history.Add("demo-ns/Deployment/demo", sample(seconds))).To(Succeed())
+			}
+		})
+		It("keeps the latest samples", func() {
+			samples := history.Samples("demo-ns/Deployment/demo")
+			Expect(samples).To(HaveLen(2))
+			Expect(samples[0].StartupDuration.Duration).To(Equal(20 * time.Second))
+			Expect(samples[1].StartupDuration.Duration).To(Equal(30 * time.Second))
+		})
+		It("persists the samples", func() {
+			loaded, err := server.NewHistory(path, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loaded.Samples("demo-ns/Deployment/demo")).To(HaveLen(2))
+		})
+		It("keeps the limit of the loaded samples", func() {
+			loaded, err := server.NewHistory(path, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loaded.Samples("demo-ns/Deployment/demo")).To(HaveLen(1))
+		})
+	})
+	When("the file is not valid", func() {
+		BeforeEach(func() {
+			Expect(os.WriteFile(path, []byte("{"), 0o600)).To(Succeed())
+		})
+		It("errors", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	When("the path is empty", func() {
+		BeforeEach(func() {
+			path = ""
+		})
+		It("keeps the samples in memory", func() {
+			Expect(history.Add("demo-ns/Deployment/demo", sample(10))).To(Succeed())
+			Expect(history.Samples("demo-ns/Deployment/demo")).To(HaveLen(1))
+		})
+	})
+})
+
+var _ = Describe("Key", func() {
+	var containers []predictor.ContainerRequest
+	BeforeEach(func() {
+		containers = []predictor.ContainerRequest{
+			{Name: "sidecar", Image: "example.com/proxy:1.0"},
+			{Name: "main", Image: "example.com/demo:2.1"},
+		}
+	})
+	It("identifies the workload with the owner", func() {
+		owner := &predictor.Owner{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "demo"}
+		Expect(server.Key("demo-ns", owner, containers)).To(Equal("demo-ns/StatefulSet/demo"))
+	})
+	It("identifies the workload without owner with the sorted images", func() {
+		Expect(server.Key("demo-ns", nil, containers)).
+			To(Equal("demo-ns/example.com/demo:2.1,example.com/proxy:1.0"))
+	})
+})
+
+func sample(startupSeconds int) server.Sample {
+	return server.Sample{
+		Timestamp:       metav1.Now(),
+		StartupDuration: metav1.Duration{Duration: time.Duration(startupSeconds) * time.Second},
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resource"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// DefaultMinSamples is the default number of the samples needed to predict
+	// the boost duration
+	DefaultMinSamples = 3
+	// DefaultWindow is the default number of the latest samples averaged by the
+	// moving average estimator
+	DefaultWindow = 10
+	// DefaultPercentile is the default percentile of the percentile estimator
+	DefaultPercentile = 90
+)
+
+// Model predicts the boost of the PODs from the samples of their workload. It is
+// the extension point of the server for the custom prediction logic.
+type Model interface {
+	// Predict returns the prediction of a given POD request from given samples
+	// of the POD workload, from the oldest to the latest
+	Predict(request *predictor.PodRequest, samples []Sample) *predictor.PodPrediction
+}
+
+// Estimator estimates the startup duration of the workload from given startup
+// durations of its samples, from the oldest to the latest. The durations are
+// never empty.
+type Estimator func(durations []time.Duration) time.Duration
+
+// MovingAverage returns the estimator of the average of a given number of the
+// latest durations
+func MovingAverage(window int) Estimator {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return func(durations []time.Duration) time.Duration {
+		if len(durations) > window {
+			durations = durations[len(durations)-window:]
+		}
+		var sum time.Duration
+		for _, duration := range durations {
+			sum += duration
+		}
+		return sum / time.Duration(len(durations))
+	}
+}
+
+// Percentile returns the estimator of a given percentile, from 0 to 100, of the
+// durations calculated with the nearest-rank method
+func Percentile(percentile float64) Estimator {
+	if percentile <= 0 || percentile > 100 {
+		percentile = DefaultPercentile
+	}
+	return func(durations []time.Duration) time.Duration {
+		sorted := append([]time.Duration(nil), durations...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		rank := int(math.Ceil(percentile / 100 * float64(len(sorted))))
+		return sorted[max(rank, 1)-1]
+	}
+}
+
+// ModelOptions are the options of the estimator model
+type ModelOptions struct {
+	// MinSamples is the number of the samples needed to predict the boost duration
+	MinSamples int
+	// Margin is added to the estimated startup duration
+	Margin time.Duration
+	// DefaultDuration is predicted until there are enough samples. The duration
+	// is not predicted when it is zero, so the controller applies the fallback.
+	DefaultDuration time.Duration
+	// CPUIncrease is the percentage increase of the container CPU requests and
+	// limits. The container resources are not predicted when it is zero.
+	CPUIncrease int64
+}
+
+// EstimatorModel predicts the boost duration as the startup duration estimated
+// from the samples increased by the margin. The container CPU resources are
+// increased by the fixed percentage.
+type EstimatorModel struct {
+	estimator Estimator
+	options   ModelOptions
+}
+
+// NewEstimatorModel returns the model that estimates the startup duration with
+// a given estimator and predicts with given options
+func NewEstimatorModel(estimator Estimator, options ModelOptions) *EstimatorModel {
+	if options.MinSamples <= 0 {
+		options.MinSamples = DefaultMinSamples
+	}
+	return &EstimatorModel{
+		estimator: estimator,
+		options:   options,
+	}
+}
+
+// Predict returns the prediction of a given POD request from given samples
+func (m *EstimatorModel) Predict(request *predictor.PodRequest, samples []Sample) *predictor.PodPrediction {
+	prediction := &predictor.PodPrediction{
+		Containers: make([]predictor.ContainerPrediction, 0, len(request.Containers)),
+	}
+	if len(samples) >= m.options.MinSamples {
+		durations := make([]time.Duration, 0, len(samples))
+		for _, sample := range samples {
+			durations = append(durations, sample.StartupDuration.Duration)
+		}
+		prediction.Duration = (m.estimator(durations) + m.options.Margin).String()
+	} else if m.options.DefaultDuration > 0 {
+		prediction.Duration = m.options.DefaultDuration.String()
+	}
+	if m.options.CPUIncrease <= 0 {
+		return prediction
+	}
+	for _, container := range request.Containers {
+		requests, ok := container.Resources.Requests[corev1.ResourceCPU]
+		if !ok {
+			continue
+		}
+		// the zero limits are not applied by the controller
+		limits := "0"
+		if quantity, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
+			limits = resource.IncreaseQuantity(quantity, m.options.CPUIncrease).String()
+		}
+		prediction.Containers = append(prediction.Containers, predictor.ContainerPrediction{
+			Name:        container.Name,
+			CPURequests: resource.IncreaseQuantity(requests, m.options.CPUIncrease).String(),
+			CPULimits:   limits,
+		})
+	}
+	return prediction
+}
@@ -0,0 +1,119 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server_test
+
+import (
+	"time"
+
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor/server"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apiResource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+var _ = Describe("Estimators", func() {
+	var durations []time.Duration
+	BeforeEach(func() {
+		durations = []time.Duration{50 * time.Second, 10 * time.Second, 20 * time.Second,
+			40 * time.Second, 30 * time.Second}
+	})
+	DescribeTable("moving average",
+		func(window int, expected time.Duration) {
+			Expect(server.MovingAverage(window)(durations)).To(Equal(expected))
+		},
+		Entry("window of the latest durations", 2, 35*time.Second),
+		Entry("window above the durations", 10, 30*time.Second),
+		Entry("default window", 0, 30*time.Second),
+	)
+	DescribeTable("percentile",
+		func(percentile float64, expected time.Duration) {
+			Expect(server.Percentile(percentile)(durations)).To(Equal(expected))
+		},
+		Entry("median", 50.0, 30*time.Second),
+		Entry("90th percentile", 90.0, 50*time.Second),
+		Entry("lowest rank", 1.0, 10*time.Second),
+		Entry("default percentile", 0.0, 50*time.Second),
+	)
+})
+
+var _ = Describe("Estimator model", func() {
+	var (
+		options    server.ModelOptions
+		samples    []server.Sample
+		request    *predictor.PodRequest
+		prediction *predictor.PodPrediction
+	)
+	BeforeEach(func() {
+		options = server.ModelOptions{MinSamples: 2, Margin: 5 * time.Second}
+		samples = []server.Sample{sample(10), sample(20)}
+		request = &predictor.PodRequest{
+			Containers: []predictor.ContainerRequest{
+				{
+					Name: "main",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: apiResource.MustParse("500m")},
+						Limits:   corev1.ResourceList{corev1.ResourceCPU: apiResource.MustParse("1")},
+					},
+				},
+				{
+					Name: "sidecar",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: apiResource.MustParse("100m")},
+					},
+				},
+				{Name: "no-cpu"},
+			},
+		}
+	})
+	JustBeforeEach(func() {
+		model := server.NewEstimatorModel(server.MovingAverage(10), options)
+		prediction = model.Predict(request, samples)
+	})
+	It("predicts the estimated duration with the margin", func() {
+		Expect(prediction.Duration).To(Equal("20s"))
+	})
+	It("does not predict the containers", func() {
+		Expect(prediction.Containers).To(BeEmpty())
+	})
+	When("there are not enough samples", func() {
+		BeforeEach(func() {
+			samples = samples[:1]
+		})
+		It("does not predict the duration", func() {
+			Expect(prediction.Duration).To(BeEmpty())
+		})
+		When("the default duration is set", func() {
+			BeforeEach(func() {
+				options.DefaultDuration = time.Minute
+			})
+			It("predicts the default duration", func() {
+				Expect(prediction.Duration).To(Equal("1m0s"))
+			})
+		})
+	})
+	When("the CPU increase is set", func() {
+		BeforeEach(func() {
+			options.CPUIncrease = 100
+		})
+		It("predicts the increased CPU of the containers with CPU requests", func() {
+			Expect(prediction.Containers).To(Equal([]predictor.ContainerPrediction{
+				{Name: "main", CPURequests: "1", CPULimits: "2"},
+				{Name: "sidecar", CPURequests: "200m", CPULimits: "0"},
+			}))
+		})
+	})
+})
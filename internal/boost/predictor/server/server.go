@@ -0,0 +1,271 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	predictorv1 "github.com/google/kube-startup-cpu-boost/api/predictor/v1"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	corev1 "k8s.io/api/core/v1"
+	apiResource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Server is the reference predictor server. It serves the JSON over HTTP predictor
+// contract, i.e. the /predict and /notify paths, and the gRPC predictor service.
+// The boost is predicted by the model from the samples of the POD workload, which
+// are reported by the controller with the reversion notifications. The server can
+// be started in-process, i.e. with the httptest package, as the test predictor.
+type Server struct {
+	predictorv1.UnimplementedPredictorServer
+	model   Model
+	history *History
+	mux     *http.ServeMux
+	log     logr.Logger
+}
+
+// New returns the predictor server that predicts with a given model and keeps
+// the samples in a given history
+func New(model Model, history *History) *Server {
+	s := &Server{
+		model:   model,
+		history: history,
+		mux:     http.NewServeMux(),
+		log:     ctrl.Log.WithName("boost-predictor"),
+	}
+	s.mux.HandleFunc("POST "+predictor.PredictPath, s.handlePredict)
+	s.mux.HandleFunc("POST "+predictor.NotifyPath, s.handleNotify)
+	return s
+}
+
+// ServeHTTP serves the JSON over HTTP predictor contract
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Predict returns the prediction of the POD boost
+func (s *Server) Predict(ctx context.Context, request *predictorv1.PredictRequest) (*predictorv1.PredictResponse, error) {
+	prediction := s.predict(fromPredictRequest(request))
+	response := &predictorv1.PredictResponse{
+		Containers: make([]*predictorv1.ContainerPrediction, 0, len(prediction.Containers)),
+	}
+	for _, container := range prediction.Containers {
+		response.Containers = append(response.Containers, &predictorv1.ContainerPrediction{
+			Name:        container.Name,
+			CpuRequests: container.CPURequests,
+			CpuLimits:   container.CPULimits,
+		})
+	}
+	if duration, err := prediction.GetDuration(); err == nil {
+		response.Duration = durationpb.New(duration)
+	}
+	return response, nil
+}
+
+// PredictDuration returns the prediction of the POD boost duration
+func (s *Server) PredictDuration(ctx context.Context, request *predictorv1.PredictRequest) (*predictorv1.PredictDurationResponse, error) {
+	response, err := s.Predict(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &predictorv1.PredictDurationResponse{Duration: response.Duration}, nil
+}
+
+// NotifyReversion records the outcome of the POD boost
+func (s *Server) NotifyReversion(ctx context.Context, request *predictorv1.NotifyReversionRequest) (*predictorv1.NotifyReversionResponse, error) {
+	if err := s.notify(fromNotifyReversionRequest(request)); err != nil {
+		return nil, err
+	}
+	return &predictorv1.NotifyReversionResponse{}, nil
+}
+
+// NotifyReversions records the outcomes of the POD boosts sent over the stream
+func (s *Server) NotifyReversions(stream predictorv1.Predictor_NotifyReversionsServer) error {
+	var count int32
+	for {
+		request, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&predictorv1.NotifyReversionsResponse{Count: count})
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.notify(fromNotifyReversionRequest(request)); err != nil {
+			return err
+		}
+		count++
+	}
+}
+
+func (s *Server) handlePredict(w http.ResponseWriter, r *http.Request) {
+	request := &predictor.PodRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode request: %s", err), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.predict(request)); err != nil {
+		s.log.Error(err, "failed to encode prediction")
+	}
+}
+
+func (s *Server) handleNotify(w http.ResponseWriter, r *http.Request) {
+	request := &predictor.NotifyRequest{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode request: %s", err), http.StatusBadRequest)
+		return
+	}
+	if err := s.notify(request); err != nil {
+		code := http.StatusInternalServerError
+		if status.Code(err) == codes.InvalidArgument {
+			code = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// predict returns the model prediction from the samples of the POD workload
+func (s *Server) predict(request *predictor.PodRequest) *predictor.PodPrediction {
+	key := Key(request.PodNamespace, request.Owner, request.Containers)
+	prediction := s.model.Predict(request, s.history.Samples(key))
+	s.log.V(2).Info("predicted pod boost", "workload", key, "pod", request.PodName,
+		"duration", prediction.Duration)
+	return prediction
+}
+
+// notify adds the sample of the POD boost to the history. The notifications of the
+// PODs that were not ready when reverted are skipped, as their startup duration
+// is not known.
+func (s *Server) notify(request *predictor.NotifyRequest) error {
+	if request.StartupDuration == "" {
+		return nil
+	}
+	sample := Sample{Timestamp: metav1.Now()}
+	var err error
+	if sample.StartupDuration.Duration, err = time.ParseDuration(request.StartupDuration); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid startup duration: %s", err)
+	}
+	if request.BoostDuration != "" {
+		if sample.BoostDuration.Duration, err = time.ParseDuration(request.BoostDuration); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid boost duration: %s", err)
+		}
+	}
+	key := Key(request.PodNamespace, request.Owner, request.Containers)
+	if err := s.history.Add(key, sample); err != nil {
+		s.log.Error(err, "failed to save history")
+		return status.Error(codes.Internal, err.Error())
+	}
+	s.log.V(2).Info("recorded pod boost", "workload", key, "pod", request.PodName,
+		"startupDuration", request.StartupDuration)
+	return nil
+}
+
+// fromPredictRequest maps the protocol message to the POD request
+func fromPredictRequest(request *predictorv1.PredictRequest) *predictor.PodRequest {
+	podRequest := &predictor.PodRequest{
+		SchemaVersion: predictor.PodRequestSchemaVersion,
+		BoostName:     request.BoostName,
+		PodName:       request.PodName,
+		PodNamespace:  request.PodNamespace,
+		Owner:         fromOwner(request.Owner),
+		NodeSelector:  request.NodeSelector,
+		Containers:    fromContainers(request.Containers),
+	}
+	for _, toleration := range request.Tolerations {
+		podRequest.Tolerations = append(podRequest.Tolerations, corev1.Toleration{
+			Key:               toleration.Key,
+			Operator:          corev1.TolerationOperator(toleration.Operator),
+			Value:             toleration.Value,
+			Effect:            corev1.TaintEffect(toleration.Effect),
+			TolerationSeconds: toleration.TolerationSeconds,
+		})
+	}
+	return podRequest
+}
+
+// fromNotifyReversionRequest maps the protocol message to the notification
+func fromNotifyReversionRequest(request *predictorv1.NotifyReversionRequest) *predictor.NotifyRequest {
+	notification := &predictor.NotifyRequest{
+		SchemaVersion: predictor.NotifyRequestSchemaVersion,
+		PodName:       request.PodName,
+		PodNamespace:  request.PodNamespace,
+		BoostName:     request.BoostName,
+		Owner:         fromOwner(request.Owner),
+		Containers:    fromContainers(request.Containers),
+	}
+	if request.StartupDuration != nil {
+		notification.StartupDuration = request.StartupDuration.AsDuration().String()
+	}
+	if request.BoostDuration != nil {
+		notification.BoostDuration = request.BoostDuration.AsDuration().String()
+	}
+	return notification
+}
+
+func fromOwner(owner *predictorv1.Owner) *predictor.Owner {
+	if owner == nil {
+		return nil
+	}
+	return &predictor.Owner{
+		APIVersion: owner.ApiVersion,
+		Kind:       owner.Kind,
+		Name:       owner.Name,
+	}
+}
+
+func fromContainers(containers []*predictorv1.Container) []predictor.ContainerRequest {
+	result := make([]predictor.ContainerRequest, 0, len(containers))
+	for _, container := range containers {
+		result = append(result, predictor.ContainerRequest{
+			Name:        container.Name,
+			Image:       container.Image,
+			ImageDigest: container.ImageDigest,
+			Resources: corev1.ResourceRequirements{
+				Requests: fromQuantities(container.Requests),
+				Limits:   fromQuantities(container.Limits),
+			},
+		})
+	}
+	return result
+}
+
+// fromQuantities maps given resource quantities by the resource name to the
+// resource list. The quantities that cannot be parsed are skipped.
+func fromQuantities(quantities map[string]string) corev1.ResourceList {
+	if len(quantities) == 0 {
+		return nil
+	}
+	resources := make(corev1.ResourceList, len(quantities))
+	for name, value := range quantities {
+		if quantity, err := apiResource.ParseQuantity(value); err == nil {
+			resources[corev1.ResourceName(name)] = quantity
+		}
+	}
+	return resources
+}
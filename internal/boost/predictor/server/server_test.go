@@ -0,0 +1,171 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	predictorv1 "github.com/google/kube-startup-cpu-boost/api/predictor/v1"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor/server"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	corev1 "k8s.io/api/core/v1"
+	apiResource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+var _ = Describe("Server", func() {
+	var (
+		history      *server.History
+		srv          *server.Server
+		endpoint     string
+		client       predictor.Predictor
+		request      *predictor.PodRequest
+		notification *predictor.NotifyRequest
+		prediction   *predictor.PodPrediction
+		err          error
+	)
+	BeforeEach(func() {
+		history, err = server.NewHistory("", 0)
+		Expect(err).NotTo(HaveOccurred())
+		srv = server.New(server.NewEstimatorModel(server.Percentile(50), server.ModelOptions{
+			MinSamples:  2,
+			CPUIncrease: 50,
+		}), history)
+		owner := &predictor.Owner{APIVersion: "apps/v1", Kind: "Deployment", Name: "demo"}
+		request = &predictor.PodRequest{
+			SchemaVersion: predictor.PodRequestSchemaVersion,
+			PodName:       "demo-",
+			PodNamespace:  "demo-ns",
+			Owner:         owner,
+			Containers: []predictor.ContainerRequest{{
+				Name:  "main",
+				Image: "example.com/demo:2.1",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: apiResource.MustParse("1")},
+				},
+			}},
+		}
+		notification = &predictor.NotifyRequest{
+			PodName:         "demo-7f6d",
+			PodNamespace:    "demo-ns",
+			Owner:           owner,
+			StartupDuration: "40s",
+			BoostDuration:   "1m",
+		}
+	})
+	// itPredictsFromNotifications verifies the predictions made by the server
+	// through the predictor client
+	itPredictsFromNotifications := func() {
+		JustBeforeEach(func() {
+			prediction, err = client.Predict(context.TODO(), request)
+		})
+		It("doesn't error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("predicts the container CPU", func() {
+			Expect(prediction.Containers).To(Equal([]predictor.ContainerPrediction{
+				{Name: "main", CPURequests: "1500m", CPULimits: "0"},
+			}))
+		})
+		It("does not predict the duration", func() {
+			Expect(prediction.Duration).To(BeEmpty())
+		})
+		When("the workload PODs were notified", func() {
+			BeforeEach(func() {
+				for _, seconds := range []int{40, 20, 30} {
+					Expect(history.Add(server.Key("demo-ns", request.Owner, nil), sample(seconds))).To(Succeed())
+				}
+			})
+			It("predicts the duration from the samples", func() {
+				Expect(prediction.Duration).To(Equal("30s"))
+			})
+		})
+		When("the POD reversion is notified", func() {
+			JustBeforeEach(func() {
+				Expect(client.NotifyReversion(context.TODO(), notification)).To(Succeed())
+			})
+			It("records the sample of the workload", func() {
+				Eventually(func() []server.Sample {
+					return history.Samples("demo-ns/Deployment/demo")
+				}).Should(HaveLen(1))
+				sample := history.Samples("demo-ns/Deployment/demo")[0]
+				Expect(sample.StartupDuration.Duration).To(Equal(40 * time.Second))
+				Expect(sample.BoostDuration.Duration).To(Equal(time.Minute))
+			})
+			When("the POD was not ready", func() {
+				BeforeEach(func() {
+					notification.StartupDuration = ""
+				})
+				It("does not record the sample", func() {
+					Consistently(func() []server.Sample {
+						return history.Samples("demo-ns/Deployment/demo")
+					}, 100*time.Millisecond).Should(BeEmpty())
+				})
+			})
+		})
+	}
+	When("called with the JSON over HTTP", func() {
+		var httpServer *httptest.Server
+		BeforeEach(func() {
+			httpServer = httptest.NewServer(srv)
+			endpoint = httpServer.URL
+			client = predictor.New(endpoint, predictor.DefaultOptions(), nil)
+		})
+		AfterEach(func() {
+			httpServer.Close()
+		})
+		itPredictsFromNotifications()
+		It("refuses the invalid notification", func() {
+			resp, err := http.Post(endpoint+predictor.NotifyPath, "application/json",
+				strings.NewReader(`{"podName": "demo-7f6d", "startupDuration": "soon"}`))
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+		It("does not serve other paths", func() {
+			resp, err := http.Post(endpoint+"/other", "application/json", strings.NewReader("{}"))
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+	When("called with the gRPC protocol", func() {
+		var grpcServer *grpc.Server
+		BeforeEach(func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			grpcServer = grpc.NewServer()
+			predictorv1.RegisterPredictorServer(grpcServer, srv)
+			go func() {
+				_ = grpcServer.Serve(listener)
+			}()
+			endpoint = predictor.GRPCScheme + "://" + listener.Addr().String()
+			options := predictor.DefaultOptions()
+			options.NotifyBatchInterval = 10 * time.Millisecond
+			client = predictor.New(endpoint, options, nil)
+		})
+		AfterEach(func() {
+			grpcServer.Stop()
+		})
+		itPredictsFromNotifications()
+	})
+})
@@ -0,0 +1,161 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boost
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// podKey identifies a POD tracked by a startup-cpu-boost
+type podKey struct {
+	boost boostKey
+	name  string
+}
+
+// podDeadline is a POD scheduled in the deadline queue
+type podDeadline struct {
+	key      podKey
+	deadline time.Time
+	index    int
+}
+
+// podDeadlineHeap is a min-heap of PODs ordered by their deadlines
+type podDeadlineHeap []*podDeadline
+
+func (h podDeadlineHeap) Len() int {
+	return len(h)
+}
+
+func (h podDeadlineHeap) Less(i, j int) bool {
+	return h[i].deadline.Before(h[j].deadline)
+}
+
+func (h podDeadlineHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *podDeadlineHeap) Push(x any) {
+	item := x.(*podDeadline)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *podDeadlineHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return item
+}
+
+// podDeadlineQueue is a delay queue of startup-cpu-boost PODs keyed by the deadlines
+// of their duration policies. Each POD is scheduled at most once, so scheduling it
+// again moves its deadline. The queue signals on the wakeup channel when the earliest
+// deadline moves, so the waiting consumer can re-arm its timer.
+type podDeadlineQueue struct {
+	sync.Mutex
+	items  podDeadlineHeap
+	index  map[podKey]*podDeadline
+	wakeup chan struct{}
+}
+
+func newPodDeadlineQueue() *podDeadlineQueue {
+	return &podDeadlineQueue{
+		index:  make(map[podKey]*podDeadline),
+		wakeup: make(chan struct{}, 1),
+	}
+}
+
+// Schedule adds a POD with a given key to the queue or moves its deadline
+func (q *podDeadlineQueue) Schedule(key podKey, deadline time.Time) {
+	q.Lock()
+	defer q.Unlock()
+	item, ok := q.index[key]
+	if ok {
+		item.deadline = deadline
+		heap.Fix(&q.items, item.index)
+	} else {
+		item = &podDeadline{key: key, deadline: deadline}
+		heap.Push(&q.items, item)
+		q.index[key] = item
+	}
+	if item.index == 0 {
+		q.notify()
+	}
+}
+
+// Unschedule removes a POD with a given key from the queue, if present
+func (q *podDeadlineQueue) Unschedule(key podKey) {
+	q.Lock()
+	defer q.Unlock()
+	item, ok := q.index[key]
+	if !ok {
+		return
+	}
+	heap.Remove(&q.items, item.index)
+	delete(q.index, key)
+}
+
+// PopDue removes the PODs which deadlines are not after a given time from the queue
+// and returns their keys in the deadline order
+func (q *podDeadlineQueue) PopDue(now time.Time) []podKey {
+	q.Lock()
+	defer q.Unlock()
+	var keys []podKey
+	for len(q.items) > 0 && !q.items[0].deadline.After(now) {
+		item := heap.Pop(&q.items).(*podDeadline)
+		delete(q.index, item.key)
+		keys = append(keys, item.key)
+	}
+	return keys
+}
+
+// Next returns the earliest deadline in the queue and true, or false if the
+// queue is empty
+func (q *podDeadlineQueue) Next() (time.Time, bool) {
+	q.Lock()
+	defer q.Unlock()
+	if len(q.items) == 0 {
+		return time.Time{}, false
+	}
+	return q.items[0].deadline, true
+}
+
+// Len returns the number of PODs in the queue
+func (q *podDeadlineQueue) Len() int {
+	q.Lock()
+	defer q.Unlock()
+	return len(q.items)
+}
+
+// Wakeup returns the channel that receives when the earliest deadline in the
+// queue moves
+func (q *podDeadlineQueue) Wakeup() <-chan struct{} {
+	return q.wakeup
+}
+
+// notify signals on the wakeup channel without blocking, as a single pending
+// signal is enough for the consumer to re-arm its timer
+func (q *podDeadlineQueue) notify() {
+	select {
+	case q.wakeup <- struct{}{}:
+	default:
+	}
+}
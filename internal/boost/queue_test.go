@@ -0,0 +1,101 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boost
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PodDeadlineQueue", func() {
+	var (
+		queue  *podDeadlineQueue
+		now    time.Time
+		first  podKey
+		second podKey
+		third  podKey
+	)
+	BeforeEach(func() {
+		queue = newPodDeadlineQueue()
+		now = time.Now()
+		boost := boostKey{name: "boost-001", namespace: "demo"}
+		first = podKey{boost: boost, name: "pod-001"}
+		second = podKey{boost: boost, name: "pod-002"}
+		third = podKey{boost: boost, name: "pod-003"}
+	})
+	When("PODs are scheduled", func() {
+		BeforeEach(func() {
+			queue.Schedule(second, now.Add(2*time.Second))
+			queue.Schedule(third, now.Add(3*time.Second))
+			queue.Schedule(first, now.Add(1*time.Second))
+		})
+		It("returns the earliest deadline", func() {
+			deadline, ok := queue.Next()
+			Expect(ok).To(BeTrue())
+			Expect(deadline).To(Equal(now.Add(1 * time.Second)))
+		})
+		It("signals the wakeup", func() {
+			Expect(queue.Wakeup()).To(Receive())
+		})
+		It("pops the due PODs in the deadline order", func() {
+			Expect(queue.PopDue(now.Add(2 * time.Second))).To(Equal([]podKey{first, second}))
+			Expect(queue.Len()).To(Equal(1))
+		})
+		It("does not pop the PODs before their deadlines", func() {
+			Expect(queue.PopDue(now)).To(BeEmpty())
+			Expect(queue.Len()).To(Equal(3))
+		})
+		When("the POD is scheduled again", func() {
+			BeforeEach(func() {
+				queue.Schedule(first, now.Add(4*time.Second))
+			})
+			It("moves the POD deadline", func() {
+				Expect(queue.Len()).To(Equal(3))
+				Expect(queue.PopDue(now.Add(3 * time.Second))).To(Equal([]podKey{second, third}))
+				Expect(queue.PopDue(now.Add(4 * time.Second))).To(Equal([]podKey{first}))
+			})
+		})
+		When("the POD is unscheduled", func() {
+			BeforeEach(func() {
+				queue.Unschedule(second)
+			})
+			It("removes the POD", func() {
+				Expect(queue.Len()).To(Equal(2))
+				Expect(queue.PopDue(now.Add(3 * time.Second))).To(Equal([]podKey{first, third}))
+			})
+		})
+	})
+	When("the queue is empty", func() {
+		It("returns no deadline", func() {
+			_, ok := queue.Next()
+			Expect(ok).To(BeFalse())
+		})
+		It("does not signal the wakeup", func() {
+			Expect(queue.Wakeup()).NotTo(Receive())
+		})
+	})
+	When("the POD is scheduled after the earliest deadline", func() {
+		BeforeEach(func() {
+			queue.Schedule(first, now.Add(1*time.Second))
+			<-queue.Wakeup()
+			queue.Schedule(second, now.Add(2*time.Second))
+		})
+		It("does not signal the wakeup", func() {
+			Expect(queue.Wakeup()).NotTo(Receive())
+		})
+	})
+})
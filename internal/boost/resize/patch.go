@@ -0,0 +1,40 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resize
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PatchStrategy resizes the POD container resources with the patch of the POD,
+// as supported by the API servers without the pods/resize subresource
+type PatchStrategy struct {
+	client client.Client
+}
+
+func NewPatchStrategy(c client.Client) Strategy {
+	return &PatchStrategy{
+		client: c,
+	}
+}
+
+// Resize patches the container resources, labels and annotations of the POD
+func (s *PatchStrategy) Resize(ctx context.Context, original, updated *corev1.Pod) error {
+	patch := client.StrategicMergeFrom(original, client.MergeFromWithOptimisticLock{})
+	return s.client.Patch(ctx, updated, patch)
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resize_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resize"
+	"github.com/google/kube-startup-cpu-boost/internal/mock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apiResource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("PatchStrategy", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *mock.MockClient
+		original   *corev1.Pod
+		updated    *corev1.Pod
+		patched    *corev1.Pod
+		patchCall  *gomock.Call
+		err        error
+	)
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock.NewMockClient(mockCtrl)
+		original = podTemplate.DeepCopy()
+		updated = original.DeepCopy()
+		updated.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = apiResource.MustParse("500m")
+		updated.Annotations["updated"] = "true"
+		patched = nil
+		patchCall = mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, obj client.Object, patch client.Patch,
+				opts ...client.PatchOption) error {
+				patched = obj.(*corev1.Pod).DeepCopy()
+				Expect(patch.Type()).To(Equal(types.StrategicMergePatchType))
+				return nil
+			})
+	})
+	JustBeforeEach(func() {
+		err = resize.NewPatchStrategy(mockClient).Resize(context.TODO(), original, updated)
+	})
+	When("patch succeeds", func() {
+		BeforeEach(func() {
+			patchCall.Times(1)
+		})
+		It("does not error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("patches the container resources and metadata without the subresource", func() {
+			cpuReq := patched.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]
+			Expect(cpuReq.String()).To(Equal("500m"))
+			Expect(patched.Annotations).To(HaveKeyWithValue("updated", "true"))
+		})
+	})
+	When("patch fails", func() {
+		BeforeEach(func() {
+			patchCall.Return(errors.New("patch failed")).Times(1)
+		})
+		It("errors", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
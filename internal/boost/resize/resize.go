@@ -0,0 +1,54 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resize contains implementation of the strategies for in-place
+// resize of POD container resources
+package resize
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/discovery"
+)
+
+const (
+	// SubResource is the name of the POD subresource used for in-place resize
+	SubResource = "resize"
+	// podsSubResource is the name of the resize subresource in the API discovery
+	podsSubResource = "pods/" + SubResource
+)
+
+// Strategy updates the POD container resources in place
+type Strategy interface {
+	// Resize updates a given original POD to match a given updated POD. The container
+	// resources, labels and annotations of the updated POD are applied. On success,
+	// the updated POD reflects the latest POD state returned by the API server.
+	Resize(ctx context.Context, original, updated *corev1.Pod) error
+}
+
+// SubResourceSupported returns true if the API server offers the pods/resize
+// subresource
+func SubResourceSupported(discoveryClient discovery.ServerResourcesInterface) (bool, error) {
+	resources, err := discoveryClient.ServerResourcesForGroupVersion(corev1.SchemeGroupVersion.String())
+	if err != nil {
+		return false, err
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == podsSubResource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resize_test
+
+import (
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resize"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("SubResourceSupported", func() {
+	var (
+		discoveryClient *fake.FakeDiscovery
+		supported       bool
+		err             error
+	)
+	BeforeEach(func() {
+		discoveryClient = &fake.FakeDiscovery{Fake: &clienttesting.Fake{}}
+		discoveryClient.Resources = []*metav1.APIResourceList{{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods"},
+				{Name: "pods/status"},
+			},
+		}}
+	})
+	JustBeforeEach(func() {
+		supported, err = resize.SubResourceSupported(discoveryClient)
+	})
+	When("API server does not offer the resize subresource", func() {
+		It("does not error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("returns false", func() {
+			Expect(supported).To(BeFalse())
+		})
+	})
+	When("API server offers the resize subresource", func() {
+		BeforeEach(func() {
+			discoveryClient.Resources[0].APIResources = append(discoveryClient.Resources[0].APIResources,
+				metav1.APIResource{Name: "pods/resize"})
+		})
+		It("does not error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("returns true", func() {
+			Expect(supported).To(BeTrue())
+		})
+	})
+	When("API server discovery fails", func() {
+		BeforeEach(func() {
+			// the fake discovery returns the not found error for the missing group version
+			discoveryClient.Resources = nil
+		})
+		It("errors", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
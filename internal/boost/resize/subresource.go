@@ -0,0 +1,81 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resize
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SubResourceStrategy resizes the POD container resources with the pods/resize
+// subresource and updates the POD labels and annotations with a separate patch
+type SubResourceStrategy struct {
+	client client.Client
+	writer client.SubResourceWriter
+}
+
+func NewSubResourceStrategy(c client.Client) Strategy {
+	return NewSubResourceStrategyWithWriter(c, c.SubResource(SubResource))
+}
+
+func NewSubResourceStrategyWithWriter(c client.Client, writer client.SubResourceWriter) Strategy {
+	return &SubResourceStrategy{
+		client: c,
+		writer: writer,
+	}
+}
+
+// Resize patches the container resources with the resize subresource first, so
+// the POD labels and annotations, i.e. the boost ones, are changed only when
+// the resize was accepted
+func (s *SubResourceStrategy) Resize(ctx context.Context, original, updated *corev1.Pod) error {
+	resized := original.DeepCopy()
+	copyContainerResources(resized, updated)
+	patch := client.StrategicMergeFrom(original, client.MergeFromWithOptimisticLock{})
+	if err := s.writer.Patch(ctx, resized, patch); err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(original.Labels, updated.Labels) &&
+		equality.Semantic.DeepEqual(original.Annotations, updated.Annotations) {
+		resized.DeepCopyInto(updated)
+		return nil
+	}
+	metadata := resized.DeepCopy()
+	metadata.Labels = updated.Labels
+	metadata.Annotations = updated.Annotations
+	if err := s.client.Patch(ctx, metadata, client.MergeFrom(resized)); err != nil {
+		return err
+	}
+	metadata.DeepCopyInto(updated)
+	return nil
+}
+
+// copyContainerResources sets the resources of the containers and init containers
+// of a given POD to the ones of a given source POD
+func copyContainerResources(pod, source *corev1.Pod) {
+	for i := range pod.Spec.InitContainers {
+		if i < len(source.Spec.InitContainers) {
+			source.Spec.InitContainers[i].Resources.DeepCopyInto(&pod.Spec.InitContainers[i].Resources)
+		}
+	}
+	for i := range pod.Spec.Containers {
+		if i < len(source.Spec.Containers) {
+			source.Spec.Containers[i].Resources.DeepCopyInto(&pod.Spec.Containers[i].Resources)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resize_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resize"
+	"github.com/google/kube-startup-cpu-boost/internal/mock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apiResource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("SubResourceStrategy", func() {
+	var (
+		mockCtrl         *gomock.Controller
+		mockClient       *mock.MockClient
+		mockSubResWriter *mock.MockSubResourceWriter
+		original         *corev1.Pod
+		updated          *corev1.Pod
+		resized          *corev1.Pod
+		patched          *corev1.Pod
+		resizePatchType  types.PatchType
+		resizeCall       *gomock.Call
+		err              error
+	)
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock.NewMockClient(mockCtrl)
+		mockSubResWriter = mock.NewMockSubResourceWriter(mockCtrl)
+		original = podTemplate.DeepCopy()
+		updated = original.DeepCopy()
+		updated.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = apiResource.MustParse("500m")
+		updated.Spec.Containers[0].Resources.Limits[corev1.ResourceCPU] = apiResource.MustParse("1")
+		resized, patched = nil, nil
+		resizeCall = mockSubResWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, obj client.Object, patch client.Patch,
+				opts ...client.SubResourcePatchOption) error {
+				resized = obj.(*corev1.Pod).DeepCopy()
+				resizePatchType = patch.Type()
+				return nil
+			})
+	})
+	JustBeforeEach(func() {
+		strategy := resize.NewSubResourceStrategyWithWriter(mockClient, mockSubResWriter)
+		err = strategy.Resize(context.TODO(), original, updated)
+	})
+	When("POD metadata is not changed", func() {
+		BeforeEach(func() {
+			resizeCall.Times(1)
+		})
+		It("does not error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("resizes the container resources with the subresource", func() {
+			cpuReq := resized.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]
+			Expect(cpuReq.String()).To(Equal("500m"))
+			cpuLimit := resized.Spec.Containers[0].Resources.Limits[corev1.ResourceCPU]
+			Expect(cpuLimit.String()).To(Equal("1"))
+		})
+		It("resizes with the strategic merge patch", func() {
+			Expect(resizePatchType).To(Equal(types.StrategicMergePatchType))
+		})
+	})
+	When("POD metadata is changed", func() {
+		var patchCall *gomock.Call
+		BeforeEach(func() {
+			delete(updated.Labels, "app")
+			updated.Annotations["updated"] = "true"
+			patchCall = mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, obj client.Object, patch client.Patch,
+					opts ...client.PatchOption) error {
+					patched = obj.(*corev1.Pod).DeepCopy()
+					Expect(patch.Type()).To(Equal(types.MergePatchType))
+					return nil
+				})
+		})
+		When("resize succeeds", func() {
+			BeforeEach(func() {
+				resizeCall.Times(1)
+				patchCall.Times(1)
+			})
+			It("does not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("does not change metadata with the subresource", func() {
+				Expect(resized.Labels).To(HaveKey("app"))
+				Expect(resized.Annotations).NotTo(HaveKey("updated"))
+			})
+			It("patches the POD metadata", func() {
+				Expect(patched.Labels).NotTo(HaveKey("app"))
+				Expect(patched.Annotations).To(HaveKeyWithValue("updated", "true"))
+			})
+			It("keeps the resized container resources", func() {
+				cpuReq := patched.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]
+				Expect(cpuReq.String()).To(Equal("500m"))
+			})
+		})
+		When("resize fails", func() {
+			BeforeEach(func() {
+				resizeCall.Return(errors.New("resize failed")).Times(1)
+				patchCall.Times(0)
+			})
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		When("metadata patch fails", func() {
+			BeforeEach(func() {
+				resizeCall.Times(1)
+				patchCall.Return(errors.New("patch failed")).Times(1)
+			})
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
@@ -15,83 +15,117 @@
 package resource
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
 
 	"github.com/go-logr/logr"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
 	corev1 "k8s.io/api/core/v1"
 	apiResource "k8s.io/apimachinery/pkg/api/resource"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
-type ContextKey string
+// AutoFallbackPolicyPrefix prefixes the name of the fallback policy applied
+// by the auto policy
+const AutoFallbackPolicyPrefix = AutoPolicyName + ".fallback."
 
+// AutoPolicy is the resource policy which CPU resources are predicted by the
+// external predictor. The prediction is made once per POD for all its containers.
 type AutoPolicy struct {
-	apiEndpoint string
+	client   predictor.Predictor
+	fallback ContainerPolicy
 }
 
-type ResourcePrediction struct {
-	CPURequests string `json:"cpuRequests"`
-	CPULimits   string `json:"cpuLimits"`
+func NewAutoPolicy(apiEndpoint string) ContainerPolicy {
+	return NewAutoPolicyWithClient(predictor.New(apiEndpoint, predictor.DefaultOptions(), nil))
 }
 
-func NewAutoPolicy(apiEndpoint string) ContainerPolicy {
+// NewAutoPolicyWithClient returns the auto resource policy that gets the predictions
+// with a given predictor client
+func NewAutoPolicyWithClient(client predictor.Predictor) ContainerPolicy {
+	return NewAutoPolicyWithFallback(client, nil)
+}
+
+// NewAutoPolicyWithFallback returns the auto resource policy that gets the predictions
+// with a given predictor client and applies a given fallback policy, if not nil, when
+// the prediction fails
+func NewAutoPolicyWithFallback(client predictor.Predictor, fallback ContainerPolicy) ContainerPolicy {
 	return &AutoPolicy{
-		apiEndpoint: apiEndpoint,
+		client:   client,
+		fallback: fallback,
 	}
 }
 
-func (p *AutoPolicy) Requests(ctx context.Context) (apiResource.Quantity, error) {
-	prediction, err := p.getPrediction(ctx)
-	if err != nil {
-		return apiResource.Quantity{}, err
-	}
-	return apiResource.ParseQuantity(prediction.CPURequests)
+// Name returns the policy name, as in the API spec
+func (p *AutoPolicy) Name() string {
+	return AutoPolicyName
 }
 
-func (p *AutoPolicy) Limits(ctx context.Context) (apiResource.Quantity, error) {
-	prediction, err := p.getPrediction(ctx)
-	if err != nil {
-		return apiResource.Quantity{}, err
-	}
-	return apiResource.ParseQuantity(prediction.CPULimits)
+// Fallback returns the policy applied when the prediction fails
+func (p *AutoPolicy) Fallback() ContainerPolicy {
+	return p.fallback
+}
+
+// Endpoint returns the endpoint of the predictor
+func (p *AutoPolicy) Endpoint() string {
+	return p.client.Endpoint()
 }
 
+// NewResources returns the container resources predicted for a given container
+// alone, without its POD. Use ApplyPolicy to share the POD prediction among
+// the POD containers.
 func (p *AutoPolicy) NewResources(ctx context.Context, container *corev1.Container) *corev1.ResourceRequirements {
-	log := ctrl.LoggerFrom(ctx).WithName("auto-cpu-policy")
-	prediction, err := p.getPrediction(ctx)
+	predictions := predictor.NewPodPredictions(&corev1.Pod{
+		Spec: corev1.PodSpec{Containers: []corev1.Container{*container}},
+	}, "", nil)
+	resources, _ := p.applyPodPolicy(ctx, predictions, container)
+	return resources
+}
 
-	if prediction == nil {
+// applyPodPolicy returns the container resources calculated from the POD prediction
+// and the auto policy name. When the prediction fails, it returns the resources
+// calculated by the fallback policy and its name, or nil if the fallback policy
+// is not defined.
+func (p *AutoPolicy) applyPodPolicy(ctx context.Context, predictions *predictor.PodPredictions,
+	container *corev1.Container) (*corev1.ResourceRequirements, string) {
+	log := ctrl.LoggerFrom(ctx).WithName("auto-cpu-policy")
+	resources, err := p.predictedResources(ctx, predictions, container, log)
+	if err == nil {
+		return resources, p.Name()
+	}
+	if p.fallback == nil {
 		log.Error(err, "failed to get prediction")
-		return nil
+		return nil, p.Name()
 	}
+	log.Error(err, "failed to get prediction, applying fallback policy", "fallback", p.fallback.Name())
+	return p.fallback.NewResources(ctx, container), AutoFallbackPolicyPrefix + p.fallback.Name()
+}
 
+// predictedResources returns the container resources calculated from the POD prediction
+func (p *AutoPolicy) predictedResources(ctx context.Context, predictions *predictor.PodPredictions,
+	container *corev1.Container, log logr.Logger) (*corev1.ResourceRequirements, error) {
+	podPrediction, err := predictions.Predict(ctx, p.client)
 	if err != nil {
-		log.Error(err, "failed to get prediction")
-		return nil
+		return nil, fmt.Errorf("failed to get prediction: %w", err)
+	}
+	prediction, ok := podPrediction.Container(container.Name)
+	if !ok {
+		return nil, fmt.Errorf("prediction has no container %q", container.Name)
 	}
-
 	cpuRequests, err := apiResource.ParseQuantity(prediction.CPURequests)
 	if err != nil {
-		log.Error(err, "failed to parse CPU requests")
-		return nil
+		return nil, fmt.Errorf("failed to parse CPU requests: %w", err)
 	}
 	cpuLimits, err := apiResource.ParseQuantity(prediction.CPULimits)
 	if err != nil {
-		log.Error(err, "failed to parse CPU limits")
-		return nil
+		return nil, fmt.Errorf("failed to parse CPU limits: %w", err)
 	}
 
 	log = log.WithValues("newCPURequests", cpuRequests.String(), "newCPULimits", cpuLimits.String())
 	result := container.Resources.DeepCopy()
 	p.setResource(corev1.ResourceCPU, result.Requests, cpuRequests, log)
 	p.setResource(corev1.ResourceCPU, result.Limits, cpuLimits, log)
-
-	fmt.Printf("result: %+v\n", result)
-	return result
+	return result, nil
 }
 
 func (p *AutoPolicy) setResource(resource corev1.ResourceName, resources corev1.ResourceList, target apiResource.Quantity, log logr.Logger) {
@@ -108,52 +142,3 @@ func (p *AutoPolicy) setResource(resource corev1.ResourceName, resources corev1.
 	}
 	resources[resource] = target
 }
-
-func (p *AutoPolicy) getPrediction(ctx context.Context) (*ResourcePrediction, error) {
-
-	fmt.Printf("ctx: %+v\n", ctx)
-	// Retrieve the pod information from the context
-	podName := ctx.Value(ContextKey("podName"))
-	podNamespace := ctx.Value(ContextKey("podNamespace"))
-
-	if podName == nil || podNamespace == nil {
-		return nil, fmt.Errorf("pod information not found in context")
-	}
-
-	// Marshal the pod information to JSON
-	reqBody, err := json.Marshal(map[string]string{
-		"podName":      podName.(string),
-		"podNamespace": podNamespace.(string),
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal pod information: %w", err)
-	}
-
-	// Create a new HTTP request with the pod information
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.apiEndpoint+"/cpu", bytes.NewBuffer(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create new request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	// Send the HTTP request
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Check for a successful response status code
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
-	// Decode the response body into a ResourcePrediction struct
-	var prediction ResourcePrediction
-	if err := json.NewDecoder(resp.Body).Decode(&prediction); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	return &prediction, nil
-}
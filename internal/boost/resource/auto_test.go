@@ -1,22 +1,20 @@
 package resource_test
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
 	"net/http/httptest"
-	"os"
 
 	apiResource "k8s.io/apimachinery/pkg/api/resource"
 
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
 	resource "github.com/google/kube-startup-cpu-boost/internal/boost/resource"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var _ = Describe("Auto Resource Policy", func() {
@@ -25,181 +23,237 @@ var _ = Describe("Auto Resource Policy", func() {
 		newResources *corev1.ResourceRequirements
 		container    *corev1.Container
 		mockServer   *httptest.Server
-		oldStdout    *os.File
-		stdoutReader *os.File
-		stdoutWriter *os.File
-		outputBuffer *bytes.Buffer
+		requests     chan predictor.PodRequest
+		prediction   predictor.ContainerPrediction
 	)
 
 	BeforeEach(func() {
 		container = containerTemplate.DeepCopy()
-
-		// Set up pipe to capture stdout
-		oldStdout = os.Stdout
-		stdoutReader, stdoutWriter, _ = os.Pipe()
-		os.Stdout = stdoutWriter
-
-		outputBuffer = new(bytes.Buffer)
+		requests = make(chan predictor.PodRequest, 1)
+		mockServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal(predictor.PredictPath))
+			Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+
+			var request predictor.PodRequest
+			err := json.NewDecoder(r.Body).Decode(&request)
+			Expect(err).NotTo(HaveOccurred())
+			requests <- request
+
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(predictor.PodPrediction{
+				Containers: []predictor.ContainerPrediction{prediction},
+			})
+		}))
 	})
 
 	JustBeforeEach(func() {
-		// Run the test case logic that triggers the fmt.Print statements.
 		policy = resource.NewAutoPolicy(mockServer.URL)
-
-		podName := "test-pod"
-		podNamespace := "test-namespace"
-
-		ctx := context.WithValue(context.TODO(), resource.ContextKey("podName"), podName)
-		ctx = context.WithValue(ctx, resource.ContextKey("podNamespace"), podNamespace)
-
-		newResources = policy.NewResources(ctx, container)
-
-		fmt.Printf("newResources: %+v\n", newResources)
-
-		// Ensure everything written to os.Stdout is captured
-		stdoutWriter.Close()                       // Close the writer
-		_, _ = io.Copy(outputBuffer, stdoutReader) // Copy the output to buffer
-		os.Stdout = oldStdout                      // Restore stdout
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-pod",
+				Namespace: "test-namespace",
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{*container},
+			},
+		}
+		newResources, _ = resource.ApplyPolicy(context.TODO(), policy,
+			predictor.NewPodPredictions(pod, "", nil), container)
 	})
 
 	AfterEach(func() {
-		if mockServer != nil {
-			mockServer.Close()
-		}
-		fmt.Fprintln(GinkgoWriter, "Captured Output:", outputBuffer.String()) // Print captured output to test log
+		mockServer.Close()
 	})
 
 	Describe("AutoPolicy", func() {
 		Context("when the API returns valid predictions", func() {
 			BeforeEach(func() {
-				mockServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-					Expect(r.URL.Path).To(Equal("/cpu"))
-					Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
-
-					var pod corev1.Pod
-					err := json.NewDecoder(r.Body).Decode(&pod)
-					Expect(err).NotTo(HaveOccurred())
-
-					prediction := resource.ResourcePrediction{
-						CPURequests: "500m",
-						CPULimits:   "1000m",
-					}
-					w.Header().Set("Content-Type", "application/json")
-					json.NewEncoder(w).Encode(prediction)
-				}))
-
-				cpuRequests, err := apiResource.ParseQuantity("300m")
-				Expect(err).NotTo(HaveOccurred())
-				cpuLimits, err := apiResource.ParseQuantity("500m")
-				Expect(err).NotTo(HaveOccurred())
-
-				container.Resources.Requests[corev1.ResourceCPU] = cpuRequests
-				container.Resources.Limits[corev1.ResourceCPU] = cpuLimits
+				prediction = predictor.ContainerPrediction{
+					Name:        container.Name,
+					CPURequests: "500m",
+					CPULimits:   "1000m",
+				}
+				container.Resources.Requests[corev1.ResourceCPU] = apiResource.MustParse("300m")
+				container.Resources.Limits[corev1.ResourceCPU] = apiResource.MustParse("500m")
+			})
+
+			It("sends the pod and its containers with current resources", func() {
+				var request predictor.PodRequest
+				Expect(requests).To(Receive(&request))
+				Expect(request.PodName).To(Equal("test-pod"))
+				Expect(request.PodNamespace).To(Equal("test-namespace"))
+				Expect(request.Containers).To(HaveLen(1))
+				Expect(request.Containers[0].Name).To(Equal(container.Name))
+				Expect(request.Containers[0].Resources.Requests.Cpu().String()).To(Equal("300m"))
+				Expect(request.Containers[0].Resources.Limits.Cpu().String()).To(Equal("500m"))
 			})
 
 			It("returns resources with valid CPU requests and limits", func() {
 				Expect(newResources).NotTo(BeNil())
-				Expect(newResources.Requests).NotTo(BeNil())
 				Expect(newResources.Requests).To(HaveKey(corev1.ResourceCPU))
-				cpuRequest := newResources.Requests[corev1.ResourceCPU]
-				// fmt.Fprintln(GinkgoWriter, "cpuRequest:", cpuRequest.String())
-				Expect(cpuRequest.String()).To(Equal("500m"))
-
-				Expect(newResources).NotTo(BeNil())
-				Expect(newResources.Requests).NotTo(BeNil())
+				Expect(newResources.Requests.Cpu().String()).To(Equal("500m"))
 				Expect(newResources.Limits).To(HaveKey(corev1.ResourceCPU))
-				cpuLimit := newResources.Limits[corev1.ResourceCPU]
-				// fmt.Fprintln(GinkgoWriter, "cpuLimit:", cpuLimit.String())
-				Expect(cpuLimit.String()).To(Equal("1"))
+				Expect(newResources.Limits.Cpu().String()).To(Equal("1"))
 			})
 		})
 
 		Context("when the API returns 400m requests and 600m limits", func() {
 			BeforeEach(func() {
-				mockServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-					Expect(r.URL.Path).To(Equal("/cpu"))
-					Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
-
-					var pod corev1.Pod
-					err := json.NewDecoder(r.Body).Decode(&pod)
-					Expect(err).NotTo(HaveOccurred())
-
-					prediction := resource.ResourcePrediction{
-						CPURequests: "400m",
-						CPULimits:   "600m",
-					}
-					w.Header().Set("Content-Type", "application/json")
-					json.NewEncoder(w).Encode(prediction)
-				}))
-
-				cpuRequests, err := apiResource.ParseQuantity("300m")
-				Expect(err).NotTo(HaveOccurred())
-				cpuLimits, err := apiResource.ParseQuantity("400m")
-				Expect(err).NotTo(HaveOccurred())
-
-				container.Resources.Requests[corev1.ResourceCPU] = cpuRequests
-				container.Resources.Limits[corev1.ResourceCPU] = cpuLimits
+				prediction = predictor.ContainerPrediction{
+					Name:        container.Name,
+					CPURequests: "400m",
+					CPULimits:   "600m",
+				}
+				container.Resources.Requests[corev1.ResourceCPU] = apiResource.MustParse("300m")
+				container.Resources.Limits[corev1.ResourceCPU] = apiResource.MustParse("400m")
 			})
 
 			It("returns resources with 400m CPU requests and 600m limits", func() {
 				Expect(newResources).NotTo(BeNil())
-				Expect(newResources.Requests).NotTo(BeNil())
-				Expect(newResources.Requests).To(HaveKey(corev1.ResourceCPU))
-				cpuRequest := newResources.Requests[corev1.ResourceCPU]
-				fmt.Fprintln(GinkgoWriter, "cpuRequest:", cpuRequest.String())
-				Expect(cpuRequest.String()).To(Equal("400m"))
-
-				Expect(newResources).NotTo(BeNil())
-				Expect(newResources.Limits).NotTo(BeNil())
-				Expect(newResources.Limits).To(HaveKey(corev1.ResourceCPU))
-				cpuLimit := newResources.Limits[corev1.ResourceCPU]
-				fmt.Fprintln(GinkgoWriter, "cpuLimit:", cpuLimit.String())
-				Expect(cpuLimit.String()).To(Equal("600m"))
+				Expect(newResources.Requests.Cpu().String()).To(Equal("400m"))
+				Expect(newResources.Limits.Cpu().String()).To(Equal("600m"))
 			})
 		})
 
 		Context("when the API returns 600m requests and 800m limits", func() {
 			BeforeEach(func() {
-				mockServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-					Expect(r.URL.Path).To(Equal("/cpu"))
-					Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
-
-					var pod corev1.Pod
-					err := json.NewDecoder(r.Body).Decode(&pod)
-					Expect(err).NotTo(HaveOccurred())
-
-					prediction := resource.ResourcePrediction{
-						CPURequests: "600m",
-						CPULimits:   "800m",
-					}
-					w.Header().Set("Content-Type", "application/json")
-					json.NewEncoder(w).Encode(prediction)
-				}))
-
-				cpuRequests, err := apiResource.ParseQuantity("500m")
-				Expect(err).NotTo(HaveOccurred())
-				cpuLimits, err := apiResource.ParseQuantity("600m")
-				Expect(err).NotTo(HaveOccurred())
-
-				container.Resources.Requests[corev1.ResourceCPU] = cpuRequests
-				container.Resources.Limits[corev1.ResourceCPU] = cpuLimits
+				prediction = predictor.ContainerPrediction{
+					Name:        container.Name,
+					CPURequests: "600m",
+					CPULimits:   "800m",
+				}
+				container.Resources.Requests[corev1.ResourceCPU] = apiResource.MustParse("500m")
+				container.Resources.Limits[corev1.ResourceCPU] = apiResource.MustParse("600m")
 			})
 
 			It("returns resources with 600m CPU requests and 800m limits", func() {
 				Expect(newResources).NotTo(BeNil())
-				Expect(newResources.Requests).NotTo(BeNil())
-				Expect(newResources.Requests).To(HaveKey(corev1.ResourceCPU))
-				cpuRequest := newResources.Requests[corev1.ResourceCPU]
-				fmt.Fprintln(GinkgoWriter, "cpuRequest:", cpuRequest.String())
-				Expect(cpuRequest.String()).To(Equal("600m"))
+				Expect(newResources.Requests.Cpu().String()).To(Equal("600m"))
+				Expect(newResources.Limits.Cpu().String()).To(Equal("800m"))
+			})
+		})
 
-				Expect(newResources).NotTo(BeNil())
-				Expect(newResources.Requests).NotTo(BeNil())
-				Expect(newResources.Limits).To(HaveKey(corev1.ResourceCPU))
-				cpuLimit := newResources.Limits[corev1.ResourceCPU]
-				fmt.Fprintln(GinkgoWriter, "cpuLimit:", cpuLimit.String())
-				Expect(cpuLimit.String()).To(Equal("800m"))
+		Context("when the API returns no prediction for the container", func() {
+			BeforeEach(func() {
+				prediction = predictor.ContainerPrediction{
+					Name:        "other-container",
+					CPURequests: "600m",
+					CPULimits:   "800m",
+				}
 			})
+
+			It("returns nil resources", func() {
+				Expect(newResources).To(BeNil())
+			})
+		})
+	})
+})
+
+var _ = Describe("Auto Resource Policy with fallback", func() {
+	var (
+		fallback     resource.ContainerPolicy
+		policy       resource.ContainerPolicy
+		container    *corev1.Container
+		mockServer   *httptest.Server
+		predictions  *predictor.PodPredictions
+		newResources *corev1.ResourceRequirements
+		policyName   string
+	)
+	BeforeEach(func() {
+		container = containerTemplate.DeepCopy()
+		fallback = resource.NewPercentageContainerPolicy(100)
+	})
+	JustBeforeEach(func() {
+		options := predictor.DefaultOptions()
+		options.MaxRetries = 0
+		client := predictor.NewClient(mockServer.URL, options)
+		policy = resource.NewAutoPolicyWithFallback(client, fallback)
+		predictions = predictor.NewPodPredictions(&corev1.Pod{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{*container}},
+		}, "", nil)
+		newResources, policyName = resource.ApplyPolicy(context.TODO(), policy, predictions, container)
+	})
+	AfterEach(func() {
+		mockServer.Close()
+	})
+	When("the predictor returns valid predictions", func() {
+		BeforeEach(func() {
+			mockServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(predictor.PodPrediction{
+					Containers: []predictor.ContainerPrediction{{
+						Name:        container.Name,
+						CPURequests: "700m",
+						CPULimits:   "1500m",
+					}},
+				})
+			}))
+		})
+		It("returns the predicted resources", func() {
+			Expect(newResources.Requests.Cpu().String()).To(Equal("700m"))
+			Expect(newResources.Limits.Cpu().String()).To(Equal("1500m"))
+		})
+		It("reports auto policy as applied", func() {
+			Expect(policyName).To(Equal(resource.AutoPolicyName))
+		})
+	})
+	When("the predictor fails", func() {
+		BeforeEach(func() {
+			mockServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+		})
+		It("returns the resources from the fallback policy", func() {
+			Expect(newResources.Requests.Cpu().String()).To(Equal("1"))
+			Expect(newResources.Limits.Cpu().String()).To(Equal("2"))
+		})
+		It("reports the fallback policy as applied", func() {
+			Expect(policyName).To(Equal("autoPolicy.fallback.percentageIncrease"))
+		})
+		When("the fallback policy is not defined", func() {
+			BeforeEach(func() {
+				fallback = nil
+			})
+			It("returns nil resources", func() {
+				Expect(newResources).To(BeNil())
+			})
+		})
+	})
+	When("the predictor returns unparsable quantities", func() {
+		BeforeEach(func() {
+			fallback = resource.NewFixedPolicy(apiResource.MustParse("2"), apiResource.MustParse("3"))
+			mockServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(predictor.PodPrediction{
+					Containers: []predictor.ContainerPrediction{{
+						Name:        container.Name,
+						CPURequests: "lots",
+						CPULimits:   "more",
+					}},
+				})
+			}))
+		})
+		It("returns the resources from the fallback policy", func() {
+			Expect(newResources.Requests.Cpu().String()).To(Equal("2"))
+			Expect(newResources.Limits.Cpu().String()).To(Equal("3"))
+		})
+		It("reports the fallback policy as applied", func() {
+			Expect(policyName).To(Equal("autoPolicy.fallback.fixedResources"))
+		})
+	})
+	When("the policy is combined with memory policy", func() {
+		BeforeEach(func() {
+			mockServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+		})
+		JustBeforeEach(func() {
+			multi := resource.NewMultiResourcePolicy(policy,
+				resource.NewPercentageResourcePolicy(corev1.ResourceMemory, 50))
+			_, policyName = resource.ApplyPolicy(context.TODO(), multi, predictions, container)
+		})
+		It("reports all applied policies", func() {
+			Expect(policyName).To(Equal("autoPolicy.fallback.percentageIncrease,memoryPercentageIncrease"))
 		})
 	})
 })
@@ -24,32 +24,53 @@ import (
 )
 
 type FixedPolicy struct {
-	cpuRequests apiResource.Quantity
-	cpuLimits   apiResource.Quantity
+	resource corev1.ResourceName
+	requests apiResource.Quantity
+	limits   apiResource.Quantity
 }
 
 func NewFixedPolicy(requests apiResource.Quantity, limits apiResource.Quantity) ContainerPolicy {
+	return NewFixedResourcePolicy(corev1.ResourceCPU, requests, limits)
+}
+
+// NewFixedResourcePolicy returns the policy that sets the given resource
+// to the given requests and limits values
+func NewFixedResourcePolicy(resource corev1.ResourceName, requests apiResource.Quantity, limits apiResource.Quantity) ContainerPolicy {
 	return &FixedPolicy{
-		cpuRequests: requests,
-		cpuLimits:   limits,
+		resource: resource,
+		requests: requests,
+		limits:   limits,
 	}
 }
 
+// Name returns the policy name, as in the API spec
+func (p *FixedPolicy) Name() string {
+	if p.resource == corev1.ResourceMemory {
+		return MemoryFixedPolicyName
+	}
+	return FixedPolicyName
+}
+
 func (p *FixedPolicy) Requests() apiResource.Quantity {
-	return p.cpuRequests
+	return p.requests
 }
 
 func (p *FixedPolicy) Limits() apiResource.Quantity {
-	return p.cpuLimits
+	return p.limits
+}
+
+func (p *FixedPolicy) Resource() corev1.ResourceName {
+	return p.resource
 }
 
 func (p *FixedPolicy) NewResources(ctx context.Context, container *corev1.Container) *corev1.ResourceRequirements {
-	log := ctrl.LoggerFrom(ctx).WithName("fixed-cpu-policy").
-		WithValues("newCPURequsts", p.cpuRequests.String()).
-		WithValues("newCPULimits", p.cpuLimits.String())
+	log := ctrl.LoggerFrom(ctx).WithName("fixed-resource-policy").
+		WithValues("resource", p.resource).
+		WithValues("newRequests", p.requests.String()).
+		WithValues("newLimits", p.limits.String())
 	result := container.Resources.DeepCopy()
-	p.setResource(corev1.ResourceCPU, result.Requests, p.cpuRequests, log)
-	p.setResource(corev1.ResourceCPU, result.Limits, p.cpuLimits, log)
+	p.setResource(p.resource, result.Requests, p.requests, log)
+	p.setResource(p.resource, result.Limits, p.limits, log)
 	return result
 }
 
@@ -62,7 +83,7 @@ func (p *FixedPolicy) setResource(resource corev1.ResourceName, resources corev1
 		return
 	}
 	if target.Cmp(current) < 0 {
-		log.V(2).Info("container has higher CPU requests than policy")
+		log.V(2).Info("container has higher resources than policy")
 		return
 	}
 	resources[resource] = target
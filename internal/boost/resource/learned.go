@@ -0,0 +1,447 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
+	corev1 "k8s.io/api/core/v1"
+	apiResource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// DefaultLearnedMinSamples is the default number of startups observed with
+	// a CPU target before it is compared with the other targets
+	DefaultLearnedMinSamples = 3
+	// DefaultLearnedMaxSamples is the default number of the latest startups kept
+	// per workload container
+	DefaultLearnedMaxSamples = 50
+	// MaxLearnedDecisions is the number of the latest CPU targets kept per
+	// startup-cpu-boost
+	MaxLearnedDecisions = 32
+
+	// LearnedReasonInitial is the reason of the minimum CPU target chosen when no
+	// startups were observed
+	LearnedReasonInitial = "Initial"
+	// LearnedReasonSampling is the reason of the CPU target kept until enough
+	// startups are observed with it
+	LearnedReasonSampling = "Sampling"
+	// LearnedReasonExploring is the reason of the CPU target raised above the
+	// fastest one observed so far
+	LearnedReasonExploring = "Exploring"
+	// LearnedReasonStartupDuration is the reason of the lowest CPU target which
+	// startups are as fast as the fastest ones
+	LearnedReasonStartupDuration = "StartupDuration"
+	// LearnedReasonCPUUsage is the reason of the CPU target lowered to the
+	// observed CPU usage
+	LearnedReasonCPUUsage = "CPUUsage"
+
+	// learnedDurationTolerance is the fraction by which the median startup of
+	// a CPU target may exceed the fastest one and still be considered as fast
+	learnedDurationTolerance = 0.1
+	// learnedExplorePercentage is the increase of the fastest CPU target that
+	// is tried next
+	learnedExplorePercentage = 25
+	// learnedUsageHeadroomPercentage is the increase of the observed CPU usage
+	// that gives the CPU target lowered to the usage
+	learnedUsageHeadroomPercentage = 20
+)
+
+// ResourceSample is the startup of a POD container observed by the learned
+// resource policy
+type ResourceSample struct {
+	// CPU is the boosted CPU requests of the container
+	CPU apiResource.Quantity `json:"cpu"`
+	// StartupDuration is the time from the POD creation until the POD is ready
+	StartupDuration metav1.Duration `json:"startupDuration"`
+	// CPUUsage is the CPU usage of the container when the POD became ready, if
+	// observed
+	CPUUsage *apiResource.Quantity `json:"cpuUsage,omitempty"`
+}
+
+// ResourceHistory stores the startups observed per workload container key
+type ResourceHistory interface {
+	// Samples returns the startups observed for a given key, from the oldest to
+	// the latest
+	Samples(ctx context.Context, key string) ([]ResourceSample, error)
+	// Add records a given startup observed for a given key
+	Add(ctx context.Context, key string, sample ResourceSample) error
+}
+
+// LearnedDecision is the CPU target chosen by the learned resource policy for
+// a container of a workload, with the reason of the choice
+type LearnedDecision struct {
+	Workload  string
+	Container string
+	CPU       apiResource.Quantity
+	Samples   int
+	Reason    string
+	Message   string
+	Time      time.Time
+}
+
+// LearnedDecisions keeps the latest CPU targets chosen by the learned resource
+// policies of a startup-cpu-boost, one per workload container. The oldest targets
+// are dropped when there are more than MaxLearnedDecisions.
+type LearnedDecisions struct {
+	sync.Mutex
+	decisions map[string]LearnedDecision
+}
+
+// NewLearnedDecisions returns the empty learned decisions
+func NewLearnedDecisions() *LearnedDecisions {
+	return &LearnedDecisions{
+		decisions: make(map[string]LearnedDecision),
+	}
+}
+
+// List returns the decisions ordered by the workload and container
+func (d *LearnedDecisions) List() []LearnedDecision {
+	d.Lock()
+	defer d.Unlock()
+	list := make([]LearnedDecision, 0, len(d.decisions))
+	for _, decision := range d.decisions {
+		list = append(list, decision)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Workload != list[j].Workload {
+			return list[i].Workload < list[j].Workload
+		}
+		return list[i].Container < list[j].Container
+	})
+	return list
+}
+
+// Merge records the decisions from given learned decisions that are newer than
+// the recorded ones
+func (d *LearnedDecisions) Merge(from *LearnedDecisions) {
+	if from == nil || from == d {
+		return
+	}
+	for _, decision := range from.List() {
+		d.record(decision)
+	}
+}
+
+// record records a given decision, replacing the older decision for the same
+// workload container
+func (d *LearnedDecisions) record(decision LearnedDecision) {
+	d.Lock()
+	defer d.Unlock()
+	key := SampleKey(decision.Workload, decision.Container)
+	if current, ok := d.decisions[key]; ok && current.Time.After(decision.Time) {
+		return
+	}
+	d.decisions[key] = decision
+	for len(d.decisions) > MaxLearnedDecisions {
+		oldest := ""
+		for k, v := range d.decisions {
+			if oldest == "" || v.Time.Before(d.decisions[oldest].Time) {
+				oldest = k
+			}
+		}
+		delete(d.decisions, oldest)
+	}
+}
+
+// LearnedPolicy is the CPU resource policy which CPU target is learned from the
+// previous startups of the same workload. The startups are grouped by the boosted
+// CPU and the lowest CPU target which median time to ready is within 10% of the
+// fastest one is chosen. The fastest CPU target that is also the highest one tried
+// is raised by 25%, so the policy climbs from the minimum while the startups get
+// faster. Optionally, the CPU usage observed when the PODs became ready is taken
+// into account: the CPU target which peak usage increased by 20% is lower is not
+// raised, but lowered to such usage when no lower target was tried. The CPU target
+// is bounded by the minimum and maximum.
+type LearnedPolicy struct {
+	history     ResourceHistory
+	min         apiResource.Quantity
+	max         apiResource.Quantity
+	minSamples  int
+	useCPUUsage bool
+	decisions   *LearnedDecisions
+}
+
+// NewLearnedPolicy returns the learned resource policy that reads the startups from
+// a given history and chooses the CPU target between given minimum and maximum once
+// given number of startups is observed with it. The CPU usage of the startups lowers
+// the CPU target if enabled. The chosen targets are recorded in given decisions,
+// unless nil.
+func NewLearnedPolicy(history ResourceHistory, min, max apiResource.Quantity, minSamples int,
+	useCPUUsage bool, decisions *LearnedDecisions) ContainerPolicy {
+	if minSamples <= 0 {
+		minSamples = DefaultLearnedMinSamples
+	}
+	return &LearnedPolicy{
+		history:     history,
+		min:         min,
+		max:         max,
+		minSamples:  minSamples,
+		useCPUUsage: useCPUUsage,
+		decisions:   decisions,
+	}
+}
+
+// Name returns the policy name, as in the API spec
+func (p *LearnedPolicy) Name() string {
+	return LearnedPolicyName
+}
+
+// Min returns the lowest CPU target
+func (p *LearnedPolicy) Min() apiResource.Quantity {
+	return p.min
+}
+
+// Max returns the highest CPU target
+func (p *LearnedPolicy) Max() apiResource.Quantity {
+	return p.max
+}
+
+// MinSamples returns the number of startups observed with a CPU target before
+// it is compared with the other targets
+func (p *LearnedPolicy) MinSamples() int {
+	return p.minSamples
+}
+
+// UseCPUUsage returns true if the CPU usage of the startups lowers the CPU target
+func (p *LearnedPolicy) UseCPUUsage() bool {
+	return p.useCPUUsage
+}
+
+// NewResources returns the container resources with the minimum CPU target, as the
+// startups of a container alone, without its POD workload, are not known. Use
+// ApplyPolicy to choose the CPU target for the POD workload.
+func (p *LearnedPolicy) NewResources(ctx context.Context, container *corev1.Container) *corev1.ResourceRequirements {
+	return p.newResources(container, p.min)
+}
+
+// Learn records a given startup observed for a given container of the workload with
+// a given key
+func (p *LearnedPolicy) Learn(ctx context.Context, workloadKey, containerName string, sample ResourceSample) error {
+	if p.history == nil {
+		return nil
+	}
+	if !p.useCPUUsage {
+		sample.CPUUsage = nil
+	}
+	return p.history.Add(ctx, SampleKey(workloadKey, containerName), sample)
+}
+
+// Decide returns the CPU target chosen from given startups of a workload container,
+// from the oldest to the latest
+func (p *LearnedPolicy) Decide(samples []ResourceSample) LearnedDecision {
+	if len(samples) == 0 {
+		return LearnedDecision{
+			CPU:     p.min,
+			Reason:  LearnedReasonInitial,
+			Message: "No startups observed, starting with the minimum",
+		}
+	}
+	levels := newLearnedLevels(samples)
+	latest := levels.find(samples[len(samples)-1].CPU)
+	if len(latest.durations) < p.minSamples {
+		return p.clampDecision(LearnedDecision{
+			CPU:     latest.cpu,
+			Samples: len(latest.durations),
+			Reason:  LearnedReasonSampling,
+			Message: fmt.Sprintf("Observed %d of %d startups with %s CPU", len(latest.durations),
+				p.minSamples, latest.cpu.String()),
+		})
+	}
+	sampled := levels.sampled(p.minSamples)
+	fastest := sampled[0].median()
+	for _, level := range sampled[1:] {
+		fastest = min(fastest, level.median())
+	}
+	threshold := time.Duration(float64(fastest) * (1 + learnedDurationTolerance))
+	chosen := sampled[len(sampled)-1]
+	for _, level := range sampled {
+		if level.median() <= threshold {
+			chosen = level
+			break
+		}
+	}
+	busy := !p.useCPUUsage || chosen.usage == nil ||
+		IncreaseQuantity(*chosen.usage, learnedUsageHeadroomPercentage).Cmp(chosen.cpu) >= 0
+	if busy && chosen == sampled[len(sampled)-1] && chosen.cpu.Cmp(p.max) < 0 {
+		next := *IncreaseQuantity(chosen.cpu, learnedExplorePercentage)
+		return p.clampDecision(LearnedDecision{
+			CPU:     next,
+			Samples: len(samples),
+			Reason:  LearnedReasonExploring,
+			Message: fmt.Sprintf("Median time to ready %s with %s CPU is the fastest, trying %s CPU",
+				chosen.median(), chosen.cpu.String(), next.String()),
+		})
+	}
+	if !busy && chosen == sampled[0] && chosen.cpu.Cmp(p.min) > 0 {
+		target := *IncreaseQuantity(*chosen.usage, learnedUsageHeadroomPercentage)
+		return p.clampDecision(LearnedDecision{
+			CPU:     target,
+			Samples: len(samples),
+			Reason:  LearnedReasonCPUUsage,
+			Message: fmt.Sprintf("Peak CPU usage %s with %s CPU leaves headroom, trying %s CPU",
+				chosen.usage.String(), chosen.cpu.String(), target.String()),
+		})
+	}
+	return p.clampDecision(LearnedDecision{
+		CPU:     chosen.cpu,
+		Samples: len(samples),
+		Reason:  LearnedReasonStartupDuration,
+		Message: fmt.Sprintf("Median time to ready %s with %s CPU is within %d%% of the fastest %s",
+			chosen.median(), chosen.cpu.String(), int(learnedDurationTolerance*100), fastest),
+	})
+}
+
+// applyPodPolicy returns the container resources with the CPU target chosen from the
+// startups of the POD workload and the learned policy name. The POD workload is resolved
+// with given POD predictions. When the startups cannot be read, the minimum CPU target
+// is applied.
+func (p *LearnedPolicy) applyPodPolicy(ctx context.Context, predictions *predictor.PodPredictions,
+	container *corev1.Container) (*corev1.ResourceRequirements, string) {
+	log := ctrl.LoggerFrom(ctx).WithName("learned-cpu-policy")
+	workloadKey := predictions.WorkloadKey(ctx)
+	var samples []ResourceSample
+	if p.history != nil {
+		var err error
+		if samples, err = p.history.Samples(ctx, SampleKey(workloadKey, container.Name)); err != nil {
+			log.Error(err, "failed to read learned resources", "workloadKey", workloadKey)
+		}
+	}
+	decision := p.Decide(samples)
+	decision.Workload = workloadKey
+	decision.Container = container.Name
+	decision.Time = time.Now()
+	if p.decisions != nil {
+		p.decisions.record(decision)
+	}
+	log.V(5).Info("learned CPU target chosen", "workloadKey", workloadKey, "cpu", decision.CPU.String(),
+		"reason", decision.Reason)
+	return p.newResources(container, decision.CPU), p.Name()
+}
+
+// newResources returns the container resources with the CPU requests and limits raised
+// to a given target. The CPU requests and limits that are not set or are higher than
+// the target are not changed.
+func (p *LearnedPolicy) newResources(container *corev1.Container, target apiResource.Quantity) *corev1.ResourceRequirements {
+	result := container.Resources.DeepCopy()
+	for _, resources := range []corev1.ResourceList{result.Requests, result.Limits} {
+		if current, ok := resources[corev1.ResourceCPU]; ok && current.Cmp(target) < 0 {
+			resources[corev1.ResourceCPU] = target
+		}
+	}
+	return result
+}
+
+// clampDecision returns a given decision with the CPU target bounded by the minimum
+// and maximum
+func (p *LearnedPolicy) clampDecision(decision LearnedDecision) LearnedDecision {
+	if decision.CPU.Cmp(p.min) < 0 {
+		decision.CPU = p.min
+	}
+	if decision.CPU.Cmp(p.max) > 0 {
+		decision.CPU = p.max
+	}
+	return decision
+}
+
+// SampleKey returns the key of the startups of a given container of the workload with
+// a given key
+func SampleKey(workloadKey, containerName string) string {
+	return workloadKey + "." + containerName
+}
+
+// FindLearnedPolicy returns the learned resource policy of a given container policy,
+// also when combined with the memory policy, and true if found
+func FindLearnedPolicy(policy ContainerPolicy) (*LearnedPolicy, bool) {
+	switch p := policy.(type) {
+	case *LearnedPolicy:
+		return p, true
+	case *MultiResourcePolicy:
+		for _, policy := range p.Policies() {
+			if learnedPolicy, ok := FindLearnedPolicy(policy); ok {
+				return learnedPolicy, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// learnedLevel groups the startups observed with the same boosted CPU
+type learnedLevel struct {
+	cpu       apiResource.Quantity
+	durations []time.Duration
+	usage     *apiResource.Quantity
+}
+
+// median returns the median time to ready of the startups
+func (l *learnedLevel) median() time.Duration {
+	sorted := slices.Clone(l.durations)
+	slices.Sort(sorted)
+	return sorted[(len(sorted)-1)/2]
+}
+
+// learnedLevels are the startups grouped by the boosted CPU, ordered by the CPU
+type learnedLevels []*learnedLevel
+
+// newLearnedLevels groups given startups by the boosted CPU. The CPU usage of the
+// group is the peak CPU usage of its startups.
+func newLearnedLevels(samples []ResourceSample) learnedLevels {
+	var levels learnedLevels
+	for _, sample := range samples {
+		level := levels.find(sample.CPU)
+		if level == nil {
+			level = &learnedLevel{cpu: sample.CPU}
+			levels = append(levels, level)
+		}
+		level.durations = append(level.durations, sample.StartupDuration.Duration)
+		if sample.CPUUsage != nil && (level.usage == nil || sample.CPUUsage.Cmp(*level.usage) > 0) {
+			usage := sample.CPUUsage.DeepCopy()
+			level.usage = &usage
+		}
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].cpu.Cmp(levels[j].cpu) < 0
+	})
+	return levels
+}
+
+// find returns the group of a given boosted CPU or nil if not found
+func (l learnedLevels) find(cpu apiResource.Quantity) *learnedLevel {
+	for _, level := range l {
+		if level.cpu.Cmp(cpu) == 0 {
+			return level
+		}
+	}
+	return nil
+}
+
+// sampled returns the groups with at least a given number of startups
+func (l learnedLevels) sampled(minSamples int) learnedLevels {
+	var sampled learnedLevels
+	for _, level := range l {
+		if len(level.durations) >= minSamples {
+			sampled = append(sampled, level)
+		}
+	}
+	return sampled
+}
@@ -0,0 +1,291 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resource"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apiResource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeResourceHistory struct {
+	samples map[string][]resource.ResourceSample
+	err     error
+}
+
+func (h *fakeResourceHistory) Samples(ctx context.Context, key string) ([]resource.ResourceSample, error) {
+	return h.samples[key], h.err
+}
+
+func (h *fakeResourceHistory) Add(ctx context.Context, key string, sample resource.ResourceSample) error {
+	h.samples[key] = append(h.samples[key], sample)
+	return h.err
+}
+
+// startups returns a given number of startups with a given CPU and time to ready
+func startups(cnt int, cpu string, startupDuration time.Duration) []resource.ResourceSample {
+	samples := make([]resource.ResourceSample, 0, cnt)
+	for i := 0; i < cnt; i++ {
+		samples = append(samples, resource.ResourceSample{
+			CPU:             apiResource.MustParse(cpu),
+			StartupDuration: metav1.Duration{Duration: startupDuration},
+		})
+	}
+	return samples
+}
+
+// withUsage returns given startups with a given CPU usage
+func withUsage(samples []resource.ResourceSample, usage string) []resource.ResourceSample {
+	for i := range samples {
+		quantity := apiResource.MustParse(usage)
+		samples[i].CPUUsage = &quantity
+	}
+	return samples
+}
+
+var _ = Describe("Learned Resource Policy", func() {
+	var (
+		history     *fakeResourceHistory
+		decisions   *resource.LearnedDecisions
+		useCPUUsage bool
+		policy      *resource.LearnedPolicy
+	)
+	BeforeEach(func() {
+		history = &fakeResourceHistory{samples: make(map[string][]resource.ResourceSample)}
+		decisions = resource.NewLearnedDecisions()
+		useCPUUsage = false
+	})
+	JustBeforeEach(func() {
+		var ok bool
+		policy, ok = resource.NewLearnedPolicy(history, apiResource.MustParse("1"), apiResource.MustParse("4"),
+			3, useCPUUsage, decisions).(*resource.LearnedPolicy)
+		Expect(ok).To(BeTrue())
+	})
+	It("has the learned policy name", func() {
+		Expect(policy.Name()).To(Equal(resource.LearnedPolicyName))
+	})
+	Describe("Decides the CPU target", func() {
+		var (
+			samples  []resource.ResourceSample
+			decision resource.LearnedDecision
+		)
+		BeforeEach(func() {
+			samples = nil
+		})
+		JustBeforeEach(func() {
+			decision = policy.Decide(samples)
+		})
+		When("no startups were observed", func() {
+			It("starts with the minimum", func() {
+				Expect(decision.CPU.String()).To(Equal("1"))
+				Expect(decision.Reason).To(Equal(resource.LearnedReasonInitial))
+			})
+		})
+		When("not enough startups were observed with the latest target", func() {
+			BeforeEach(func() {
+				samples = startups(2, "1", time.Minute)
+			})
+			It("keeps the latest target", func() {
+				Expect(decision.CPU.String()).To(Equal("1"))
+				Expect(decision.Samples).To(Equal(2))
+				Expect(decision.Reason).To(Equal(resource.LearnedReasonSampling))
+				Expect(decision.Message).To(Equal("Observed 2 of 3 startups with 1 CPU"))
+			})
+		})
+		When("the latest target is above the maximum", func() {
+			BeforeEach(func() {
+				samples = startups(1, "8", time.Minute)
+			})
+			It("bounds the target by the maximum", func() {
+				Expect(decision.CPU.String()).To(Equal("4"))
+				Expect(decision.Reason).To(Equal(resource.LearnedReasonSampling))
+			})
+		})
+		When("the highest target is the fastest", func() {
+			BeforeEach(func() {
+				samples = append(startups(3, "1", time.Minute), startups(3, "1250m", 40*time.Second)...)
+			})
+			It("tries the higher target", func() {
+				Expect(decision.CPU.String()).To(Equal("1570m"))
+				Expect(decision.Samples).To(Equal(6))
+				Expect(decision.Reason).To(Equal(resource.LearnedReasonExploring))
+			})
+		})
+		When("the higher target is not faster", func() {
+			BeforeEach(func() {
+				samples = append(startups(3, "1", time.Minute), startups(3, "1250m", 40*time.Second)...)
+				samples = append(samples, startups(3, "1570m", 41*time.Second)...)
+			})
+			It("chooses the lowest target as fast as the fastest", func() {
+				Expect(decision.CPU.String()).To(Equal("1250m"))
+				Expect(decision.Reason).To(Equal(resource.LearnedReasonStartupDuration))
+				Expect(decision.Message).To(Equal("Median time to ready 40s with 1250m CPU is within 10% of the fastest 40s"))
+			})
+		})
+		When("the fastest target is the maximum", func() {
+			BeforeEach(func() {
+				samples = append(startups(3, "2", time.Minute), startups(3, "4", 30*time.Second)...)
+			})
+			It("keeps the maximum", func() {
+				Expect(decision.CPU.String()).To(Equal("4"))
+				Expect(decision.Reason).To(Equal(resource.LearnedReasonStartupDuration))
+			})
+		})
+		When("the CPU usage is used", func() {
+			BeforeEach(func() {
+				useCPUUsage = true
+			})
+			When("the usage is lower than the target", func() {
+				BeforeEach(func() {
+					samples = withUsage(startups(3, "2", time.Minute), "1200m")
+				})
+				It("lowers the target to the usage", func() {
+					Expect(decision.CPU.String()).To(Equal("1440m"))
+					Expect(decision.Reason).To(Equal(resource.LearnedReasonCPUUsage))
+				})
+			})
+			When("the usage is close to the target", func() {
+				BeforeEach(func() {
+					samples = withUsage(startups(3, "2", time.Minute), "1900m")
+				})
+				It("tries the higher target", func() {
+					Expect(decision.CPU.String()).To(Equal("2500m"))
+					Expect(decision.Reason).To(Equal(resource.LearnedReasonExploring))
+				})
+			})
+			When("the lower target was slower", func() {
+				BeforeEach(func() {
+					samples = append(startups(3, "1440m", 2*time.Minute), withUsage(startups(3, "2", time.Minute), "1200m")...)
+				})
+				It("keeps the target", func() {
+					Expect(decision.CPU.String()).To(Equal("2"))
+					Expect(decision.Reason).To(Equal(resource.LearnedReasonStartupDuration))
+				})
+			})
+		})
+		When("the CPU usage is not used", func() {
+			BeforeEach(func() {
+				samples = withUsage(startups(3, "2", time.Minute), "1200m")
+			})
+			It("ignores the usage", func() {
+				Expect(decision.CPU.String()).To(Equal("2500m"))
+				Expect(decision.Reason).To(Equal(resource.LearnedReasonExploring))
+			})
+		})
+	})
+	Describe("Applies the policy to the POD container", func() {
+		var (
+			pod          *corev1.Pod
+			container    *corev1.Container
+			newResources *corev1.ResourceRequirements
+			policyName   string
+		)
+		BeforeEach(func() {
+			container = containerTemplate.DeepCopy()
+			pod = &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{*container}}}
+		})
+		JustBeforeEach(func() {
+			newResources, policyName = resource.ApplyPolicy(context.TODO(), policy,
+				predictor.NewPodPredictions(pod, "", nil), container)
+		})
+		It("raises the CPU requests to the minimum and keeps the higher limits", func() {
+			Expect(policyName).To(Equal(resource.LearnedPolicyName))
+			Expect(newResources.Requests.Cpu().String()).To(Equal("1"))
+			Expect(newResources.Limits.Cpu().String()).To(Equal("1"))
+		})
+		It("records the decision", func() {
+			list := decisions.List()
+			Expect(list).To(HaveLen(1))
+			Expect(list[0].Workload).To(Equal(predictor.WorkloadKey(nil, pod)))
+			Expect(list[0].Container).To(Equal(container.Name))
+			Expect(list[0].Reason).To(Equal(resource.LearnedReasonInitial))
+			Expect(list[0].Time).NotTo(BeZero())
+		})
+		When("the startups of the workload container were observed", func() {
+			BeforeEach(func() {
+				key := resource.SampleKey(predictor.WorkloadKey(nil, pod), container.Name)
+				history.samples[key] = startups(2, "2", time.Minute)
+			})
+			It("raises the CPU requests and limits to the learned target", func() {
+				Expect(newResources.Requests.Cpu().String()).To(Equal("2"))
+				Expect(newResources.Limits.Cpu().String()).To(Equal("2"))
+			})
+		})
+		When("the startups cannot be read", func() {
+			BeforeEach(func() {
+				history.err = errors.New("history error")
+			})
+			It("applies the minimum", func() {
+				Expect(newResources.Requests.Cpu().String()).To(Equal("1"))
+			})
+		})
+	})
+	Describe("Learns the startup", func() {
+		var sample resource.ResourceSample
+		BeforeEach(func() {
+			sample = withUsage(startups(1, "2", time.Minute), "1")[0]
+		})
+		JustBeforeEach(func() {
+			Expect(policy.Learn(context.TODO(), "deployment.demo.0123", "main", sample)).To(Succeed())
+		})
+		It("records the startup without the usage", func() {
+			Expect(history.samples).To(HaveKey("deployment.demo.0123.main"))
+			Expect(history.samples["deployment.demo.0123.main"][0].CPUUsage).To(BeNil())
+		})
+		When("the CPU usage is used", func() {
+			BeforeEach(func() {
+				useCPUUsage = true
+			})
+			It("records the startup with the usage", func() {
+				Expect(history.samples["deployment.demo.0123.main"][0].CPUUsage.String()).To(Equal("1"))
+			})
+		})
+	})
+	It("is found in the multi resource policy", func() {
+		multi := resource.NewMultiResourcePolicy(policy, resource.NewPercentageResourcePolicy(corev1.ResourceMemory, 20))
+		found, ok := resource.FindLearnedPolicy(multi)
+		Expect(ok).To(BeTrue())
+		Expect(found).To(BeIdenticalTo(policy))
+		_, ok = resource.FindLearnedPolicy(resource.NewPercentageContainerPolicy(20))
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("Learned decisions", func() {
+	It("keeps the latest decisions ordered by the workload and container", func() {
+		decisions := resource.NewLearnedDecisions()
+		policy := resource.NewLearnedPolicy(nil, apiResource.MustParse("1"), apiResource.MustParse("2"), 0, false, decisions)
+		for i := 0; i <= resource.MaxLearnedDecisions; i++ {
+			container := corev1.Container{Name: fmt.Sprintf("container-%02d", i)}
+			pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{container}}}
+			resource.ApplyPolicy(context.TODO(), policy, predictor.NewPodPredictions(pod, "", nil), &container)
+		}
+		list := decisions.List()
+		Expect(list).To(HaveLen(resource.MaxLearnedDecisions))
+		Expect(list[0].Container).To(Equal("container-01"))
+		merged := resource.NewLearnedDecisions()
+		merged.Merge(decisions)
+		Expect(merged.List()).To(Equal(list))
+	})
+})
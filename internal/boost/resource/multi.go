@@ -0,0 +1,76 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// MultiResourcePolicy combines the policies for different resources,
+// i.e. CPU and memory, of a single container
+type MultiResourcePolicy struct {
+	policies []ContainerPolicy
+}
+
+// NewMultiResourcePolicy returns the policy that applies the given policies
+// one after another
+func NewMultiResourcePolicy(policies ...ContainerPolicy) ContainerPolicy {
+	return &MultiResourcePolicy{
+		policies: policies,
+	}
+}
+
+// Policies returns the combined policies
+func (p *MultiResourcePolicy) Policies() []ContainerPolicy {
+	return p.policies
+}
+
+// Name returns the names of the combined policies separated by comma
+func (p *MultiResourcePolicy) Name() string {
+	names := make([]string, 0, len(p.policies))
+	for _, policy := range p.policies {
+		names = append(names, policy.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+// NewResources returns the container resources calculated by the subsequent
+// policies. The policy that fails to calculate the resources is skipped.
+func (p *MultiResourcePolicy) NewResources(ctx context.Context, container *corev1.Container) *corev1.ResourceRequirements {
+	predictions := predictor.NewPodPredictions(&corev1.Pod{
+		Spec: corev1.PodSpec{Containers: []corev1.Container{*container}},
+	}, "", nil)
+	resources, _ := p.applyPodPolicy(ctx, predictions, container)
+	return resources
+}
+
+// applyPodPolicy returns the container resources calculated by the subsequent policies,
+// sharing given POD predictions, and the names of the applied policies separated by comma
+func (p *MultiResourcePolicy) applyPodPolicy(ctx context.Context, predictions *predictor.PodPredictions,
+	container *corev1.Container) (*corev1.ResourceRequirements, string) {
+	current := container.DeepCopy()
+	var names []string
+	for _, policy := range p.policies {
+		if resources, name := ApplyPolicy(ctx, policy, predictions, current); resources != nil {
+			current.Resources = *resources
+			names = append(names, name)
+		}
+	}
+	return &current.Resources, strings.Join(names, ",")
+}
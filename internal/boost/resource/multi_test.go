@@ -0,0 +1,67 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource_test
+
+import (
+	"context"
+
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resource"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apiResource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+var _ = Describe("MultiResourcePolicy", func() {
+	var (
+		container    *corev1.Container
+		policy       resource.ContainerPolicy
+		newResources *corev1.ResourceRequirements
+	)
+	BeforeEach(func() {
+		container = containerTemplate.DeepCopy()
+		container.Resources.Requests[corev1.ResourceMemory] = apiResource.MustParse("100Mi")
+		container.Resources.Limits[corev1.ResourceMemory] = apiResource.MustParse("200Mi")
+		policy = resource.NewMultiResourcePolicy(
+			resource.NewPercentageContainerPolicy(100),
+			resource.NewFixedResourcePolicy(corev1.ResourceMemory,
+				apiResource.MustParse("500Mi"), apiResource.MustParse("1Gi")),
+		)
+	})
+	JustBeforeEach(func() {
+		newResources = policy.NewResources(context.TODO(), container)
+	})
+	It("returns resources with a valid CPU requests", func() {
+		qty := newResources.Requests[corev1.ResourceCPU]
+		Expect(qty.String()).To(Equal("1"))
+	})
+	It("returns resources with a valid CPU limits", func() {
+		qty := newResources.Limits[corev1.ResourceCPU]
+		Expect(qty.String()).To(Equal("2"))
+	})
+	It("returns resources with a valid memory requests", func() {
+		qty := newResources.Requests[corev1.ResourceMemory]
+		Expect(qty.String()).To(Equal("500Mi"))
+	})
+	It("returns resources with a valid memory limits", func() {
+		qty := newResources.Limits[corev1.ResourceMemory]
+		Expect(qty.String()).To(Equal("1Gi"))
+	})
+	It("does not modify the container", func() {
+		qty := container.Resources.Requests[corev1.ResourceCPU]
+		Expect(qty.String()).To(Equal("500m"))
+	})
+})
@@ -23,33 +23,54 @@ import (
 )
 
 type PercentageContainerPolicy struct {
+	resource   corev1.ResourceName
 	percentage int64
 }
 
 func NewPercentageContainerPolicy(percentage int64) ContainerPolicy {
+	return NewPercentageResourcePolicy(corev1.ResourceCPU, percentage)
+}
+
+// NewPercentageResourcePolicy returns the policy that increases the given
+// resource by the given percentage value
+func NewPercentageResourcePolicy(resource corev1.ResourceName, percentage int64) ContainerPolicy {
 	return &PercentageContainerPolicy{
+		resource:   resource,
 		percentage: percentage,
 	}
 }
 
+// Name returns the policy name, as in the API spec
+func (p *PercentageContainerPolicy) Name() string {
+	if p.resource == corev1.ResourceMemory {
+		return MemoryPercentagePolicyName
+	}
+	return PercentagePolicyName
+}
+
 func (p *PercentageContainerPolicy) Percentage() int64 {
 	return p.percentage
 }
 
+func (p *PercentageContainerPolicy) Resource() corev1.ResourceName {
+	return p.resource
+}
+
 func (p *PercentageContainerPolicy) NewResources(ctx context.Context, container *corev1.Container) *corev1.ResourceRequirements {
 	result := container.Resources.DeepCopy()
-	p.increaseResource(corev1.ResourceCPU, result.Requests)
-	p.increaseResource(corev1.ResourceCPU, result.Limits)
+	p.increaseResource(p.resource, result.Requests)
+	p.increaseResource(p.resource, result.Limits)
 	return result
 }
 
 func (p *PercentageContainerPolicy) increaseResource(resource corev1.ResourceName, resources corev1.ResourceList) {
 	if quantity, ok := resources[resource]; ok {
-		resources[resource] = *increaseQuantity(quantity, p.percentage)
+		resources[resource] = *IncreaseQuantity(quantity, p.percentage)
 	}
 }
 
-func increaseQuantity(quantity apiResource.Quantity, incPerc int64) *apiResource.Quantity {
+// IncreaseQuantity returns a given quantity increased by a given percentage value
+func IncreaseQuantity(quantity apiResource.Quantity, incPerc int64) *apiResource.Quantity {
 	quantityDec := quantity.AsDec()
 	decPerc := inf.NewDec(100+incPerc, 2)
 	decResult := &inf.Dec{}
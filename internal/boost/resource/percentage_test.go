@@ -70,4 +70,27 @@ var _ = Describe("PercentageResourcePolicy", func() {
 			Expect(newResources.Limits).To(HaveLen(0))
 		})
 	})
+	When("The policy is for memory resource", func() {
+		JustBeforeEach(func() {
+			policy = resource.NewPercentageResourcePolicy(corev1.ResourceMemory, percentage)
+			newResources = policy.NewResources(context.TODO(), container)
+		})
+		BeforeEach(func() {
+			container = containerTemplate.DeepCopy()
+			container.Resources.Requests[corev1.ResourceMemory] = apiResource.MustParse("100Mi")
+			container.Resources.Limits[corev1.ResourceMemory] = apiResource.MustParse("200Mi")
+		})
+		It("returns resources with a valid memory requests", func() {
+			qty := newResources.Requests[corev1.ResourceMemory]
+			Expect(qty.String()).To(Equal("120Mi"))
+		})
+		It("returns resources with a valid memory limits", func() {
+			qty := newResources.Limits[corev1.ResourceMemory]
+			Expect(qty.String()).To(Equal("240Mi"))
+		})
+		It("returns resources with unchanged CPU resources", func() {
+			Expect(newResources.Requests[corev1.ResourceCPU]).To(Equal(container.Resources.Requests[corev1.ResourceCPU]))
+			Expect(newResources.Limits[corev1.ResourceCPU]).To(Equal(container.Resources.Limits[corev1.ResourceCPU]))
+		})
+	})
 })
@@ -18,9 +18,40 @@ package resource
 import (
 	"context"
 
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
 	corev1 "k8s.io/api/core/v1"
 )
 
+const (
+	FixedPolicyName            = "fixedResources"
+	MemoryFixedPolicyName      = "memoryFixedResources"
+	PercentagePolicyName       = "percentageIncrease"
+	MemoryPercentagePolicyName = "memoryPercentageIncrease"
+	AutoPolicyName             = "autoPolicy"
+	LearnedPolicyName          = "learnedResources"
+)
+
 type ContainerPolicy interface {
 	NewResources(ctx context.Context, container *corev1.Container) *corev1.ResourceRequirements
+	// Name returns the policy name, as in the API spec
+	Name() string
+}
+
+// podPolicy is implemented by the policies which calculate the container resources
+// from the prediction of the whole POD
+type podPolicy interface {
+	applyPodPolicy(ctx context.Context, predictions *predictor.PodPredictions,
+		container *corev1.Container) (*corev1.ResourceRequirements, string)
+}
+
+// ApplyPolicy returns the resources of a given container calculated by a given policy
+// and the name of the policy that was actually applied. The policies that require
+// a prediction share given POD predictions, so the predictor is called once per POD.
+// The resources are nil when the policy fails to calculate them.
+func ApplyPolicy(ctx context.Context, policy ContainerPolicy, predictions *predictor.PodPredictions,
+	container *corev1.Container) (*corev1.ResourceRequirements, string) {
+	if podPolicy, ok := policy.(podPolicy); ok {
+		return podPolicy.applyPodPolicy(ctx, predictions, container)
+	}
+	return policy.NewResources(ctx, container), policy.Name()
 }
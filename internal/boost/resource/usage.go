@@ -0,0 +1,80 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apiResource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PodMetricsGVK is the kind of the POD metrics served by the metrics.k8s.io API,
+// i.e. by the metrics-server
+var PodMetricsGVK = schema.GroupVersionKind{Group: "metrics.k8s.io", Version: "v1beta1", Kind: "PodMetrics"}
+
+// CPUUsageReader reads the CPU usage of POD containers
+type CPUUsageReader interface {
+	// ContainerCPUUsage returns the CPU usage of the containers of a given POD by
+	// the container name
+	ContainerCPUUsage(ctx context.Context, pod *corev1.Pod) (map[string]apiResource.Quantity, error)
+}
+
+// MetricsCPUUsageReader reads the CPU usage of POD containers from the metrics.k8s.io
+// API. Any implementation of the API can serve the metrics, i.e. the metrics-server
+// or a local stand-in. The metrics are read as unstructured objects, which are not
+// cached by the controller client.
+type MetricsCPUUsageReader struct {
+	reader client.Reader
+}
+
+// NewMetricsCPUUsageReader returns the reader of the CPU usage that reads the POD
+// metrics with a given reader
+func NewMetricsCPUUsageReader(reader client.Reader) *MetricsCPUUsageReader {
+	return &MetricsCPUUsageReader{reader: reader}
+}
+
+// ContainerCPUUsage returns the CPU usage of the containers of a given POD by the
+// container name. The containers with invalid usage are skipped.
+func (r *MetricsCPUUsageReader) ContainerCPUUsage(ctx context.Context, pod *corev1.Pod) (map[string]apiResource.Quantity, error) {
+	metrics := &unstructured.Unstructured{}
+	metrics.SetGroupVersionKind(PodMetricsGVK)
+	if err := r.reader.Get(ctx, client.ObjectKeyFromObject(pod), metrics); err != nil {
+		return nil, fmt.Errorf("failed to get pod metrics: %w", err)
+	}
+	containers, _, err := unstructured.NestedSlice(metrics.Object, "containers")
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode pod metrics: %w", err)
+	}
+	usage := make(map[string]apiResource.Quantity, len(containers))
+	for _, container := range containers {
+		fields, ok := container.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(fields, "name")
+		cpu, _, _ := unstructured.NestedString(fields, "usage", "cpu")
+		quantity, err := apiResource.ParseQuantity(cpu)
+		if name == "" || err != nil {
+			continue
+		}
+		usage[name] = quantity
+	}
+	return usage, nil
+}
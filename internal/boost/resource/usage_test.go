@@ -0,0 +1,81 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource_test
+
+import (
+	"context"
+
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resource"
+	"github.com/google/kube-startup-cpu-boost/internal/mock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apiResource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Metrics CPU usage reader", func() {
+	var (
+		mockClient *mock.MockClient
+		getErr     error
+		pod        *corev1.Pod
+		usage      map[string]apiResource.Quantity
+		err        error
+	)
+	BeforeEach(func() {
+		mockClient = mock.NewMockClient(gomock.NewController(GinkgoT()))
+		getErr = nil
+		pod = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "demo-7f6d", Namespace: "demo"}}
+		mockClient.EXPECT().Get(gomock.Any(), gomock.Eq(types.NamespacedName{Namespace: "demo", Name: "demo-7f6d"}),
+			gomock.AssignableToTypeOf(&unstructured.Unstructured{})).
+			DoAndReturn(func(ctx context.Context, key types.NamespacedName, obj client.Object, opts ...client.GetOption) error {
+				metrics := obj.(*unstructured.Unstructured)
+				Expect(metrics.GroupVersionKind()).To(Equal(resource.PodMetricsGVK))
+				if getErr != nil {
+					return getErr
+				}
+				metrics.Object["containers"] = []interface{}{
+					map[string]interface{}{"name": "main", "usage": map[string]interface{}{"cpu": "1250m", "memory": "64Mi"}},
+					map[string]interface{}{"name": "sidecar", "usage": map[string]interface{}{"cpu": "invalid"}},
+				}
+				return nil
+			})
+	})
+	JustBeforeEach(func() {
+		usage, err = resource.NewMetricsCPUUsageReader(mockClient).ContainerCPUUsage(context.TODO(), pod)
+	})
+	It("returns the CPU usage of the containers", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(usage).To(HaveLen(1))
+		Expect(usage).To(HaveKey("main"))
+		cpu := usage["main"]
+		Expect(cpu.String()).To(Equal("1250m"))
+	})
+	When("the POD metrics are not available", func() {
+		BeforeEach(func() {
+			getErr = apierrors.NewNotFound(schema.GroupResource{Group: "metrics.k8s.io", Resource: "pods"}, "demo-7f6d")
+		})
+		It("returns error", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
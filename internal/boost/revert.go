@@ -0,0 +1,108 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boost
+
+import (
+	"context"
+	"fmt"
+
+	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resize"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	MemoryLimitsBoostedEventReason  = "BoostMemoryLimitsNotReverted"
+	MemoryLimitsBoostedEventMessage = "Memory limits boosted by StartupCPUBoost %s were left boosted: %s"
+)
+
+// RevertPodResources updates POD's container resource requests and limits to their
+// original values using the data from StartupCPUBoost annotation. The POD is resized
+// in place with a given resize strategy.
+//
+// The memory limits are reverted with a separate update, once the remaining resources
+// are reverted. The boost label and the memory limits in the boost annotation are kept
+// on the POD until the memory limits are reverted, so the reversion that failed is
+// retried when the function is called with the updated POD. The kubelet does not
+// decrease memory limits below the current container memory usage, thus such update
+// may be refused. When the update is refused by the API server, the memory limits are
+// left boosted, the POD is considered as reverted and the warning event is recorded
+// with a given recorder, if not nil. The memory limits are left boosted in the same way
+// when the memory resize policy of any of the containers requires the container restart,
+// so the reversion does not restart the containers. The given POD reflects the resources
+// left on the POD.
+func RevertPodResources(ctx context.Context, resizer resize.Strategy, recorder record.EventRecorder,
+	pod *corev1.Pod) error {
+	annotation, err := bpod.BoostAnnotationFromPod(pod)
+	if err != nil {
+		return fmt.Errorf("failed to update pod spec: %s", err)
+	}
+	original := pod.DeepCopy()
+	if err := bpod.RevertResourceBoost(pod); err != nil {
+		return fmt.Errorf("failed to update pod spec: %s", err)
+	}
+	// the remaining resources are already reverted when the reversion is retried
+	if !equality.Semantic.DeepEqual(original, pod) {
+		if err := resizer.Resize(ctx, original, pod); err != nil {
+			return err
+		}
+	}
+	if !bpod.HasMemoryLimitsBoost(pod, annotation) {
+		return nil
+	}
+	if name := bpod.MemoryLimitsResizeRequiresRestart(pod, annotation); name != "" {
+		return leaveMemoryLimitsBoosted(ctx, resizer, recorder, pod,
+			fmt.Errorf("memory resize of container %s requires restart", name))
+	}
+	original = pod.DeepCopy()
+	if _, err := bpod.RevertMemoryLimits(pod, annotation); err != nil {
+		original.DeepCopyInto(pod)
+		return leaveMemoryLimitsBoosted(ctx, resizer, recorder, pod, err)
+	}
+	bpod.RemoveResourceBoost(pod)
+	if err := resizer.Resize(ctx, original, pod); err != nil {
+		original.DeepCopyInto(pod)
+		if !apierrors.IsInvalid(err) && !apierrors.IsForbidden(err) {
+			return fmt.Errorf("failed to revert memory limits: %w", err)
+		}
+		return leaveMemoryLimitsBoosted(ctx, resizer, recorder, pod, err)
+	}
+	return nil
+}
+
+// leaveMemoryLimitsBoosted removes the boost label and annotation from a given POD
+// which memory limits cannot be reverted, so the POD is no longer considered boosted,
+// and records the warning event with the reason of a given error
+func leaveMemoryLimitsBoosted(ctx context.Context, resizer resize.Strategy, recorder record.EventRecorder,
+	pod *corev1.Pod, reason error) error {
+	original := pod.DeepCopy()
+	boostName := pod.Labels[bpod.BoostLabelKey]
+	bpod.RemoveResourceBoost(pod)
+	if err := resizer.Resize(ctx, original, pod); err != nil {
+		original.DeepCopyInto(pod)
+		return err
+	}
+	ctrl.LoggerFrom(ctx).WithValues("pod", pod.Name).
+		Error(reason, "failed to revert memory limits, leaving them boosted")
+	if recorder != nil {
+		recorder.Eventf(pod, corev1.EventTypeWarning, MemoryLimitsBoostedEventReason,
+			MemoryLimitsBoostedEventMessage, boostName, reason)
+	}
+	return nil
+}
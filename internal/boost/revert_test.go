@@ -0,0 +1,212 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package boost_test
+
+import (
+	"context"
+	"errors"
+
+	cpuboost "github.com/google/kube-startup-cpu-boost/internal/boost"
+	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resize"
+	"github.com/google/kube-startup-cpu-boost/internal/mock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apiResource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("RevertPodResources", func() {
+	var (
+		pod        *corev1.Pod
+		err        error
+		mockCtrl   *gomock.Controller
+		mockClient *mock.MockClient
+		updates    []corev1.Pod
+		updateCall *gomock.Call
+		recorder   *record.FakeRecorder
+	)
+	BeforeEach(func() {
+		pod = podTemplate.DeepCopy()
+		updates = nil
+		recorder = record.NewFakeRecorder(10)
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock.NewMockClient(mockCtrl)
+		updateCall = mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				updates = append(updates, *obj.(*corev1.Pod).DeepCopy())
+				return nil
+			})
+	})
+	JustBeforeEach(func() {
+		err = cpuboost.RevertPodResources(context.TODO(), resize.NewPatchStrategy(mockClient), recorder, pod)
+	})
+	When("POD has no boosted memory", func() {
+		BeforeEach(func() {
+			updateCall.Times(1)
+		})
+		It("does not error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("updates the POD once", func() {
+			Expect(updates).To(HaveLen(1))
+			Expect(updates[0].Annotations).NotTo(HaveKey(bpod.BoostAnnotationKey))
+		})
+	})
+	When("POD has boosted memory", func() {
+		BeforeEach(func() {
+			annot := *annotTemplate
+			annot.InitMemoryRequests = map[string]string{"container-one": "100Mi"}
+			annot.InitMemoryLimits = map[string]string{"container-one": "200Mi"}
+			pod.Annotations[bpod.BoostAnnotationKey] = annot.ToJSON()
+			pod.Spec.Containers[0].Resources.Requests[corev1.ResourceMemory] = apiResource.MustParse("150Mi")
+			pod.Spec.Containers[0].Resources.Limits[corev1.ResourceMemory] = apiResource.MustParse("300Mi")
+		})
+		When("memory limits update succeeds", func() {
+			BeforeEach(func() {
+				updateCall.Times(2)
+			})
+			It("does not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("reverts memory requests before memory limits", func() {
+				Expect(updates).To(HaveLen(2))
+				memReq := updates[0].Spec.Containers[0].Resources.Requests[corev1.ResourceMemory]
+				memLimit := updates[0].Spec.Containers[0].Resources.Limits[corev1.ResourceMemory]
+				Expect(memReq.String()).To(Equal("100Mi"))
+				Expect(memLimit.String()).To(Equal("300Mi"))
+				memLimit = updates[1].Spec.Containers[0].Resources.Limits[corev1.ResourceMemory]
+				Expect(memLimit.String()).To(Equal("200Mi"))
+			})
+		})
+		When("memory limits update succeeds", func() {
+			BeforeEach(func() {
+				updateCall.Times(2)
+			})
+			It("keeps the boost label until memory limits are reverted", func() {
+				Expect(updates).To(HaveLen(2))
+				Expect(updates[0].Labels).To(HaveKey(bpod.BoostLabelKey))
+			})
+			It("removes the boost label and annotation", func() {
+				Expect(updates).To(HaveLen(2))
+				Expect(updates[1].Labels).NotTo(HaveKey(bpod.BoostLabelKey))
+				Expect(updates[1].Annotations).NotTo(HaveKey(bpod.BoostAnnotationKey))
+			})
+		})
+		When("memory limits update fails", func() {
+			BeforeEach(func() {
+				updateCall.Times(1)
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(errors.New("update failed")).Times(1)
+			})
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+			It("keeps the boost label and memory limits in the annotation", func() {
+				Expect(pod.Labels).To(HaveKey(bpod.BoostLabelKey))
+				annot, err := bpod.BoostAnnotationFromPod(pod)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(annot.InitMemoryLimits).To(HaveKeyWithValue("container-one", "200Mi"))
+			})
+			It("does not record an event", func() {
+				Expect(recorder.Events).To(BeEmpty())
+			})
+		})
+		When("memory limits update is refused", func() {
+			BeforeEach(func() {
+				updateCall.Times(1)
+				refused := apierrors.NewInvalid(schema.GroupKind{Kind: "Pod"}, pod.Name, field.ErrorList{
+					field.Forbidden(field.NewPath("spec"), "memory limits cannot be decreased"),
+				})
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(refused).Times(1)
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+						updates = append(updates, *obj.(*corev1.Pod).DeepCopy())
+						return nil
+					}).Times(1)
+			})
+			It("does not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("removes the boost label and annotation leaving memory limits boosted", func() {
+				Expect(updates).To(HaveLen(2))
+				Expect(updates[1].Labels).NotTo(HaveKey(bpod.BoostLabelKey))
+				Expect(updates[1].Annotations).NotTo(HaveKey(bpod.BoostAnnotationKey))
+				memLimit := updates[1].Spec.Containers[0].Resources.Limits[corev1.ResourceMemory]
+				Expect(memLimit.String()).To(Equal("300Mi"))
+			})
+			It("records a warning event", func() {
+				Expect(recorder.Events).To(HaveLen(1))
+				event := <-recorder.Events
+				Expect(event).To(ContainSubstring(cpuboost.MemoryLimitsBoostedEventReason))
+			})
+		})
+		When("memory resize policy requires container restart", func() {
+			BeforeEach(func() {
+				pod.Spec.Containers[0].ResizePolicy = []corev1.ContainerResizePolicy{
+					{ResourceName: corev1.ResourceMemory, RestartPolicy: corev1.RestartContainer},
+				}
+				updateCall.Times(2)
+			})
+			It("does not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("removes the boost label and annotation leaving memory limits boosted", func() {
+				Expect(updates).To(HaveLen(2))
+				Expect(updates[1].Labels).NotTo(HaveKey(bpod.BoostLabelKey))
+				Expect(updates[1].Annotations).NotTo(HaveKey(bpod.BoostAnnotationKey))
+				memLimit := updates[1].Spec.Containers[0].Resources.Limits[corev1.ResourceMemory]
+				Expect(memLimit.String()).To(Equal("300Mi"))
+			})
+			It("records a warning event", func() {
+				Expect(recorder.Events).To(HaveLen(1))
+				event := <-recorder.Events
+				Expect(event).To(ContainSubstring(cpuboost.MemoryLimitsBoostedEventReason))
+				Expect(event).To(ContainSubstring("requires restart"))
+			})
+		})
+		When("reversion of memory limits is retried", func() {
+			BeforeEach(func() {
+				annot := *annotTemplate
+				annot.InitCPURequests = nil
+				annot.InitCPULimits = nil
+				annot.InitMemoryLimits = map[string]string{"container-one": "200Mi"}
+				pod.Annotations[bpod.BoostAnnotationKey] = annot.ToJSON()
+				updateCall.Times(1)
+			})
+			It("updates the POD once", func() {
+				Expect(updates).To(HaveLen(1))
+				memLimit := updates[0].Spec.Containers[0].Resources.Limits[corev1.ResourceMemory]
+				Expect(memLimit.String()).To(Equal("200Mi"))
+				Expect(updates[0].Labels).NotTo(HaveKey(bpod.BoostLabelKey))
+			})
+		})
+	})
+	When("POD update fails", func() {
+		BeforeEach(func() {
+			updateCall.Return(errors.New("update failed")).Times(1)
+		})
+		It("errors", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
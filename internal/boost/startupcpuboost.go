@@ -24,11 +24,16 @@ import (
 	"github.com/go-logr/logr"
 	autoscaling "github.com/google/kube-startup-cpu-boost/api/v1alpha1"
 	"github.com/google/kube-startup-cpu-boost/internal/boost/duration"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/history"
 	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resize"
 	"github.com/google/kube-startup-cpu-boost/internal/boost/resource"
 	"github.com/google/kube-startup-cpu-boost/internal/metrics"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -41,25 +46,69 @@ type StartupCPUBoost interface {
 	Name() string
 	// Namespace returns startup-cpu-boost namespace
 	Namespace() string
+	// Priority returns startup-cpu-boost priority
+	Priority() int32
 	// ResourcePolicy returns the resource policy for a given container
 	ResourcePolicy(containerName string) (resource.ContainerPolicy, bool)
-	// DurationPolicies returns configured duration policies
-	DurationPolicies() map[string]duration.Policy
+	// DurationPolicy returns configured duration policy, combined if multiple
+	// duration policies are configured
+	DurationPolicy() duration.Policy
+	// PhaseDurationPolicies returns the duration policies of the resource boost
+	// ramp-down phases
+	PhaseDurationPolicies() []duration.Policy
 	// Pod returns a POD if tracked by startup-cpu-boost
 	Pod(name string) (*corev1.Pod, bool)
 	// UpsertPod inserts new or updates existing POD to startup-cpu-boost tracking
 	UpsertPod(ctx context.Context, pod *corev1.Pod) error
 	// DeletePod removes the POD from the startup-cpu-boost tracking
 	DeletePod(ctx context.Context, pod *corev1.Pod) error
-	// ValidatePolicy validates policy with a given name on all startup-cpu-boost PODs.
-	ValidatePolicy(ctx context.Context, name string) []*corev1.Pod
-	// RevertResources updates POD's container resource requests and limits to their original
-	// values using the data from StartupCPUBoost annotation
+	// ValidatePolicy validates duration policy on all startup-cpu-boost PODs.
+	ValidatePolicy(ctx context.Context) []*corev1.Pod
+	// ValidatePodPolicy validates duration policy on a tracked POD with a given name.
+	// It returns the POD and true if the POD violated the policy.
+	ValidatePodPolicy(ctx context.Context, podName string) (*corev1.Pod, bool)
+	// SetPodScheduler sets the scheduler of the time dependent duration policy validation
+	// and schedules the tracked PODs
+	SetPodScheduler(scheduler PodScheduler)
+	// RevertResources moves the POD to the next phase of the resource boost ramp-down, if
+	// any, or updates POD's container resource requests and limits to their original values
+	// using the data from StartupCPUBoost annotation
 	RevertResources(ctx context.Context, pod *corev1.Pod) error
+	// ObservePodResize updates the tracked in-place resize of a given POD with the state
+	// reported in the POD status. It returns the resize status and true if it changed.
+	ObservePodResize(pod *corev1.Pod) (PodResizeStatus, bool)
+	// PodResizeStatus returns the status of the tracked in-place resize of a POD with
+	// a given name
+	PodResizeStatus(podName string) (PodResizeStatus, bool)
+	// ObservePodStartup tracks the startup of a given boosted POD until it is learned
+	// by the learned duration and resource policies and recorded in the startup profile.
+	// The startups are written in the background. It returns true if anything is learned.
+	ObservePodStartup(ctx context.Context, pod *corev1.Pod) bool
+	// TracksPodStartup returns true if the startup of a POD with a given name is
+	// tracked by the learned duration or resource policy, or for the startup profile
+	TracksPodStartup(podName string) bool
+	// CheckPodResizes marks the tracked in-place resizes that did not complete in time
+	// as stuck and returns them
+	CheckPodResizes(now time.Time) []PodResize
 	// Matches verifies if a boost selector matches the given POD
 	Matches(pod *corev1.Pod) bool
 	// Stats returns the StartupCPUBoost usage statistics
 	Stats() StartupCPUBoostStats
+	// PredictorEndpoints returns the keys of the predictor endpoints called by the
+	// auto policies
+	PredictorEndpoints() []predictor.EndpointKey
+	// TransferState moves the tracked PODs, in-place resizes, startups and usage statistics
+	// from a given startup-cpu-boost, i.e. the one that is replaced after an API spec update
+	TransferState(from StartupCPUBoost)
+}
+
+// PodScheduler schedules the validation of the time dependent duration policies
+// of startup-cpu-boost PODs
+type PodScheduler interface {
+	// SchedulePod schedules the validation of a POD with a given name at a given time
+	SchedulePod(boost StartupCPUBoost, podName string, deadline time.Time)
+	// UnschedulePod cancels the scheduled validation of a POD with a given name
+	UnschedulePod(boost StartupCPUBoost, podName string)
 }
 
 const (
@@ -83,6 +132,15 @@ type StartupCPUBoostStats struct {
 	// totalContainerBoosts is a number of a containers which CPU resources
 	// were increased (boosted)
 	TotalContainerBoosts int
+	// PendingResizes is a number of the in-place resizes of POD resources, i.e.
+	// reverts and ramp-down phases, that were not yet applied by the kubelet
+	PendingResizes int
+	// FailedResizes is a number of the pending in-place resizes of POD resources
+	// that were refused by the kubelet or are stuck
+	FailedResizes int
+	// LearnedResources are the latest CPU targets chosen by the learned resource
+	// policies, ordered by the workload and container
+	LearnedResources []resource.LearnedDecision
 }
 
 // StartupCPUBoostImpl is an implementation of a StartupCPUBoost CRD
@@ -90,33 +148,83 @@ type StartupCPUBoostImpl struct {
 	sync.RWMutex
 	name             string
 	namespace        string
+	priority         int32
 	selector         labels.Selector
-	durationPolicies map[string]duration.Policy
-	resourcePolicies map[string]resource.ContainerPolicy
+	durationPolicy   duration.Policy
+	phases           []boostPhase
+	resourcePolicies *containerPolicies
 	pods             map[string]*corev1.Pod
-	client           client.Client
+	resizes          map[string]*PodResize
+	startups         map[string]*podStartup
+	learned          *resource.LearnedDecisions
+	usage            resource.CPUUsageReader
+	profiles         startupProfiles
+	writer           *startupWriter
+	resizer          resize.Strategy
+	recorder         record.EventRecorder
+	scheduler        PodScheduler
 	stats            StartupCPUBoostStats
+	replacement      *StartupCPUBoostImpl
+	predictors       []predictor.EndpointKey
 }
 
 // NewStartupCPUBoost constructs startup-cpu-boost implementation from a given API spec
-func NewStartupCPUBoost(client client.Client, boost *autoscaling.StartupCPUBoost) (StartupCPUBoost, error) {
+func NewStartupCPUBoost(resizer resize.Strategy, boost *autoscaling.StartupCPUBoost) (StartupCPUBoost, error) {
+	return NewStartupCPUBoostWithClient(resizer, nil, boost)
+}
+
+// NewStartupCPUBoostWithClient constructs startup-cpu-boost implementation from a given
+// API spec. The predictor credentials of the auto policies are read from the Secrets, the
+// durations and startups of the learned duration and resource policies are stored in the
+// ConfigMaps, the CPU usage of the learned resource policies is read from the POD metrics
+// and the startups are recorded in the StartupProfiles with a given client. The client
+// is expected to read the ConfigMaps and Secrets from the API server rather than from
+// the informer cache, so the ConfigMaps and Secrets of the whole cluster are not cached
+// and the reads of the ConfigMaps follow the writes. When the client is nil, the learned
+// duration policy applies its fallback duration and the learned resource policy its
+// minimum CPU target only, and the startups are not recorded.
+func NewStartupCPUBoostWithClient(resizer resize.Strategy, c client.Client,
+	boost *autoscaling.StartupCPUBoost) (StartupCPUBoost, error) {
+	return NewStartupCPUBoostWithRecorder(resizer, c, nil, boost)
+}
+
+// NewStartupCPUBoostWithRecorder constructs startup-cpu-boost implementation from a given
+// API spec, as NewStartupCPUBoostWithClient does. The warnings about the POD resources
+// left boosted after the reversion are recorded as events with a given recorder.
+func NewStartupCPUBoostWithRecorder(resizer resize.Strategy, c client.Client, recorder record.EventRecorder,
+	boost *autoscaling.StartupCPUBoost) (StartupCPUBoost, error) {
 	selector, err := metav1.LabelSelectorAsSelector(&boost.Selector)
 	if err != nil {
 		return nil, err
 	}
-	resourcePolicies, err := mapResourcePolicy(boost.Spec.ResourcePolicy)
+	predictors := &predictorClients{namespace: boost.Namespace, secrets: c}
+	histories := learnedHistories{client: c, boost: boost}
+	learned := resource.NewLearnedDecisions()
+	resourcePolicies, err := mapResourcePolicy(boost.Spec.ResourcePolicy, predictors, histories, learned)
 	if err != nil {
 		return nil, err
 	}
+	durationPolicy := mapDurationPolicy(boost.Spec.DurationPolicy, nil, predictors, histories)
+	phases := mapBoostPhases(boost.Spec.Phases, predictors)
 	return &StartupCPUBoostImpl{
 		name:             boost.Name,
 		namespace:        boost.Namespace,
+		priority:         boost.Spec.Priority,
 		selector:         selector,
-		durationPolicies: mapDurationPolicy(boost.Spec.DurationPolicy),
+		durationPolicy:   durationPolicy,
+		phases:           phases,
 		resourcePolicies: resourcePolicies,
 		pods:             make(map[string]*corev1.Pod),
-		client:           client,
+		resizes:          make(map[string]*PodResize),
+		startups:         make(map[string]*podStartup),
+		learned:          learned,
+		usage:            histories.usageReader(),
+		profiles:         histories.startupProfiles(boost.Spec.StartupProfile),
+		writer:           &startupWriter{},
+		resizer:          resizer,
+		recorder:         recorder,
 		stats:            StartupCPUBoostStats{},
+		predictors:       predictors.endpoints,
 	}, nil
 }
 
@@ -130,15 +238,30 @@ func (b *StartupCPUBoostImpl) Namespace() string {
 	return b.namespace
 }
 
+// Priority returns startup-cpu-boost priority
+func (b *StartupCPUBoostImpl) Priority() int32 {
+	return b.priority
+}
+
 // ResourcePolicy returns the resource policy for a given container
 func (b *StartupCPUBoostImpl) ResourcePolicy(containerName string) (resource.ContainerPolicy, bool) {
-	policy, ok := b.resourcePolicies[containerName]
-	return policy, ok
+	return b.resourcePolicies.policy(containerName)
+}
+
+// DurationPolicy returns configured duration policy, combined if multiple
+// duration policies are configured
+func (b *StartupCPUBoostImpl) DurationPolicy() duration.Policy {
+	return b.durationPolicy
 }
 
-// DurationPolicies returns configured duration policies
-func (b *StartupCPUBoostImpl) DurationPolicies() map[string]duration.Policy {
-	return b.durationPolicies
+// PhaseDurationPolicies returns the duration policies of the resource boost
+// ramp-down phases
+func (b *StartupCPUBoostImpl) PhaseDurationPolicies() []duration.Policy {
+	policies := make([]duration.Policy, 0, len(b.phases))
+	for _, phase := range b.phases {
+		policies = append(policies, phase.durationPolicy)
+	}
+	return policies
 }
 
 // Pod returns a POD if tracked by startup-cpu-boost.
@@ -153,23 +276,33 @@ func (b *StartupCPUBoostImpl) Pod(name string) (*corev1.Pod, bool) {
 // The update of existing POD triggers validation logic and may result in POD update
 func (b *StartupCPUBoostImpl) UpsertPod(ctx context.Context, pod *corev1.Pod) error {
 	b.Lock()
+	if next := b.replacement; next != nil {
+		b.Unlock()
+		return next.UpsertPod(ctx, pod)
+	}
 	defer b.Unlock()
 	log := b.loggerFromContext(ctx).WithValues("pod", pod.Name)
 	log.V(5).Info("handling pod upsert")
-	_, existing := b.pods[pod.Name]
+	current, existing := b.pods[pod.Name]
 	b.pods[pod.Name] = pod
 	statsEvent := StartupCPUBoostStatsEvent{StartupCPUBoostStatsPodCreateEvent, pod}
 	if existing {
 		statsEvent.Type = StartupCPUBoostStatsPodUpdateEvent
 	}
 	b.updateStats(statsEvent)
+	// the deadline depends only on the POD creation and boost phase start times
+	if !existing || podBoostPhase(current) != podBoostPhase(pod) {
+		b.schedulePod(pod)
+	}
 	log.V(5).Info("pod upserted successfully")
-	condPolicy, ok := b.durationPolicies[duration.PodConditionPolicyName]
-	if !ok {
+	policy := b.podDurationPolicy(pod)
+	_, podCondition := duration.FindPolicy(policy, duration.PodConditionPolicyName)
+	_, containerStatus := duration.FindPolicy(policy, duration.ContainerStatusPolicyName)
+	if !podCondition && !containerStatus {
 		log.V(5).Info("pod duration policy not found, skipping resource reversion")
 		return nil
 	}
-	if valid := b.validatePolicyOnPod(ctx, condPolicy, pod); !valid {
+	if violated := b.policyViolatedOnPod(ctx, policy, pod); violated {
 		log.V(5).Info("reverting pod resources")
 		if err := b.revertResources(ctx, pod); err != nil {
 			return fmt.Errorf("pod resources reversion failed: %s", err)
@@ -179,39 +312,100 @@ func (b *StartupCPUBoostImpl) UpsertPod(ctx context.Context, pod *corev1.Pod) er
 	return nil
 }
 
-// DeletePod removes the POD from the startup-cpu-boost tracking
+// DeletePod removes the POD from the startup-cpu-boost tracking. The startup of the
+// POD that was not yet recorded in the startup profile is recorded with the startup
+// stages the POD reached.
 func (b *StartupCPUBoostImpl) DeletePod(ctx context.Context, pod *corev1.Pod) error {
 	b.Lock()
-	defer b.Unlock()
+	if next := b.replacement; next != nil {
+		b.Unlock()
+		return next.DeletePod(ctx, pod)
+	}
 	log := b.loggerFromContext(ctx).WithValues("pod", pod.Name)
 	log.V(5).Info("handling pod delete")
+	var sample *autoscaling.StartupSample
+	if startup, ok := b.startups[pod.Name]; ok {
+		sample = startup.sample
+	}
 	delete(b.pods, pod.Name)
+	delete(b.resizes, pod.Name)
+	delete(b.startups, pod.Name)
+	b.unschedulePod(pod.Name)
 	b.updateStats(StartupCPUBoostStatsEvent{StartupCPUBoostStatsPodDeleteEvent, pod})
+	b.Unlock()
+	// the profile is written in the background, as it calls the API server
+	if sample != nil {
+		updateStartupSample(sample, pod)
+		b.writer.enqueue(func() {
+			b.recordPodStartup(ctx, pod, *sample)
+		})
+	}
 	return nil
 }
 
-// ValidatePolicy validates policy with a given name on all startup-cpu-boost PODs.
+// ValidatePolicy validates duration policy on all startup-cpu-boost PODs. The PODs in
+// the resource boost ramp-down are validated with the duration policy of their phase.
 // The function returns slice of PODs that violated the policy.
-func (b *StartupCPUBoostImpl) ValidatePolicy(ctx context.Context, name string) (violated []*corev1.Pod) {
+func (b *StartupCPUBoostImpl) ValidatePolicy(ctx context.Context) (violated []*corev1.Pod) {
 	b.RLock()
 	defer b.RUnlock()
 	violated = make([]*corev1.Pod, 0)
-	policy, ok := b.durationPolicies[name]
-	if !ok {
-		return
-	}
 	for _, pod := range b.pods {
-		if !b.validatePolicyOnPod(ctx, policy, pod) {
+		policy := b.podDurationPolicy(pod)
+		if policy == nil {
+			continue
+		}
+		if b.policyViolatedOnPod(ctx, policy, pod) {
 			violated = append(violated, pod)
 		}
 	}
 	return
 }
 
-// RevertResources updates POD's container resource requests and limits to their original
-// values using the data from StartupCPUBoost annotation
+// ValidatePodPolicy validates duration policy on a tracked POD with a given name. It
+// returns the POD and true if the POD violated the policy. Otherwise, the POD is
+// scheduled for the next validation if its policy deadline moved to the future, i.e.
+// when the predicted duration of the auto duration policy has changed.
+func (b *StartupCPUBoostImpl) ValidatePodPolicy(ctx context.Context, podName string) (*corev1.Pod, bool) {
+	b.RLock()
+	defer b.RUnlock()
+	pod, ok := b.pods[podName]
+	if !ok {
+		return nil, false
+	}
+	policy := b.podDurationPolicy(pod)
+	if policy == nil {
+		return nil, false
+	}
+	if b.policyViolatedOnPod(ctx, policy, pod) {
+		return pod, true
+	}
+	if deadline, ok := duration.Deadline(policy, pod); ok && deadline.After(time.Now()) && b.scheduler != nil {
+		b.scheduler.SchedulePod(b, pod.Name, deadline)
+	}
+	return nil, false
+}
+
+// SetPodScheduler sets the scheduler of the time dependent duration policy validation
+// and schedules the tracked PODs
+func (b *StartupCPUBoostImpl) SetPodScheduler(scheduler PodScheduler) {
+	b.Lock()
+	defer b.Unlock()
+	b.scheduler = scheduler
+	for _, pod := range b.pods {
+		b.schedulePod(pod)
+	}
+}
+
+// RevertResources moves the POD to the next phase of the resource boost ramp-down, if
+// any, or updates POD's container resource requests and limits to their original values
+// using the data from StartupCPUBoost annotation
 func (b *StartupCPUBoostImpl) RevertResources(ctx context.Context, pod *corev1.Pod) error {
 	b.Lock()
+	if next := b.replacement; next != nil {
+		b.Unlock()
+		return next.RevertResources(ctx, pod)
+	}
 	defer b.Unlock()
 	return b.revertResources(ctx, pod)
 }
@@ -221,9 +415,51 @@ func (b *StartupCPUBoostImpl) Matches(pod *corev1.Pod) bool {
 	return b.selector.Matches(labels.Set(pod.Labels))
 }
 
+// PredictorEndpoints returns the keys of the predictor endpoints called by the
+// auto policies
+func (b *StartupCPUBoostImpl) PredictorEndpoints() []predictor.EndpointKey {
+	return b.predictors
+}
+
 // Stats returns the StartupCPUBoost usage statistics
 func (b *StartupCPUBoostImpl) Stats() StartupCPUBoostStats {
-	return b.stats
+	b.RLock()
+	defer b.RUnlock()
+	stats := b.stats
+	stats.PendingResizes, stats.FailedResizes = b.resizeStats()
+	stats.LearnedResources = b.learned.List()
+	return stats
+}
+
+// TransferState moves the tracked PODs, in-place resizes, startups and usage statistics
+// from a given startup-cpu-boost, i.e. the one that is replaced after an API spec update.
+// The replaced startup-cpu-boost forwards the later POD updates to this one, so the PODs
+// handled with a reference obtained before the replacement are not lost.
+func (b *StartupCPUBoostImpl) TransferState(from StartupCPUBoost) {
+	src, ok := from.(*StartupCPUBoostImpl)
+	if !ok || src == b {
+		return
+	}
+	src.Lock()
+	defer src.Unlock()
+	b.Lock()
+	defer b.Unlock()
+	for name, pod := range src.pods {
+		b.pods[name] = pod
+	}
+	src.pods = make(map[string]*corev1.Pod)
+	for name, podResize := range src.resizes {
+		b.resizes[name] = podResize
+	}
+	src.resizes = make(map[string]*PodResize)
+	for name, startup := range src.startups {
+		b.startups[name] = startup
+	}
+	src.startups = make(map[string]*podStartup)
+	b.learned.Merge(src.learned)
+	b.stats = src.stats
+	b.updateStats(StartupCPUBoostStatsEvent{Type: StartupCPUBoostStatsPodUpdateEvent})
+	src.replacement = b
 }
 
 // loggerFromContext provides Logger from a current context with configured
@@ -247,20 +483,126 @@ func (b *StartupCPUBoostImpl) validatePolicyOnPod(ctx context.Context, p duratio
 	return
 }
 
-// revertResources updates POD's container resource requests and limits to their original
-// values using the data from StartupCPUBoost annotation
+// policyViolatedOnPod returns true if a given policy is not valid on a given POD
+// or on any of its boosted containers
+func (b *StartupCPUBoostImpl) policyViolatedOnPod(ctx context.Context, p duration.Policy, pod *corev1.Pod) bool {
+	if !b.validatePolicyOnPod(ctx, p, pod) {
+		return true
+	}
+	return len(endedContainers(p, pod)) > 0
+}
+
+// podDurationPolicy returns the duration policy of the current resource boost phase
+// of a given POD. The PODs in the phase that is no longer defined are validated with
+// the duration policy of the last phase.
+func (b *StartupCPUBoostImpl) podDurationPolicy(pod *corev1.Pod) duration.Policy {
+	phase := podBoostPhase(pod)
+	switch {
+	case phase == 0 || len(b.phases) == 0:
+		return b.durationPolicy
+	case phase > len(b.phases):
+		return b.phases[len(b.phases)-1].durationPolicy
+	}
+	return b.phases[phase-1].durationPolicy
+}
+
+// revertResources reverts the POD containers which duration ended while the POD duration
+// policy is valid. Otherwise, it moves the POD to the next phase of the resource boost
+// ramp-down, if any, or updates POD's container resource requests and limits to their
+// original values using the data from StartupCPUBoost annotation
 func (b *StartupCPUBoostImpl) revertResources(ctx context.Context, pod *corev1.Pod) error {
-	if err := bpod.RevertResourceBoost(pod); err != nil {
-		return fmt.Errorf("failed to update pod spec: %s", err)
+	if policy := b.podDurationPolicy(pod); policy != nil && policy.Valid(pod) {
+		if ended := endedContainers(policy, pod); len(ended) > 0 {
+			return b.revertContainers(ctx, pod, ended)
+		}
 	}
-	if err := b.client.Update(ctx, pod); err != nil {
+	if next := podBoostPhase(pod) + 1; next <= len(b.phases) {
+		return b.applyPhase(ctx, pod, next)
+	}
+	return b.revertPod(ctx, pod)
+}
+
+// revertPod updates POD's container resource requests and limits to their original
+// values using the data from StartupCPUBoost annotation
+func (b *StartupCPUBoostImpl) revertPod(ctx context.Context, pod *corev1.Pod) error {
+	if err := RevertPodResources(ctx, b.resizer, b.recorder, pod); err != nil {
 		return err
 	}
+	b.trackPodResize(pod)
+	b.observePodRevert(pod.Name, time.Now())
 	delete(b.pods, pod.Name)
+	b.unschedulePod(pod.Name)
 	b.updateStats(StartupCPUBoostStatsEvent{StartupCPUBoostStatsPodDeleteEvent, pod})
 	return nil
 }
 
+// revertContainers updates the resources of given POD containers to their original
+// values. The whole POD is reverted when none of its containers remains boosted.
+func (b *StartupCPUBoostImpl) revertContainers(ctx context.Context, pod *corev1.Pod, containerNames []string) error {
+	annotation, err := bpod.BoostAnnotationFromPod(pod)
+	if err != nil {
+		return fmt.Errorf("failed to update pod spec: %s", err)
+	}
+	if len(containerNames) >= len(annotation.BoostedContainers()) {
+		return b.revertPod(ctx, pod)
+	}
+	updated := pod.DeepCopy()
+	if err := bpod.RevertContainerResourceBoost(updated, containerNames...); err != nil {
+		return fmt.Errorf("failed to update pod spec: %s", err)
+	}
+	if err := b.resizer.Resize(ctx, pod, updated); err != nil {
+		return err
+	}
+	b.trackPodResize(updated)
+	b.pods[pod.Name] = updated
+	b.schedulePod(updated)
+	b.updateStats(StartupCPUBoostStatsEvent{StartupCPUBoostStatsPodUpdateEvent, updated})
+	b.loggerFromContext(ctx).WithValues("pod", pod.Name).
+		Info("pod container resources reverted", "containers", containerNames)
+	return nil
+}
+
+// applyPhase updates POD's container CPU resources to the values of a given phase of
+// the resource boost ramp-down. The updated POD replaces the tracked one, so the
+// duration policy of the new phase is used in the subsequent validations.
+func (b *StartupCPUBoostImpl) applyPhase(ctx context.Context, pod *corev1.Pod, phase int) error {
+	updated := pod.DeepCopy()
+	if err := bpod.ApplyBoostPhase(updated, phase, b.phases[phase-1].percentage, time.Now()); err != nil {
+		return fmt.Errorf("failed to update pod spec: %s", err)
+	}
+	if err := b.resizer.Resize(ctx, pod, updated); err != nil {
+		return err
+	}
+	b.trackPodResize(updated)
+	b.pods[pod.Name] = updated
+	b.schedulePod(updated)
+	b.loggerFromContext(ctx).WithValues("pod", pod.Name).
+		Info("pod resources decreased to boost phase", "phase", phase)
+	return nil
+}
+
+// schedulePod schedules the validation of a given POD at the deadline of its duration
+// policy, if the policy depends on time and the scheduler is set
+func (b *StartupCPUBoostImpl) schedulePod(pod *corev1.Pod) {
+	if b.scheduler == nil {
+		return
+	}
+	deadline, ok := duration.Deadline(b.podDurationPolicy(pod), pod)
+	if !ok {
+		b.scheduler.UnschedulePod(b, pod.Name)
+		return
+	}
+	b.scheduler.SchedulePod(b, pod.Name, deadline)
+}
+
+// unschedulePod cancels the scheduled validation of a POD with a given name, if the
+// scheduler is set
+func (b *StartupCPUBoostImpl) unschedulePod(podName string) {
+	if b.scheduler != nil {
+		b.scheduler.UnschedulePod(b, podName)
+	}
+}
+
 // updateStats updates the StartupCPUBoost usage statistics based on the
 // received update event
 func (b *StartupCPUBoostImpl) updateStats(e StartupCPUBoostStatsEvent) {
@@ -282,34 +624,104 @@ func (b *StartupCPUBoostImpl) updateStats(e StartupCPUBoostStatsEvent) {
 // boostContainersLen returns the number of containers that were boosted
 // by StartupCPUBoost in a given Pod
 func boostContainersLen(pod *corev1.Pod) (cnt int) {
-	if annot, err := bpod.BoostAnnotationFromPod(pod); err == nil {
-		return len(annot.InitCPURequests)
+	annot, err := bpod.BoostAnnotationFromPod(pod)
+	if err != nil {
+		return
 	}
-	return
+	containers := make(map[string]bool)
+	for _, values := range []map[string]string{annot.InitCPURequests, annot.InitCPULimits,
+		annot.InitMemoryRequests, annot.InitMemoryLimits} {
+		for name := range values {
+			containers[name] = true
+		}
+	}
+	return len(containers)
 }
 
-// mapDurationPolicy maps the Duration Policy from the API spec to the map of policy
-// implementations with policy name keys
-func mapDurationPolicy(policiesSpec autoscaling.DurationPolicy) map[string]duration.Policy {
-	policies := make(map[string]duration.Policy)
+// mapDurationPolicy maps the Duration Policy from the API spec to the policy
+// implementation. Multiple policies are combined with the operator from the API
+// spec. The fixed duration is measured since the time returned by a given start
+// time function, or since the POD creation if nil. The auto policy calls the predictor
+// with a client from given predictor clients, and the learned policy stores the
+// durations in a history from given duration histories. It returns nil if no policy
+// is defined.
+func mapDurationPolicy(policiesSpec autoscaling.DurationPolicy, startTimeFunc duration.StartTimeFunc,
+	predictors *predictorClients, histories learnedHistories) duration.Policy {
+	var policies []duration.Policy
 	if fixedPolicy := policiesSpec.Fixed; fixedPolicy != nil {
 		d := fixedPolicyToDuration(*fixedPolicy)
-		policies[duration.FixedDurationPolicyName] = duration.NewFixedDurationPolicy(d)
+		if startTimeFunc != nil {
+			policies = append(policies, duration.NewFixedDurationPolicyWithStartTimeFunc(time.Now, startTimeFunc, d))
+		} else {
+			policies = append(policies, duration.NewFixedDurationPolicy(d))
+		}
 	}
 	if condPolicy := policiesSpec.PodCondition; condPolicy != nil {
-		policies[duration.PodConditionPolicyName] = duration.NewPodConditionPolicy(condPolicy.Type, condPolicy.Status)
+		policies = append(policies, duration.NewPodConditionPolicy(condPolicy.Type, condPolicy.Status))
 	}
 	if autoPolicy := policiesSpec.AutoPolicy; autoPolicy != nil {
-		policies[duration.AutoDurationPolicyName] = duration.NewAutoDurationPolicy(autoPolicy.ApiEndpoint)
+		predictorClient := predictors.client(autoPolicy.ApiEndpoint, autoPolicy.Timeout, autoPolicy.Service,
+			autoPolicy.CABundle, autoPolicy.SecretRef)
+		fallback := duration.DefaultAutoFallback
+		if autoPolicy.Fallback != nil {
+			fallback = fixedPolicyToDuration(*autoPolicy.Fallback)
+		}
+		policies = append(policies, duration.NewAutoDurationPolicyWithFallback(predictorClient, time.Now, fallback))
 	}
-	return policies
+	if statusPolicy := policiesSpec.ContainerStatus; statusPolicy != nil {
+		condition := duration.ContainerCondition(statusPolicy.Condition)
+		policies = append(policies, duration.NewContainerStatusPolicy(condition))
+	}
+	if learnedPolicy := policiesSpec.Learned; learnedPolicy != nil {
+		var margin time.Duration
+		if learnedPolicy.Margin != nil {
+			margin = learnedPolicy.Margin.Duration
+		}
+		policies = append(policies, duration.NewLearnedDurationPolicy(histories.durationHistory(learnedPolicy.MaxSamples),
+			learnedPolicy.Condition, int(learnedPolicy.Percentile), margin, int(learnedPolicy.MinSamples),
+			fixedPolicyToDuration(learnedPolicy.Fallback)))
+	}
+	switch len(policies) {
+	case 0:
+		return nil
+	case 1:
+		return policies[0]
+	}
+	operator := duration.OperatorAny
+	if policiesSpec.Operator == autoscaling.DurationPolicyOperatorAll {
+		operator = duration.OperatorAll
+	}
+	return duration.NewCompositePolicy(operator, policies...)
 }
 
-// mapResourcePolicy maps the Resource Policy from the API spec to the map of policy
-// implementations with container name keys
-func mapResourcePolicy(spec autoscaling.ResourcePolicy) (map[string]resource.ContainerPolicy, error) {
+// endedContainers returns the names of the boosted POD containers which boost duration
+// ended according to the container policies of a given duration policy
+func endedContainers(policy duration.Policy, pod *corev1.Pod) []string {
+	if _, ok := duration.FindPolicy(policy, duration.ContainerStatusPolicyName); !ok {
+		return nil
+	}
+	annotation, err := bpod.BoostAnnotationFromPod(pod)
+	if err != nil {
+		return nil
+	}
+	var ended []string
+	for _, name := range annotation.BoostedContainers() {
+		if !duration.ValidContainer(policy, pod, name) {
+			ended = append(ended, name)
+		}
+	}
+	return ended
+}
+
+// mapResourcePolicy maps the Resource Policy from the API spec to the policy
+// implementations matched by container names. The auto policies call the predictor
+// with the clients from given predictor clients. The learned policies store the
+// startups in a history from given learned histories and record the chosen CPU
+// targets in given learned decisions.
+func mapResourcePolicy(spec autoscaling.ResourcePolicy, predictors *predictorClients, histories learnedHistories,
+	learned *resource.LearnedDecisions) (*containerPolicies, error) {
 	var errs []error
-	policies := make(map[string]resource.ContainerPolicy)
+	policies := newContainerPolicies()
 	for _, policySpec := range spec.ContainerPolicies {
 		var policy resource.ContainerPolicy
 		var cnt int
@@ -322,14 +734,35 @@ func mapResourcePolicy(spec autoscaling.ResourcePolicy) (map[string]resource.Con
 			cnt++
 		}
 		if autoPolicy := policySpec.AutoPolicy; autoPolicy != nil {
-			policy = resource.NewAutoPolicy(autoPolicy.ApiEndpoint)
+			predictorClient := predictors.client(autoPolicy.ApiEndpoint, autoPolicy.Timeout, autoPolicy.Service,
+				autoPolicy.CABundle, autoPolicy.SecretRef)
+			policy = resource.NewAutoPolicyWithFallback(predictorClient, mapFallbackPolicy(autoPolicy.Fallback))
+			cnt++
+		}
+		if learnedResources := policySpec.LearnedResources; learnedResources != nil {
+			policy = resource.NewLearnedPolicy(histories.resourceHistory(learnedResources.MaxSamples),
+				learnedResources.Min, learnedResources.Max, int(learnedResources.MinSamples),
+				learnedResources.UseCPUUsage, learned)
 			cnt++
 		}
 		if cnt != 1 {
-			errs = append(errs, fmt.Errorf("invalid number of resource policies fo container %s; must be one", policySpec.ContainerName))
+			errs = append(errs, fmt.Errorf("invalid number of resource policies for container %s; must be one", policySpec.ContainerName))
+			continue
+		}
+		memoryPolicy, err := mapMemoryPolicy(policySpec)
+		if err != nil {
+			errs = append(errs, err)
 			continue
 		}
-		policies[policySpec.ContainerName] = policy
+		if memoryPolicy != nil {
+			policy = resource.NewMultiResourcePolicy(policy, memoryPolicy)
+		}
+		if err := addContainerPolicy(policies, policySpec, policy); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := policies.exclude(spec.ExcludedContainers...); err != nil {
+		errs = append(errs, fmt.Errorf("invalid excluded containers: %w", err))
 	}
 	if len(errs) > 0 {
 		return nil, errors.Join(errs...)
@@ -337,6 +770,177 @@ func mapResourcePolicy(spec autoscaling.ResourcePolicy) (map[string]resource.Con
 	return policies, nil
 }
 
+// addContainerPolicy adds the policy for the containers matched by the container
+// name or regular expression from a given container policy API spec
+func addContainerPolicy(policies *containerPolicies, spec autoscaling.ContainerPolicy, policy resource.ContainerPolicy) error {
+	switch {
+	case spec.ContainerName != "" && spec.ContainerNameRegex != "":
+		return fmt.Errorf("both container name and regex are set for container %s; must be one", spec.ContainerName)
+	case spec.ContainerNameRegex != "":
+		if err := policies.addRegexp(spec.ContainerNameRegex, policy); err != nil {
+			return fmt.Errorf("invalid container name regex %s: %w", spec.ContainerNameRegex, err)
+		}
+	case spec.ContainerName != "":
+		if err := policies.addName(spec.ContainerName, policy); err != nil {
+			return fmt.Errorf("invalid container name pattern %s: %w", spec.ContainerName, err)
+		}
+	default:
+		return errors.New("container name or regex is not set")
+	}
+	return nil
+}
+
+// mapFallbackPolicy maps the fallback policy of the auto resource policy from the API
+// spec to the policy implementation. It returns nil if fallback policy is not defined.
+func mapFallbackPolicy(spec *autoscaling.AutoResourceFallbackPolicy) resource.ContainerPolicy {
+	switch {
+	case spec == nil:
+		return nil
+	case spec.FixedResources != nil:
+		return resource.NewFixedPolicy(spec.FixedResources.Requests, spec.FixedResources.Limits)
+	case spec.PercentageIncrease != nil:
+		return resource.NewPercentageContainerPolicy(spec.PercentageIncrease.Value)
+	}
+	return nil
+}
+
+// mapMemoryPolicy maps the memory resource policy from the container policy API spec
+// to the policy implementation. It returns nil if memory policy is not defined.
+func mapMemoryPolicy(spec autoscaling.ContainerPolicy) (resource.ContainerPolicy, error) {
+	if spec.MemoryFixedResources != nil && spec.MemoryPercentageIncrease != nil {
+		return nil, fmt.Errorf("invalid number of memory resource policies for container %s; must be at most one", spec.ContainerName)
+	}
+	if fixedResources := spec.MemoryFixedResources; fixedResources != nil {
+		return resource.NewFixedResourcePolicy(corev1.ResourceMemory, fixedResources.Requests, fixedResources.Limits), nil
+	}
+	if percIncrease := spec.MemoryPercentageIncrease; percIncrease != nil {
+		return resource.NewPercentageResourcePolicy(corev1.ResourceMemory, percIncrease.Value), nil
+	}
+	return nil, nil
+}
+
+// predictorClients creates the predictor clients of the auto policies of a
+// startup-cpu-boost in a given namespace. The predictor credentials are read
+// from the Secrets with a given reader. The keys of the predictor endpoints are
+// collected, so the state shared by their clients is released with the boost.
+type predictorClients struct {
+	namespace string
+	secrets   client.Reader
+	endpoints []predictor.EndpointKey
+}
+
+// client returns the predictor client for the predictor endpoint or Service, timeout,
+// CA bundle and credentials Secret from the API spec. The Service and Secret default
+// to the startup-cpu-boost namespace, and the Service endpoint takes precedence. The
+// endpoint scheme selects the predictor protocol.
+func (p *predictorClients) client(apiEndpoint string, timeout *metav1.Duration,
+	service *autoscaling.PredictorServiceReference, caBundle []byte,
+	secretRef *autoscaling.PredictorSecretReference) predictor.Predictor {
+	endpoint := apiEndpoint
+	if service != nil {
+		endpoint = predictorServiceEndpoint(p.namespace, service)
+	}
+	options := predictorOptions(timeout)
+	p.endpoints = append(p.endpoints, predictor.NewEndpointKey(endpoint, options))
+	if len(caBundle) == 0 && secretRef == nil {
+		return predictor.New(endpoint, options, nil)
+	}
+	var secret *types.NamespacedName
+	if secretRef != nil {
+		secret = &types.NamespacedName{Namespace: secretRef.Namespace, Name: secretRef.Name}
+		if secret.Namespace == "" {
+			secret.Namespace = p.namespace
+		}
+	}
+	credentials := predictor.NewCredentials(p.secrets, secret, caBundle)
+	return predictor.New(endpoint, options, credentials)
+}
+
+// learnedHistories creates the histories of the durations and startups learned by the
+// learned duration and resource policies of a given startup-cpu-boost, and the store
+// of its startup profiles. The histories are stored in the ConfigMaps and the profiles
+// in the StartupProfiles with a given client.
+type learnedHistories struct {
+	client client.Client
+	boost  *autoscaling.StartupCPUBoost
+}
+
+// durationHistory returns the history that keeps a given number of the latest durations
+// per workload, or the default number if not set. It returns nil if the client is not
+// set.
+func (h learnedHistories) durationHistory(maxSamples int32) duration.DurationHistory {
+	if h.client == nil {
+		return nil
+	}
+	if maxSamples <= 0 {
+		maxSamples = duration.DefaultLearnedMaxSamples
+	}
+	return history.NewConfigMapHistory(h.client, h.boost, int(maxSamples))
+}
+
+// resourceHistory returns the history that keeps a given number of the latest startups
+// per workload container, or the default number if not set. It returns nil if the
+// client is not set.
+func (h learnedHistories) resourceHistory(maxSamples int32) resource.ResourceHistory {
+	if h.client == nil {
+		return nil
+	}
+	if maxSamples <= 0 {
+		maxSamples = resource.DefaultLearnedMaxSamples
+	}
+	return history.NewConfigMapResourceHistory(h.client, h.boost, int(maxSamples))
+}
+
+// usageReader returns the reader of the container CPU usage from the POD metrics. It
+// returns nil if the client is not set.
+func (h learnedHistories) usageReader() resource.CPUUsageReader {
+	if h.client == nil {
+		return nil
+	}
+	return resource.NewMetricsCPUUsageReader(h.client)
+}
+
+// startupProfiles returns the store of the startup profiles that keep a given number of
+// the latest startups per workload, or the default number if not set. It returns nil if
+// the startup profile policy or the client is not set.
+func (h learnedHistories) startupProfiles(policy *autoscaling.StartupProfilePolicy) startupProfiles {
+	if h.client == nil || policy == nil {
+		return nil
+	}
+	maxSamples := policy.MaxSamples
+	if maxSamples <= 0 {
+		maxSamples = history.DefaultStartupProfileMaxSamples
+	}
+	return history.NewStartupProfileStore(h.client, h.boost, int(maxSamples))
+}
+
+// predictorServiceEndpoint returns the endpoint of the predictor Service from the API
+// spec, which defaults to a given namespace
+func predictorServiceEndpoint(namespace string, service *autoscaling.PredictorServiceReference) string {
+	if service.Namespace != "" {
+		namespace = service.Namespace
+	}
+	var port int32 = predictor.DefaultServicePort
+	if service.Port != nil {
+		port = *service.Port
+	}
+	var path string
+	if service.Path != nil {
+		path = *service.Path
+	}
+	return predictor.ServiceEndpoint(namespace, service.Name, port, path)
+}
+
+// predictorOptions returns the predictor client options with a given timeout
+// from the API spec, if set
+func predictorOptions(timeout *metav1.Duration) predictor.Options {
+	options := predictor.DefaultOptions()
+	if timeout != nil && timeout.Duration > 0 {
+		options.Timeout = timeout.Duration
+	}
+	return options
+}
+
 // fixedPolicyToDuration maps the attributes from FixedDurationPolicy API spec to the
 // time duration
 func fixedPolicyToDuration(policy autoscaling.FixedDurationPolicy) time.Duration {
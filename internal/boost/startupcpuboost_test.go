@@ -21,6 +21,8 @@ import (
 	autoscaling "github.com/google/kube-startup-cpu-boost/api/v1alpha1"
 	cpuboost "github.com/google/kube-startup-cpu-boost/internal/boost"
 	"github.com/google/kube-startup-cpu-boost/internal/boost/duration"
+	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resize"
 	"github.com/google/kube-startup-cpu-boost/internal/boost/resource"
 	"github.com/google/kube-startup-cpu-boost/internal/metrics"
 	"github.com/google/kube-startup-cpu-boost/internal/mock"
@@ -30,6 +32,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apiResource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var _ = Describe("StartupCPUBoost", func() {
@@ -103,6 +106,221 @@ var _ = Describe("StartupCPUBoost", func() {
 				Expect(fixedPolicy.Limits()).To(Equal(containerTwoFixedLim))
 			})
 		})
+		When("the spec has CPU and memory resource policy for container", func() {
+			BeforeEach(func() {
+				spec.Spec.ResourcePolicy = autoscaling.ResourcePolicy{
+					ContainerPolicies: []autoscaling.ContainerPolicy{
+						{
+							ContainerName: "container-one",
+							PercentageIncrease: &autoscaling.PercentageIncrease{
+								Value: 120,
+							},
+							MemoryPercentageIncrease: &autoscaling.PercentageIncrease{
+								Value: 50,
+							},
+						},
+					},
+				}
+			})
+			It("does not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("returns valid resource policy for container", func() {
+				p, ok := boost.ResourcePolicy("container-one")
+				Expect(ok).To(BeTrue())
+				Expect(p).To(BeAssignableToTypeOf(&resource.MultiResourcePolicy{}))
+				policies := p.(*resource.MultiResourcePolicy).Policies()
+				Expect(policies).To(HaveLen(2))
+				memPolicy, ok := policies[1].(*resource.PercentageContainerPolicy)
+				Expect(ok).To(BeTrue())
+				Expect(memPolicy.Resource()).To(Equal(corev1.ResourceMemory))
+				Expect(memPolicy.Percentage()).To(Equal(int64(50)))
+			})
+		})
+		When("the spec has auto resource policy with fallback policy for container", func() {
+			BeforeEach(func() {
+				spec.Spec.ResourcePolicy = autoscaling.ResourcePolicy{
+					ContainerPolicies: []autoscaling.ContainerPolicy{
+						{
+							ContainerName: "container-one",
+							AutoPolicy: &autoscaling.AutoResourcePolicy{
+								ApiEndpoint: "http://predictor.local",
+								Fallback: &autoscaling.AutoResourceFallbackPolicy{
+									PercentageIncrease: &autoscaling.PercentageIncrease{
+										Value: 80,
+									},
+								},
+							},
+						},
+					},
+				}
+			})
+			It("does not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("returns auto resource policy with valid fallback policy", func() {
+				p, ok := boost.ResourcePolicy("container-one")
+				Expect(ok).To(BeTrue())
+				Expect(p).To(BeAssignableToTypeOf(&resource.AutoPolicy{}))
+				fallback, ok := p.(*resource.AutoPolicy).Fallback().(*resource.PercentageContainerPolicy)
+				Expect(ok).To(BeTrue())
+				Expect(fallback.Percentage()).To(Equal(int64(80)))
+			})
+		})
+		When("the spec has learned resource policy for container", func() {
+			BeforeEach(func() {
+				spec.Spec.ResourcePolicy = autoscaling.ResourcePolicy{
+					ContainerPolicies: []autoscaling.ContainerPolicy{
+						{
+							ContainerName: "container-one",
+							LearnedResources: &autoscaling.LearnedResourcePolicy{
+								Min:         apiResource.MustParse("1"),
+								Max:         apiResource.MustParse("4"),
+								UseCPUUsage: true,
+							},
+						},
+					},
+				}
+			})
+			It("does not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("returns learned resource policy with valid bounds", func() {
+				p, ok := boost.ResourcePolicy("container-one")
+				Expect(ok).To(BeTrue())
+				learnedPolicy, ok := p.(*resource.LearnedPolicy)
+				Expect(ok).To(BeTrue())
+				Expect(learnedPolicy.Min()).To(Equal(apiResource.MustParse("1")))
+				Expect(learnedPolicy.Max()).To(Equal(apiResource.MustParse("4")))
+				Expect(learnedPolicy.MinSamples()).To(Equal(resource.DefaultLearnedMinSamples))
+				Expect(learnedPolicy.UseCPUUsage()).To(BeTrue())
+			})
+		})
+		When("the spec has auto resource policy with predictor service", func() {
+			BeforeEach(func() {
+				port := int32(8443)
+				path := "/api/v1"
+				spec.Spec.ResourcePolicy = autoscaling.ResourcePolicy{
+					ContainerPolicies: []autoscaling.ContainerPolicy{
+						{
+							ContainerName: "container-one",
+							AutoPolicy: &autoscaling.AutoResourcePolicy{
+								Service: &autoscaling.PredictorServiceReference{
+									Namespace: "predictor-ns",
+									Name:      "predictor",
+									Port:      &port,
+									Path:      &path,
+								},
+								SecretRef: &autoscaling.PredictorSecretReference{
+									Name: "predictor-credentials",
+								},
+							},
+						},
+						{
+							ContainerName: "container-two",
+							AutoPolicy: &autoscaling.AutoResourcePolicy{
+								Service: &autoscaling.PredictorServiceReference{
+									Name: "predictor",
+								},
+							},
+						},
+					},
+				}
+			})
+			It("does not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("returns auto resource policy with the service endpoint", func() {
+				p, ok := boost.ResourcePolicy("container-one")
+				Expect(ok).To(BeTrue())
+				Expect(p).To(BeAssignableToTypeOf(&resource.AutoPolicy{}))
+				Expect(p.(*resource.AutoPolicy).Endpoint()).To(Equal("https://predictor.predictor-ns.svc:8443/api/v1"))
+			})
+			It("defaults the service namespace and port", func() {
+				p, ok := boost.ResourcePolicy("container-two")
+				Expect(ok).To(BeTrue())
+				Expect(p).To(BeAssignableToTypeOf(&resource.AutoPolicy{}))
+				Expect(p.(*resource.AutoPolicy).Endpoint()).To(Equal("https://predictor." + spec.Namespace + ".svc:443"))
+			})
+		})
+		When("the spec has container policy with two memory resource policies", func() {
+			BeforeEach(func() {
+				spec.Spec.ResourcePolicy = autoscaling.ResourcePolicy{
+					ContainerPolicies: []autoscaling.ContainerPolicy{
+						{
+							ContainerName:            "container-one",
+							PercentageIncrease:       &autoscaling.PercentageIncrease{Value: 120},
+							MemoryPercentageIncrease: &autoscaling.PercentageIncrease{Value: 50},
+							MemoryFixedResources:     &autoscaling.FixedResources{},
+						},
+					},
+				}
+			})
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		When("the spec has resource policies with container name patterns", func() {
+			percPolicy := func(name, regex string, value int64) autoscaling.ContainerPolicy {
+				return autoscaling.ContainerPolicy{
+					ContainerName:      name,
+					ContainerNameRegex: regex,
+					PercentageIncrease: &autoscaling.PercentageIncrease{Value: value},
+				}
+			}
+			percentageFor := func(containerName string) int64 {
+				p, ok := boost.ResourcePolicy(containerName)
+				Expect(ok).To(BeTrue())
+				return p.(*resource.PercentageContainerPolicy).Percentage()
+			}
+			BeforeEach(func() {
+				spec.Spec.ResourcePolicy = autoscaling.ResourcePolicy{
+					ContainerPolicies: []autoscaling.ContainerPolicy{
+						percPolicy(cpuboost.DefaultContainerName, "", 10),
+						percPolicy("", "worker-[0-9]+", 20),
+						percPolicy("app-*", "", 30),
+						percPolicy("app-web-*", "", 40),
+						percPolicy("app", "", 50),
+					},
+					ExcludedContainers: []string{"istio-*"},
+				}
+			})
+			It("does not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("returns exact name policy", func() {
+				Expect(percentageFor("app")).To(Equal(int64(50)))
+			})
+			It("returns most specific glob policy", func() {
+				Expect(percentageFor("app-web-1")).To(Equal(int64(40)))
+				Expect(percentageFor("app-db")).To(Equal(int64(30)))
+			})
+			It("returns regex policy", func() {
+				Expect(percentageFor("worker-12")).To(Equal(int64(20)))
+			})
+			It("returns default policy", func() {
+				Expect(percentageFor("worker-a")).To(Equal(int64(10)))
+			})
+			It("does not return policy for excluded container", func() {
+				_, ok := boost.ResourcePolicy("istio-proxy")
+				Expect(ok).To(BeFalse())
+			})
+		})
+		When("the spec has container policy with invalid name regex", func() {
+			BeforeEach(func() {
+				spec.Spec.ResourcePolicy = autoscaling.ResourcePolicy{
+					ContainerPolicies: []autoscaling.ContainerPolicy{
+						{
+							ContainerNameRegex: "app-[",
+							PercentageIncrease: &autoscaling.PercentageIncrease{Value: 120},
+						},
+					},
+				}
+			})
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
 		When("the spec has container policy without resource policy", func() {
 			BeforeEach(func() {
 				spec.Spec.ResourcePolicy = autoscaling.ResourcePolicy{
@@ -139,16 +357,81 @@ var _ = Describe("StartupCPUBoost", func() {
 				}
 			})
 			It("returns fixed duration policy implementation", func() {
-				Expect(boost.DurationPolicies()).To(HaveKey(duration.FixedDurationPolicyName))
+				Expect(boost.DurationPolicy()).To(BeAssignableToTypeOf(&duration.FixedDurationPolicy{}))
 			})
 			It("returned fixed duration policy implementation is valid", func() {
-				p := boost.DurationPolicies()[duration.FixedDurationPolicyName]
+				p := boost.DurationPolicy()
 				fixedP, ok := p.(*duration.FixedDurationPolicy)
 				Expect(ok).To(BeTrue())
 				expDuration := time.Duration(spec.Spec.DurationPolicy.Fixed.Value) * time.Second
 				Expect(fixedP.Duration()).To(Equal(expDuration))
 			})
 		})
+		When("the spec has auto duration policy with fallback", func() {
+			BeforeEach(func() {
+				spec.Spec.DurationPolicy.AutoPolicy = &autoscaling.AutoDurationPolicy{
+					ApiEndpoint: "http://predictor.local",
+					Fallback: &autoscaling.FixedDurationPolicy{
+						Unit:  autoscaling.FixedDurationPolicyUnitMin,
+						Value: 2,
+					},
+				}
+			})
+			It("returns auto duration policy implementation with valid fallback", func() {
+				p, ok := boost.DurationPolicy().(*duration.AutoDurationPolicy)
+				Expect(ok).To(BeTrue())
+				Expect(p.Fallback()).To(Equal(2 * time.Minute))
+			})
+		})
+		When("the spec has auto duration policy without fallback", func() {
+			BeforeEach(func() {
+				spec.Spec.DurationPolicy.AutoPolicy = &autoscaling.AutoDurationPolicy{
+					ApiEndpoint: "http://predictor.local",
+				}
+			})
+			It("returns auto duration policy implementation with default fallback", func() {
+				p, ok := boost.DurationPolicy().(*duration.AutoDurationPolicy)
+				Expect(ok).To(BeTrue())
+				Expect(p.Fallback()).To(Equal(duration.DefaultAutoFallback))
+			})
+		})
+		When("the spec has auto duration policy with predictor service", func() {
+			BeforeEach(func() {
+				spec.Spec.DurationPolicy.AutoPolicy = &autoscaling.AutoDurationPolicy{
+					Service: &autoscaling.PredictorServiceReference{
+						Namespace: "predictor-ns",
+						Name:      "predictor",
+					},
+					CABundle: []byte("ca-bundle"),
+				}
+			})
+			It("returns auto duration policy implementation with the service endpoint", func() {
+				p, ok := boost.DurationPolicy().(*duration.AutoDurationPolicy)
+				Expect(ok).To(BeTrue())
+				Expect(p.Endpoint()).To(Equal("https://predictor.predictor-ns.svc:443"))
+			})
+			It("returns the predictor endpoint key", func() {
+				Expect(boost.PredictorEndpoints()).To(HaveLen(1))
+				Expect(boost.PredictorEndpoints()[0].Endpoint).To(Equal("https://predictor.predictor-ns.svc:443"))
+			})
+		})
+		When("the spec has learned duration policy", func() {
+			BeforeEach(func() {
+				spec.Spec.DurationPolicy.Learned = &autoscaling.LearnedDurationPolicy{
+					Condition: corev1.ContainersReady,
+					Fallback: autoscaling.FixedDurationPolicy{
+						Unit:  autoscaling.FixedDurationPolicyUnitSec,
+						Value: 45,
+					},
+				}
+			})
+			It("returns learned duration policy implementation with valid condition and fallback", func() {
+				p, ok := boost.DurationPolicy().(*duration.LearnedDurationPolicy)
+				Expect(ok).To(BeTrue())
+				Expect(p.Condition()).To(Equal(corev1.ContainersReady))
+				Expect(p.Fallback()).To(Equal(45 * time.Second))
+			})
+		})
 		When("the spec has pod condition duration policy", func() {
 			BeforeEach(func() {
 				spec.Spec.DurationPolicy.Fixed = &autoscaling.FixedDurationPolicy{
@@ -161,15 +444,32 @@ var _ = Describe("StartupCPUBoost", func() {
 				}
 			})
 			It("returns pod condition duration policy implementation", func() {
-				Expect(boost.DurationPolicies()).To(HaveKey(duration.PodConditionPolicyName))
+				_, ok := duration.FindPolicy(boost.DurationPolicy(), duration.PodConditionPolicyName)
+				Expect(ok).To(BeTrue())
 			})
 			It("returned pod condition duration policy implementation is valid", func() {
-				p := boost.DurationPolicies()[duration.PodConditionPolicyName]
+				p, _ := duration.FindPolicy(boost.DurationPolicy(), duration.PodConditionPolicyName)
 				podCondP, ok := p.(*duration.PodConditionPolicy)
 				Expect(ok).To(BeTrue())
 				Expect(podCondP.Condition()).To(Equal(spec.Spec.DurationPolicy.PodCondition.Type))
 				Expect(podCondP.Status()).To(Equal(spec.Spec.DurationPolicy.PodCondition.Status))
 			})
+			It("returns composite duration policy with any operator", func() {
+				p, ok := boost.DurationPolicy().(*duration.CompositePolicy)
+				Expect(ok).To(BeTrue())
+				Expect(p.Operator()).To(Equal(duration.OperatorAny))
+				Expect(p.Policies()).To(HaveLen(2))
+			})
+			When("the spec has all duration policy operator", func() {
+				BeforeEach(func() {
+					spec.Spec.DurationPolicy.Operator = autoscaling.DurationPolicyOperatorAll
+				})
+				It("returns composite duration policy with all operator", func() {
+					p, ok := boost.DurationPolicy().(*duration.CompositePolicy)
+					Expect(ok).To(BeTrue())
+					Expect(p.Operator()).To(Equal(duration.OperatorAll))
+				})
+			})
 		})
 	})
 	Describe("Upserts a POD", func() {
@@ -182,7 +482,7 @@ var _ = Describe("StartupCPUBoost", func() {
 			mockClient = mock.NewMockClient(mockCtrl)
 		})
 		JustBeforeEach(func() {
-			boost, err = cpuboost.NewStartupCPUBoost(mockClient, spec)
+			boost, err = cpuboost.NewStartupCPUBoost(resize.NewPatchStrategy(mockClient), spec)
 			Expect(err).ShouldNot(HaveOccurred())
 		})
 		When("POD does not exist", func() {
@@ -252,7 +552,7 @@ var _ = Describe("StartupCPUBoost", func() {
 							Status: corev1.ConditionTrue,
 						}}
 						mockClient.EXPECT().
-							Update(gomock.Any(), gomock.Eq(pod)).
+							Patch(gomock.Any(), gomock.Eq(pod), gomock.Any()).
 							Return(nil)
 					})
 					It("doesn't error", func() {
@@ -273,6 +573,177 @@ var _ = Describe("StartupCPUBoost", func() {
 			})
 		})
 	})
+	Describe("Steps down POD resources through the boost phases", func() {
+		var (
+			mockCtrl   *gomock.Controller
+			mockClient *mock.MockClient
+			violated   []*corev1.Pod
+		)
+		BeforeEach(func() {
+			mockCtrl = gomock.NewController(GinkgoT())
+			mockClient = mock.NewMockClient(mockCtrl)
+			pod.CreationTimestamp = metav1.NewTime(time.Now().Add(-1 * time.Hour))
+			spec.Spec.DurationPolicy = autoscaling.DurationPolicy{
+				Fixed: &autoscaling.FixedDurationPolicy{
+					Unit:  autoscaling.FixedDurationPolicyUnitSec,
+					Value: 30,
+				},
+			}
+			spec.Spec.Phases = []autoscaling.BoostPhase{
+				{
+					PercentageIncrease: autoscaling.PercentageIncrease{Value: 50},
+					DurationPolicy: autoscaling.DurationPolicy{
+						PodCondition: &autoscaling.PodConditionDurationPolicy{
+							Type:   corev1.PodReady,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			}
+		})
+		JustBeforeEach(func() {
+			boost, err = cpuboost.NewStartupCPUBoost(resize.NewPatchStrategy(mockClient), spec)
+			Expect(err).ShouldNot(HaveOccurred())
+			err = boost.UpsertPod(context.TODO(), pod)
+			Expect(err).ShouldNot(HaveOccurred())
+			violated = boost.ValidatePolicy(context.TODO())
+		})
+		It("returns phase duration policies", func() {
+			policies := boost.PhaseDurationPolicies()
+			Expect(policies).To(HaveLen(1))
+			Expect(policies[0]).To(BeAssignableToTypeOf(&duration.PodConditionPolicy{}))
+		})
+		It("returns the POD which duration policy ended", func() {
+			Expect(violated).To(HaveLen(1))
+		})
+		When("the POD resources are reverted", func() {
+			var updated *corev1.Pod
+			JustBeforeEach(func() {
+				mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, p *corev1.Pod, patch client.Patch, opts ...client.PatchOption) error {
+						updated = p
+						return nil
+					})
+				err = boost.RevertResources(context.TODO(), violated[0])
+			})
+			It("doesn't error", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+			It("decreases CPU resources to the phase values", func() {
+				cpuReq := updated.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]
+				Expect(cpuReq.String()).To(Equal("750m"))
+				cpuLimit := updated.Spec.Containers[0].Resources.Limits[corev1.ResourceCPU]
+				Expect(cpuLimit.String()).To(Equal("1500m"))
+			})
+			It("records the phase in the POD annotation", func() {
+				annot, err := bpod.BoostAnnotationFromPod(updated)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(annot.Phase).To(Equal(1))
+				Expect(annot.PhaseTimestamp).NotTo(BeNil())
+			})
+			It("keeps tracking the updated POD", func() {
+				p, found := boost.Pod(pod.Name)
+				Expect(found).To(BeTrue())
+				Expect(p).To(Equal(updated))
+			})
+			It("validates the POD with the phase duration policy", func() {
+				Expect(boost.ValidatePolicy(context.TODO())).To(BeEmpty())
+			})
+			When("the phase duration policy ends", func() {
+				JustBeforeEach(func() {
+					ready := updated.DeepCopy()
+					ready.Status.Conditions = []corev1.PodCondition{{
+						Type:   corev1.PodReady,
+						Status: corev1.ConditionTrue,
+					}}
+					mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+					err = boost.UpsertPod(context.TODO(), ready)
+				})
+				It("doesn't error", func() {
+					Expect(err).ShouldNot(HaveOccurred())
+				})
+				It("reverts the POD resources", func() {
+					_, found := boost.Pod(pod.Name)
+					Expect(found).To(BeFalse())
+				})
+			})
+		})
+	})
+	Describe("Reverts POD containers with container status policy", func() {
+		var (
+			mockCtrl   *gomock.Controller
+			mockClient *mock.MockClient
+			updated    *corev1.Pod
+		)
+		BeforeEach(func() {
+			mockCtrl = gomock.NewController(GinkgoT())
+			mockClient = mock.NewMockClient(mockCtrl)
+			spec.Spec.DurationPolicy = autoscaling.DurationPolicy{
+				ContainerStatus: &autoscaling.ContainerStatusDurationPolicy{
+					Condition: autoscaling.ContainerStatusConditionStarted,
+				},
+			}
+			annot := &bpod.BoostPodAnnotation{
+				BoostTimestamp:  time.Now(),
+				InitCPURequests: map[string]string{"container-one": "500m", "container-two": "500m"},
+				InitCPULimits:   map[string]string{"container-one": "1", "container-two": "1"},
+			}
+			pod.Annotations[bpod.BoostAnnotationKey] = annot.ToJSON()
+			started, notStarted := true, false
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+				{Name: "container-one", Started: &started},
+				{Name: "container-two", Started: &notStarted},
+			}
+			updated = nil
+			mockClient.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, p *corev1.Pod, patch client.Patch, opts ...client.PatchOption) error {
+					updated = p.DeepCopy()
+					return nil
+				}).AnyTimes()
+		})
+		JustBeforeEach(func() {
+			boost, err = cpuboost.NewStartupCPUBoost(resize.NewPatchStrategy(mockClient), spec)
+			Expect(err).ShouldNot(HaveOccurred())
+			err = boost.UpsertPod(context.TODO(), pod)
+		})
+		It("doesn't error", func() {
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		It("reverts the started container", func() {
+			Expect(updated).NotTo(BeNil())
+			cpuReq := updated.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]
+			Expect(cpuReq.String()).To(Equal("500m"))
+		})
+		It("keeps not started container boosted", func() {
+			cpuReq := updated.Spec.Containers[1].Resources.Requests[corev1.ResourceCPU]
+			Expect(cpuReq.String()).To(Equal("1"))
+			annot, err := bpod.BoostAnnotationFromPod(updated)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(annot.BoostedContainers()).To(Equal([]string{"container-two"}))
+		})
+		It("keeps tracking the POD", func() {
+			_, found := boost.Pod(pod.Name)
+			Expect(found).To(BeTrue())
+			Expect(boost.Stats().ActiveContainerBoosts).To(Equal(1))
+		})
+		When("all containers are started", func() {
+			JustBeforeEach(func() {
+				tracked, _ := boost.Pod(pod.Name)
+				allStarted := tracked.DeepCopy()
+				started := true
+				allStarted.Status.ContainerStatuses[1].Started = &started
+				err = boost.UpsertPod(context.TODO(), allStarted)
+			})
+			It("doesn't error", func() {
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+			It("reverts the POD resources", func() {
+				Expect(updated.Labels).NotTo(HaveKey(bpod.BoostLabelKey))
+				_, found := boost.Pod(pod.Name)
+				Expect(found).To(BeFalse())
+			})
+		})
+	})
 	Describe("Deletes a pod", func() {
 		JustBeforeEach(func() {
 			boost, err = cpuboost.NewStartupCPUBoost(nil, spec)
@@ -299,4 +770,124 @@ var _ = Describe("StartupCPUBoost", func() {
 			})
 		})
 	})
+	Describe("Schedules PODs for duration policy validation", func() {
+		var scheduler *podSchedulerStub
+		BeforeEach(func() {
+			scheduler = &podSchedulerStub{deadlines: make(map[string]time.Time)}
+			pod.CreationTimestamp = metav1.NewTime(time.Now())
+			spec.Spec.DurationPolicy = autoscaling.DurationPolicy{
+				Fixed: &autoscaling.FixedDurationPolicy{
+					Unit:  autoscaling.FixedDurationPolicyUnitSec,
+					Value: 30,
+				},
+			}
+		})
+		JustBeforeEach(func() {
+			boost, err = cpuboost.NewStartupCPUBoost(nil, spec)
+			Expect(err).ShouldNot(HaveOccurred())
+			err = boost.UpsertPod(context.TODO(), pod)
+			Expect(err).ShouldNot(HaveOccurred())
+			boost.SetPodScheduler(scheduler)
+		})
+		It("schedules the tracked POD at its policy deadline", func() {
+			Expect(scheduler.deadlines).To(HaveKeyWithValue(pod.Name,
+				pod.CreationTimestamp.Add(30*time.Second)))
+		})
+		It("schedules the upserted POD", func() {
+			other := pod.DeepCopy()
+			other.Name = "other"
+			Expect(boost.UpsertPod(context.TODO(), other)).To(Succeed())
+			Expect(scheduler.deadlines).To(HaveKey(other.Name))
+		})
+		It("unschedules the deleted POD", func() {
+			Expect(boost.DeletePod(context.TODO(), pod)).To(Succeed())
+			Expect(scheduler.deadlines).NotTo(HaveKey(pod.Name))
+		})
+		It("does not return the POD within policy duration as violated", func() {
+			_, violated := boost.ValidatePodPolicy(context.TODO(), pod.Name)
+			Expect(violated).To(BeFalse())
+		})
+		When("the POD duration policy ended", func() {
+			BeforeEach(func() {
+				pod.CreationTimestamp = metav1.NewTime(time.Now().Add(-1 * time.Hour))
+			})
+			It("returns the POD as violated", func() {
+				violated, ok := boost.ValidatePodPolicy(context.TODO(), pod.Name)
+				Expect(ok).To(BeTrue())
+				Expect(violated.Name).To(Equal(pod.Name))
+			})
+		})
+		When("the POD duration policy does not depend on time", func() {
+			BeforeEach(func() {
+				spec.Spec.DurationPolicy = autoscaling.DurationPolicy{
+					PodCondition: &autoscaling.PodConditionDurationPolicy{
+						Type:   corev1.PodReady,
+						Status: corev1.ConditionTrue,
+					},
+				}
+			})
+			It("does not schedule the POD", func() {
+				Expect(scheduler.deadlines).To(BeEmpty())
+			})
+		})
+	})
+	Describe("Transfers state from other startup-cpu-boost", func() {
+		var other cpuboost.StartupCPUBoost
+		JustBeforeEach(func() {
+			other, err = cpuboost.NewStartupCPUBoost(nil, spec)
+			Expect(err).ShouldNot(HaveOccurred())
+			err = other.UpsertPod(context.TODO(), pod)
+			Expect(err).ShouldNot(HaveOccurred())
+			boost, err = cpuboost.NewStartupCPUBoost(nil, spec)
+			Expect(err).ShouldNot(HaveOccurred())
+			boost.TransferState(other)
+		})
+		It("stores the pods", func() {
+			_, found := boost.Pod(pod.Name)
+			Expect(found).To(BeTrue())
+		})
+		It("removes the pods from other startup-cpu-boost", func() {
+			_, found := other.Pod(pod.Name)
+			Expect(found).To(BeFalse())
+		})
+		When("the POD is upserted to other startup-cpu-boost", func() {
+			var otherPod *corev1.Pod
+			JustBeforeEach(func() {
+				otherPod = pod.DeepCopy()
+				otherPod.Name = "other-pod"
+				err = other.UpsertPod(context.TODO(), otherPod)
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+			It("stores the pod", func() {
+				_, found := boost.Pod(otherPod.Name)
+				Expect(found).To(BeTrue())
+			})
+			It("does not store the pod in other startup-cpu-boost", func() {
+				_, found := other.Pod(otherPod.Name)
+				Expect(found).To(BeFalse())
+			})
+		})
+		It("carries over statistics", func() {
+			stats := boost.Stats()
+			Expect(stats.ActiveContainerBoosts).To(Equal(2))
+			Expect(stats.TotalContainerBoosts).To(Equal(2))
+		})
+		It("does not change metrics", func() {
+			Expect(metrics.BoostContainersActive(boost.Namespace(), boost.Name())).To(Equal(float64(2)))
+			Expect(metrics.BoostContainersTotal(boost.Namespace(), boost.Name())).To(Equal(float64(2)))
+		})
+	})
 })
+
+// podSchedulerStub records the deadlines of the scheduled PODs
+type podSchedulerStub struct {
+	deadlines map[string]time.Time
+}
+
+func (s *podSchedulerStub) SchedulePod(_ cpuboost.StartupCPUBoost, podName string, deadline time.Time) {
+	s.deadlines[podName] = deadline
+}
+
+func (s *podSchedulerStub) UnschedulePod(_ cpuboost.StartupCPUBoost, podName string) {
+	delete(s.deadlines, podName)
+}
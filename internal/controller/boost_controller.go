@@ -16,44 +16,80 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	"github.com/go-logr/logr"
 	autoscaling "github.com/google/kube-startup-cpu-boost/api/v1alpha1"
 	"github.com/google/kube-startup-cpu-boost/internal/boost"
+	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resize"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
-	BoostActiveConditionTrueReason   = "Ready"
-	BoostActiveConditionTrueMessage  = "Can boost new containers"
-	BoostActiveConditionFalseReason  = "NotFound"
-	BoostActiveConditionFalseMessage = "StartupCPUBoost not found"
+	BoostActiveConditionTrueReason      = "Ready"
+	BoostActiveConditionTrueMessage     = "Can boost new containers"
+	BoostActiveConditionFalseReason     = "NotFound"
+	BoostActiveConditionFalseMessage    = "StartupCPUBoost not found"
+	BoostActiveConditionDeletingReason  = "Deleting"
+	BoostActiveConditionDeletingMessage = "StartupCPUBoost is being deleted"
+	BoostRevertedConditionFalseReason   = "RevertingPods"
+	BoostRevertedConditionFalseMessage  = "Reverting resources of %d boosted pods"
+	BoostRevertedConditionErrorReason   = "RevertFailed"
+	BoostRevertedConditionErrorMessage  = "Failed to revert resources of %d boosted pods: %s"
+	BoostResizedConditionTrueReason     = "Completed"
+	BoostResizedConditionTrueMessage    = "In-place resizes of boosted pods are applied"
+	BoostResizedConditionFalseReason    = "ResizeFailed"
+	BoostResizedConditionFalseMessage   = "In-place resize of %d boosted pods was refused or is stuck"
+
+	// BoostFinalizer is the finalizer that makes the StartupCPUBoost deletion wait
+	// until all of its boosted PODs have their resources reverted
+	BoostFinalizer = "autoscaling.x-k8s.io/startup-cpu-boost"
+	// BoostDeletionRequeueInterval is the time after which the deletion of a
+	// StartupCPUBoost is retried when some of its PODs failed to revert
+	BoostDeletionRequeueInterval = 5 * time.Second
+	// BoostResizeCheckInterval is the time after which a StartupCPUBoost is reconciled
+	// again when the in-place resizes of its PODs are pending, so the stuck ones are
+	// reported
+	BoostResizeCheckInterval = 30 * time.Second
 )
 
 // StartupCPUBoostReconciler reconciles a StartupCPUBoost object
 type StartupCPUBoostReconciler struct {
 	client.Client
-	Scheme  *runtime.Scheme
-	Log     logr.Logger
-	Manager boost.Manager
+	Scheme   *runtime.Scheme
+	Log      logr.Logger
+	Manager  boost.Manager
+	Resizer  resize.Strategy
+	Recorder record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=autoscaling.x-k8s.io,resources=startupcpuboosts,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=autoscaling.x-k8s.io,resources=startupcpuboosts/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=autoscaling.x-k8s.io,resources=startupcpuboosts/finalizers,verbs=update
-//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;update;watch
+//+kubebuilder:rbac:groups=autoscaling.x-k8s.io,resources=startupprofiles,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups=autoscaling.x-k8s.io,resources=startupprofiles/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;update;patch;watch
+//+kubebuilder:rbac:groups="",resources=pods/resize,verbs=patch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;update
+//+kubebuilder:rbac:groups=metrics.k8s.io,resources=pods,verbs=get
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -64,6 +100,21 @@ func (r *StartupCPUBoostReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 	log := r.Log.WithValues("name", boostObj.Name, "namespace", boostObj.Namespace)
+	if !boostObj.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, &boostObj, log)
+	}
+	if !controllerutil.ContainsFinalizer(&boostObj, BoostFinalizer) {
+		log.V(5).Info("adding boost finalizer")
+		controllerutil.AddFinalizer(&boostObj, BoostFinalizer)
+		if err = r.Client.Update(ctx, &boostObj); err != nil {
+			if apierrors.IsConflict(err) {
+				log.V(5).Info("boost finalizer update conflict, requeueing")
+				return ctrl.Result{Requeue: true}, nil
+			}
+			log.Error(err, "boost finalizer update error")
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+	}
 	newBoostObj := boostObj.DeepCopy()
 	activeCondition := metav1.Condition{
 		Type:    "Active",
@@ -71,15 +122,22 @@ func (r *StartupCPUBoostReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		Reason:  BoostActiveConditionFalseReason,
 		Message: BoostActiveConditionFalseMessage,
 	}
+	var result ctrl.Result
 	boost, ok := r.Manager.StartupCPUBoost(boostObj.Namespace, boostObj.Name)
 	if ok {
 		log.V(5).Info("found boost in a manager")
+		r.reportStuckPodResizes(boost)
 		stats := boost.Stats()
 		activeCondition.Status = metav1.ConditionTrue
 		activeCondition.Reason = BoostActiveConditionTrueReason
 		activeCondition.Message = BoostActiveConditionTrueMessage
 		newBoostObj.Status.ActiveContainerBoosts = int32(stats.ActiveContainerBoosts)
 		newBoostObj.Status.TotalContainerBoosts = int32(stats.TotalContainerBoosts)
+		setResizedCondition(newBoostObj, stats)
+		setLearnedResources(newBoostObj, stats)
+		if stats.PendingResizes > stats.FailedResizes {
+			result.RequeueAfter = BoostResizeCheckInterval
+		}
 	}
 	meta.SetStatusCondition(&newBoostObj.Status.Conditions, activeCondition)
 	if !equality.Semantic.DeepEqual(newBoostObj.Status, boostObj.Status) {
@@ -94,21 +152,216 @@ func (r *StartupCPUBoostReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		log.Error(err, "boost status update error")
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	return result, nil
+}
+
+// reportStuckPodResizes records the events for the in-place resizes of StartupCPUBoost
+// PODs that became stuck
+func (r *StartupCPUBoostReconciler) reportStuckPodResizes(b boost.StartupCPUBoost) {
+	for _, podResize := range b.CheckPodResizes(time.Now()) {
+		r.Recorder.Eventf(podResize.Pod, corev1.EventTypeWarning, PodResizeStuckEventReason,
+			PodResizeStuckEventMessage, b.Name(), boost.ResizeStuckTimeout, podResize.Status.State)
+	}
+}
+
+// setResizedCondition sets the Resized condition of a StartupCPUBoost to false when
+// the in-place resizes of its PODs failed. The condition is set back to true once
+// there are no failed resizes.
+func setResizedCondition(boostObj *autoscaling.StartupCPUBoost, stats boost.StartupCPUBoostStats) {
+	if stats.FailedResizes > 0 {
+		meta.SetStatusCondition(&boostObj.Status.Conditions, metav1.Condition{
+			Type:    "Resized",
+			Status:  metav1.ConditionFalse,
+			Reason:  BoostResizedConditionFalseReason,
+			Message: fmt.Sprintf(BoostResizedConditionFalseMessage, stats.FailedResizes),
+		})
+		return
+	}
+	if meta.FindStatusCondition(boostObj.Status.Conditions, "Resized") == nil {
+		return
+	}
+	meta.SetStatusCondition(&boostObj.Status.Conditions, metav1.Condition{
+		Type:    "Resized",
+		Status:  metav1.ConditionTrue,
+		Reason:  BoostResizedConditionTrueReason,
+		Message: BoostResizedConditionTrueMessage,
+	})
+}
+
+// setLearnedResources sets the learned resources of a StartupCPUBoost status to the CPU
+// targets chosen by its learned resource policies. The status is kept when no targets
+// were chosen yet, i.e. after the controller restart.
+func setLearnedResources(boostObj *autoscaling.StartupCPUBoost, stats boost.StartupCPUBoostStats) {
+	if len(stats.LearnedResources) == 0 {
+		return
+	}
+	learnedResources := make([]autoscaling.LearnedResourceStatus, 0, len(stats.LearnedResources))
+	for _, decision := range stats.LearnedResources {
+		learnedResources = append(learnedResources, autoscaling.LearnedResourceStatus{
+			Workload:       decision.Workload,
+			Container:      decision.Container,
+			CPU:            decision.CPU,
+			Samples:        int32(decision.Samples),
+			Reason:         decision.Reason,
+			Message:        decision.Message,
+			LastUpdateTime: metav1.NewTime(decision.Time),
+		})
+	}
+	boostObj.Status.LearnedResources = learnedResources
+}
+
+// reconcileDelete reverts the resources of all PODs boosted by a StartupCPUBoost
+// that is being deleted and removes the finalizer once there are no PODs left.
+// The progress is reported with the Reverted status condition.
+func (r *StartupCPUBoostReconciler) reconcileDelete(ctx context.Context, boostObj *autoscaling.StartupCPUBoost,
+	log logr.Logger) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(boostObj, BoostFinalizer) {
+		return ctrl.Result{}, nil
+	}
+	log.V(5).Info("handling boost deletion")
+	r.Manager.RemoveStartupCPUBoost(ctx, boostObj.Namespace, boostObj.Name)
+	var podList corev1.PodList
+	if err := r.Client.List(ctx, &podList,
+		client.InNamespace(boostObj.Namespace),
+		client.MatchingLabels{bpod.BoostLabelKey: boostObj.Name},
+	); err != nil {
+		log.Error(err, "boost pods list error")
+		return ctrl.Result{}, err
+	}
+	// the terminating PODs are not reverted, as their resources are released anyway
+	pods := make([]*corev1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		if podList.Items[i].DeletionTimestamp != nil {
+			continue
+		}
+		pods = append(pods, &podList.Items[i])
+	}
+	if cnt := len(pods); cnt > 0 {
+		revertedCondition := metav1.Condition{
+			Type:    "Reverted",
+			Status:  metav1.ConditionFalse,
+			Reason:  BoostRevertedConditionFalseReason,
+			Message: fmt.Sprintf(BoostRevertedConditionFalseMessage, cnt),
+		}
+		if result, err := r.updateDeletingStatus(ctx, boostObj, revertedCondition, log); err != nil || !result.IsZero() {
+			return result, err
+		}
+	}
+	var errs []error
+	for _, pod := range pods {
+		if err := r.revertPod(ctx, pod); err != nil {
+			errs = append(errs, fmt.Errorf("pod %s: %w", pod.Name, err))
+			continue
+		}
+		log.V(5).Info("pod resources reverted", "pod", pod.Name)
+	}
+	if len(errs) > 0 {
+		err := errors.Join(errs...)
+		log.Error(err, "boost pods resources reversion failed")
+		revertedCondition := metav1.Condition{
+			Type:    "Reverted",
+			Status:  metav1.ConditionFalse,
+			Reason:  BoostRevertedConditionErrorReason,
+			Message: fmt.Sprintf(BoostRevertedConditionErrorMessage, len(errs), err),
+		}
+		if result, err := r.updateDeletingStatus(ctx, boostObj, revertedCondition, log); err != nil || !result.IsZero() {
+			return result, err
+		}
+		return ctrl.Result{RequeueAfter: BoostDeletionRequeueInterval}, nil
+	}
+	controllerutil.RemoveFinalizer(boostObj, BoostFinalizer)
+	if err := r.Client.Update(ctx, boostObj); err != nil {
+		if apierrors.IsConflict(err) {
+			log.V(5).Info("boost finalizer update conflict, requeueing")
+			return ctrl.Result{Requeue: true}, nil
+		}
+		log.Error(err, "boost finalizer update error")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	log.Info("boost pods reverted, finalizer removed")
+	return ctrl.Result{}, nil
+}
+
+// updateDeletingStatus updates the status of a StartupCPUBoost that is being deleted
+// with a given Reverted condition
+func (r *StartupCPUBoostReconciler) updateDeletingStatus(ctx context.Context, boostObj *autoscaling.StartupCPUBoost,
+	revertedCondition metav1.Condition, log logr.Logger) (ctrl.Result, error) {
+	newBoostObj := boostObj.DeepCopy()
+	meta.SetStatusCondition(&newBoostObj.Status.Conditions, metav1.Condition{
+		Type:    "Active",
+		Status:  metav1.ConditionFalse,
+		Reason:  BoostActiveConditionDeletingReason,
+		Message: BoostActiveConditionDeletingMessage,
+	})
+	meta.SetStatusCondition(&newBoostObj.Status.Conditions, revertedCondition)
+	if equality.Semantic.DeepEqual(newBoostObj.Status, boostObj.Status) {
+		return ctrl.Result{}, nil
+	}
+	log.V(5).Info("updating boost status")
+	if err := r.Client.Status().Update(ctx, newBoostObj); err != nil {
+		if apierrors.IsConflict(err) {
+			log.V(5).Info("boost status update conflict, requeueing")
+			return ctrl.Result{Requeue: true}, nil
+		}
+		log.Error(err, "boost status update error")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	newBoostObj.DeepCopyInto(boostObj)
 	return ctrl.Result{}, nil
 }
 
+// revertPod updates POD's container resource requests and limits to their original
+// values using the data from StartupCPUBoost annotation. The POD that is gone or
+// terminating is considered as reverted, as the update of the terminating POD may
+// conflict or be refused as invalid.
+func (r *StartupCPUBoostReconciler) revertPod(ctx context.Context, pod *corev1.Pod) error {
+	err := boost.RevertPodResources(ctx, r.Resizer, r.Recorder, pod)
+	if err == nil || apierrors.IsNotFound(err) {
+		return nil
+	}
+	if !apierrors.IsConflict(err) && !apierrors.IsInvalid(err) {
+		return err
+	}
+	var current corev1.Pod
+	if getErr := r.Client.Get(ctx, client.ObjectKeyFromObject(pod), &current); getErr != nil {
+		if apierrors.IsNotFound(getErr) {
+			return nil
+		}
+		return err
+	}
+	if current.DeletionTimestamp != nil {
+		return nil
+	}
+	return err
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *StartupCPUBoostReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	boostPodHandler := NewBoostPodHandler(r.Manager, ctrl.Log.WithName("pod-handler"))
+	boostPodHandler := NewBoostPodHandler(r.Manager, r.Recorder, ctrl.Log.WithName("pod-handler"))
 	lsPredicate, err := predicate.LabelSelectorPredicate(*boostPodHandler.GetPodLabelSelector())
 	if err != nil {
 		return err
 	}
+	// the PODs which resources were reverted no longer have the boost label, but their
+	// in-place resize is tracked until it completes and their startup is tracked until
+	// they meet the condition of the learned duration policy or are recorded in the
+	// startup profile
+	resizePredicate := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		pod, ok := obj.(*corev1.Pod)
+		if !ok {
+			return false
+		}
+		if _, ok = r.Manager.StartupCPUBoostForPodResize(pod); ok {
+			return true
+		}
+		_, ok = r.Manager.StartupCPUBoostForPodStartup(pod)
+		return ok
+	})
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&autoscaling.StartupCPUBoost{}).
 		Watches(&corev1.Pod{},
 			boostPodHandler,
-			builder.WithPredicates(lsPredicate)).
+			builder.WithPredicates(predicate.Or(lsPredicate, resizePredicate))).
 		WithEventFilter(r).
 		Complete(r)
 }
@@ -120,10 +373,15 @@ func (r *StartupCPUBoostReconciler) Create(e event.CreateEvent) bool {
 	}
 	log := r.Log.WithValues("name", boostObj.Name, "namespace", boostObj.Namespace)
 	log.V(5).Info("handling boost create event")
+	if !boostObj.DeletionTimestamp.IsZero() {
+		log.V(5).Info("boost is being deleted, skipping registration")
+		return true
+	}
 	ctx := ctrl.LoggerInto(context.Background(), log)
-	boost, err := boost.NewStartupCPUBoost(r.Client, boostObj)
+	boost, err := boost.NewStartupCPUBoostWithRecorder(r.Resizer, r.Client, r.Recorder, boostObj)
 	if err != nil {
 		log.Error(err, "boost creation error")
+		return true
 	}
 	if err := r.Manager.AddStartupCPUBoost(ctx, boost); err != nil {
 		log.Error(err, "boost registration error")
@@ -145,11 +403,35 @@ func (r *StartupCPUBoostReconciler) Delete(e event.DeleteEvent) bool {
 
 func (r *StartupCPUBoostReconciler) Update(e event.UpdateEvent) bool {
 	boostObj, ok := e.ObjectNew.(*autoscaling.StartupCPUBoost)
-	if !ok {
+	oldBoostObj, oldOk := e.ObjectOld.(*autoscaling.StartupCPUBoost)
+	if !ok || !oldOk {
 		return true
 	}
 	log := r.Log.WithValues("name", boostObj.Name, "namespace", boostObj.Namespace)
 	log.V(5).Info("handling boost update event")
+	if !boostObj.DeletionTimestamp.IsZero() {
+		return true
+	}
+	if equality.Semantic.DeepEqual(boostObj.Spec, oldBoostObj.Spec) &&
+		equality.Semantic.DeepEqual(boostObj.Selector, oldBoostObj.Selector) {
+		log.V(5).Info("boost spec did not change")
+		return true
+	}
+	ctx := ctrl.LoggerInto(context.Background(), log)
+	newBoost, err := boost.NewStartupCPUBoostWithRecorder(r.Resizer, r.Client, r.Recorder, boostObj)
+	if err != nil {
+		log.Error(err, "boost creation error")
+		return true
+	}
+	if _, ok := r.Manager.StartupCPUBoost(boostObj.Namespace, boostObj.Name); !ok {
+		if err := r.Manager.AddStartupCPUBoost(ctx, newBoost); err != nil {
+			log.Error(err, "boost registration error")
+		}
+		return true
+	}
+	if err := r.Manager.UpdateStartupCPUBoost(ctx, newBoost); err != nil {
+		log.Error(err, "boost update error")
+	}
 	return true
 }
 
@@ -16,20 +16,32 @@ package controller_test
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/go-logr/logr"
 	autoscaling "github.com/google/kube-startup-cpu-boost/api/v1alpha1"
 	"github.com/google/kube-startup-cpu-boost/internal/boost"
+	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resize"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/resource"
 	"github.com/google/kube-startup-cpu-boost/internal/controller"
 	"github.com/google/kube-startup-cpu-boost/internal/mock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	apiResource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
 var _ = Describe("BoostController", func() {
@@ -38,6 +50,8 @@ var _ = Describe("BoostController", func() {
 		mockClient  *mock.MockClient
 		mockManager *mock.MockManager
 		mockBoost   *mock.MockStartupCPUBoost
+		recorder    *record.FakeRecorder
+		stuck       []boost.PodResize
 		boostCtrl   controller.StartupCPUBoostReconciler
 	)
 	BeforeEach(func() {
@@ -45,10 +59,17 @@ var _ = Describe("BoostController", func() {
 		mockClient = mock.NewMockClient(mockCtrl)
 		mockManager = mock.NewMockManager(mockCtrl)
 		mockBoost = mock.NewMockStartupCPUBoost(mockCtrl)
+		stuck = nil
+		mockBoost.EXPECT().CheckPodResizes(gomock.Any()).DoAndReturn(func(now time.Time) []boost.PodResize {
+			return stuck
+		}).AnyTimes()
+		recorder = record.NewFakeRecorder(10)
 		boostCtrl = controller.StartupCPUBoostReconciler{
-			Log:     logr.Discard(),
-			Client:  mockClient,
-			Manager: mockManager,
+			Log:      logr.Discard(),
+			Client:   mockClient,
+			Manager:  mockManager,
+			Resizer:  resize.NewPatchStrategy(mockClient),
+			Recorder: recorder,
 		}
 	})
 	Describe("Receives reconcile request", func() {
@@ -95,6 +116,7 @@ var _ = Describe("BoostController", func() {
 						boostObj := obj.(*autoscaling.StartupCPUBoost)
 						boostObj.Name = name
 						boostObj.Namespace = namespace
+						boostObj.Finalizers = []string{controller.BoostFinalizer}
 						meta.SetStatusCondition(&boostObj.Status.Conditions, activeConditionTrue)
 						boostObj.Status.TotalContainerBoosts = int32(totalContainerBoosts)
 						boostObj.Status.ActiveContainerBoosts = int32(activeContainerBoosts)
@@ -128,6 +150,7 @@ var _ = Describe("BoostController", func() {
 						boostObj := obj.(*autoscaling.StartupCPUBoost)
 						boostObj.Name = name
 						boostObj.Namespace = namespace
+						boostObj.Finalizers = []string{controller.BoostFinalizer}
 						return nil
 					})
 					mockClient.EXPECT().Status().Return(mockSubResWriter).Times(1)
@@ -140,5 +163,370 @@ var _ = Describe("BoostController", func() {
 				})
 			})
 		})
+		When("boost has pending in-place resizes", func() {
+			var (
+				stats        boost.StartupCPUBoostStats
+				statusUpdate *autoscaling.StartupCPUBoost
+				boostObj     *autoscaling.StartupCPUBoost
+			)
+			BeforeEach(func() {
+				stats = boost.StartupCPUBoostStats{PendingResizes: 2}
+				statusUpdate = nil
+				boostObj = &autoscaling.StartupCPUBoost{}
+				boostObj.Name = name
+				boostObj.Namespace = namespace
+				boostObj.Finalizers = []string{controller.BoostFinalizer}
+				meta.SetStatusCondition(&boostObj.Status.Conditions, metav1.Condition{
+					Type:    "Active",
+					Status:  metav1.ConditionTrue,
+					Reason:  controller.BoostActiveConditionTrueReason,
+					Message: controller.BoostActiveConditionTrueMessage,
+				})
+				mockManager.EXPECT().StartupCPUBoost(gomock.Eq(namespace), gomock.Eq(name)).Times(1).Return(mockBoost, true)
+				mockBoost.EXPECT().Stats().Times(1).DoAndReturn(func() boost.StartupCPUBoostStats {
+					return stats
+				})
+				mockClient.EXPECT().Get(gomock.Any(), gomock.Eq(req.NamespacedName), gomock.Any()).
+					Times(1).DoAndReturn(func(c context.Context, cc client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					boostObj.DeepCopyInto(obj.(*autoscaling.StartupCPUBoost))
+					return nil
+				})
+				mockSubResWriter := mock.NewMockSubResourceWriter(mockCtrl)
+				mockSubResWriter.EXPECT().Update(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(c context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+						statusUpdate = obj.(*autoscaling.StartupCPUBoost)
+						return nil
+					}).AnyTimes()
+				mockClient.EXPECT().Status().Return(mockSubResWriter).AnyTimes()
+			})
+			It("does not error", func() {
+				Expect(err).To(BeNil())
+			})
+			It("requeues to check the resizes", func() {
+				Expect(result).To(Equal(ctrl.Result{RequeueAfter: controller.BoostResizeCheckInterval}))
+			})
+			It("does not update the status", func() {
+				Expect(statusUpdate).To(BeNil())
+			})
+			When("some of the resizes failed", func() {
+				BeforeEach(func() {
+					stats.FailedResizes = 1
+				})
+				It("sets the Resized condition to false", func() {
+					Expect(statusUpdate).NotTo(BeNil())
+					cond := meta.FindStatusCondition(statusUpdate.Status.Conditions, "Resized")
+					Expect(cond).NotTo(BeNil())
+					Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+					Expect(cond.Reason).To(Equal(controller.BoostResizedConditionFalseReason))
+				})
+			})
+			When("all of the resizes failed", func() {
+				BeforeEach(func() {
+					stats.FailedResizes = 2
+				})
+				It("returns empty result", func() {
+					Expect(result).To(Equal(ctrl.Result{}))
+				})
+			})
+			When("the failed resizes are gone", func() {
+				BeforeEach(func() {
+					stats.PendingResizes = 0
+					meta.SetStatusCondition(&boostObj.Status.Conditions, metav1.Condition{
+						Type:   "Resized",
+						Status: metav1.ConditionFalse,
+						Reason: controller.BoostResizedConditionFalseReason,
+					})
+				})
+				It("sets the Resized condition to true", func() {
+					Expect(statusUpdate).NotTo(BeNil())
+					cond := meta.FindStatusCondition(statusUpdate.Status.Conditions, "Resized")
+					Expect(cond).NotTo(BeNil())
+					Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+				})
+			})
+			When("the learned resource policy chose the CPU targets", func() {
+				BeforeEach(func() {
+					stats.LearnedResources = []resource.LearnedDecision{{
+						Workload:  "deployment.demo.0123",
+						Container: "main",
+						CPU:       apiResource.MustParse("1250m"),
+						Samples:   6,
+						Reason:    resource.LearnedReasonExploring,
+						Message:   "Median time to ready 40s with 1 CPU is the fastest, trying 1250m CPU",
+						Time:      time.Now(),
+					}}
+				})
+				It("sets the learned resources in the status", func() {
+					Expect(statusUpdate).NotTo(BeNil())
+					Expect(statusUpdate.Status.LearnedResources).To(HaveLen(1))
+					learned := statusUpdate.Status.LearnedResources[0]
+					Expect(learned.Workload).To(Equal("deployment.demo.0123"))
+					Expect(learned.Container).To(Equal("main"))
+					Expect(learned.CPU.String()).To(Equal("1250m"))
+					Expect(learned.Samples).To(Equal(int32(6)))
+					Expect(learned.Reason).To(Equal(resource.LearnedReasonExploring))
+				})
+			})
+			When("no CPU targets were chosen since the controller start", func() {
+				BeforeEach(func() {
+					stats.FailedResizes = 1
+					boostObj.Status.LearnedResources = []autoscaling.LearnedResourceStatus{{
+						Workload:  "deployment.demo.0123",
+						Container: "main",
+						CPU:       apiResource.MustParse("1"),
+						Reason:    resource.LearnedReasonInitial,
+					}}
+				})
+				It("keeps the learned resources in the status", func() {
+					Expect(statusUpdate).NotTo(BeNil())
+					Expect(statusUpdate.Status.LearnedResources).To(HaveLen(1))
+				})
+			})
+			When("some of the resizes became stuck", func() {
+				BeforeEach(func() {
+					mockBoost.EXPECT().Name().Return(name).AnyTimes()
+					stuck = []boost.PodResize{{
+						Pod:    podTemplate.DeepCopy(),
+						Status: boost.PodResizeStatus{State: bpod.ResizeStateDeferred, Stuck: true},
+					}}
+				})
+				It("records the resize event", func() {
+					Expect(recorder.Events).To(HaveLen(1))
+					event := <-recorder.Events
+					Expect(event).To(ContainSubstring(controller.PodResizeStuckEventReason))
+				})
+			})
+		})
+		When("boost has no finalizer", func() {
+			BeforeEach(func() {
+				mockManager.EXPECT().StartupCPUBoost(gomock.Eq(namespace), gomock.Eq(name)).Times(1).Return(mockBoost, true)
+				mockBoost.EXPECT().Stats().Times(1).Return(boost.StartupCPUBoostStats{})
+				mockClient.EXPECT().Get(gomock.Any(), gomock.Eq(req.NamespacedName), gomock.Any()).
+					Times(1).DoAndReturn(func(c context.Context, cc client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					boostObj := obj.(*autoscaling.StartupCPUBoost)
+					boostObj.Name = name
+					boostObj.Namespace = namespace
+					return nil
+				})
+				mockClient.EXPECT().Update(gomock.Any(), gomock.Cond(func(b any) bool {
+					boostObj, ok := b.(*autoscaling.StartupCPUBoost)
+					return ok && controllerutil.ContainsFinalizer(boostObj, controller.BoostFinalizer)
+				})).Return(nil).Times(1)
+				mockSubResWriter := mock.NewMockSubResourceWriter(mockCtrl)
+				mockSubResWriter.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				mockClient.EXPECT().Status().Return(mockSubResWriter).Times(1)
+			})
+			It("does not error", func() {
+				Expect(err).To(BeNil())
+			})
+			It("returns empty result", func() {
+				Expect(result).To(Equal(ctrl.Result{}))
+			})
+		})
+		When("boost is being deleted", func() {
+			var (
+				pod              *corev1.Pod
+				pods             []corev1.Pod
+				mockSubResWriter *mock.MockSubResourceWriter
+				podUpdateCall    *gomock.Call
+				boostUpdateCall  *gomock.Call
+			)
+			BeforeEach(func() {
+				pod = podTemplate.DeepCopy()
+				pods = []corev1.Pod{*pod}
+				mockManager.EXPECT().RemoveStartupCPUBoost(gomock.Any(), gomock.Eq(namespace), gomock.Eq(name)).Times(1)
+				mockClient.EXPECT().Get(gomock.Any(), gomock.Eq(req.NamespacedName), gomock.Any()).
+					Times(1).DoAndReturn(func(c context.Context, cc client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					boostObj := obj.(*autoscaling.StartupCPUBoost)
+					boostObj.Name = name
+					boostObj.Namespace = namespace
+					boostObj.Finalizers = []string{controller.BoostFinalizer}
+					now := metav1.Now()
+					boostObj.DeletionTimestamp = &now
+					return nil
+				})
+				mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).DoAndReturn(func(c context.Context, list client.ObjectList, opts ...client.ListOption) error {
+					podList := list.(*corev1.PodList)
+					podList.Items = pods
+					return nil
+				})
+				mockSubResWriter = mock.NewMockSubResourceWriter(mockCtrl)
+				mockClient.EXPECT().Status().Return(mockSubResWriter).AnyTimes()
+				mockSubResWriter.EXPECT().Update(gomock.Any(), gomock.Cond(func(b any) bool {
+					boostObj := b.(*autoscaling.StartupCPUBoost)
+					cond := meta.FindStatusCondition(boostObj.Status.Conditions, "Reverted")
+					return cond != nil && cond.Status == metav1.ConditionFalse
+				})).Return(nil).MinTimes(1)
+				podUpdateCall = mockClient.EXPECT().Patch(gomock.Any(), gomock.Cond(func(p any) bool {
+					updatedPod, ok := p.(*corev1.Pod)
+					if !ok {
+						return false
+					}
+					_, hasLabel := updatedPod.Labels[bpod.BoostLabelKey]
+					return updatedPod.Name == pod.Name && !hasLabel
+				}), gomock.Any())
+				boostUpdateCall = mockClient.EXPECT().Update(gomock.Any(), gomock.Cond(func(b any) bool {
+					boostObj, ok := b.(*autoscaling.StartupCPUBoost)
+					return ok && !controllerutil.ContainsFinalizer(boostObj, controller.BoostFinalizer)
+				}))
+			})
+			When("pod resources are reverted", func() {
+				BeforeEach(func() {
+					podUpdateCall.Return(nil).Times(1)
+					boostUpdateCall.Return(nil).Times(1)
+				})
+				It("does not error", func() {
+					Expect(err).To(BeNil())
+				})
+				It("returns empty result", func() {
+					Expect(result).To(Equal(ctrl.Result{}))
+				})
+			})
+			When("pod resources reversion fails", func() {
+				BeforeEach(func() {
+					podUpdateCall.Return(errors.New("update failed")).Times(1)
+					boostUpdateCall.Times(0)
+				})
+				It("does not error", func() {
+					Expect(err).To(BeNil())
+				})
+				It("returns requeue result", func() {
+					Expect(result).To(Equal(ctrl.Result{RequeueAfter: controller.BoostDeletionRequeueInterval}))
+				})
+			})
+			When("pod resources reversion conflicts", func() {
+				var podGetCall *gomock.Call
+				BeforeEach(func() {
+					podUpdateCall.Return(apierrors.NewConflict(schema.GroupResource{Resource: "pods"},
+						pod.Name, errors.New("conflict"))).Times(1)
+					podGetCall = mockClient.EXPECT().Get(gomock.Any(),
+						gomock.Eq(types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}), gomock.Any())
+				})
+				When("pod is terminating", func() {
+					BeforeEach(func() {
+						podGetCall.DoAndReturn(func(c context.Context, cc client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+							now := metav1.Now()
+							obj.(*corev1.Pod).DeletionTimestamp = &now
+							return nil
+						}).Times(1)
+						boostUpdateCall.Return(nil).Times(1)
+					})
+					It("does not error", func() {
+						Expect(err).To(BeNil())
+					})
+					It("returns empty result", func() {
+						Expect(result).To(Equal(ctrl.Result{}))
+					})
+				})
+				When("pod is not terminating", func() {
+					BeforeEach(func() {
+						podGetCall.Return(nil).Times(1)
+						boostUpdateCall.Times(0)
+					})
+					It("returns requeue result", func() {
+						Expect(result).To(Equal(ctrl.Result{RequeueAfter: controller.BoostDeletionRequeueInterval}))
+					})
+				})
+			})
+			When("one of the pods is terminating", func() {
+				BeforeEach(func() {
+					terminating := podTemplate.DeepCopy()
+					terminating.Name = "terminating-pod"
+					now := metav1.Now()
+					terminating.DeletionTimestamp = &now
+					pods = []corev1.Pod{*terminating, *pod}
+					podUpdateCall.Return(nil).Times(1)
+					boostUpdateCall.Return(nil).Times(1)
+				})
+				It("does not error", func() {
+					Expect(err).To(BeNil())
+				})
+				It("returns empty result", func() {
+					Expect(result).To(Equal(ctrl.Result{}))
+				})
+			})
+		})
+	})
+	Describe("Receives update event", func() {
+		var (
+			oldBoostObj *autoscaling.StartupCPUBoost
+			newBoostObj *autoscaling.StartupCPUBoost
+			result      bool
+		)
+		BeforeEach(func() {
+			oldBoostObj = &autoscaling.StartupCPUBoost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "boost-001",
+					Namespace: "demo",
+				},
+				Spec: autoscaling.StartupCPUBoostSpec{
+					DurationPolicy: autoscaling.DurationPolicy{
+						Fixed: &autoscaling.FixedDurationPolicy{
+							Unit:  autoscaling.FixedDurationPolicyUnitSec,
+							Value: 60,
+						},
+					},
+				},
+			}
+			newBoostObj = oldBoostObj.DeepCopy()
+		})
+		JustBeforeEach(func() {
+			result = boostCtrl.Update(event.UpdateEvent{
+				ObjectOld: oldBoostObj,
+				ObjectNew: newBoostObj,
+			})
+		})
+		When("boost spec did not change", func() {
+			BeforeEach(func() {
+				newBoostObj.Status.ActiveContainerBoosts = 1
+				mockManager.EXPECT().UpdateStartupCPUBoost(gomock.Any(), gomock.Any()).Times(0)
+				mockManager.EXPECT().AddStartupCPUBoost(gomock.Any(), gomock.Any()).Times(0)
+			})
+			It("returns true", func() {
+				Expect(result).To(BeTrue())
+			})
+		})
+		When("boost spec changed", func() {
+			BeforeEach(func() {
+				newBoostObj.Spec.DurationPolicy.Fixed.Value = 120
+			})
+			When("boost is registered in boost manager", func() {
+				BeforeEach(func() {
+					mockManager.EXPECT().StartupCPUBoost(gomock.Eq(newBoostObj.Namespace), gomock.Eq(newBoostObj.Name)).
+						Return(mockBoost, true)
+					mockManager.EXPECT().UpdateStartupCPUBoost(gomock.Any(), gomock.Any()).Times(1).
+						DoAndReturn(func(ctx context.Context, b boost.StartupCPUBoost) error {
+							Expect(b.Name()).To(Equal(newBoostObj.Name))
+							Expect(b.Namespace()).To(Equal(newBoostObj.Namespace))
+							return nil
+						})
+				})
+				It("returns true", func() {
+					Expect(result).To(BeTrue())
+				})
+			})
+			When("boost is not registered in boost manager", func() {
+				BeforeEach(func() {
+					mockManager.EXPECT().StartupCPUBoost(gomock.Eq(newBoostObj.Namespace), gomock.Eq(newBoostObj.Name)).
+						Return(nil, false)
+					mockManager.EXPECT().AddStartupCPUBoost(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+				})
+				It("returns true", func() {
+					Expect(result).To(BeTrue())
+				})
+			})
+		})
+		When("boost is being deleted", func() {
+			BeforeEach(func() {
+				newBoostObj.Spec.DurationPolicy.Fixed.Value = 120
+				now := metav1.Now()
+				newBoostObj.DeletionTimestamp = &now
+				mockManager.EXPECT().UpdateStartupCPUBoost(gomock.Any(), gomock.Any()).Times(0)
+			})
+			It("returns true", func() {
+				Expect(result).To(BeTrue())
+			})
+		})
 	})
 })
@@ -16,6 +16,7 @@ package controller
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/go-logr/logr"
 	"github.com/google/kube-startup-cpu-boost/internal/boost"
@@ -24,11 +25,21 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+const (
+	PodResizeDeferredEventReason   = "BoostResizeDeferred"
+	PodResizeInfeasibleEventReason = "BoostResizeInfeasible"
+	PodResizeFailedEventReason     = "BoostResizeFailed"
+	PodResizeStuckEventReason      = "BoostResizeStuck"
+	PodResizeEventMessage          = "In-place resize of pod resources by StartupCPUBoost %s is %s"
+	PodResizeStuckEventMessage     = "In-place resize of pod resources by StartupCPUBoost %s did not complete within %s, state: %s"
+)
+
 type BoostPodHandler interface {
 	Create(context.Context, event.CreateEvent, workqueue.RateLimitingInterface)
 	Delete(context.Context, event.DeleteEvent, workqueue.RateLimitingInterface)
@@ -38,14 +49,16 @@ type BoostPodHandler interface {
 }
 
 type boostPodHandler struct {
-	manager boost.Manager
-	log     logr.Logger
+	manager  boost.Manager
+	recorder record.EventRecorder
+	log      logr.Logger
 }
 
-func NewBoostPodHandler(manager boost.Manager, log logr.Logger) BoostPodHandler {
+func NewBoostPodHandler(manager boost.Manager, recorder record.EventRecorder, log logr.Logger) BoostPodHandler {
 	return &boostPodHandler{
-		manager: manager,
-		log:     log,
+		manager:  manager,
+		recorder: recorder,
+		log:      log,
 	}
 }
 
@@ -63,6 +76,7 @@ func (h *boostPodHandler) Create(ctx context.Context, e event.CreateEvent, wq wo
 	}
 	boostName := boost.Name()
 	log.WithValues("boost", boostName)
+	boost.ObservePodStartup(ctx, pod)
 	if err := boost.UpsertPod(ctx, pod); err != nil {
 		log.Error(err, "failed to handle pod create")
 	}
@@ -105,8 +119,11 @@ func (h *boostPodHandler) Update(ctx context.Context, e event.UpdateEvent, wq wo
 	}
 	log := h.log.WithValues("pod", pod.Name, "namespace", pod.Namespace)
 	log.V(5).Info("handling pod update")
-	if equality.Semantic.DeepEqual(pod.Status.Conditions, oldPod.Status.Conditions) {
-		log.V(5).Info("pod update skipped: conditions did not change")
+	if equality.Semantic.DeepEqual(pod.Status.Conditions, oldPod.Status.Conditions) &&
+		equality.Semantic.DeepEqual(pod.Status.ContainerStatuses, oldPod.Status.ContainerStatuses) &&
+		equality.Semantic.DeepEqual(pod.Status.InitContainerStatuses, oldPod.Status.InitContainerStatuses) &&
+		pod.Status.Resize == oldPod.Status.Resize {
+		log.V(5).Info("pod update skipped: conditions and container statuses did not change")
 		return
 	}
 	boost, ok := h.boostForPod(pod)
@@ -114,8 +131,14 @@ func (h *boostPodHandler) Update(ctx context.Context, e event.UpdateEvent, wq wo
 		log.V(5).Info("pod update skipped: no boost for pod")
 		return
 	}
-	if err := boost.UpsertPod(ctx, pod); err != nil {
-		log.Error(err, "pod update failed")
+	// the resize is observed before the upsert, as the upsert may resize the pod again
+	h.observePodResize(boost, pod, log)
+	// the startup is observed before the upsert, as the revert removes the boost annotation
+	boost.ObservePodStartup(ctx, pod)
+	if _, boosted := pod.Labels[bpod.BoostLabelKey]; boosted {
+		if err := boost.UpsertPod(ctx, pod); err != nil {
+			log.Error(err, "pod update failed")
+		}
 	}
 	wq.Add(reconcile.Request{
 		NamespacedName: types.NamespacedName{
@@ -146,10 +169,43 @@ func (h *boostPodHandler) GetPodLabelSelector() *metav1.LabelSelector {
 	}
 }
 
+// boostForPod returns the startup-cpu-boost from the pod boost label or, when the label
+// was removed on revert, the one that tracks the in-place resize or the startup of the pod
 func (h *boostPodHandler) boostForPod(pod *corev1.Pod) (boost.StartupCPUBoost, bool) {
 	boostName, ok := pod.Labels[bpod.BoostLabelKey]
 	if !ok {
-		return nil, false
+		if b, ok := h.manager.StartupCPUBoostForPodResize(pod); ok {
+			return b, true
+		}
+		return h.manager.StartupCPUBoostForPodStartup(pod)
 	}
 	return h.manager.StartupCPUBoost(pod.Namespace, boostName)
 }
+
+// observePodResize updates the in-place resize of a pod tracked by a given startup-cpu-boost
+// and records the event when the kubelet defers, refuses or fails the resize
+func (h *boostPodHandler) observePodResize(b boost.StartupCPUBoost, pod *corev1.Pod, log logr.Logger) {
+	status, changed := b.ObservePodResize(pod)
+	if !changed {
+		return
+	}
+	log = log.WithValues("resizeState", status.State, "resizeMessage", status.Message)
+	var reason string
+	switch status.State {
+	case bpod.ResizeStateDeferred:
+		reason = PodResizeDeferredEventReason
+	case bpod.ResizeStateInfeasible:
+		reason = PodResizeInfeasibleEventReason
+	case bpod.ResizeStateError:
+		reason = PodResizeFailedEventReason
+	default:
+		log.V(5).Info("pod resize state changed")
+		return
+	}
+	log.Info("pod resize not applied")
+	message := fmt.Sprintf(PodResizeEventMessage, b.Name(), status.State)
+	if status.Message != "" {
+		message = fmt.Sprintf("%s: %s", message, status.Message)
+	}
+	h.recorder.Event(pod, corev1.EventTypeWarning, reason, message)
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	"github.com/google/kube-startup-cpu-boost/internal/boost"
 	"github.com/google/kube-startup-cpu-boost/internal/boost/pod"
 	"github.com/google/kube-startup-cpu-boost/internal/controller"
 	"github.com/google/kube-startup-cpu-boost/internal/mock"
@@ -26,6 +27,7 @@ import (
 	"go.uber.org/mock/gomock"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -38,14 +40,16 @@ var _ = Describe("BoostPodHandler", func() {
 		mgrMockCall *gomock.Call
 		podHandler  controller.BoostPodHandler
 		wq          workqueue.RateLimitingInterface
+		recorder    *record.FakeRecorder
 	)
 	BeforeEach(func() {
 		mockCtrl = gomock.NewController(GinkgoT())
 		mgrMock = mock.NewMockManager(mockCtrl)
 		wq = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		recorder = record.NewFakeRecorder(10)
 	})
 	JustBeforeEach(func() {
-		podHandler = controller.NewBoostPodHandler(mgrMock, logr.Discard())
+		podHandler = controller.NewBoostPodHandler(mgrMock, recorder, logr.Discard())
 	})
 	Describe("Receives create event", func() {
 		var (
@@ -89,6 +93,8 @@ var _ = Describe("BoostPodHandler", func() {
 					gomock.Any(),
 					gomock.Eq(pod),
 				).Return(nil)
+				boostMock.EXPECT().ObservePodStartup(gomock.Any(), gomock.Eq(pod)).
+					Return(false).Times(1)
 				mgrMockCall.Return(boostMock, true)
 			})
 			It("sends a valid call to the boost manager and a boost", func() {
@@ -190,6 +196,24 @@ var _ = Describe("BoostPodHandler", func() {
 				Expect(wq.Len()).To(Equal(0))
 			})
 		})
+		When("Pod container statuses has changed", func() {
+			BeforeEach(func() {
+				started := true
+				newPod.Status.ContainerStatuses = []corev1.ContainerStatus{
+					{
+						Name:    "container-one",
+						Started: &started,
+					},
+				}
+				mgrMockCall = mgrMock.EXPECT().StartupCPUBoost(
+					gomock.Eq(newPod.Namespace),
+					gomock.Eq(specTemplate.Name),
+				).Return(nil, false)
+			})
+			It("sends a valid call to the boost manager", func() {
+				mgrMockCall.Times(1)
+			})
+		})
 		When("Pod status conditions has changed", func() {
 			BeforeEach(func() {
 				oldPod.Status.Conditions = []corev1.PodCondition{
@@ -233,6 +257,10 @@ var _ = Describe("BoostPodHandler", func() {
 						gomock.Any(),
 						gomock.Eq(newPod),
 					).Return(nil)
+					boostMock.EXPECT().ObservePodResize(gomock.Eq(newPod)).
+						Return(boost.PodResizeStatus{}, false)
+					boostMock.EXPECT().ObservePodStartup(gomock.Any(), gomock.Eq(newPod)).
+						Return(false).Times(1)
 					mgrMockCall.Return(boostMock, true)
 				})
 				It("sends a valid call to the boost manager and a boost", func() {
@@ -248,6 +276,94 @@ var _ = Describe("BoostPodHandler", func() {
 					Expect(req.Name).To(Equal(specTemplate.Name))
 					Expect(req.Namespace).To(Equal(specTemplate.Namespace))
 				})
+				It("does not record events", func() {
+					Expect(recorder.Events).To(BeEmpty())
+				})
+			})
+		})
+		When("Pod resize status has changed on reverted pod", func() {
+			var (
+				boostMock          *mock.MockStartupCPUBoost
+				resizeStatus       boost.PodResizeStatus
+				resizeStateChanged bool
+			)
+			BeforeEach(func() {
+				delete(oldPod.Labels, pod.BoostLabelKey)
+				delete(newPod.Labels, pod.BoostLabelKey)
+				newPod.Status.Resize = corev1.PodResizeStatusInfeasible
+				resizeStatus = boost.PodResizeStatus{
+					State:   pod.ResizeStateInfeasible,
+					Message: "Node didn't have enough capacity",
+				}
+				resizeStateChanged = true
+				boostMock = mock.NewMockStartupCPUBoost(mockCtrl)
+				boostMock.EXPECT().Name().Return(specTemplate.Name).AnyTimes()
+				boostMock.EXPECT().Namespace().Return(specTemplate.Namespace).AnyTimes()
+				boostMock.EXPECT().UpsertPod(gomock.Any(), gomock.Any()).Times(0)
+				boostMock.EXPECT().ObservePodResize(gomock.Eq(newPod)).
+					DoAndReturn(func(p *corev1.Pod) (boost.PodResizeStatus, bool) {
+						return resizeStatus, resizeStateChanged
+					}).Times(1)
+				boostMock.EXPECT().ObservePodStartup(gomock.Any(), gomock.Eq(newPod)).
+					Return(false).Times(1)
+				mgrMockCall = mgrMock.EXPECT().StartupCPUBoostForPodResize(gomock.Eq(newPod)).
+					Return(boostMock, true).Times(1)
+			})
+			It("sends reconciliation request", func() {
+				Expect(wq.Len()).To(Equal(1))
+			})
+			It("records the resize event", func() {
+				Expect(recorder.Events).To(HaveLen(1))
+				event := <-recorder.Events
+				Expect(event).To(ContainSubstring(corev1.EventTypeWarning))
+				Expect(event).To(ContainSubstring(controller.PodResizeInfeasibleEventReason))
+				Expect(event).To(ContainSubstring("Node didn't have enough capacity"))
+			})
+			When("resize state did not change", func() {
+				BeforeEach(func() {
+					resizeStateChanged = false
+				})
+				It("does not record events", func() {
+					Expect(recorder.Events).To(BeEmpty())
+				})
+			})
+			When("resize completed", func() {
+				BeforeEach(func() {
+					resizeStatus = boost.PodResizeStatus{State: pod.ResizeStateCompleted}
+				})
+				It("does not record events", func() {
+					Expect(recorder.Events).To(BeEmpty())
+				})
+			})
+		})
+		When("Pod status conditions has changed on reverted pod with tracked startup", func() {
+			var boostMockStartupCall *gomock.Call
+			BeforeEach(func() {
+				delete(oldPod.Labels, pod.BoostLabelKey)
+				delete(newPod.Labels, pod.BoostLabelKey)
+				newPod.Status.Conditions = []corev1.PodCondition{{
+					Type:   corev1.PodReady,
+					Status: corev1.ConditionTrue,
+				}}
+				boostMock := mock.NewMockStartupCPUBoost(mockCtrl)
+				boostMock.EXPECT().Name().Return(specTemplate.Name).AnyTimes()
+				boostMock.EXPECT().Namespace().Return(specTemplate.Namespace).AnyTimes()
+				boostMock.EXPECT().UpsertPod(gomock.Any(), gomock.Any()).Times(0)
+				boostMock.EXPECT().ObservePodResize(gomock.Eq(newPod)).
+					Return(boost.PodResizeStatus{}, false).Times(1)
+				boostMockStartupCall = boostMock.EXPECT().ObservePodStartup(gomock.Any(), gomock.Eq(newPod)).
+					Return(true)
+				mgrMock.EXPECT().StartupCPUBoostForPodResize(gomock.Eq(newPod)).
+					Return(nil, false).Times(1)
+				mgrMockCall = mgrMock.EXPECT().StartupCPUBoostForPodStartup(gomock.Eq(newPod)).
+					Return(boostMock, true)
+			})
+			It("sends a valid call to the boost manager and a boost", func() {
+				mgrMockCall.Times(1)
+				boostMockStartupCall.Times(1)
+			})
+			It("sends reconciliation request", func() {
+				Expect(wq.Len()).To(Equal(1))
 			})
 		})
 	})
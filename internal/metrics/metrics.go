@@ -33,6 +33,15 @@ var (
 	// boostContainersActive is a number of a containers which
 	// CPU resources and not yet reverted to their original values.
 	boostContainersActive *prometheus.GaugeVec
+	// boostResizeFailuresTotal is a number of the in-place resizes
+	// of boosted POD resources that were refused or got stuck.
+	boostResizeFailuresTotal *prometheus.CounterVec
+	// predictorRequestDuration is a latency of the predictor API
+	// calls, including the retries.
+	predictorRequestDuration *prometheus.HistogramVec
+	// predictorErrorsTotal is a number of the failed predictor API
+	// calls.
+	predictorErrorsTotal *prometheus.CounterVec
 )
 
 // init initializes all of the Kube Startup CPU Boost metrics.
@@ -58,6 +67,28 @@ func init() {
 			Help:      "Number of a containers which CPU resources and not yet reverted to their original values",
 		}, []string{"namespace", "boost"},
 	)
+	boostResizeFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: KubeStartupCPUBoostSubsystem,
+			Name:      "resize_failures_total",
+			Help:      "Number of the in-place resizes of boosted POD resources that were refused or got stuck",
+		}, []string{"namespace", "boost", "reason"},
+	)
+	predictorRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: KubeStartupCPUBoostSubsystem,
+			Name:      "predictor_request_duration_seconds",
+			Help:      "Latency of the predictor API calls, including the retries",
+			Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+		}, []string{"path"},
+	)
+	predictorErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: KubeStartupCPUBoostSubsystem,
+			Name:      "predictor_errors_total",
+			Help:      "Number of the failed predictor API calls",
+		}, []string{"path", "reason"},
+	)
 }
 
 // Register registers all of the Kube Startup CPU Boost metrics
@@ -67,6 +98,9 @@ func Register() {
 		boostConfigurations,
 		boostContainersTotal,
 		boostContainersActive,
+		boostResizeFailuresTotal,
+		predictorRequestDuration,
+		predictorErrorsTotal,
 	)
 }
 
@@ -102,9 +136,35 @@ func AddBoostContainersTotal(namespace string, boost string, value float64) {
 		Add(value)
 }
 
+// AddResizeFailure increments the resizeFailuresTotal metric for
+// a given namespace, boost name and failure reason
+func AddResizeFailure(namespace string, boost string, reason string) {
+	boostResizeFailuresTotal.With(
+		prometheus.Labels{"namespace": namespace, "boost": boost, "reason": reason}).
+		Inc()
+}
+
+// ObservePredictorRequest records the latency in seconds of a predictor
+// API call to a given path
+func ObservePredictorRequest(path string, seconds float64) {
+	predictorRequestDuration.With(
+		prometheus.Labels{"path": path}).
+		Observe(seconds)
+}
+
+// AddPredictorError increments the predictorErrorsTotal metric for
+// a given path and failure reason
+func AddPredictorError(path string, reason string) {
+	predictorErrorsTotal.With(
+		prometheus.Labels{"path": path, "reason": reason}).
+		Inc()
+}
+
 // ClearSystemMetrics clears all of the system metrics.
 func ClearSystemMetrics() {
 	boostConfigurations.Reset()
+	predictorRequestDuration.Reset()
+	predictorErrorsTotal.Reset()
 }
 
 // ClearBoostMetrics clears all of relevant metrics for given
@@ -116,6 +176,9 @@ func ClearBoostMetrics(namespace string, boost string) {
 	boostContainersActive.Delete(
 		prometheus.Labels{"namespace": namespace, "boost": boost},
 	)
+	boostResizeFailuresTotal.DeletePartialMatch(
+		prometheus.Labels{"namespace": namespace, "boost": boost},
+	)
 }
 
 // BoostConfigurations returns value for a totalBoostConfigurations
@@ -144,6 +207,33 @@ func BoostContainersActive(namespace string, boost string) float64 {
 	})
 }
 
+// ResizeFailures returns value for a resizeFailuresTotal metric
+// for a given namespace, boost name and failure reason.
+func ResizeFailures(namespace string, boost string, reason string) float64 {
+	return counterVecValue(boostResizeFailuresTotal, prometheus.Labels{
+		"namespace": namespace,
+		"boost":     boost,
+		"reason":    reason,
+	})
+}
+
+// PredictorRequests returns the number of the predictor API calls
+// to a given path recorded in a predictorRequestDuration metric.
+func PredictorRequests(path string) uint64 {
+	return histogramVecCount(predictorRequestDuration, prometheus.Labels{
+		"path": path,
+	})
+}
+
+// PredictorErrors returns value for a predictorErrorsTotal metric
+// for a given path and failure reason.
+func PredictorErrors(path string, reason string) float64 {
+	return counterVecValue(predictorErrorsTotal, prometheus.Labels{
+		"path":   path,
+		"reason": reason,
+	})
+}
+
 // CounterVecValue collects and returns value for a counterVec
 // metric for a given labels. Created for purpose of tests.
 func counterVecValue(vec *prometheus.CounterVec, labels prometheus.Labels) (value float64) {
@@ -170,6 +260,19 @@ func gaugeVecValue(vec *prometheus.GaugeVec, labels prometheus.Labels) (value fl
 	return
 }
 
+// histogramVecCount collects and returns sample count for a histogramVec
+// metric for a given labels. Created for purpose of tests.
+func histogramVecCount(vec *prometheus.HistogramVec, labels prometheus.Labels) (count uint64) {
+	obs, err := vec.GetMetricWith(labels)
+	if err != nil {
+		return
+	}
+	collect(obs.(prometheus.Histogram), func(m *dto.Metric) {
+		count += m.GetHistogram().GetSampleCount()
+	})
+	return
+}
+
 // collect collects the given prometheus collector and writes
 // corresponding metric to the DTO object for further processing.
 func collect(col prometheus.Collector, do func(*dto.Metric)) {
@@ -85,4 +85,48 @@ var _ = Describe("Metrics", func() {
 			Expect(metrics.BoostContainersTotal(namespace, boost)).To(Equal(float64(8)))
 		})
 	})
+	Describe("adds resize failure metric", func() {
+		var (
+			namespace = "default"
+			boost     = "boost-01"
+		)
+		BeforeEach(func() {
+			metrics.ClearBoostMetrics(namespace, boost)
+		})
+		JustBeforeEach(func() {
+			metrics.AddResizeFailure(namespace, boost, "Infeasible")
+			metrics.AddResizeFailure(namespace, boost, "Infeasible")
+			metrics.AddResizeFailure(namespace, boost, "Stuck")
+		})
+		It("updates the resize failures metric for each reason", func() {
+			Expect(metrics.ResizeFailures(namespace, boost, "Infeasible")).To(Equal(float64(2)))
+			Expect(metrics.ResizeFailures(namespace, boost, "Stuck")).To(Equal(float64(1)))
+		})
+		When("boost metrics are cleared", func() {
+			JustBeforeEach(func() {
+				metrics.ClearBoostMetrics(namespace, boost)
+			})
+			It("clears the resize failures metric", func() {
+				Expect(metrics.ResizeFailures(namespace, boost, "Infeasible")).To(Equal(float64(0)))
+			})
+		})
+	})
+	Describe("records predictor calls", func() {
+		var path = "/cpu"
+		BeforeEach(func() {
+			metrics.ClearSystemMetrics()
+		})
+		JustBeforeEach(func() {
+			metrics.ObservePredictorRequest(path, 0.02)
+			metrics.ObservePredictorRequest(path, 0.5)
+			metrics.AddPredictorError(path, "Timeout")
+		})
+		It("updates the predictor request duration metric", func() {
+			Expect(metrics.PredictorRequests(path)).To(Equal(uint64(2)))
+		})
+		It("updates the predictor errors metric", func() {
+			Expect(metrics.PredictorErrors(path, "Timeout")).To(Equal(float64(1)))
+			Expect(metrics.PredictorErrors(path, "Status")).To(Equal(float64(0)))
+		})
+	})
 })
@@ -30,6 +30,7 @@ import (
 	boost "github.com/google/kube-startup-cpu-boost/internal/boost"
 	gomock "go.uber.org/mock/gomock"
 	v1 "k8s.io/api/core/v1"
+	record "k8s.io/client-go/tools/record"
 	reconcile "sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -82,6 +83,18 @@ func (mr *MockManagerMockRecorder) RemoveStartupCPUBoost(arg0, arg1, arg2 any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveStartupCPUBoost", reflect.TypeOf((*MockManager)(nil).RemoveStartupCPUBoost), arg0, arg1, arg2)
 }
 
+// SetEventRecorder mocks base method.
+func (m *MockManager) SetEventRecorder(arg0 record.EventRecorder) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetEventRecorder", arg0)
+}
+
+// SetEventRecorder indicates an expected call of SetEventRecorder.
+func (mr *MockManagerMockRecorder) SetEventRecorder(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEventRecorder", reflect.TypeOf((*MockManager)(nil).SetEventRecorder), arg0)
+}
+
 // SetStartupCPUBoostReconciler mocks base method.
 func (m *MockManager) SetStartupCPUBoostReconciler(arg0 reconcile.Reconciler) {
 	m.ctrl.T.Helper()
@@ -137,3 +150,47 @@ func (mr *MockManagerMockRecorder) StartupCPUBoostForPod(arg0, arg1 any) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartupCPUBoostForPod", reflect.TypeOf((*MockManager)(nil).StartupCPUBoostForPod), arg0, arg1)
 }
+
+// StartupCPUBoostForPodResize mocks base method.
+func (m *MockManager) StartupCPUBoostForPodResize(arg0 *v1.Pod) (boost.StartupCPUBoost, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartupCPUBoostForPodResize", arg0)
+	ret0, _ := ret[0].(boost.StartupCPUBoost)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// StartupCPUBoostForPodResize indicates an expected call of StartupCPUBoostForPodResize.
+func (mr *MockManagerMockRecorder) StartupCPUBoostForPodResize(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartupCPUBoostForPodResize", reflect.TypeOf((*MockManager)(nil).StartupCPUBoostForPodResize), arg0)
+}
+
+// StartupCPUBoostForPodStartup mocks base method.
+func (m *MockManager) StartupCPUBoostForPodStartup(arg0 *v1.Pod) (boost.StartupCPUBoost, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartupCPUBoostForPodStartup", arg0)
+	ret0, _ := ret[0].(boost.StartupCPUBoost)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// StartupCPUBoostForPodStartup indicates an expected call of StartupCPUBoostForPodStartup.
+func (mr *MockManagerMockRecorder) StartupCPUBoostForPodStartup(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartupCPUBoostForPodStartup", reflect.TypeOf((*MockManager)(nil).StartupCPUBoostForPodStartup), arg0)
+}
+
+// UpdateStartupCPUBoost mocks base method.
+func (m *MockManager) UpdateStartupCPUBoost(arg0 context.Context, arg1 boost.StartupCPUBoost) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStartupCPUBoost", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStartupCPUBoost indicates an expected call of UpdateStartupCPUBoost.
+func (mr *MockManagerMockRecorder) UpdateStartupCPUBoost(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStartupCPUBoost", reflect.TypeOf((*MockManager)(nil).UpdateStartupCPUBoost), arg0, arg1)
+}
@@ -26,9 +26,11 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	boost "github.com/google/kube-startup-cpu-boost/internal/boost"
 	duration "github.com/google/kube-startup-cpu-boost/internal/boost/duration"
+	predictor "github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
 	resource "github.com/google/kube-startup-cpu-boost/internal/boost/resource"
 	gomock "go.uber.org/mock/gomock"
 	v1 "k8s.io/api/core/v1"
@@ -57,6 +59,20 @@ func (m *MockStartupCPUBoost) EXPECT() *MockStartupCPUBoostMockRecorder {
 	return m.recorder
 }
 
+// CheckPodResizes mocks base method.
+func (m *MockStartupCPUBoost) CheckPodResizes(arg0 time.Time) []boost.PodResize {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckPodResizes", arg0)
+	ret0, _ := ret[0].([]boost.PodResize)
+	return ret0
+}
+
+// CheckPodResizes indicates an expected call of CheckPodResizes.
+func (mr *MockStartupCPUBoostMockRecorder) CheckPodResizes(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckPodResizes", reflect.TypeOf((*MockStartupCPUBoost)(nil).CheckPodResizes), arg0)
+}
+
 // DeletePod mocks base method.
 func (m *MockStartupCPUBoost) DeletePod(arg0 context.Context, arg1 *v1.Pod) error {
 	m.ctrl.T.Helper()
@@ -71,18 +87,18 @@ func (mr *MockStartupCPUBoostMockRecorder) DeletePod(arg0, arg1 any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePod", reflect.TypeOf((*MockStartupCPUBoost)(nil).DeletePod), arg0, arg1)
 }
 
-// DurationPolicies mocks base method.
-func (m *MockStartupCPUBoost) DurationPolicies() map[string]duration.Policy {
+// DurationPolicy mocks base method.
+func (m *MockStartupCPUBoost) DurationPolicy() duration.Policy {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DurationPolicies")
-	ret0, _ := ret[0].(map[string]duration.Policy)
+	ret := m.ctrl.Call(m, "DurationPolicy")
+	ret0, _ := ret[0].(duration.Policy)
 	return ret0
 }
 
-// DurationPolicies indicates an expected call of DurationPolicies.
-func (mr *MockStartupCPUBoostMockRecorder) DurationPolicies() *gomock.Call {
+// DurationPolicy indicates an expected call of DurationPolicy.
+func (mr *MockStartupCPUBoostMockRecorder) DurationPolicy() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DurationPolicies", reflect.TypeOf((*MockStartupCPUBoost)(nil).DurationPolicies))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DurationPolicy", reflect.TypeOf((*MockStartupCPUBoost)(nil).DurationPolicy))
 }
 
 // Matches mocks base method.
@@ -127,6 +143,49 @@ func (mr *MockStartupCPUBoostMockRecorder) Namespace() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Namespace", reflect.TypeOf((*MockStartupCPUBoost)(nil).Namespace))
 }
 
+// ObservePodResize mocks base method.
+func (m *MockStartupCPUBoost) ObservePodResize(arg0 *v1.Pod) (boost.PodResizeStatus, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ObservePodResize", arg0)
+	ret0, _ := ret[0].(boost.PodResizeStatus)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// ObservePodResize indicates an expected call of ObservePodResize.
+func (mr *MockStartupCPUBoostMockRecorder) ObservePodResize(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObservePodResize", reflect.TypeOf((*MockStartupCPUBoost)(nil).ObservePodResize), arg0)
+}
+
+// ObservePodStartup mocks base method.
+func (m *MockStartupCPUBoost) ObservePodStartup(arg0 context.Context, arg1 *v1.Pod) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ObservePodStartup", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ObservePodStartup indicates an expected call of ObservePodStartup.
+func (mr *MockStartupCPUBoostMockRecorder) ObservePodStartup(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObservePodStartup", reflect.TypeOf((*MockStartupCPUBoost)(nil).ObservePodStartup), arg0, arg1)
+}
+
+// PhaseDurationPolicies mocks base method.
+func (m *MockStartupCPUBoost) PhaseDurationPolicies() []duration.Policy {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PhaseDurationPolicies")
+	ret0, _ := ret[0].([]duration.Policy)
+	return ret0
+}
+
+// PhaseDurationPolicies indicates an expected call of PhaseDurationPolicies.
+func (mr *MockStartupCPUBoostMockRecorder) PhaseDurationPolicies() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PhaseDurationPolicies", reflect.TypeOf((*MockStartupCPUBoost)(nil).PhaseDurationPolicies))
+}
+
 // Pod mocks base method.
 func (m *MockStartupCPUBoost) Pod(arg0 string) (*v1.Pod, bool) {
 	m.ctrl.T.Helper()
@@ -142,6 +201,49 @@ func (mr *MockStartupCPUBoostMockRecorder) Pod(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pod", reflect.TypeOf((*MockStartupCPUBoost)(nil).Pod), arg0)
 }
 
+// PodResizeStatus mocks base method.
+func (m *MockStartupCPUBoost) PodResizeStatus(arg0 string) (boost.PodResizeStatus, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PodResizeStatus", arg0)
+	ret0, _ := ret[0].(boost.PodResizeStatus)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// PodResizeStatus indicates an expected call of PodResizeStatus.
+func (mr *MockStartupCPUBoostMockRecorder) PodResizeStatus(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PodResizeStatus", reflect.TypeOf((*MockStartupCPUBoost)(nil).PodResizeStatus), arg0)
+}
+
+// PredictorEndpoints mocks base method.
+func (m *MockStartupCPUBoost) PredictorEndpoints() []predictor.EndpointKey {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PredictorEndpoints")
+	ret0, _ := ret[0].([]predictor.EndpointKey)
+	return ret0
+}
+
+// PredictorEndpoints indicates an expected call of PredictorEndpoints.
+func (mr *MockStartupCPUBoostMockRecorder) PredictorEndpoints() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PredictorEndpoints", reflect.TypeOf((*MockStartupCPUBoost)(nil).PredictorEndpoints))
+}
+
+// Priority mocks base method.
+func (m *MockStartupCPUBoost) Priority() int32 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Priority")
+	ret0, _ := ret[0].(int32)
+	return ret0
+}
+
+// Priority indicates an expected call of Priority.
+func (mr *MockStartupCPUBoostMockRecorder) Priority() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Priority", reflect.TypeOf((*MockStartupCPUBoost)(nil).Priority))
+}
+
 // ResourcePolicy mocks base method.
 func (m *MockStartupCPUBoost) ResourcePolicy(arg0 string) (resource.ContainerPolicy, bool) {
 	m.ctrl.T.Helper()
@@ -171,6 +273,18 @@ func (mr *MockStartupCPUBoostMockRecorder) RevertResources(arg0, arg1 any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevertResources", reflect.TypeOf((*MockStartupCPUBoost)(nil).RevertResources), arg0, arg1)
 }
 
+// SetPodScheduler mocks base method.
+func (m *MockStartupCPUBoost) SetPodScheduler(arg0 boost.PodScheduler) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPodScheduler", arg0)
+}
+
+// SetPodScheduler indicates an expected call of SetPodScheduler.
+func (mr *MockStartupCPUBoostMockRecorder) SetPodScheduler(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPodScheduler", reflect.TypeOf((*MockStartupCPUBoost)(nil).SetPodScheduler), arg0)
+}
+
 // Stats mocks base method.
 func (m *MockStartupCPUBoost) Stats() boost.StartupCPUBoostStats {
 	m.ctrl.T.Helper()
@@ -185,6 +299,32 @@ func (mr *MockStartupCPUBoostMockRecorder) Stats() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockStartupCPUBoost)(nil).Stats))
 }
 
+// TracksPodStartup mocks base method.
+func (m *MockStartupCPUBoost) TracksPodStartup(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TracksPodStartup", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// TracksPodStartup indicates an expected call of TracksPodStartup.
+func (mr *MockStartupCPUBoostMockRecorder) TracksPodStartup(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TracksPodStartup", reflect.TypeOf((*MockStartupCPUBoost)(nil).TracksPodStartup), arg0)
+}
+
+// TransferState mocks base method.
+func (m *MockStartupCPUBoost) TransferState(arg0 boost.StartupCPUBoost) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "TransferState", arg0)
+}
+
+// TransferState indicates an expected call of TransferState.
+func (mr *MockStartupCPUBoostMockRecorder) TransferState(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferState", reflect.TypeOf((*MockStartupCPUBoost)(nil).TransferState), arg0)
+}
+
 // UpsertPod mocks base method.
 func (m *MockStartupCPUBoost) UpsertPod(arg0 context.Context, arg1 *v1.Pod) error {
 	m.ctrl.T.Helper()
@@ -199,16 +339,31 @@ func (mr *MockStartupCPUBoostMockRecorder) UpsertPod(arg0, arg1 any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertPod", reflect.TypeOf((*MockStartupCPUBoost)(nil).UpsertPod), arg0, arg1)
 }
 
+// ValidatePodPolicy mocks base method.
+func (m *MockStartupCPUBoost) ValidatePodPolicy(arg0 context.Context, arg1 string) (*v1.Pod, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidatePodPolicy", arg0, arg1)
+	ret0, _ := ret[0].(*v1.Pod)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// ValidatePodPolicy indicates an expected call of ValidatePodPolicy.
+func (mr *MockStartupCPUBoostMockRecorder) ValidatePodPolicy(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidatePodPolicy", reflect.TypeOf((*MockStartupCPUBoost)(nil).ValidatePodPolicy), arg0, arg1)
+}
+
 // ValidatePolicy mocks base method.
-func (m *MockStartupCPUBoost) ValidatePolicy(arg0 context.Context, arg1 string) []*v1.Pod {
+func (m *MockStartupCPUBoost) ValidatePolicy(arg0 context.Context) []*v1.Pod {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ValidatePolicy", arg0, arg1)
+	ret := m.ctrl.Call(m, "ValidatePolicy", arg0)
 	ret0, _ := ret[0].([]*v1.Pod)
 	return ret0
 }
 
 // ValidatePolicy indicates an expected call of ValidatePolicy.
-func (mr *MockStartupCPUBoostMockRecorder) ValidatePolicy(arg0, arg1 any) *gomock.Call {
+func (mr *MockStartupCPUBoostMockRecorder) ValidatePolicy(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidatePolicy", reflect.TypeOf((*MockStartupCPUBoost)(nil).ValidatePolicy), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidatePolicy", reflect.TypeOf((*MockStartupCPUBoost)(nil).ValidatePolicy), arg0)
 }
@@ -1,4 +1,4 @@
-// Copyright 2023 Google LLC
+// Copyright 2024 Google LLC
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -11,6 +11,7 @@
 // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 // See the License for the specific language governing permissions and
 // limitations under the License.
+//
 
 // Code generated by MockGen. DO NOT EDIT.
 // Source: github.com/google/kube-startup-cpu-boost/internal/boost (interfaces: TimeTicker)
@@ -52,6 +53,18 @@ func (m *MockTimeTicker) EXPECT() *MockTimeTickerMockRecorder {
 	return m.recorder
 }
 
+// Reset mocks base method.
+func (m *MockTimeTicker) Reset(arg0 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Reset", arg0)
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockTimeTickerMockRecorder) Reset(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockTimeTicker)(nil).Reset), arg0)
+}
+
 // Stop mocks base method.
 func (m *MockTimeTicker) Stop() {
 	m.ctrl.T.Helper()
@@ -17,33 +17,43 @@ package webhook
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/google/kube-startup-cpu-boost/internal/boost"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/duration"
 	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
 	resource "github.com/google/kube-startup-cpu-boost/internal/boost/resource"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 // +kubebuilder:webhook:path=/mutate-v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,timeoutSeconds=2,groups="",resources=pods,verbs=create,versions=v1,name=cpuboost.autoscaling.x-k8s.io,admissionReviewVersions=v1
+//+kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
 
 type podCPUBoostHandler struct {
 	decoder      admission.Decoder
 	manager      boost.Manager
+	owners       *predictor.OwnerResolver
 	removeLimits bool
 }
 
-func NewPodCPUBoostWebHook(mgr boost.Manager, scheme *runtime.Scheme, removeLimits bool) *webhook.Admission {
+// NewPodCPUBoostWebHook returns the POD admission webhook that boosts the POD resources.
+// The POD owners sent to the predictors are resolved with a given reader, which is
+// expected to be backed by the informer cache.
+func NewPodCPUBoostWebHook(mgr boost.Manager, scheme *runtime.Scheme, reader client.Reader, removeLimits bool) *webhook.Admission {
 	return &webhook.Admission{
 		Handler: &podCPUBoostHandler{
 			manager:      mgr,
 			decoder:      admission.NewDecoder(scheme),
+			owners:       predictor.NewOwnerResolver(reader),
 			removeLimits: removeLimits,
 		},
 	}
@@ -73,50 +83,26 @@ func (h *podCPUBoostHandler) Handle(ctx context.Context, req admission.Request)
 }
 
 func (h *podCPUBoostHandler) boostContainerResources(ctx context.Context, b boost.StartupCPUBoost, pod *corev1.Pod, log logr.Logger) {
-
-	fmt.Println("PodName: ", pod.Name)
-	fmt.Println("PodGenerateName: ", pod.GenerateName)
-	fmt.Println("PodUID: ", pod.UID)
-	fmt.Println("PodNamespace: ", pod.Namespace)
-
-	podName := pod.Name
-	podGenerateName := pod.GenerateName
-	podNamespace := pod.Namespace
-
-	if podName == "" {
-		podName = podGenerateName
-	}
-
-	ctx = context.WithValue(ctx, resource.ContextKey("podName"), podName)
-	ctx = context.WithValue(ctx, resource.ContextKey("podNamespace"), podNamespace)
-
+	predictions := predictor.NewPodPredictions(pod, b.Name(), h.owners)
 	annotation := bpod.NewBoostAnnotation()
-	for i, container := range pod.Spec.Containers {
-		policy, found := b.ResourcePolicy(container.Name)
-		if !found {
-			continue
-		}
-		log = log.WithValues("container", container.Name,
-			"cpuRequests", container.Resources.Requests.Cpu().String(),
-			"cpuLimits", container.Resources.Limits.Cpu().String(),
-		)
-		if resizeRequiresRestart(container, corev1.ResourceCPU) {
-			log.Info("skipping container due to restart policy")
+	for i := range pod.Spec.InitContainers {
+		container := &pod.Spec.InitContainers[i]
+		if bpod.IsSidecarContainer(container) {
+			h.boostContainer(ctx, b, predictions, container, annotation, log)
 			continue
 		}
-		updateBoostAnnotation(annotation, container.Name, container.Resources)
-		resources := policy.NewResources(ctx, &container)
-		log = log.WithValues(
-			"newCpuRequests", resources.Requests.Cpu().String(),
-			"newCpuLimits", resources.Limits.Cpu().String(),
-		)
-		if h.removeLimits {
-			delete(resources.Limits, corev1.ResourceCPU)
-		}
-		pod.Spec.Containers[i].Resources = *resources
-		log.Info("pod resources increased")
+		// Plain init containers run to completion before the main containers start,
+		// so they are boosted for their whole lifetime and never reverted
+		h.boostContainer(ctx, b, predictions, container, nil, log)
+	}
+	for i := range pod.Spec.Containers {
+		h.boostContainer(ctx, b, predictions, &pod.Spec.Containers[i], annotation, log)
 	}
-	if len(annotation.InitCPULimits) > 0 || len(annotation.InitCPURequests) > 0 {
+	if len(annotation.InitCPULimits) > 0 || len(annotation.InitCPURequests) > 0 ||
+		len(annotation.InitMemoryLimits) > 0 || len(annotation.InitMemoryRequests) > 0 {
+		setDurationDeadline(ctx, b, predictions, annotation, log)
+		setLearnedDurationDeadline(ctx, b, predictions, annotation, log)
+		setLearnedResourcesWorkloadKey(ctx, predictions, annotation)
 		if pod.Annotations == nil {
 			pod.Annotations = make(map[string]string)
 		}
@@ -126,18 +112,145 @@ func (h *podCPUBoostHandler) boostContainerResources(ctx context.Context, b boos
 		}
 		pod.Labels[bpod.BoostLabelKey] = b.Name()
 	}
+}
+
+// boostContainer increases the resources of a given container according to the
+// startup-cpu-boost resource policy. The policies share given POD predictions. The
+// original resources are recorded in a given annotation, so they can be reverted in
+// place. When the annotation is nil, the container is boosted without the possibility
+// of revert.
+func (h *podCPUBoostHandler) boostContainer(ctx context.Context, b boost.StartupCPUBoost, predictions *predictor.PodPredictions,
+	container *corev1.Container, annotation *bpod.BoostPodAnnotation, log logr.Logger) {
+	policy, found := b.ResourcePolicy(container.Name)
+	if !found {
+		return
+	}
+	log = log.WithValues("container", container.Name,
+		"cpuRequests", container.Resources.Requests.Cpu().String(),
+		"cpuLimits", container.Resources.Limits.Cpu().String(),
+	)
+	revertable := annotation != nil
+	if revertable && resizeRequiresRestart(*container, corev1.ResourceCPU) {
+		log.Info("skipping container due to restart policy")
+		return
+	}
+	resources, policyName := resource.ApplyPolicy(ctx, policy, predictions, container)
+	if resources == nil {
+		log.Info("skipping container due to missing resources from policy")
+		return
+	}
+	if revertable && resizeRequiresRestart(*container, corev1.ResourceMemory) {
+		log.V(5).Info("skipping container memory due to restart policy")
+		keepResource(resources, container.Resources, corev1.ResourceMemory)
+	}
+	if revertable {
+		updateBoostAnnotation(annotation, container.Name, container.Resources, *resources)
+		annotation.Policies[container.Name] = policyName
+	}
+	log = log.WithValues(
+		"policy", policyName,
+		"newCpuRequests", resources.Requests.Cpu().String(),
+		"newCpuLimits", resources.Limits.Cpu().String(),
+		"newMemoryRequests", resources.Requests.Memory().String(),
+		"newMemoryLimits", resources.Limits.Memory().String(),
+	)
+	if h.removeLimits {
+		delete(resources.Limits, corev1.ResourceCPU)
+	}
+	container.Resources = *resources
+	log.Info("pod resources increased")
+}
+
+// setDurationDeadline records the boost deadline predicted by the auto duration policy,
+// if configured, in a given annotation, so the policy is evaluated without calling the
+// predictor. The prediction is shared with the resource policies when they use the same
+// predictor endpoint. When the prediction fails, the deadline is set by the policy fallback.
+func setDurationDeadline(ctx context.Context, b boost.StartupCPUBoost, predictions *predictor.PodPredictions,
+	annotation *bpod.BoostPodAnnotation, log logr.Logger) {
+	policy, found := duration.FindPolicy(b.DurationPolicy(), duration.AutoDurationPolicyName)
+	if !found {
+		return
+	}
+	autoPolicy, ok := policy.(*duration.AutoDurationPolicy)
+	if !ok {
+		return
+	}
+	deadline, err := autoPolicy.PredictDeadline(ctx, predictions)
+	if err != nil {
+		log.Error(err, "failed to predict boost duration, applying fallback duration",
+			"fallback", autoPolicy.Fallback().String())
+	}
+	annotation.DurationDeadline = &deadline
+}
+
+// setLearnedDurationDeadline records the boost deadline of the learned duration policy,
+// if configured, and the workload key of a given POD in a given annotation, so the
+// controller learns the POD startup duration with the same key. The workload is the POD
+// top-level owner resolved with given POD predictions. Until there are enough learned
+// durations, the deadline is set by the policy fallback.
+func setLearnedDurationDeadline(ctx context.Context, b boost.StartupCPUBoost, predictions *predictor.PodPredictions,
+	annotation *bpod.BoostPodAnnotation, log logr.Logger) {
+	policy, found := duration.FindPolicy(b.DurationPolicy(), duration.LearnedDurationPolicyName)
+	if !found {
+		return
+	}
+	learnedPolicy, ok := policy.(*duration.LearnedDurationPolicy)
+	if !ok {
+		return
+	}
+	key := predictions.WorkloadKey(ctx)
+	deadline, learned := learnedPolicy.PredictDeadline(ctx, key)
+	if !learned {
+		log.V(5).Info("not enough learned boost durations, applying fallback duration",
+			"workloadKey", key, "fallback", learnedPolicy.Fallback().String())
+	}
+	annotation.WorkloadKey = key
+	annotation.DurationDeadline = &deadline
+}
 
-	ctx = context.WithValue(ctx, resource.ContextKey("podName"), nil)
-	_ = context.WithValue(ctx, resource.ContextKey("podNamespace"), nil)
+// setLearnedResourcesWorkloadKey records the workload key of the POD in a given annotation
+// when any of the POD containers is boosted by the learned resource policy, so the
+// controller learns the POD startup with the same key. The workload is the POD top-level
+// owner resolved with given POD predictions.
+func setLearnedResourcesWorkloadKey(ctx context.Context, predictions *predictor.PodPredictions,
+	annotation *bpod.BoostPodAnnotation) {
+	for _, policyName := range annotation.Policies {
+		if slices.Contains(strings.Split(policyName, ","), resource.LearnedPolicyName) {
+			annotation.WorkloadKey = predictions.WorkloadKey(ctx)
+			return
+		}
+	}
 }
 
-func updateBoostAnnotation(annot *bpod.BoostPodAnnotation, containerName string, resources corev1.ResourceRequirements) {
+// updateBoostAnnotation records the original container resources in the boost annotation.
+// The CPU resources are always recorded, while the memory resources only when changed by
+// the boost.
+func updateBoostAnnotation(annot *bpod.BoostPodAnnotation, containerName string, resources, newResources corev1.ResourceRequirements) {
 	if cpuRequests, ok := resources.Requests[corev1.ResourceCPU]; ok {
 		annot.InitCPURequests[containerName] = cpuRequests.String()
 	}
 	if cpuLimits, ok := resources.Limits[corev1.ResourceCPU]; ok {
 		annot.InitCPULimits[containerName] = cpuLimits.String()
 	}
+	if memRequests, ok := resources.Requests[corev1.ResourceMemory]; ok &&
+		!memRequests.Equal(newResources.Requests[corev1.ResourceMemory]) {
+		annot.InitMemoryRequests[containerName] = memRequests.String()
+	}
+	if memLimits, ok := resources.Limits[corev1.ResourceMemory]; ok &&
+		!memLimits.Equal(newResources.Limits[corev1.ResourceMemory]) {
+		annot.InitMemoryLimits[containerName] = memLimits.String()
+	}
+}
+
+// keepResource sets a given resource in new resource requirements back to its
+// original value
+func keepResource(newResources *corev1.ResourceRequirements, resources corev1.ResourceRequirements, name corev1.ResourceName) {
+	if quantity, ok := resources.Requests[name]; ok {
+		newResources.Requests[name] = quantity
+	}
+	if quantity, ok := resources.Limits[name]; ok {
+		newResources.Limits[name] = quantity
+	}
 }
 
 func resizeRequiresRestart(c corev1.Container, r corev1.ResourceName) bool {
@@ -19,9 +19,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strconv"
+	"sync/atomic"
+	"time"
 
+	"github.com/google/kube-startup-cpu-boost/internal/boost/duration"
 	bpod "github.com/google/kube-startup-cpu-boost/internal/boost/pod"
+	"github.com/google/kube-startup-cpu-boost/internal/boost/predictor"
 	"github.com/google/kube-startup-cpu-boost/internal/boost/resource"
 	"github.com/google/kube-startup-cpu-boost/internal/mock"
 	bwebhook "github.com/google/kube-startup-cpu-boost/internal/webhook"
@@ -30,10 +36,14 @@ import (
 	"go.uber.org/mock/gomock"
 	"gomodules.xyz/jsonpatch/v2"
 	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiResource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
@@ -44,6 +54,7 @@ var _ = Describe("Pod CPU Boost Webhook", func() {
 			mockCtrl     *gomock.Controller
 			manager      *mock.MockManager
 			managerCall  *gomock.Call
+			reader       *mock.MockClient
 			pod          *corev1.Pod
 			response     webhook.AdmissionResponse
 			removeLimits bool
@@ -52,6 +63,7 @@ var _ = Describe("Pod CPU Boost Webhook", func() {
 			pod = podTemplate.DeepCopy()
 			mockCtrl = gomock.NewController(GinkgoT())
 			manager = mock.NewMockManager(mockCtrl)
+			reader = mock.NewMockClient(mockCtrl)
 			managerCall = manager.EXPECT().StartupCPUBoostForPod(
 				gomock.Any(),
 				gomock.Cond(func(x any) bool {
@@ -73,7 +85,7 @@ var _ = Describe("Pod CPU Boost Webhook", func() {
 					},
 				},
 			}
-			hook := bwebhook.NewPodCPUBoostWebHook(manager, scheme.Scheme, removeLimits)
+			hook := bwebhook.NewPodCPUBoostWebHook(manager, scheme.Scheme, reader, removeLimits)
 			response = hook.Handle(context.TODO(), admissionReq)
 		})
 		When("there is no matching Startup CPU Boost", func() {
@@ -100,6 +112,7 @@ var _ = Describe("Pod CPU Boost Webhook", func() {
 				BeforeEach(func() {
 					boost = mock.NewMockStartupCPUBoost(mockCtrl)
 					boost.EXPECT().Name().AnyTimes().Return("boost-one")
+					boost.EXPECT().DurationPolicy().AnyTimes().Return(nil)
 					resPolicyCallOne = boost.EXPECT().ResourcePolicy(gomock.Eq(containerOneName)).Return(nil, false)
 					resPolicyCallTwo = boost.EXPECT().ResourcePolicy(gomock.Eq(containerTwoName)).Return(nil, false)
 					managerCall.Return(boost, true)
@@ -127,6 +140,7 @@ var _ = Describe("Pod CPU Boost Webhook", func() {
 					boost = mock.NewMockStartupCPUBoost(mockCtrl)
 					boostName = "boost-one"
 					boost.EXPECT().Name().AnyTimes().Return(boostName)
+					boost.EXPECT().DurationPolicy().AnyTimes().Return(nil)
 					resPolicy = resource.NewPercentageContainerPolicy(120)
 					resPolicyCallOne = boost.EXPECT().ResourcePolicy(gomock.Eq(containerOneName)).Return(resPolicy, true)
 					resPolicyCallTwo = boost.EXPECT().ResourcePolicy(gomock.Eq(containerTwoName)).Return(nil, false)
@@ -159,6 +173,7 @@ var _ = Describe("Pod CPU Boost Webhook", func() {
 						containerOneName,
 						pod.Spec.Containers[0].Resources.Limits.Cpu().String(),
 					))
+					Expect(annot.Policies).To(HaveKeyWithValue(containerOneName, "percentageIncrease"))
 				})
 				It("returns admission with container-one requests patch", func() {
 					patch := containerResourcePatch(pod, resPolicy, "requests", 0)
@@ -226,6 +241,160 @@ var _ = Describe("Pod CPU Boost Webhook", func() {
 					})
 				})
 			})
+			When("there is a CPU and memory policy for one container", func() {
+				var (
+					boost     *mock.MockStartupCPUBoost
+					resPolicy resource.ContainerPolicy
+				)
+				BeforeEach(func() {
+					boost = mock.NewMockStartupCPUBoost(mockCtrl)
+					boost.EXPECT().Name().AnyTimes().Return("boost-one")
+					boost.EXPECT().DurationPolicy().AnyTimes().Return(nil)
+					resPolicy = resource.NewMultiResourcePolicy(
+						resource.NewPercentageContainerPolicy(120),
+						resource.NewPercentageResourcePolicy(corev1.ResourceMemory, 50),
+					)
+					boost.EXPECT().ResourcePolicy(gomock.Eq(containerOneName)).Return(resPolicy, true)
+					boost.EXPECT().ResourcePolicy(gomock.Eq(containerTwoName)).Return(nil, false)
+					managerCall.Return(boost, true)
+					removeLimits = true
+					pod.Spec.Containers[0].Resources.Requests[corev1.ResourceMemory] = apiResource.MustParse("100Mi")
+					pod.Spec.Containers[0].Resources.Limits[corev1.ResourceMemory] = apiResource.MustParse("200Mi")
+				})
+				It("allows the admission", func() {
+					Expect(response.Allowed).To(BeTrue())
+				})
+				It("returns admission with boost annotation patch with memory resources", func() {
+					annotPatch, found := boostAnnotationPatch(response.Patches)
+					Expect(found).To(BeTrue())
+					annot, err := boostAnnotationFromPatch(annotPatch)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(annot.InitMemoryRequests).To(HaveKeyWithValue(containerOneName, "100Mi"))
+					Expect(annot.InitMemoryLimits).To(HaveKeyWithValue(containerOneName, "200Mi"))
+				})
+				It("returns admission with boost annotation patch with applied policies", func() {
+					annotPatch, found := boostAnnotationPatch(response.Patches)
+					Expect(found).To(BeTrue())
+					annot, err := boostAnnotationFromPatch(annotPatch)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(annot.Policies).To(HaveKeyWithValue(containerOneName,
+						"percentageIncrease,memoryPercentageIncrease"))
+				})
+				It("returns admission with container-one memory requests patch", func() {
+					Expect(response.Patches).To(ContainElement(jsonpatch.Operation{
+						Operation: "replace",
+						Path:      "/spec/containers/0/resources/requests/memory",
+						Value:     "150Mi",
+					}))
+				})
+				It("returns admission with container-one memory limits patch", func() {
+					Expect(response.Patches).To(ContainElement(jsonpatch.Operation{
+						Operation: "replace",
+						Path:      "/spec/containers/0/resources/limits/memory",
+						Value:     "300Mi",
+					}))
+				})
+				When("container has restart container memory resize policy", func() {
+					BeforeEach(func() {
+						pod.Spec.Containers[0].ResizePolicy = []corev1.ContainerResizePolicy{
+							{
+								ResourceName:  corev1.ResourceMemory,
+								RestartPolicy: corev1.RestartContainer,
+							},
+						}
+					})
+					It("returns admission with boost annotation patch without memory resources", func() {
+						annotPatch, found := boostAnnotationPatch(response.Patches)
+						Expect(found).To(BeTrue())
+						annot, err := boostAnnotationFromPatch(annotPatch)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(annot.InitCPURequests).To(HaveKey(containerOneName))
+						Expect(annot.InitMemoryRequests).NotTo(HaveKey(containerOneName))
+						Expect(annot.InitMemoryLimits).NotTo(HaveKey(containerOneName))
+					})
+					It("returns admission without container-one memory patches", func() {
+						for _, patch := range response.Patches {
+							Expect(patch.Path).NotTo(HaveSuffix("/memory"))
+						}
+					})
+				})
+			})
+			When("there is a policy for init containers", func() {
+				var (
+					boostName     string
+					boost         *mock.MockStartupCPUBoost
+					resPolicy     resource.ContainerPolicy
+					initName      string
+					sidecarName   string
+					restartAlways corev1.ContainerRestartPolicy
+				)
+				BeforeEach(func() {
+					boostName = "boost-one"
+					initName = "init"
+					sidecarName = "sidecar"
+					restartAlways = corev1.ContainerRestartPolicyAlways
+					pod.Spec.InitContainers = []corev1.Container{
+						*pod.Spec.Containers[0].DeepCopy(),
+						*pod.Spec.Containers[0].DeepCopy(),
+					}
+					pod.Spec.InitContainers[0].Name = initName
+					pod.Spec.InitContainers[1].Name = sidecarName
+					pod.Spec.InitContainers[1].RestartPolicy = &restartAlways
+					boost = mock.NewMockStartupCPUBoost(mockCtrl)
+					boost.EXPECT().Name().AnyTimes().Return(boostName)
+					boost.EXPECT().DurationPolicy().AnyTimes().Return(nil)
+					resPolicy = resource.NewPercentageContainerPolicy(120)
+					boost.EXPECT().ResourcePolicy(gomock.Eq(initName)).Return(resPolicy, true)
+					boost.EXPECT().ResourcePolicy(gomock.Eq(sidecarName)).AnyTimes().Return(resPolicy, true)
+					boost.EXPECT().ResourcePolicy(gomock.Eq(containerOneName)).Return(nil, false)
+					boost.EXPECT().ResourcePolicy(gomock.Eq(containerTwoName)).Return(nil, false)
+					managerCall.Return(boost, true)
+					removeLimits = false
+				})
+				It("allows the admission", func() {
+					Expect(response.Allowed).To(BeTrue())
+				})
+				It("returns admission with init container requests patch", func() {
+					Expect(response.Patches).To(ContainElement(jsonpatch.Operation{
+						Operation: "replace",
+						Path:      "/spec/initContainers/0/resources/requests/cpu",
+						Value:     "1100m",
+					}))
+				})
+				It("returns admission with sidecar container requests patch", func() {
+					Expect(response.Patches).To(ContainElement(jsonpatch.Operation{
+						Operation: "replace",
+						Path:      "/spec/initContainers/1/resources/requests/cpu",
+						Value:     "1100m",
+					}))
+				})
+				It("returns admission with boost label patch", func() {
+					Expect(response.Patches).To(ContainElement(boostLabelPatch(boostName)))
+				})
+				It("returns admission with boost annotation patch with sidecar container only", func() {
+					annotPatch, found := boostAnnotationPatch(response.Patches)
+					Expect(found).To(BeTrue())
+					annot, err := boostAnnotationFromPatch(annotPatch)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(annot.InitCPURequests).To(HaveKey(sidecarName))
+					Expect(annot.InitCPURequests).NotTo(HaveKey(initName))
+				})
+				When("only plain init container is boosted", func() {
+					BeforeEach(func() {
+						pod.Spec.InitContainers = pod.Spec.InitContainers[:1]
+					})
+					It("returns admission with init container requests patch", func() {
+						Expect(response.Patches).To(ContainElement(jsonpatch.Operation{
+							Operation: "replace",
+							Path:      "/spec/initContainers/0/resources/requests/cpu",
+							Value:     "1100m",
+						}))
+					})
+					It("returns admission without boost label patch", func() {
+						Expect(response.Patches).NotTo(ContainElement(boostLabelPatch(boostName)))
+					})
+				})
+			})
 			When("there is a policy for two containers", func() {
 				var (
 					resPolicyCallOne *gomock.Call
@@ -234,6 +403,7 @@ var _ = Describe("Pod CPU Boost Webhook", func() {
 				BeforeEach(func() {
 					boost := mock.NewMockStartupCPUBoost(mockCtrl)
 					boost.EXPECT().Name().AnyTimes().Return("boost-one")
+					boost.EXPECT().DurationPolicy().AnyTimes().Return(nil)
 					resPolicy := resource.NewPercentageContainerPolicy(120)
 					resPolicyCallOne = boost.EXPECT().ResourcePolicy(gomock.Eq(containerOneName)).Return(resPolicy, true)
 					resPolicyCallTwo = boost.EXPECT().ResourcePolicy(gomock.Eq(containerTwoName)).Return(resPolicy, true)
@@ -250,10 +420,228 @@ var _ = Describe("Pod CPU Boost Webhook", func() {
 					Expect(response.Patches).To(HaveLen(6))
 				})
 			})
+			When("there are auto policies for two containers and auto duration policy", func() {
+				var (
+					server   *httptest.Server
+					fallback time.Duration
+					calls    atomic.Int32
+					request  atomic.Pointer[predictor.PodRequest]
+					failed   bool
+				)
+				BeforeEach(func() {
+					calls.Store(0)
+					failed = false
+					server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						calls.Add(1)
+						if failed {
+							w.WriteHeader(http.StatusInternalServerError)
+							return
+						}
+						podRequest := &predictor.PodRequest{}
+						_ = json.NewDecoder(r.Body).Decode(podRequest)
+						request.Store(podRequest)
+						w.Header().Set("Content-Type", "application/json")
+						_ = json.NewEncoder(w).Encode(predictor.PodPrediction{
+							Containers: []predictor.ContainerPrediction{
+								{Name: containerOneName, CPURequests: "3", CPULimits: "4"},
+								{Name: containerTwoName, CPURequests: "5", CPULimits: "6"},
+							},
+							Duration: "10m",
+						})
+					}))
+					fallback = time.Minute
+					options := predictor.DefaultOptions()
+					options.MaxRetries = 0
+					durationPolicy := duration.NewAutoDurationPolicyWithFallback(
+						predictor.NewClient(server.URL, options), time.Now, fallback)
+					resPolicy := resource.NewAutoPolicyWithFallback(predictor.NewClient(server.URL, options),
+						resource.NewPercentageContainerPolicy(120))
+					boost := mock.NewMockStartupCPUBoost(mockCtrl)
+					boost.EXPECT().Name().AnyTimes().Return("boost-one")
+					boost.EXPECT().DurationPolicy().AnyTimes().Return(durationPolicy)
+					boost.EXPECT().ResourcePolicy(gomock.Eq(containerOneName)).Return(resPolicy, true)
+					boost.EXPECT().ResourcePolicy(gomock.Eq(containerTwoName)).Return(resPolicy, true)
+					managerCall.Return(boost, true)
+					removeLimits = false
+				})
+				AfterEach(func() {
+					server.Close()
+				})
+				It("calls the predictor once", func() {
+					Expect(calls.Load()).To(Equal(int32(1)))
+				})
+				It("sends all containers with their original resources", func() {
+					podRequest := request.Load()
+					Expect(podRequest).NotTo(BeNil())
+					Expect(podRequest.SchemaVersion).To(Equal(predictor.PodRequestSchemaVersion))
+					Expect(podRequest.BoostName).To(Equal("boost-one"))
+					Expect(podRequest.PodName).To(Equal(pod.Name))
+					Expect(podRequest.Owner).To(BeNil())
+					Expect(podRequest.Containers).To(HaveLen(2))
+					Expect(podRequest.Containers[0].Resources.Requests.Cpu().String()).To(Equal(containerOneCPUReq))
+					Expect(podRequest.Containers[1].Resources.Requests.Cpu().String()).To(Equal(containerTwoCPUReq))
+				})
+				It("returns admission with predicted containers requests patches", func() {
+					Expect(response.Patches).To(ContainElements(
+						jsonpatch.Operation{
+							Operation: "replace",
+							Path:      "/spec/containers/0/resources/requests/cpu",
+							Value:     "3",
+						},
+						jsonpatch.Operation{
+							Operation: "replace",
+							Path:      "/spec/containers/1/resources/requests/cpu",
+							Value:     "5",
+						},
+					))
+				})
+				It("returns admission with boost annotation patch with predicted deadline", func() {
+					annotPatch, found := boostAnnotationPatch(response.Patches)
+					Expect(found).To(BeTrue())
+					annot, err := boostAnnotationFromPatch(annotPatch)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(annot.DurationDeadline).NotTo(BeNil())
+					Expect(*annot.DurationDeadline).To(BeTemporally("~", time.Now().Add(10*time.Minute), 5*time.Second))
+				})
+				When("the pod is owned by the deployment replica set", func() {
+					BeforeEach(func() {
+						isController := true
+						pod.OwnerReferences = []metav1.OwnerReference{{
+							APIVersion: "apps/v1",
+							Kind:       "ReplicaSet",
+							Name:       "demo-5d8f",
+							Controller: &isController,
+						}}
+						reader.EXPECT().Get(gomock.Any(),
+							gomock.Eq(types.NamespacedName{Namespace: pod.Namespace, Name: "demo-5d8f"}),
+							gomock.AssignableToTypeOf(&appsv1.ReplicaSet{})).
+							DoAndReturn(func(ctx context.Context, key types.NamespacedName, obj client.Object, opts ...client.GetOption) error {
+								obj.SetOwnerReferences([]metav1.OwnerReference{{
+									APIVersion: "apps/v1",
+									Kind:       "Deployment",
+									Name:       "demo",
+									Controller: &isController,
+								}})
+								return nil
+							}).Times(1)
+					})
+					It("sends the deployment as the pod owner", func() {
+						podRequest := request.Load()
+						Expect(podRequest).NotTo(BeNil())
+						Expect(podRequest.Owner).To(Equal(&predictor.Owner{
+							APIVersion: "apps/v1",
+							Kind:       "Deployment",
+							Name:       "demo",
+						}))
+					})
+				})
+				When("the prediction fails", func() {
+					BeforeEach(func() {
+						failed = true
+					})
+					It("calls the predictor once", func() {
+						Expect(calls.Load()).To(Equal(int32(1)))
+					})
+					It("returns admission with boost annotation patch with fallback deadline", func() {
+						annotPatch, found := boostAnnotationPatch(response.Patches)
+						Expect(found).To(BeTrue())
+						annot, err := boostAnnotationFromPatch(annotPatch)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(annot.DurationDeadline).NotTo(BeNil())
+						Expect(*annot.DurationDeadline).To(BeTemporally("~", time.Now().Add(fallback), 5*time.Second))
+					})
+				})
+			})
+			When("there is a policy for two containers and learned duration policy", func() {
+				var history learnedDurations
+				BeforeEach(func() {
+					history = make(learnedDurations)
+					durationPolicy := duration.NewLearnedDurationPolicy(history, corev1.PodReady, 50,
+						10*time.Second, 3, time.Minute)
+					boost := mock.NewMockStartupCPUBoost(mockCtrl)
+					boost.EXPECT().Name().AnyTimes().Return("boost-one")
+					boost.EXPECT().DurationPolicy().AnyTimes().Return(durationPolicy)
+					resPolicy := resource.NewPercentageContainerPolicy(120)
+					boost.EXPECT().ResourcePolicy(gomock.Eq(containerOneName)).Return(resPolicy, true)
+					boost.EXPECT().ResourcePolicy(gomock.Eq(containerTwoName)).Return(resPolicy, true)
+					managerCall.Return(boost, true)
+				})
+				It("returns admission with boost annotation patch with workload key and fallback deadline", func() {
+					annotPatch, found := boostAnnotationPatch(response.Patches)
+					Expect(found).To(BeTrue())
+					annot, err := boostAnnotationFromPatch(annotPatch)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(annot.WorkloadKey).To(Equal(predictor.WorkloadKey(nil, pod)))
+					Expect(annot.DurationDeadline).NotTo(BeNil())
+					Expect(*annot.DurationDeadline).To(BeTemporally("~", time.Now().Add(time.Minute), 5*time.Second))
+				})
+				When("there are enough learned durations of the workload", func() {
+					BeforeEach(func() {
+						history[predictor.WorkloadKey(nil, pod)] = []time.Duration{
+							20 * time.Second, 40 * time.Second, 30 * time.Second,
+						}
+					})
+					It("returns admission with boost annotation patch with learned deadline", func() {
+						annotPatch, found := boostAnnotationPatch(response.Patches)
+						Expect(found).To(BeTrue())
+						annot, err := boostAnnotationFromPatch(annotPatch)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(annot.DurationDeadline).NotTo(BeNil())
+						Expect(*annot.DurationDeadline).To(BeTemporally("~", time.Now().Add(40*time.Second), 5*time.Second))
+					})
+				})
+			})
+			When("there is a learned resource policy for one container", func() {
+				BeforeEach(func() {
+					boost := mock.NewMockStartupCPUBoost(mockCtrl)
+					boost.EXPECT().Name().AnyTimes().Return("boost-one")
+					boost.EXPECT().DurationPolicy().AnyTimes().Return(nil)
+					resPolicy := resource.NewLearnedPolicy(nil, apiResource.MustParse("2"), apiResource.MustParse("4"),
+						3, false, nil)
+					boost.EXPECT().ResourcePolicy(gomock.Eq(containerOneName)).Return(resPolicy, true)
+					boost.EXPECT().ResourcePolicy(gomock.Eq(containerTwoName)).Return(nil, false)
+					managerCall.Return(boost, true)
+				})
+				It("returns admission with container requests and limits patches with the minimum", func() {
+					Expect(response.Patches).To(ContainElements(
+						jsonpatch.Operation{
+							Operation: "replace",
+							Path:      "/spec/containers/0/resources/requests/cpu",
+							Value:     "2",
+						},
+						jsonpatch.Operation{
+							Operation: "replace",
+							Path:      "/spec/containers/0/resources/limits/cpu",
+							Value:     "2",
+						},
+					))
+				})
+				It("returns admission with boost annotation patch with workload key", func() {
+					annotPatch, found := boostAnnotationPatch(response.Patches)
+					Expect(found).To(BeTrue())
+					annot, err := boostAnnotationFromPatch(annotPatch)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(annot.WorkloadKey).To(Equal(predictor.WorkloadKey(nil, pod)))
+					Expect(annot.Policies).To(HaveKeyWithValue(containerOneName, resource.LearnedPolicyName))
+					Expect(annot.DurationDeadline).To(BeNil())
+				})
+			})
 		})
 	})
 })
 
+// learnedDurations is the in-memory history of the learned durations
+type learnedDurations map[string][]time.Duration
+
+func (h learnedDurations) Durations(ctx context.Context, key string) ([]time.Duration, error) {
+	return h[key], nil
+}
+
+func (h learnedDurations) Add(ctx context.Context, key string, d time.Duration) error {
+	h[key] = append(h[key], d)
+	return nil
+}
+
 func boostAnnotationFromPatch(patch jsonpatch.Operation) (*bpod.BoostPodAnnotation, error) {
 	valueMap, ok := patch.Value.(map[string]interface{})
 	if !ok {
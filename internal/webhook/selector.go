@@ -0,0 +1,70 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// selectorsOverlap returns true if there may be a POD matched by both given label
+// selectors. The check is conservative: the selectors are considered overlapping
+// unless their requirements for a common label key contradict each other.
+func selectorsOverlap(a, b *metav1.LabelSelector) (bool, error) {
+	selectorA, err := metav1.LabelSelectorAsSelector(a)
+	if err != nil {
+		return false, err
+	}
+	selectorB, err := metav1.LabelSelectorAsSelector(b)
+	if err != nil {
+		return false, err
+	}
+	requirementsA, _ := selectorA.Requirements()
+	requirementsB, _ := selectorB.Requirements()
+	for i := range requirementsA {
+		for j := range requirementsB {
+			if requirementsA[i].Key() != requirementsB[j].Key() {
+				continue
+			}
+			if requirementContradicts(requirementsA[i], requirementsB[j]) ||
+				requirementContradicts(requirementsB[j], requirementsA[i]) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// requirementContradicts returns true if none of the label values satisfying
+// requirement a satisfies requirement b for the same label key
+func requirementContradicts(a, b labels.Requirement) bool {
+	switch a.Operator() {
+	case selection.DoesNotExist:
+		switch b.Operator() {
+		case selection.In, selection.Equals, selection.DoubleEquals, selection.Exists,
+			selection.GreaterThan, selection.LessThan:
+			return true
+		}
+	case selection.In, selection.Equals, selection.DoubleEquals:
+		switch b.Operator() {
+		case selection.In, selection.Equals, selection.DoubleEquals:
+			return !a.Values().HasAny(b.Values().UnsortedList()...)
+		case selection.NotIn, selection.NotEquals:
+			return b.Values().IsSuperset(a.Values())
+		}
+	}
+	return false
+}
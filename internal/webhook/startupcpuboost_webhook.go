@@ -17,26 +17,37 @@ package webhook
 import (
 	"context"
 	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/google/kube-startup-cpu-boost/api/v1alpha1"
+	"github.com/google/kube-startup-cpu-boost/internal/boost"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
-type StartupCPUBoostWebhook struct{}
+type StartupCPUBoostWebhook struct {
+	// Client is used to find the existing StartupCPUBoosts overlapping with
+	// the validated one. The overlap warnings are not returned when nil
+	Client client.Reader
+}
 
 var _ webhook.CustomValidator = &StartupCPUBoostWebhook{}
 
 func setupWebhookForStartupCPUBoost(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(&v1alpha1.StartupCPUBoost{}).
-		WithValidator(&StartupCPUBoostWebhook{}).
+		WithValidator(&StartupCPUBoostWebhook{Client: mgr.GetClient()}).
 		Complete()
 }
 
@@ -47,7 +58,10 @@ func (w *StartupCPUBoostWebhook) ValidateCreate(ctx context.Context, obj runtime
 	boost := obj.(*v1alpha1.StartupCPUBoost)
 	log := ctrl.LoggerFrom(ctx).WithName("boost-validate-webhook")
 	log.V(5).Info("handling create validation", "boos", klog.KObj(boost))
-	return nil, validate(boost)
+	if err := validate(boost); err != nil {
+		return nil, err
+	}
+	return w.overlapWarnings(ctx, boost), nil
 }
 
 // ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type
@@ -55,7 +69,10 @@ func (w *StartupCPUBoostWebhook) ValidateUpdate(ctx context.Context, oldObj, new
 	boost := newObj.(*v1alpha1.StartupCPUBoost)
 	log := ctrl.LoggerFrom(ctx).WithName("boost-validate-webhook")
 	log.V(5).Info("handling update validation", "startupcpuboost", klog.KObj(boost))
-	return nil, validate(boost)
+	if err := validate(boost); err != nil {
+		return nil, err
+	}
+	return w.overlapWarnings(ctx, boost), nil
 }
 
 // ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type
@@ -63,16 +80,69 @@ func (w *StartupCPUBoostWebhook) ValidateDelete(ctx context.Context, obj runtime
 	return nil, nil
 }
 
+// overlapWarnings returns the admission warnings for the existing StartupCPUBoosts
+// in the same namespace which selectors overlap with the selector of a given
+// StartupCPUBoost and have the same priority. For such, the precedence is
+// determined by the name order only.
+func (w *StartupCPUBoostWebhook) overlapWarnings(ctx context.Context, boost *v1alpha1.StartupCPUBoost) admission.Warnings {
+	if w.Client == nil {
+		return nil
+	}
+	log := ctrl.LoggerFrom(ctx).WithName("boost-validate-webhook")
+	boostList := &v1alpha1.StartupCPUBoostList{}
+	if err := w.Client.List(ctx, boostList, client.InNamespace(boost.Namespace)); err != nil {
+		log.Error(err, "failed to list startup-cpu-boosts, skipping overlap validation")
+		return nil
+	}
+	sort.Slice(boostList.Items, func(i, j int) bool {
+		return boostList.Items[i].Name < boostList.Items[j].Name
+	})
+	var warnings admission.Warnings
+	for i := range boostList.Items {
+		other := &boostList.Items[i]
+		if other.Name == boost.Name || other.Spec.Priority != boost.Spec.Priority {
+			continue
+		}
+		overlap, err := selectorsOverlap(&boost.Selector, &other.Selector)
+		if err != nil {
+			log.V(5).Info("skipping overlap validation due to invalid selector",
+				"other", other.Name, "error", err.Error())
+			continue
+		}
+		if overlap {
+			warnings = append(warnings, fmt.Sprintf(
+				"selector overlaps with StartupCPUBoost %q of the same priority %d, "+
+					"the one first in the name order takes precedence",
+				other.Name, other.Spec.Priority))
+		}
+	}
+	return warnings
+}
+
 // validate verifies if Startup CPU Boost is valid. This is programmatic
 // validation on a top of declarative API validation
 func validate(boost *v1alpha1.StartupCPUBoost) error {
 	var allErrs field.ErrorList
-	if errs := validateContainerPolicies(boost.Spec.ResourcePolicy.ContainerPolicies); len(errs) > 0 {
+	if errs := validateContainerPolicies(boost.Spec.ResourcePolicy.ContainerPolicies, boost.Namespace); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+	if errs := validateExcludedContainers(boost.Spec.ResourcePolicy.ExcludedContainers); len(errs) > 0 {
 		allErrs = append(allErrs, errs...)
 	}
 	if err := validateDurationPolicy(boost.Spec.DurationPolicy); err != nil {
 		allErrs = append(allErrs, err)
 	}
+	if autoPolicy := boost.Spec.DurationPolicy.AutoPolicy; autoPolicy != nil {
+		fldPath := field.NewPath("spec").Child("durationPolicy").Child("autoPolicy")
+		if err := validatePredictorTimeout(fldPath, autoPolicy.Timeout); err != nil {
+			allErrs = append(allErrs, err)
+		}
+		allErrs = append(allErrs, validatePredictorReferences(fldPath, boost.Namespace, autoPolicy.ApiEndpoint,
+			autoPolicy.Service, autoPolicy.SecretRef)...)
+	}
+	if errs := validatePhases(boost.Spec.Phases, boost.Spec.DurationPolicy); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
 	if len(allErrs) > 0 {
 		return apierrors.NewInvalid(
 			schema.GroupKind{Group: "autoscaling.x-k8s.io", Kind: "StartupCPUBoost"},
@@ -93,14 +163,60 @@ func validateDurationPolicy(policy v1alpha1.DurationPolicy) *field.Error {
 	if policy.AutoPolicy != nil {
 		cnt++
 	}
-	if cnt != 1 {
-		err := errors.New("one type of duration policy should be defined")
+	if policy.ContainerStatus != nil {
+		cnt++
+	}
+	if policy.Learned != nil {
+		cnt++
+	}
+	if cnt == 0 {
+		err := errors.New("at least one type of duration policy should be defined")
 		return field.Invalid(fldPath, policy, err.Error())
 	}
+	if policy.Learned != nil && policy.AutoPolicy != nil {
+		return field.Invalid(fldPath.Child("learned"), policy.Learned,
+			"learned duration policy is not supported with auto duration policy")
+	}
+	if learned := policy.Learned; learned != nil && learned.MinSamples > 0 && learned.MaxSamples > 0 &&
+		learned.MinSamples > learned.MaxSamples {
+		return field.Invalid(fldPath.Child("learned").Child("minSamples"), learned.MinSamples,
+			"min samples must not be greater than max samples")
+	}
 	return nil
 }
 
-func validateContainerPolicies(policies []v1alpha1.ContainerPolicy) field.ErrorList {
+func validatePhases(phases []v1alpha1.BoostPhase, durationPolicy v1alpha1.DurationPolicy) field.ErrorList {
+	var allErrs field.ErrorList
+	baseFldPath := field.NewPath("spec").Child("phases")
+	if len(phases) > 0 && durationPolicy.ContainerStatus != nil {
+		allErrs = append(allErrs, field.Invalid(baseFldPath, phases,
+			"boost phases are not supported with container status duration policy"))
+	}
+	for i := range phases {
+		fldPath := baseFldPath.Index(i).Child("durationPolicy")
+		policy := phases[i].DurationPolicy
+		if policy.Fixed == nil && policy.PodCondition == nil && policy.AutoPolicy == nil &&
+			policy.ContainerStatus == nil && policy.Learned == nil {
+			allErrs = append(allErrs, field.Invalid(fldPath, policy,
+				"at least one type of duration policy should be defined"))
+		}
+		if policy.AutoPolicy != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("autoPolicy"), policy.AutoPolicy,
+				"auto duration policy is not supported in boost phases"))
+		}
+		if policy.ContainerStatus != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("containerStatus"), policy.ContainerStatus,
+				"container status duration policy is not supported in boost phases"))
+		}
+		if policy.Learned != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("learned"), policy.Learned,
+				"learned duration policy is not supported in boost phases"))
+		}
+	}
+	return allErrs
+}
+
+func validateContainerPolicies(policies []v1alpha1.ContainerPolicy, namespace string) field.ErrorList {
 	var allErrs field.ErrorList
 	baseFldPath := field.NewPath("spec").
 		Child("resourcePolicy").
@@ -117,12 +233,142 @@ func validateContainerPolicies(policies []v1alpha1.ContainerPolicy) field.ErrorL
 		if policies[i].AutoPolicy != nil {
 			cnt++
 		}
+		if policies[i].LearnedResources != nil {
+			cnt++
+		}
 		if cnt != 1 {
 			allErrs = append(allErrs, field.Invalid(fldPath,
 				policies[i],
 				"one type of resource policy should be defined",
 			))
 		}
+		if policies[i].MemoryFixedResources != nil && policies[i].MemoryPercentageIncrease != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath,
+				policies[i],
+				"at most one type of memory resource policy should be defined",
+			))
+		}
+		if err := validateContainerName(fldPath, policies[i]); err != nil {
+			allErrs = append(allErrs, err)
+		}
+		if autoPolicy := policies[i].AutoPolicy; autoPolicy != nil {
+			if err := validatePredictorTimeout(fldPath.Child("autoPolicy"), autoPolicy.Timeout); err != nil {
+				allErrs = append(allErrs, err)
+			}
+			if err := validateFallbackPolicy(fldPath.Child("autoPolicy"), autoPolicy.Fallback); err != nil {
+				allErrs = append(allErrs, err)
+			}
+			allErrs = append(allErrs, validatePredictorReferences(fldPath.Child("autoPolicy"), namespace,
+				autoPolicy.ApiEndpoint, autoPolicy.Service, autoPolicy.SecretRef)...)
+		}
+		if err := validateLearnedResourcePolicy(fldPath.Child("learnedResources"), policies[i].LearnedResources); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	return allErrs
+}
+
+// validateLearnedResourcePolicy verifies if the CPU targets of a learned resource policy,
+// if set, are positive and the minimum is not greater than the maximum, and if the
+// minimum number of samples is not greater than the maximum
+func validateLearnedResourcePolicy(fldPath *field.Path, policy *v1alpha1.LearnedResourcePolicy) *field.Error {
+	switch {
+	case policy == nil:
+		return nil
+	case policy.Min.Sign() <= 0:
+		return field.Invalid(fldPath.Child("min"), policy.Min.String(), "min should be positive")
+	case policy.Min.Cmp(policy.Max) > 0:
+		return field.Invalid(fldPath.Child("max"), policy.Max.String(), "max must not be lower than min")
+	case policy.MinSamples > 0 && policy.MaxSamples > 0 && policy.MinSamples > policy.MaxSamples:
+		return field.Invalid(fldPath.Child("minSamples"), policy.MinSamples,
+			"min samples must not be greater than max samples")
+	}
+	return nil
+}
+
+// validateFallbackPolicy verifies if the fallback policy of an auto resource policy,
+// if set, defines exactly one type of resource policy
+func validateFallbackPolicy(fldPath *field.Path, fallback *v1alpha1.AutoResourceFallbackPolicy) *field.Error {
+	if fallback == nil {
+		return nil
+	}
+	if (fallback.PercentageIncrease == nil) == (fallback.FixedResources == nil) {
+		return field.Invalid(fldPath.Child("fallback"), fallback,
+			"one of percentageIncrease or fixedResources should be defined")
+	}
+	return nil
+}
+
+// validatePredictorTimeout verifies if the predictor call timeout of an auto policy,
+// if set, is positive
+func validatePredictorTimeout(fldPath *field.Path, timeout *metav1.Duration) *field.Error {
+	if timeout != nil && timeout.Duration <= 0 {
+		return field.Invalid(fldPath.Child("timeout"), timeout.Duration.String(),
+			"timeout should be positive")
+	}
+	return nil
+}
+
+// validatePredictorReferences verifies if the predictor of an auto policy is referenced
+// either by the endpoint or by the Service, and if the credentials Secret, if set, is
+// in a given namespace of the StartupCPUBoost and is not sent in plaintext. The
+// Service may be in any namespace, while the Secret is read on behalf of the boost
+// owner, so the Secrets of other namespaces are not allowed.
+func validatePredictorReferences(fldPath *field.Path, namespace, apiEndpoint string,
+	service *v1alpha1.PredictorServiceReference, secretRef *v1alpha1.PredictorSecretReference) field.ErrorList {
+	var allErrs field.ErrorList
+	if service != nil && apiEndpoint != "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("service"), service.Name,
+			"service should not be defined together with apiEndpoint"))
+	}
+	if secretRef == nil {
+		return allErrs
+	}
+	if secretRef.Namespace != "" && secretRef.Namespace != namespace {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("secretRef").Child("namespace"),
+			"secret should be in the namespace of the StartupCPUBoost"))
+	}
+	if service == nil && isPlaintextEndpoint(apiEndpoint) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("apiEndpoint"), apiEndpoint,
+			"apiEndpoint should use https or grpcs when secretRef is defined"))
+	}
+	return allErrs
+}
+
+// isPlaintextEndpoint returns true if a given predictor endpoint is called without TLS
+func isPlaintextEndpoint(endpoint string) bool {
+	endpoint = strings.ToLower(endpoint)
+	return strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "grpc://")
+}
+
+func validateContainerName(fldPath *field.Path, policy v1alpha1.ContainerPolicy) *field.Error {
+	if (policy.ContainerName == "") == (policy.ContainerNameRegex == "") {
+		return field.Invalid(fldPath, policy,
+			"one of containerName or containerNameRegex should be defined")
+	}
+	if policy.ContainerNameRegex != "" {
+		if _, err := regexp.Compile(policy.ContainerNameRegex); err != nil {
+			return field.Invalid(fldPath.Child("containerNameRegex"),
+				policy.ContainerNameRegex, err.Error())
+		}
+		return nil
+	}
+	if err := boost.ValidateContainerNameGlob(policy.ContainerName); err != nil {
+		return field.Invalid(fldPath.Child("containerName"),
+			policy.ContainerName, err.Error())
+	}
+	return nil
+}
+
+func validateExcludedContainers(names []string) field.ErrorList {
+	var allErrs field.ErrorList
+	baseFldPath := field.NewPath("spec").
+		Child("resourcePolicy").
+		Child("excludedContainers")
+	for i := range names {
+		if err := boost.ValidateContainerNameGlob(names[i]); err != nil {
+			allErrs = append(allErrs, field.Invalid(baseFldPath.Index(i), names[i], err.Error()))
+		}
 	}
 	return allErrs
 }
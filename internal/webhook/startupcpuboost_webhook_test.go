@@ -16,11 +16,18 @@ package webhook_test
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/kube-startup-cpu-boost/api/v1alpha1"
+	"github.com/google/kube-startup-cpu-boost/internal/mock"
 	"github.com/google/kube-startup-cpu-boost/internal/webhook"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	apiResource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 var _ = Describe("StartupCPUBoost webhook", func() {
@@ -59,18 +66,36 @@ var _ = Describe("StartupCPUBoost webhook", func() {
 						DurationPolicy: v1alpha1.DurationPolicy{
 							Fixed:        &v1alpha1.FixedDurationPolicy{},
 							PodCondition: &v1alpha1.PodConditionDurationPolicy{},
+							Operator:     v1alpha1.DurationPolicyOperatorAll,
 						},
 					},
 				}
 			})
-			It("errors", func() {
+			It("does not error", func() {
 				By("validating create event")
 				_, err = w.ValidateCreate(context.TODO(), &boost)
-				Expect(err).To(HaveOccurred())
+				Expect(err).NotTo(HaveOccurred())
 
 				By("validating update event")
 				_, err = w.ValidateUpdate(context.TODO(), nil, &boost)
-				Expect(err).To(HaveOccurred())
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+		When("Startup CPU Boost has container status duration policy", func() {
+			BeforeEach(func() {
+				boost = v1alpha1.StartupCPUBoost{
+					Spec: v1alpha1.StartupCPUBoostSpec{
+						DurationPolicy: v1alpha1.DurationPolicy{
+							ContainerStatus: &v1alpha1.ContainerStatusDurationPolicy{
+								Condition: v1alpha1.ContainerStatusConditionReady,
+							},
+						},
+					},
+				}
+			})
+			It("does not error", func() {
+				_, err = w.ValidateCreate(context.TODO(), &boost)
+				Expect(err).NotTo(HaveOccurred())
 			})
 		})
 		When("Startup CPU Boost has one duration policy", func() {
@@ -93,6 +118,166 @@ var _ = Describe("StartupCPUBoost webhook", func() {
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
+		When("Startup CPU Boost has boost phases", func() {
+			BeforeEach(func() {
+				boost = v1alpha1.StartupCPUBoost{
+					Spec: v1alpha1.StartupCPUBoostSpec{
+						DurationPolicy: v1alpha1.DurationPolicy{
+							Fixed: &v1alpha1.FixedDurationPolicy{},
+						},
+						Phases: []v1alpha1.BoostPhase{
+							{
+								DurationPolicy: v1alpha1.DurationPolicy{
+									PodCondition: &v1alpha1.PodConditionDurationPolicy{},
+								},
+							},
+						},
+					},
+				}
+			})
+			It("does not error", func() {
+				_, err = w.ValidateCreate(context.TODO(), &boost)
+				Expect(err).NotTo(HaveOccurred())
+			})
+			When("boost phase has no duration policy", func() {
+				BeforeEach(func() {
+					boost.Spec.Phases[0].DurationPolicy = v1alpha1.DurationPolicy{}
+				})
+				It("errors", func() {
+					_, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).To(HaveOccurred())
+				})
+			})
+			When("boost has container status duration policy", func() {
+				BeforeEach(func() {
+					boost.Spec.DurationPolicy.ContainerStatus = &v1alpha1.ContainerStatusDurationPolicy{
+						Condition: v1alpha1.ContainerStatusConditionStarted,
+					}
+				})
+				It("errors", func() {
+					_, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).To(HaveOccurred())
+				})
+			})
+			When("boost phase has auto duration policy", func() {
+				BeforeEach(func() {
+					boost.Spec.Phases[0].DurationPolicy.AutoPolicy = &v1alpha1.AutoDurationPolicy{}
+				})
+				It("errors", func() {
+					_, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).To(HaveOccurred())
+				})
+			})
+			When("boost phase has learned duration policy", func() {
+				BeforeEach(func() {
+					boost.Spec.Phases[0].DurationPolicy.Learned = &v1alpha1.LearnedDurationPolicy{}
+				})
+				It("errors", func() {
+					_, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+		When("Startup CPU Boost has learned duration policy", func() {
+			BeforeEach(func() {
+				boost = v1alpha1.StartupCPUBoost{
+					Spec: v1alpha1.StartupCPUBoostSpec{
+						DurationPolicy: v1alpha1.DurationPolicy{
+							Learned: &v1alpha1.LearnedDurationPolicy{
+								MinSamples: 5,
+								MaxSamples: 50,
+								Fallback: v1alpha1.FixedDurationPolicy{
+									Unit:  v1alpha1.FixedDurationPolicyUnitSec,
+									Value: 30,
+								},
+							},
+						},
+					},
+				}
+			})
+			It("does not error", func() {
+				_, err = w.ValidateCreate(context.TODO(), &boost)
+				Expect(err).NotTo(HaveOccurred())
+			})
+			When("the boost has auto duration policy", func() {
+				BeforeEach(func() {
+					boost.Spec.DurationPolicy.AutoPolicy = &v1alpha1.AutoDurationPolicy{
+						ApiEndpoint: "http://predictor.local",
+					}
+				})
+				It("errors", func() {
+					_, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).To(HaveOccurred())
+				})
+			})
+			When("the min samples are greater than the max samples", func() {
+				BeforeEach(func() {
+					boost.Spec.DurationPolicy.Learned.MinSamples = 60
+				})
+				It("errors", func() {
+					_, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+		When("Startup CPU Boost has learned resource policy", func() {
+			BeforeEach(func() {
+				boost = v1alpha1.StartupCPUBoost{
+					Spec: v1alpha1.StartupCPUBoostSpec{
+						ResourcePolicy: v1alpha1.ResourcePolicy{
+							ContainerPolicies: []v1alpha1.ContainerPolicy{
+								{
+									ContainerName: "container-one",
+									LearnedResources: &v1alpha1.LearnedResourcePolicy{
+										Min: apiResource.MustParse("1"),
+										Max: apiResource.MustParse("4"),
+									},
+								},
+							},
+						},
+						DurationPolicy: v1alpha1.DurationPolicy{
+							Fixed: &v1alpha1.FixedDurationPolicy{
+								Unit:  v1alpha1.FixedDurationPolicyUnitSec,
+								Value: 30,
+							},
+						},
+					},
+				}
+			})
+			It("does not error", func() {
+				_, err = w.ValidateCreate(context.TODO(), &boost)
+				Expect(err).NotTo(HaveOccurred())
+			})
+			When("the min is greater than the max", func() {
+				BeforeEach(func() {
+					boost.Spec.ResourcePolicy.ContainerPolicies[0].LearnedResources.Min = apiResource.MustParse("5")
+				})
+				It("errors", func() {
+					_, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).To(HaveOccurred())
+				})
+			})
+			When("the min is zero", func() {
+				BeforeEach(func() {
+					boost.Spec.ResourcePolicy.ContainerPolicies[0].LearnedResources.Min = apiResource.MustParse("0")
+				})
+				It("errors", func() {
+					_, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).To(HaveOccurred())
+				})
+			})
+			When("the container has other resource policy", func() {
+				BeforeEach(func() {
+					boost.Spec.ResourcePolicy.ContainerPolicies[0].PercentageIncrease = &v1alpha1.PercentageIncrease{
+						Value: 50,
+					}
+				})
+				It("errors", func() {
+					_, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
 		When("Startup CPU Boost has container without resource policies", func() {
 			BeforeEach(func() {
 				boost = v1alpha1.StartupCPUBoost{
@@ -149,6 +334,385 @@ var _ = Describe("StartupCPUBoost webhook", func() {
 				Expect(err).To(HaveOccurred())
 			})
 		})
+		When("Startup CPU Boost has container with two memory resource policies", func() {
+			BeforeEach(func() {
+				boost = v1alpha1.StartupCPUBoost{
+					Spec: v1alpha1.StartupCPUBoostSpec{
+						ResourcePolicy: v1alpha1.ResourcePolicy{
+							ContainerPolicies: []v1alpha1.ContainerPolicy{
+								{
+									ContainerName:            "container-one",
+									PercentageIncrease:       &v1alpha1.PercentageIncrease{},
+									MemoryFixedResources:     &v1alpha1.FixedResources{},
+									MemoryPercentageIncrease: &v1alpha1.PercentageIncrease{},
+								},
+							},
+						},
+						DurationPolicy: v1alpha1.DurationPolicy{
+							PodCondition: &v1alpha1.PodConditionDurationPolicy{},
+						},
+					},
+				}
+			})
+			It("errors", func() {
+				By("validating create event")
+				_, err = w.ValidateCreate(context.TODO(), &boost)
+				Expect(err).To(HaveOccurred())
+
+				By("validating update event")
+				_, err = w.ValidateUpdate(context.TODO(), nil, &boost)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		When("Startup CPU Boost has container with name and name regex", func() {
+			BeforeEach(func() {
+				boost = v1alpha1.StartupCPUBoost{
+					Spec: v1alpha1.StartupCPUBoostSpec{
+						ResourcePolicy: v1alpha1.ResourcePolicy{
+							ContainerPolicies: []v1alpha1.ContainerPolicy{
+								{
+									ContainerName:      "container-one",
+									ContainerNameRegex: "container-.*",
+									PercentageIncrease: &v1alpha1.PercentageIncrease{},
+								},
+							},
+						},
+						DurationPolicy: v1alpha1.DurationPolicy{
+							PodCondition: &v1alpha1.PodConditionDurationPolicy{},
+						},
+					},
+				}
+			})
+			It("errors", func() {
+				By("validating create event")
+				_, err = w.ValidateCreate(context.TODO(), &boost)
+				Expect(err).To(HaveOccurred())
+
+				By("validating update event")
+				_, err = w.ValidateUpdate(context.TODO(), nil, &boost)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		When("Startup CPU Boost has container with invalid name regex", func() {
+			BeforeEach(func() {
+				boost = v1alpha1.StartupCPUBoost{
+					Spec: v1alpha1.StartupCPUBoostSpec{
+						ResourcePolicy: v1alpha1.ResourcePolicy{
+							ContainerPolicies: []v1alpha1.ContainerPolicy{
+								{
+									ContainerNameRegex: "container-[",
+									PercentageIncrease: &v1alpha1.PercentageIncrease{},
+								},
+							},
+						},
+						DurationPolicy: v1alpha1.DurationPolicy{
+							PodCondition: &v1alpha1.PodConditionDurationPolicy{},
+						},
+					},
+				}
+			})
+			It("errors", func() {
+				By("validating create event")
+				_, err = w.ValidateCreate(context.TODO(), &boost)
+				Expect(err).To(HaveOccurred())
+
+				By("validating update event")
+				_, err = w.ValidateUpdate(context.TODO(), nil, &boost)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		When("Startup CPU Boost has container with invalid name pattern", func() {
+			BeforeEach(func() {
+				boost = v1alpha1.StartupCPUBoost{
+					Spec: v1alpha1.StartupCPUBoostSpec{
+						ResourcePolicy: v1alpha1.ResourcePolicy{
+							ContainerPolicies: []v1alpha1.ContainerPolicy{
+								{
+									ContainerName:      "container-[",
+									PercentageIncrease: &v1alpha1.PercentageIncrease{},
+								},
+							},
+						},
+						DurationPolicy: v1alpha1.DurationPolicy{
+							PodCondition: &v1alpha1.PodConditionDurationPolicy{},
+						},
+					},
+				}
+			})
+			It("errors", func() {
+				By("validating create event")
+				_, err = w.ValidateCreate(context.TODO(), &boost)
+				Expect(err).To(HaveOccurred())
+
+				By("validating update event")
+				_, err = w.ValidateUpdate(context.TODO(), nil, &boost)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		When("Startup CPU Boost has invalid excluded container pattern", func() {
+			BeforeEach(func() {
+				boost = v1alpha1.StartupCPUBoost{
+					Spec: v1alpha1.StartupCPUBoostSpec{
+						ResourcePolicy: v1alpha1.ResourcePolicy{
+							ContainerPolicies: []v1alpha1.ContainerPolicy{
+								{
+									ContainerName:      "*",
+									PercentageIncrease: &v1alpha1.PercentageIncrease{},
+								},
+							},
+							ExcludedContainers: []string{"istio-["},
+						},
+						DurationPolicy: v1alpha1.DurationPolicy{
+							PodCondition: &v1alpha1.PodConditionDurationPolicy{},
+						},
+					},
+				}
+			})
+			It("errors", func() {
+				By("validating create event")
+				_, err = w.ValidateCreate(context.TODO(), &boost)
+				Expect(err).To(HaveOccurred())
+
+				By("validating update event")
+				_, err = w.ValidateUpdate(context.TODO(), nil, &boost)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		When("Startup CPU Boost has container with name regex and excluded containers", func() {
+			BeforeEach(func() {
+				boost = v1alpha1.StartupCPUBoost{
+					Spec: v1alpha1.StartupCPUBoostSpec{
+						ResourcePolicy: v1alpha1.ResourcePolicy{
+							ContainerPolicies: []v1alpha1.ContainerPolicy{
+								{
+									ContainerNameRegex: "container-.*",
+									PercentageIncrease: &v1alpha1.PercentageIncrease{},
+								},
+							},
+							ExcludedContainers: []string{"istio-*"},
+						},
+						DurationPolicy: v1alpha1.DurationPolicy{
+							PodCondition: &v1alpha1.PodConditionDurationPolicy{},
+						},
+					},
+				}
+			})
+			It("does not error", func() {
+				By("validating create event")
+				_, err = w.ValidateCreate(context.TODO(), &boost)
+				Expect(err).NotTo(HaveOccurred())
+
+				By("validating update event")
+				_, err = w.ValidateUpdate(context.TODO(), nil, &boost)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+		When("Startup CPU Boost has auto policies with predictor timeouts", func() {
+			var timeout time.Duration
+			BeforeEach(func() {
+				timeout = time.Second
+			})
+			JustBeforeEach(func() {
+				boost = v1alpha1.StartupCPUBoost{
+					Spec: v1alpha1.StartupCPUBoostSpec{
+						ResourcePolicy: v1alpha1.ResourcePolicy{
+							ContainerPolicies: []v1alpha1.ContainerPolicy{
+								{
+									ContainerName: "container-one",
+									AutoPolicy: &v1alpha1.AutoResourcePolicy{
+										Timeout: &metav1.Duration{Duration: timeout},
+									},
+								},
+							},
+						},
+						DurationPolicy: v1alpha1.DurationPolicy{
+							AutoPolicy: &v1alpha1.AutoDurationPolicy{
+								Timeout: &metav1.Duration{Duration: timeout},
+							},
+						},
+					},
+				}
+			})
+			It("does not error", func() {
+				_, err = w.ValidateCreate(context.TODO(), &boost)
+				Expect(err).NotTo(HaveOccurred())
+			})
+			When("the timeouts are not positive", func() {
+				BeforeEach(func() {
+					timeout = 0
+				})
+				It("errors", func() {
+					_, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("spec.durationPolicy.autoPolicy.timeout"))
+					Expect(err.Error()).To(ContainSubstring("spec.resourcePolicy.containerPolicies[0].autoPolicy.timeout"))
+				})
+			})
+		})
+		When("Startup CPU Boost has auto resource policy with fallback policy", func() {
+			var fallback *v1alpha1.AutoResourceFallbackPolicy
+			BeforeEach(func() {
+				fallback = &v1alpha1.AutoResourceFallbackPolicy{
+					PercentageIncrease: &v1alpha1.PercentageIncrease{Value: 100},
+				}
+			})
+			JustBeforeEach(func() {
+				boost = v1alpha1.StartupCPUBoost{
+					Spec: v1alpha1.StartupCPUBoostSpec{
+						ResourcePolicy: v1alpha1.ResourcePolicy{
+							ContainerPolicies: []v1alpha1.ContainerPolicy{
+								{
+									ContainerName: "container-one",
+									AutoPolicy: &v1alpha1.AutoResourcePolicy{
+										Fallback: fallback,
+									},
+								},
+							},
+						},
+						DurationPolicy: v1alpha1.DurationPolicy{
+							PodCondition: &v1alpha1.PodConditionDurationPolicy{},
+						},
+					},
+				}
+			})
+			It("does not error", func() {
+				_, err = w.ValidateCreate(context.TODO(), &boost)
+				Expect(err).NotTo(HaveOccurred())
+			})
+			When("the fallback policy has two types of resource policies", func() {
+				BeforeEach(func() {
+					fallback.FixedResources = &v1alpha1.FixedResources{}
+				})
+				It("errors", func() {
+					_, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("spec.resourcePolicy.containerPolicies[0].autoPolicy.fallback"))
+				})
+			})
+			When("the fallback policy has no resource policy", func() {
+				BeforeEach(func() {
+					fallback = &v1alpha1.AutoResourceFallbackPolicy{}
+				})
+				It("errors", func() {
+					_, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+		When("Startup CPU Boost has auto policies with predictor references", func() {
+			var (
+				apiEndpoint string
+				service     *v1alpha1.PredictorServiceReference
+				secretRef   *v1alpha1.PredictorSecretReference
+			)
+			BeforeEach(func() {
+				apiEndpoint = ""
+				service = &v1alpha1.PredictorServiceReference{
+					Namespace: "predictor-ns",
+					Name:      "predictor",
+				}
+				secretRef = &v1alpha1.PredictorSecretReference{
+					Name: "predictor-credentials",
+				}
+			})
+			JustBeforeEach(func() {
+				boost = v1alpha1.StartupCPUBoost{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "demo-ns",
+						Name:      "boost-001",
+					},
+					Spec: v1alpha1.StartupCPUBoostSpec{
+						ResourcePolicy: v1alpha1.ResourcePolicy{
+							ContainerPolicies: []v1alpha1.ContainerPolicy{
+								{
+									ContainerName: "container-one",
+									AutoPolicy: &v1alpha1.AutoResourcePolicy{
+										ApiEndpoint: apiEndpoint,
+										Service:     service,
+										SecretRef:   secretRef,
+									},
+								},
+							},
+						},
+						DurationPolicy: v1alpha1.DurationPolicy{
+							AutoPolicy: &v1alpha1.AutoDurationPolicy{
+								ApiEndpoint: apiEndpoint,
+								Service:     service,
+								SecretRef:   secretRef,
+							},
+						},
+					},
+				}
+			})
+			It("does not error", func() {
+				_, err = w.ValidateCreate(context.TODO(), &boost)
+				Expect(err).NotTo(HaveOccurred())
+			})
+			When("the secret is in the namespace of the boost", func() {
+				BeforeEach(func() {
+					secretRef.Namespace = "demo-ns"
+				})
+				It("does not error", func() {
+					_, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+			When("the secret is in other namespace", func() {
+				BeforeEach(func() {
+					secretRef.Namespace = "predictor-ns"
+				})
+				It("errors", func() {
+					_, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("spec.durationPolicy.autoPolicy.secretRef.namespace"))
+					Expect(err.Error()).To(ContainSubstring("spec.resourcePolicy.containerPolicies[0].autoPolicy.secretRef.namespace"))
+				})
+			})
+			When("both the endpoint and the service are set", func() {
+				BeforeEach(func() {
+					apiEndpoint = "https://predictor.local"
+				})
+				It("errors", func() {
+					_, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("spec.durationPolicy.autoPolicy.service"))
+					Expect(err.Error()).To(ContainSubstring("spec.resourcePolicy.containerPolicies[0].autoPolicy.service"))
+				})
+			})
+			When("the secret is used with the plain HTTP endpoint", func() {
+				BeforeEach(func() {
+					service = nil
+					apiEndpoint = "http://predictor.local"
+				})
+				It("errors", func() {
+					_, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("spec.durationPolicy.autoPolicy.apiEndpoint"))
+					Expect(err.Error()).To(ContainSubstring("spec.resourcePolicy.containerPolicies[0].autoPolicy.apiEndpoint"))
+				})
+			})
+			When("the secret is used with the plaintext gRPC endpoint", func() {
+				BeforeEach(func() {
+					service = nil
+					apiEndpoint = "grpc://predictor.local:9090"
+				})
+				It("errors", func() {
+					_, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("spec.durationPolicy.autoPolicy.apiEndpoint"))
+				})
+			})
+			When("the secret is used with the HTTPS endpoint", func() {
+				BeforeEach(func() {
+					service = nil
+					apiEndpoint = "https://predictor.local"
+				})
+				It("does not error", func() {
+					_, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+		})
 		When("Startup CPU Boost has container with one resource policies", func() {
 			BeforeEach(func() {
 				boost = v1alpha1.StartupCPUBoost{
@@ -177,5 +741,110 @@ var _ = Describe("StartupCPUBoost webhook", func() {
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
+		When("other Startup CPU Boosts exist in the namespace", func() {
+			var (
+				mockCtrl   *gomock.Controller
+				mockClient *mock.MockClient
+				existing   []v1alpha1.StartupCPUBoost
+				warnings   admission.Warnings
+			)
+			BeforeEach(func() {
+				boost = v1alpha1.StartupCPUBoost{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "boost-new",
+						Namespace: "demo",
+					},
+					Selector: metav1.LabelSelector{
+						MatchLabels: map[string]string{"app": "demo"},
+					},
+					Spec: v1alpha1.StartupCPUBoostSpec{
+						ResourcePolicy: v1alpha1.ResourcePolicy{
+							ContainerPolicies: []v1alpha1.ContainerPolicy{
+								{
+									ContainerName:      "container-one",
+									PercentageIncrease: &v1alpha1.PercentageIncrease{},
+								},
+							},
+						},
+						DurationPolicy: v1alpha1.DurationPolicy{
+							PodCondition: &v1alpha1.PodConditionDurationPolicy{},
+						},
+					},
+				}
+				existing = []v1alpha1.StartupCPUBoost{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "boost-overlapping", Namespace: "demo"},
+						Selector: metav1.LabelSelector{
+							MatchExpressions: []metav1.LabelSelectorRequirement{
+								{
+									Key:      "app",
+									Operator: metav1.LabelSelectorOpIn,
+									Values:   []string{"demo", "other"},
+								},
+							},
+						},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "boost-disjoint", Namespace: "demo"},
+						Selector: metav1.LabelSelector{
+							MatchLabels: map[string]string{"app": "other"},
+						},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "boost-everything", Namespace: "demo"},
+						Spec: v1alpha1.StartupCPUBoostSpec{
+							Priority: 10,
+						},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "boost-new", Namespace: "demo"},
+						Selector:   boost.Selector,
+					},
+				}
+				mockCtrl = gomock.NewController(GinkgoT())
+				mockClient = mock.NewMockClient(mockCtrl)
+				mockClient.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&v1alpha1.StartupCPUBoostList{}),
+					gomock.Eq(client.InNamespace("demo"))).
+					DoAndReturn(func(ctx context.Context, list *v1alpha1.StartupCPUBoostList, opts ...client.ListOption) error {
+						list.Items = existing
+						return nil
+					}).AnyTimes()
+				w.Client = mockClient
+			})
+			It("warns about overlapping boosts with the same priority", func() {
+				By("validating create event")
+				warnings, err = w.ValidateCreate(context.TODO(), &boost)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(warnings).To(HaveLen(1))
+				Expect(warnings[0]).To(ContainSubstring("boost-overlapping"))
+
+				By("validating update event")
+				warnings, err = w.ValidateUpdate(context.TODO(), nil, &boost)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(warnings).To(HaveLen(1))
+				Expect(warnings[0]).To(ContainSubstring("boost-overlapping"))
+			})
+			When("the boost has the priority of other overlapping boost", func() {
+				BeforeEach(func() {
+					boost.Spec.Priority = 10
+				})
+				It("warns about overlapping boosts with the same priority", func() {
+					warnings, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(warnings).To(HaveLen(1))
+					Expect(warnings[0]).To(ContainSubstring("boost-everything"))
+				})
+			})
+			When("the boost has unique priority", func() {
+				BeforeEach(func() {
+					boost.Spec.Priority = 5
+				})
+				It("does not warn", func() {
+					warnings, err = w.ValidateCreate(context.TODO(), &boost)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(warnings).To(BeEmpty())
+				})
+			})
+		})
 	})
 })